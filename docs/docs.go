@@ -15,21 +15,41 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/products": {
-            "get": {
-                "description": "Retorna todos os produtos cadastrados",
+        "/allocations": {
+            "post": {
+                "description": "Propõe de quais galpões cada linha do pedido deve sair, considerando saldo disponível, distância e prioridade. Opcionalmente reserva o estoque escolhido",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "allocations"
+                ],
+                "summary": "Propor alocação de pedido",
+                "parameters": [
+                    {
+                        "description": "Pedido",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/allocation.Order"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave de idempotência para repetições seguras",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
                 ],
-                "summary": "Listar produtos",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/httpresponse.Response"
+                            "$ref": "#/definitions/api-estoque_internal_model_allocation_response_create.CreateResponse"
                         }
                     },
                     "400": {
@@ -37,32 +57,26 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
                     }
                 }
-            },
-            "post": {
-                "description": "Faz a criação de um novo produto",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "Retorna a árvore completa de categorias, com as subcategorias aninhadas em 'children'",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
-                ],
-                "summary": "Criar produto",
-                "parameters": [
-                    {
-                        "description": "Produto",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/product.Product"
-                        }
-                    }
+                    "categories"
                 ],
+                "summary": "Listar categorias",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -70,32 +84,35 @@ const docTemplate = `{
                             "$ref": "#/definitions/httpresponse.Response"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
                     }
                 }
-            }
-        },
-        "/products/{id}": {
-            "get": {
-                "description": "Retorna um produto específico pelo seu ID",
+            },
+            "put": {
+                "description": "Renomeia a categoria e/ou move para outro pai ('parentId') ou para a raiz ('moveToRoot': true). Não é possível mover para dentro da própria subárvore",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "categories"
                 ],
-                "summary": "Buscar produto por ID",
+                "summary": "Atualizar categoria",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "UUID do Produto",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Categoria",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/category.Category"
+                        }
                     }
                 ],
                 "responses": {
@@ -116,11 +133,17 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
                     }
                 }
             },
-            "put": {
-                "description": "Atualiza os dados de um produto existente",
+            "post": {
+                "description": "Cria uma categoria, opcionalmente como subcategoria de 'parentId'. O nome é único entre irmãos, ignorando acentos, maiúsculas e espaços",
                 "consumes": [
                     "application/json"
                 ],
@@ -128,17 +151,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "categories"
                 ],
-                "summary": "Atualizar produto",
+                "summary": "Criar categoria",
                 "parameters": [
                     {
-                        "description": "Produto",
-                        "name": "product",
+                        "description": "Categoria",
+                        "name": "category",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/product.Product"
+                            "$ref": "#/definitions/category.Category"
                         }
                     }
                 ],
@@ -155,30 +178,37 @@ const docTemplate = `{
                             "$ref": "#/definitions/httpresponse.Response"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Exclui um produto pelo seu ID",
+            }
+        },
+        "/categories/report": {
+            "get": {
+                "description": "Quantidade, reservas e valor de estoque (preço atual x quantidade) por categoria. 'own' considera os produtos da própria categoria e 'total' soma todas as subcategorias",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "categories"
                 ],
-                "summary": "Remover produto",
+                "summary": "Relatório de estoque por categoria",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "UUID do Produto",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "UUID da categoria raiz do relatório",
+                        "name": "categoryId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID do armazém",
+                        "name": "warehouseId",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -203,16 +233,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/stock-items": {
+        "/categories/{id}": {
             "get": {
-                "description": "Pega todos os registros de item de estoque",
+                "description": "Retorna a categoria e o caminho da raiz até ela",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stock-items"
+                    "categories"
+                ],
+                "summary": "Buscar categoria por ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID da Categoria",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Listar items do estoque",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -225,30 +264,31 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
                     }
                 }
             },
-            "post": {
-                "description": "Faz a criação de item de estoque",
-                "consumes": [
-                    "application/json"
-                ],
+            "delete": {
+                "description": "Remove uma categoria sem subcategorias e sem produtos vinculados",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stock-items"
+                    "categories"
                 ],
-                "summary": "Cria item de estoque",
+                "summary": "Remover categoria",
                 "parameters": [
                     {
-                        "description": "Stock Item",
-                        "name": "stockItem",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/stockitems.StockItems"
-                        }
+                        "type": "string",
+                        "description": "UUID da Categoria",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -263,13 +303,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
                     }
                 }
             }
         },
-        "/stock-items/baixa": {
+        "/graphql": {
             "post": {
-                "description": "Atualiza os dados de um item de estoque existente",
+                "description": "Consulta produtos, galpões, itens de estoque e movimentações com suas relações numa única requisição. Somente leitura. O schema está disponível por introspecção. Erros de consulta voltam com status 200 no campo 'errors', como define o GraphQL",
                 "consumes": [
                     "application/json"
                 ],
@@ -277,17 +329,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stock-items"
+                    "graphql"
                 ],
-                "summary": "Atualizar item de estoque",
+                "summary": "Consulta GraphQL",
                 "parameters": [
                     {
-                        "description": "Stock Item",
-                        "name": "stockItem",
+                        "description": "Consulta",
+                        "name": "query",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/stockitems.StockItemsBaixa"
+                            "$ref": "#/definitions/graph.Request"
                         }
                     }
                 ],
@@ -295,7 +347,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/httpresponse.Response"
+                            "type": "object"
                         }
                     },
                     "400": {
@@ -303,47 +355,49 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/httpresponse.Response"
-                        }
                     }
                 }
             }
         },
-        "/stock-items/{idWarehouse}/{idProduct}": {
-            "get": {
-                "description": "Retorna um item de estoque específico pelo idWarehouse e idProduct",
+        "/import/products": {
+            "post": {
+                "description": "Importa produtos de um CSV (separado por ',' ou ';') ou XLSX. Colunas: sku (opcional), name, description, price, categoryId, status, lengthMm, widthMm, heightMm, weightGrams e, opcionalmente, price_\u003cmoeda\u003e (ex: price_ARS). Preços inteiros na menor unidade da moeda. Cada linha passa pelas mesmas validações do POST /products; a importação é tudo ou nada. Com dryRun=true apenas valida e devolve o relatório",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stock-items"
+                    "imports"
                 ],
-                "summary": "Buscar item de estoque por ID",
+                "summary": "Importar produtos de planilha",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "UUID do Warehouse",
-                        "name": "idWarehouse",
-                        "in": "path",
+                        "type": "file",
+                        "description": "Planilha CSV ou XLSX",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
                     },
+                    {
+                        "type": "boolean",
+                        "description": "Apenas validar, sem gravar",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "UUID do Produto",
-                        "name": "idProduct",
-                        "in": "path",
-                        "required": true
+                        "description": "Chave de idempotência para repetições seguras",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/httpresponse.Response"
+                            "$ref": "#/definitions/importfile.ImportFileResponse"
                         }
                     },
                     "400": {
@@ -352,42 +406,60 @@ const docTemplate = `{
                             "$ref": "#/definitions/httpresponse.Response"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/importfile.ImportFileResponse"
+                        }
                     }
                 }
-            },
-            "put": {
-                "description": "Atualiza os dados de um item de estoque existente",
+            }
+        },
+        "/import/stock-items": {
+            "post": {
+                "description": "Importa saldos iniciais de estoque de um CSV (separado por ',' ou ';') ou XLSX. Colunas: warehouseId, productId, quantity e reserved (opcional, padrão 0). Cada linha passa pelas mesmas validações do POST /stock-items; a importação é tudo ou nada. Com dryRun=true apenas valida e devolve o relatório",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stock-items"
+                    "imports"
                 ],
-                "summary": "Atualizar item de estoque",
+                "summary": "Importar saldos iniciais de planilha",
                 "parameters": [
                     {
-                        "description": "Stock Item",
-                        "name": "stockItem",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/stockitems.StockItems"
-                        }
+                        "type": "file",
+                        "description": "Planilha CSV ou XLSX",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apenas validar, sem gravar",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave de idempotência para repetições seguras",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/httpresponse.Response"
+                            "$ref": "#/definitions/importfile.ImportFileResponse"
                         }
                     },
                     "400": {
@@ -396,37 +468,49 @@ const docTemplate = `{
                             "$ref": "#/definitions/httpresponse.Response"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/importfile.ImportFileResponse"
+                        }
                     }
                 }
-            },
-            "delete": {
-                "description": "Exclui um item de estoque pelo idWarehouse e idProduct",
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Retorna todos os produtos cadastrados",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stock-items"
+                    "products"
                 ],
-                "summary": "Remover item de estoque",
+                "summary": "Listar produtos",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itens por página (padrão 50, máximo 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "UUID do Warehouse",
-                        "name": "idWarehouse",
-                        "in": "path",
-                        "required": true
+                        "description": "next_cursor retornado pela página anterior",
+                        "name": "cursor",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "UUID do Produto",
-                        "name": "idProduct",
-                        "in": "path",
-                        "required": true
+                        "description": "Moeda ISO 4217 do preço, ex: BRL, ARS, UYU, PYG (padrão: moeda configurada na API)",
+                        "name": "currency",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -441,29 +525,35 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/httpresponse.Response"
-                        }
                     }
                 }
-            }
-        },
-        "/stock-move": {
-            "get": {
-                "description": "Retorna todas as movimentações de estoque",
+            },
+            "post": {
+                "description": "Faz a criação de um novo produto",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stock-moves"
+                    "products"
                 ],
-                "summary": "Listar movimentações de estoque",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                "summary": "Criar produto",
+                "parameters": [
+                    {
+                        "description": "Produto",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/product.Product"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
@@ -473,11 +563,19 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/products/bulk": {
             "post": {
-                "description": "Cria uma nova movimentação de estoque",
+                "description": "Recebe até 500 produtos identificados pelo 'sku': cria os que não existem e atualiza os existentes, em uma única transação. Cada item segue as regras do POST /products; em produtos existentes, 'images' e dimensões omitidas são mantidas e 'status' segue as transições permitidas. O resultado de cada item (created, updated, unchanged ou error) volta na mesma posição do lote; itens com erro não impedem a gravação dos demais",
                 "consumes": [
                     "application/json"
                 ],
@@ -485,25 +583,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stock-moves"
+                    "products"
                 ],
-                "summary": "Criar movimentação de estoque",
+                "summary": "Criar ou atualizar produtos em lote",
                 "parameters": [
                     {
-                        "description": "Movimentação de Estoque",
-                        "name": "stockMove",
+                        "description": "Lote de produtos",
+                        "name": "products",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/stockmoves.StockMove"
+                            "$ref": "#/definitions/product.BulkUpsert"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave de idempotência para repetições seguras",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/httpresponse.Response"
+                            "$ref": "#/definitions/bulkupsert.BulkUpsertResponse"
                         }
                     },
                     "400": {
@@ -511,34 +615,50 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
                     }
                 }
             }
         },
-        "/stock-move/by-product/{idProduct}": {
+        "/products/export": {
             "get": {
-                "description": "Retorna todas as movimentações de estoque de um produto específico",
+                "description": "Exporta todos os produtos em CSV ou XLSX, com as colunas aceitas pela importação. O arquivo é enviado conforme as linhas são lidas",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
                 ],
                 "tags": [
-                    "stock-moves"
+                    "products"
                 ],
-                "summary": "Listar movimentações por produto",
+                "summary": "Exportar catálogo de produtos",
                 "parameters": [
                     {
+                        "enum": [
+                            "csv",
+                            "xlsx"
+                        ],
                         "type": "string",
-                        "description": "UUID do Produto",
-                        "name": "idProduct",
-                        "in": "path",
-                        "required": true
+                        "description": "Formato do arquivo (padrão csv)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Moeda ISO 4217 do preço, ex: BRL, ARS, UYU, PYG (padrão: moeda configurada na API)",
+                        "name": "currency",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/httpresponse.Response"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -550,30 +670,53 @@ const docTemplate = `{
                 }
             }
         },
-        "/stock-move/by-warehouse-product/{idWarehouse}/{idProduct}": {
+        "/products/search": {
             "get": {
-                "description": "Retorna todas as movimentações de estoque filtradas por armazém e produto",
+                "description": "Busca textual em português por nome, categoria e descrição, ignorando acentos. O último termo casa por prefixo, para uso em autocompletar. Resultados ordenados por relevância",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stock-moves"
+                    "products"
                 ],
-                "summary": "Listar movimentações por armazém e produto",
+                "summary": "Buscar produtos por texto",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "UUID do Armazém",
-                        "name": "idWarehouse",
-                        "in": "path",
+                        "description": "Texto da busca",
+                        "name": "q",
+                        "in": "query",
                         "required": true
                     },
+                    {
+                        "type": "boolean",
+                        "description": "Apenas produtos ativos",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apenas produtos com saldo disponível em algum armazém",
+                        "name": "inStock",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itens por página (padrão 50, máximo 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "UUID do Produto",
-                        "name": "idProduct",
-                        "in": "path",
-                        "required": true
+                        "description": "next_cursor retornado pela página anterior",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Moeda ISO 4217 do preço, ex: BRL, ARS, UYU, PYG (padrão: moeda configurada na API)",
+                        "name": "currency",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -592,23 +735,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/stock-move/by-warehouse/{idWarehouse}": {
+        "/products/{id}": {
             "get": {
-                "description": "Retorna todas as movimentações de estoque de um armazém específico",
+                "description": "Retorna um produto específico pelo seu ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stock-moves"
+                    "products"
                 ],
-                "summary": "Listar movimentações por armazém",
+                "summary": "Buscar produto por ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "UUID do Armazém",
-                        "name": "idWarehouse",
+                        "description": "UUID do Produto",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Moeda ISO 4217 do preço, ex: BRL, ARS, UYU, PYG (padrão: moeda configurada na API)",
+                        "name": "currency",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -616,6 +765,12 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Versão do produto, para uso no If-Match"
+                            }
                         }
                     },
                     "400": {
@@ -623,27 +778,42 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
                     }
                 }
-            }
-        },
-        "/stock-move/{id}": {
-            "get": {
-                "description": "Retorna uma movimentação de estoque específica pelo seu ID",
+            },
+            "put": {
+                "description": "Atualiza os dados de um produto existente. Com If-Match, só atualiza se o produto não mudou desde a leitura",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stock-moves"
+                    "products"
                 ],
-                "summary": "Buscar movimentação de estoque por ID",
+                "summary": "Atualizar produto",
                 "parameters": [
+                    {
+                        "description": "Produto",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/product.Product"
+                        }
+                    },
                     {
                         "type": "string",
-                        "description": "UUID da Movimentação",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "ETag forte retornado pelo GET; um único valor ou *",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -664,56 +834,37 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
-                    }
-                }
-            }
-        },
-        "/warehouses": {
-            "get": {
-                "description": "Retorna a lista de todos os armazéns cadastrados",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "warehouse"
-                ],
-                "summary": "Listar armazéns",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "412": {
+                        "description": "Precondition Failed",
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Atualiza os dados de um armazém existente",
-                "consumes": [
-                    "application/json"
-                ],
+            "delete": {
+                "description": "Exclui um produto pelo seu ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "warehouse"
+                    "products"
                 ],
-                "summary": "Atualizar armazém",
+                "summary": "Remover produto",
                 "parameters": [
                     {
-                        "description": "Warehouse",
-                        "name": "warehouse",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/warehouse.Warehouse"
-                        }
+                        "type": "string",
+                        "description": "UUID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -736,28 +887,53 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/products/{id}/images": {
             "post": {
-                "description": "Cria um novo armazém no sistema",
+                "description": "Envia uma imagem (JPEG, PNG, GIF ou WebP) em multipart/form-data. A API grava o arquivo, gera uma miniatura de até 320px e acrescenta a imagem ao produto. Com 'position', as imagens a partir daquela posição são deslocadas; com 'primary', a imagem passa a ser a principal",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "warehouse"
+                    "products"
                 ],
-                "summary": "Criar armazém",
+                "summary": "Enviar imagem do produto",
                 "parameters": [
                     {
-                        "description": "Warehouse",
-                        "name": "warehouse",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/warehouse.Warehouse"
-                        }
+                        "type": "string",
+                        "description": "UUID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Arquivo da imagem",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Texto alternativo",
+                        "name": "alt",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Posição da imagem (padrão: ao final)",
+                        "name": "position",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Marcar como imagem principal",
+                        "name": "primary",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
@@ -773,8 +949,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/httpresponse.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
                         "schema": {
                             "$ref": "#/definitions/httpresponse.Response"
                         }
@@ -782,23 +970,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/warehouses/{id}": {
+        "/products/{id}/price-history": {
             "get": {
-                "description": "Retorna um armazém específico pelo seu UUID",
+                "description": "Retorna as alterações de preço do produto na moeda pedida, da mais recente à mais antiga, com o momento em que passaram a valer e o usuário responsável. Com 'at', retorna apenas o preço vigente naquele momento",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "warehouse"
+                    "products"
                 ],
-                "summary": "Buscar armazém por ID",
+                "summary": "Histórico de preços do produto",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "UUID do Armazém",
+                        "description": "UUID do Produto",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Moeda ISO 4217 do preço, ex: BRL, ARS, UYU, PYG (padrão: moeda configurada na API)",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Data RFC 3339 para consultar o preço vigente, ex: 2024-11-29T12:00:00-03:00",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itens por página (padrão 50, máximo 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor retornado pela página anterior",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -821,23 +1033,31 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Exclui um armazém específico pelo seu UUID",
+            }
+        },
+        "/products/{id}/price-schedule": {
+            "get": {
+                "description": "Retorna os agendamentos de preço do produto, pela data de vigência. Por padrão apenas os pendentes; com 'all=true', inclui aplicados e cancelados",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "warehouse"
+                    "products"
                 ],
-                "summary": "Remover armazém",
+                "summary": "Listar agendamentos de preço",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "UUID do Armazém",
+                        "description": "UUID do Produto",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Incluir agendamentos aplicados e cancelados",
+                        "name": "all",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -860,44 +1080,2288 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "httpresponse.Response": {
-            "type": "object",
-            "properties": {
-                "msg": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "integer"
-                }
-            }
-        },
-        "product.Product": {
-            "type": "object",
-            "properties": {
-                "category": {
-                    "type": "string"
-                },
-                "createdAt": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
+            },
+            "post": {
+                "description": "Agenda um novo preço para o produto a partir de 'effectiveAt'. Um worker aplica o preço quando a data chega e o registra no histórico",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Agendar alteração de preço",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Agendamento",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/product.PriceSchedule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}/price-schedule/{scheduleId}": {
+            "delete": {
+                "description": "Cancela um agendamento de preço ainda pendente",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Cancelar agendamento de preço",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID do Produto",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID do Agendamento",
+                        "name": "scheduleId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-items": {
+            "get": {
+                "description": "Pega todos os registros de item de estoque",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock-items"
+                ],
+                "summary": "Listar items do estoque",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Itens por página (padrão 50, máximo 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor retornado pela página anterior",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Faz a criação de item de estoque",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock-items"
+                ],
+                "summary": "Cria item de estoque",
+                "parameters": [
+                    {
+                        "description": "Stock Item",
+                        "name": "stockItem",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/stockitems.StockItems"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave de idempotência para repetições seguras",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-items/baixa": {
+            "post": {
+                "description": "Atualiza os dados de um item de estoque existente",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock-items"
+                ],
+                "summary": "Atualizar item de estoque",
+                "parameters": [
+                    {
+                        "description": "Stock Item",
+                        "name": "stockItem",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/stockitems.StockItemsBaixa"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave de idempotência para repetições seguras",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-items/export": {
+            "get": {
+                "description": "Exporta todos os itens de estoque, com os nomes do produto e do armazém, em CSV ou XLSX. O arquivo é enviado conforme as linhas são lidas",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "stock-items"
+                ],
+                "summary": "Exportar posições de estoque",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "Formato do arquivo (padrão csv)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-items/{idWarehouse}/{idProduct}": {
+            "get": {
+                "description": "Retorna um item de estoque específico pelo idWarehouse e idProduct",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock-items"
+                ],
+                "summary": "Buscar item de estoque por ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID do Warehouse",
+                        "name": "idWarehouse",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID do Produto",
+                        "name": "idProduct",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Versão do item, para uso no If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Atualiza os dados de um item de estoque existente. Com If-Match, só atualiza se o item não mudou desde a leitura",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock-items"
+                ],
+                "summary": "Atualizar item de estoque",
+                "parameters": [
+                    {
+                        "description": "Stock Item",
+                        "name": "stockItem",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/stockitems.StockItems"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave de idempotência para repetições seguras",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag forte retornado pelo GET; um único valor ou *",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Exclui um item de estoque pelo idWarehouse e idProduct",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock-items"
+                ],
+                "summary": "Remover item de estoque",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID do Warehouse",
+                        "name": "idWarehouse",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID do Produto",
+                        "name": "idProduct",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-move": {
+            "get": {
+                "description": "Consulta o razão de estoque com filtros combináveis e ordenação, para auditoria",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock-moves"
+                ],
+                "summary": "Consultar movimentações de estoque",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Início do período, inclusivo (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fim do período, exclusivo (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "in",
+                            "out",
+                            "reversal"
+                        ],
+                        "type": "string",
+                        "description": "Tipo da movimentação",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho do motivo, sem diferenciar maiúsculas",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade absoluta mínima",
+                        "name": "minQty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade absoluta máxima",
+                        "name": "maxQty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID da categoria do produto, incluindo subcategorias",
+                        "name": "categoryId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "UUIDs dos armazéns",
+                        "name": "warehouseId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "UUIDs dos produtos",
+                        "name": "productId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Usuário que registrou a movimentação",
+                        "name": "createdBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "createdAt",
+                            "-createdAt",
+                            "qtyMoved",
+                            "-qtyMoved"
+                        ],
+                        "type": "string",
+                        "description": "Ordenação (padrão -createdAt)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itens por página (padrão 50, máximo 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor retornado pela página anterior",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registra uma movimentação no razão de estoque, sem alterar o saldo do item de estoque",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock-moves"
+                ],
+                "summary": "Criar movimentação de estoque",
+                "parameters": [
+                    {
+                        "description": "Movimentação de Estoque",
+                        "name": "stockMove",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/stockmoves.StockMove"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave de idempotência para repetições seguras",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-move/by-product/{idProduct}": {
+            "get": {
+                "description": "Retorna todas as movimentações de estoque de um produto específico",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock-moves"
+                ],
+                "summary": "Listar movimentações por produto",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID do Produto",
+                        "name": "idProduct",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itens por página (padrão 50, máximo 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor retornado pela página anterior",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-move/by-warehouse-product/{idWarehouse}/{idProduct}": {
+            "get": {
+                "description": "Retorna todas as movimentações de estoque filtradas por armazém e produto",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock-moves"
+                ],
+                "summary": "Listar movimentações por armazém e produto",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID do Armazém",
+                        "name": "idWarehouse",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID do Produto",
+                        "name": "idProduct",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itens por página (padrão 50, máximo 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor retornado pela página anterior",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-move/by-warehouse/{idWarehouse}": {
+            "get": {
+                "description": "Retorna todas as movimentações de estoque de um armazém específico",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock-moves"
+                ],
+                "summary": "Listar movimentações por armazém",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID do Armazém",
+                        "name": "idWarehouse",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itens por página (padrão 50, máximo 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor retornado pela página anterior",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-move/export": {
+            "get": {
+                "description": "Exporta o razão de estoque em CSV ou XLSX, com os nomes do produto e do armazém. Aceita os mesmos filtros e ordenação da consulta, sem paginação. O arquivo é enviado conforme as linhas são lidas",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "stock-moves"
+                ],
+                "summary": "Exportar movimentações de estoque",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "Formato do arquivo (padrão csv)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Início do período, inclusivo (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fim do período, exclusivo (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "in",
+                            "out",
+                            "reversal"
+                        ],
+                        "type": "string",
+                        "description": "Tipo da movimentação",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trecho do motivo, sem diferenciar maiúsculas",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade absoluta mínima",
+                        "name": "minQty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade absoluta máxima",
+                        "name": "maxQty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID da categoria do produto, incluindo subcategorias",
+                        "name": "categoryId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "UUIDs dos armazéns",
+                        "name": "warehouseId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "UUIDs dos produtos",
+                        "name": "productId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Usuário que registrou a movimentação",
+                        "name": "createdBy",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "createdAt",
+                            "-createdAt",
+                            "qtyMoved",
+                            "-qtyMoved"
+                        ],
+                        "type": "string",
+                        "description": "Ordenação (padrão -createdAt)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-move/{id}": {
+            "get": {
+                "description": "Retorna uma movimentação de estoque específica pelo seu ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock-moves"
+                ],
+                "summary": "Buscar movimentação de estoque por ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID da Movimentação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-move/{id}/reverse": {
+            "post": {
+                "description": "Cria uma movimentação com a quantidade oposta vinculada à original. Se a original alterou o saldo (baixas, transferências), a quantidade oposta é aplicada no item de estoque; movimentações criadas só no razão são estornadas só no razão. Cada movimentação só pode ser estornada uma vez, e movimentações que alteraram o saldo de um galpão encerrado não podem ser estornadas",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock-moves"
+                ],
+                "summary": "Estornar movimentação de estoque",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID da Movimentação",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Motivo do estorno",
+                        "name": "reversal",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/stockmoves.Reversal"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chave de idempotência para repetições seguras",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stream/stock": {
+            "get": {
+                "description": "Envia um evento a cada alteração de saldo dos itens de estoque filtrados. Cada evento traz 'id' (sequência de publicação, crescente e com possíveis lacunas), 'event' (tipo, ex: stock.deducted) e em 'data' o evento com o saldo após a alteração. Para retomar sem perder eventos, reconecte com o header Last-Event-ID (o EventSource faz isso sozinho) ou o parâmetro 'lastEventId'. Se não for possível retomar, o evento 'stream.reset' indica que os saldos devem ser recarregados. Como o EventSource não envia headers, o token pode ir no parâmetro 'access_token'",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "stream"
+                ],
+                "summary": "Stream de saldos de estoque (Server-Sent Events)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUIDs dos armazéns, separados por vírgula",
+                        "name": "warehouseId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUIDs dos produtos, separados por vírgula",
+                        "name": "productId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Último 'id' recebido, alternativa ao header Last-Event-ID",
+                        "name": "lastEventId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token JWT, alternativa ao header Authorization",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "text/event-stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stream/stock/ws": {
+            "get": {
+                "description": "Mesmo conteúdo do stream SSE em um WebSocket: cada mensagem de texto é o evento em JSON, com 'sequence' servindo de Last-Event-ID. Para retomar, reconecte com 'lastEventId'. A mensagem {\"type\":\"stream.reset\"} indica que os saldos devem ser recarregados. O servidor envia ping a cada 15 segundos",
+                "tags": [
+                    "stream"
+                ],
+                "summary": "Stream de saldos de estoque (WebSocket)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUIDs dos armazéns, separados por vírgula",
+                        "name": "warehouseId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUIDs dos produtos, separados por vírgula",
+                        "name": "productId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Última 'sequence' recebida",
+                        "name": "lastEventId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token JWT, alternativa ao header Authorization",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tcc/{txId}": {
+            "get": {
+                "description": "Retorna o estado da transação, as linhas reservadas e o prazo. Um Try vencido continua 'tried' até ser cancelado pela rotina de expiração",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tcc"
+                ],
+                "summary": "Buscar transação TCC",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID global da transação",
+                        "name": "txId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tcc/{txId}/cancel": {
+            "post": {
+                "description": "Libera a reserva do Try. Repetir o Cancel não libera de novo. Um Cancel recebido antes do Try registra a transação como cancelada, e o Try que chegar depois é recusado. Uma transação já confirmada é recusada",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tcc"
+                ],
+                "summary": "Fase Cancel de uma transação TCC",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID global da transação",
+                        "name": "txId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tcc/{txId}/confirm": {
+            "post": {
+                "description": "Transforma a reserva do Try em baixa de estoque, registrada no razão com o ID da transação no motivo e o usuário do token como autor. Repetir o Confirm não dá baixa de novo. Uma transação cancelada ou com o prazo vencido é recusada",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tcc"
+                ],
+                "summary": "Fase Confirm de uma transação TCC",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID global da transação",
+                        "name": "txId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tcc/{txId}/try": {
+            "post": {
+                "description": "Reserva as linhas para a transação global até o prazo 'timeout_seconds' (ou o padrão da configuração). Todas as linhas são reservadas ou nenhuma. Repetir o Try com as mesmas linhas devolve a transação sem reservar de novo; uma transação já cancelada, inclusive por Cancel recebido antes do Try, é recusada. Sem Confirm dentro do prazo a reserva é liberada automaticamente",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tcc"
+                ],
+                "summary": "Fase Try de uma transação TCC",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID global da transação",
+                        "name": "txId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Linhas a reservar e prazo",
+                        "name": "try",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/tcc.Try"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/warehouses": {
+            "get": {
+                "description": "Retorna a lista dos armazéns ativos cadastrados",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Listar armazéns",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Incluir armazéns encerrados",
+                        "name": "includeClosed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itens por página (padrão 50, máximo 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor retornado pela página anterior",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Atualiza os dados de um armazém existente. Atributos omitidos ficam como estão; string vazia limpa os campos opcionais do endereço e a UF. 'region' e 'state' são a mesma UF. Com If-Match, só atualiza se o armazém não mudou desde a leitura",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Atualizar armazém",
+                "parameters": [
+                    {
+                        "description": "Warehouse",
+                        "name": "warehouse",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/warehouse.Warehouse"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag forte retornado pelo GET; um único valor ou *",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Cria um novo armazém no sistema. 'region' e 'state' são a mesma UF: informe uma delas, ou as duas com o mesmo valor",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Criar armazém",
+                "parameters": [
+                    {
+                        "description": "Warehouse",
+                        "name": "warehouse",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/warehouse.Warehouse"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/warehouses/nearest": {
+            "get": {
+                "description": "Retorna os armazéns com saldo disponível do produto, ordenados pela distância (great-circle) até o ponto informado",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Buscar armazéns mais próximos",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Latitude",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude",
+                        "name": "lng",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID do Produto",
+                        "name": "productId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Quantidade máxima de armazéns (padrão 5, máximo 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/warehouses/{id}": {
+            "get": {
+                "description": "Retorna um armazém específico pelo seu UUID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Buscar armazém por ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID do Armazém",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Versão do armazém, para uso no If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Encerra um armazém. Sem destino, é recusado enquanto houver estoque ou reservas; com destino, todo o estoque restante é transferido para ele",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Encerrar armazém",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID do Armazém",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID do Armazém de destino do estoque restante",
+                        "name": "targetWarehouseId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/warehouses/{id}/utilization": {
+            "get": {
+                "description": "Retorna o volume e o peso ocupados pelo estoque do armazém em relação às capacidades cadastradas",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Ocupação do armazém",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID do Armazém",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Retorna todas as assinaturas, da mais recente à mais antiga. O segredo não é retornado",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Listar assinaturas de webhook",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Altera URL, segredo, tipos de evento e filtros. Atributos omitidos ficam como estão; uma lista vazia remove o filtro. 'isActive' true reativa uma assinatura desativada e zera as falhas; as entregas pendentes voltam a ser enviadas. Eventos ocorridos enquanto desativada não são entregues",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Atualizar assinatura de webhook",
+                "parameters": [
+                    {
+                        "description": "Assinatura",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Subscription"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Cadastra uma URL para receber os eventos de estoque e de produto por POST. 'eventTypes', 'warehouseIds' e 'productIds' filtram os eventos; vazios recebem todos. Cada entrega é assinada com HMAC-SHA256 do segredo sobre \"\u003cX-Webhook-Timestamp\u003e.\u003cbody\u003e\", enviado em X-Webhook-Signature como \"sha256=\u003chex\u003e\". Sem 'secret', um segredo é gerado e retornado apenas nesta resposta. Entregas sem resposta 2xx são repetidas com espera exponencial, e a assinatura é desativada após falhas seguidas. URLs da rede interna (loopback, redes privadas, link-local) são recusadas, também quando o nome passa a resolver para uma delas na entrega",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Criar assinatura de webhook",
+                "parameters": [
+                    {
+                        "description": "Assinatura",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Subscription"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Retorna a assinatura, sem o segredo, com a contagem de falhas seguidas e o motivo caso esteja desativada",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Buscar assinatura de webhook por ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID da Assinatura",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a assinatura e o log de entregas dela",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Remover assinatura de webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID da Assinatura",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Retorna as entregas da assinatura, da mais recente à mais antiga, com o evento enviado, o número de tentativas, o status HTTP e o erro da última tentativa (apenas a linha de status, sem o corpo da resposta) e, se pendente, quando será a próxima",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Log de entregas do webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID da Assinatura",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por situação: pending, delivered ou failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Itens por página (padrão 50, máximo 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor retornado pela página anterior",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/deliveries.DeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/httpresponse.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "allocation.Allocation": {
+            "type": "object",
+            "properties": {
+                "quantity": {
+                    "type": "integer"
+                },
+                "warehouse_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "allocation.LineProposal": {
+            "type": "object",
+            "properties": {
+                "allocated": {
+                    "type": "integer"
+                },
+                "allocations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/allocation.Allocation"
+                    }
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "requested": {
+                    "type": "integer"
+                },
+                "split": {
+                    "type": "boolean"
+                },
+                "unallocated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "allocation.Order": {
+            "type": "object",
+            "properties": {
+                "latitude": {
+                    "type": "number"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/allocation.OrderLine"
+                    }
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "reserve": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "allocation.OrderLine": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api-estoque_internal_model_allocation_response_create.CreateResponse": {
+            "type": "object",
+            "properties": {
+                "fulfillable": {
+                    "type": "boolean"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/allocation.LineProposal"
+                    }
+                },
+                "reserved": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "bulkupsert.BulkUpsertResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/product.BulkResult"
+                    }
+                },
+                "unchanged": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "category.Category": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/category.Category"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "moveToRoot": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parentId": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "deliveries.DeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Delivery"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "graph.Request": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object"
+                }
+            }
+        },
+        "httpresponse.Response": {
+            "type": "object",
+            "properties": {
+                "msg": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "importfile.ImportFileResponse": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/imports.RowError"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "integer"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/imports.RowError"
+                    }
+                }
+            }
+        },
+        "imports.RowError": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "integer"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "product.BulkResult": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "msg": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "type": "string"
+                },
+                "sku": {
+                    "type": "string"
+                }
+            }
+        },
+        "product.BulkUpsert": {
+            "type": "object",
+            "properties": {
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/product.Product"
+                    }
+                }
+            }
+        },
+        "product.Image": {
+            "type": "object",
+            "properties": {
+                "alt": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "primary": {
+                    "type": "boolean"
+                },
+                "thumbnailUrl": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "product.Price": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "display": {
+                    "type": "string"
+                },
+                "minorUnits": {
+                    "type": "integer"
+                }
+            }
+        },
+        "product.PriceSchedule": {
+            "type": "object",
+            "properties": {
+                "appliedAt": {
+                    "type": "string"
+                },
+                "canceledAt": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "string"
+                },
+                "effectiveAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "productId": {
+                    "type": "string"
+                }
+            }
+        },
+        "product.Product": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "nome da categoria, somente leitura",
+                    "type": "string"
+                },
+                "categoryId": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "moeda de 'price', somente leitura",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "heightMm": {
+                    "type": "integer"
                 },
                 "id": {
                     "type": "string"
                 },
-                "imagesJson": {},
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/product.Image"
+                    }
+                },
                 "isActive": {
                     "type": "boolean"
                 },
+                "lengthMm": {
+                    "type": "integer"
+                },
                 "name": {
                     "type": "string"
                 },
                 "price": {
+                    "description": "na moeda de 'currency' nas respostas; na moeda padrao na escrita",
+                    "type": "integer"
+                },
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/product.Price"
+                    }
+                },
+                "sku": {
+                    "description": "codigo externo, unico quando informado",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "weightGrams": {
+                    "type": "integer"
+                },
+                "widthMm": {
                     "type": "integer"
                 }
             }
@@ -936,12 +3400,23 @@ const docTemplate = `{
                 }
             }
         },
+        "stockmoves.Reversal": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
         "stockmoves.StockMove": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
+                "created_by": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -954,25 +3429,185 @@ const docTemplate = `{
                 "reason": {
                     "type": "string"
                 },
+                "reversal_of": {
+                    "type": "string"
+                },
+                "reversed_by": {
+                    "type": "string"
+                },
                 "warehouse_id": {
                     "type": "string"
                 }
             }
         },
+        "tcc.Try": {
+            "type": "object",
+            "properties": {
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stockitems.StockItemsBaixa"
+                    }
+                },
+                "timeout_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
         "warehouse.Warehouse": {
             "type": "object",
             "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "complement": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "district": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
+                "latitude": {
+                    "type": "number"
+                },
                 "location": {
                     "type": "string"
                 },
+                "longitude": {
+                    "type": "number"
+                },
                 "name": {
                     "type": "string"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "street": {
+                    "type": "string"
+                },
+                "volume_capacity_liters": {
+                    "description": "Capacidades nulas indicam galpao sem limite cadastrado.",
+                    "type": "integer"
+                },
+                "weight_capacity_kg": {
+                    "type": "integer"
+                },
+                "zip_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "eventType": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "lastStatusCode": {
+                    "type": "integer"
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subscriptionId": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook.Subscription": {
+            "type": "object",
+            "properties": {
+                "consecutiveFailures": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "string"
+                },
+                "disabledAt": {
+                    "type": "string"
+                },
+                "disabledReason": {
+                    "type": "string"
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "isActive": {
+                    "type": "boolean"
+                },
+                "productIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "warehouseIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"api-estoque/internal/model/currency"
 	"api-estoque/internal/utils"
 	"context"
 	"time"
@@ -13,6 +14,66 @@ import (
 type Config struct {
 	SupabaseConnString string `envconfig:"SUPABASE_CONN_STRING" required:"true"`
 	JwtSecret          string `envconfig:"JWT_SECRET" required:"true"`
+	// CapacityPolicy define o que acontece quando uma entrada ultrapassa a
+	// capacidade do galpao: "warn" registra um aviso, "reject" recusa a operacao.
+	CapacityPolicy string `envconfig:"CAPACITY_POLICY" default:"warn"`
+	// Tempo durante o qual uma Idempotency-Key e lembrada e sua resposta reaproveitada.
+	IdempotencyKeyTTL time.Duration `envconfig:"IDEMPOTENCY_KEY_TTL" default:"24h"`
+	// Por quanto tempo uma chave em processamento fica presa sem ser renovada;
+	// depois disso (queda ou panic no meio da requisicao) outra tentativa a assume.
+	IdempotencyLockTimeout time.Duration `envconfig:"IDEMPOTENCY_LOCK_TIMEOUT" default:"30s"`
+	// Onde ficam as imagens enviadas: "local" grava em StorageLocalDir e serve
+	// os arquivos em StoragePublicURL, "s3" envia para um bucket compativel com S3.
+	StorageBackend   string `envconfig:"STORAGE_BACKEND" default:"local"`
+	StorageLocalDir  string `envconfig:"STORAGE_LOCAL_DIR" default:"./uploads"`
+	StoragePublicURL string `envconfig:"STORAGE_PUBLIC_URL" default:"/api/v1/estoque/uploads"`
+	S3Endpoint       string `envconfig:"S3_ENDPOINT"`
+	S3Region         string `envconfig:"S3_REGION" default:"us-east-1"`
+	S3Bucket         string `envconfig:"S3_BUCKET"`
+	S3AccessKey      string `envconfig:"S3_ACCESS_KEY"`
+	S3SecretKey      string `envconfig:"S3_SECRET_KEY"`
+	S3PublicURL      string `envconfig:"S3_PUBLIC_URL"`
+	// Tamanho maximo, em bytes, de uma imagem enviada.
+	ImageMaxBytes int64 `envconfig:"IMAGE_MAX_BYTES" default:"5242880"`
+	// Tamanho maximo, em bytes, de uma planilha de importacao.
+	ImportMaxBytes int64 `envconfig:"IMPORT_MAX_BYTES" default:"10485760"`
+	// Moeda ISO 4217 de "Product"."Price" e das respostas sem o parametro 'currency'.
+	DefaultCurrency string `envconfig:"DEFAULT_CURRENCY" default:"BRL"`
+	// Intervalo entre as verificacoes de alteracoes de preco agendadas.
+	PriceScheduleInterval time.Duration `envconfig:"PRICE_SCHEDULE_INTERVAL" default:"1m"`
+	// Para onde vao os eventos do outbox: "memory" distribui dentro do processo,
+	// "file" grava uma linha JSON por evento em EventFile.
+	EventPublisher string `envconfig:"EVENT_PUBLISHER" default:"memory"`
+	EventFile      string `envconfig:"EVENT_FILE" default:"./events.jsonl"`
+	// Intervalo entre as leituras de eventos pendentes no outbox.
+	OutboxInterval time.Duration `envconfig:"OUTBOX_INTERVAL" default:"1s"`
+	// Tempo durante o qual eventos ja publicados ficam guardados no outbox.
+	OutboxRetention time.Duration `envconfig:"OUTBOX_RETENTION" default:"168h"`
+	// Entregas de webhook: intervalo entre as leituras de entregas pendentes,
+	// tempo maximo de cada requisicao, tentativas por entrega e falhas seguidas
+	// ate a assinatura ser desativada.
+	WebhookInterval     time.Duration `envconfig:"WEBHOOK_INTERVAL" default:"5s"`
+	WebhookTimeout      time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+	WebhookMaxAttempts  int           `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"10"`
+	WebhookDisableAfter int           `envconfig:"WEBHOOK_DISABLE_AFTER" default:"20"`
+	// De onde vem os eventos do servico de pedidos: "memory" usa uma fila dentro
+	// do processo, "file" le uma linha JSON por evento de OrderEventsFile e grava
+	// as respostas em OrderRepliesFile.
+	OrderConsumer    string `envconfig:"ORDER_CONSUMER" default:"memory"`
+	OrderEventsFile  string `envconfig:"ORDER_EVENTS_FILE" default:"./orders.jsonl"`
+	OrderRepliesFile string `envconfig:"ORDER_REPLIES_FILE" default:"./order-replies.jsonl"`
+	// Tempo durante o qual mensagens de pedido ja respondidas ficam no inbox
+	// para descartar entregas repetidas.
+	OrderInboxRetention time.Duration `envconfig:"ORDER_INBOX_RETENTION" default:"720h"`
+	// Transacoes TCC do checkout: prazo padrao e maximo da reserva feita no Try,
+	// intervalo entre as buscas por Tries vencidos e tempo durante o qual
+	// transacoes finalizadas ficam guardadas para recusar chamadas atrasadas.
+	TccDefaultTimeout time.Duration `envconfig:"TCC_DEFAULT_TIMEOUT" default:"2m"`
+	TccMaxTimeout     time.Duration `envconfig:"TCC_MAX_TIMEOUT" default:"1h"`
+	TccExpiryInterval time.Duration `envconfig:"TCC_EXPIRY_INTERVAL" default:"5s"`
+	TccRetention      time.Duration `envconfig:"TCC_RETENTION" default:"168h"`
+	// Porta do servidor gRPC, que roda ao lado da API HTTP.
+	GrpcPort string `envconfig:"GRPC_PORT" default:"9090"`
 }
 
 var Env Config
@@ -44,4 +105,63 @@ func Load() {
 	if err := envconfig.Process("", &Env); err != nil {
 		logger.Fatal("Erro ao processar variaveis de ambiente", err)
 	}
+
+	if Env.CapacityPolicy != "warn" && Env.CapacityPolicy != "reject" {
+		logger.Fatalf("CAPACITY_POLICY invalida: %s (valores aceitos: warn, reject)", Env.CapacityPolicy)
+	}
+
+	switch Env.StorageBackend {
+	case "local":
+	case "s3":
+		if Env.S3Endpoint == "" || Env.S3Bucket == "" || Env.S3AccessKey == "" || Env.S3SecretKey == "" {
+			logger.Fatal("STORAGE_BACKEND=s3 exige S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY e S3_SECRET_KEY")
+		}
+	default:
+		logger.Fatalf("STORAGE_BACKEND invalido: %s (valores aceitos: local, s3)", Env.StorageBackend)
+	}
+
+	defaultCurrency, ok := currency.Lookup(Env.DefaultCurrency)
+	if !ok {
+		logger.Fatalf("DEFAULT_CURRENCY nao suportada: %s", Env.DefaultCurrency)
+	}
+	Env.DefaultCurrency = defaultCurrency.Code
+
+	if Env.IdempotencyLockTimeout <= 0 {
+		logger.Fatal("IDEMPOTENCY_LOCK_TIMEOUT deve ser maior que zero")
+	}
+
+	if Env.PriceScheduleInterval <= 0 {
+		logger.Fatal("PRICE_SCHEDULE_INTERVAL deve ser maior que zero")
+	}
+
+	if Env.EventPublisher != "memory" && Env.EventPublisher != "file" {
+		logger.Fatalf("EVENT_PUBLISHER invalido: %s (valores aceitos: memory, file)", Env.EventPublisher)
+	}
+	if Env.OutboxInterval <= 0 {
+		logger.Fatal("OUTBOX_INTERVAL deve ser maior que zero")
+	}
+	if Env.OutboxRetention <= 0 {
+		logger.Fatal("OUTBOX_RETENTION deve ser maior que zero")
+	}
+
+	if Env.WebhookInterval <= 0 || Env.WebhookTimeout <= 0 {
+		logger.Fatal("WEBHOOK_INTERVAL e WEBHOOK_TIMEOUT devem ser maiores que zero")
+	}
+	if Env.WebhookMaxAttempts < 1 || Env.WebhookDisableAfter < 1 {
+		logger.Fatal("WEBHOOK_MAX_ATTEMPTS e WEBHOOK_DISABLE_AFTER devem ser maiores que zero")
+	}
+
+	if Env.OrderConsumer != "memory" && Env.OrderConsumer != "file" {
+		logger.Fatalf("ORDER_CONSUMER invalido: %s (valores aceitos: memory, file)", Env.OrderConsumer)
+	}
+	if Env.OrderInboxRetention <= 0 {
+		logger.Fatal("ORDER_INBOX_RETENTION deve ser maior que zero")
+	}
+
+	if Env.TccDefaultTimeout <= 0 || Env.TccMaxTimeout < Env.TccDefaultTimeout {
+		logger.Fatal("TCC_DEFAULT_TIMEOUT deve ser maior que zero e no maximo TCC_MAX_TIMEOUT")
+	}
+	if Env.TccExpiryInterval <= 0 || Env.TccRetention <= 0 {
+		logger.Fatal("TCC_EXPIRY_INTERVAL e TCC_RETENTION devem ser maiores que zero")
+	}
 }
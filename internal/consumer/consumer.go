@@ -0,0 +1,49 @@
+package consumer
+
+import (
+	"api-estoque/internal/config"
+	"api-estoque/internal/model/order"
+	"context"
+	"time"
+)
+
+// retryDelay e a espera antes de entregar de novo uma mensagem cujo handler
+// falhou
+const retryDelay = 5 * time.Second
+
+// Handler processa o corpo de uma mensagem. Retornar erro faz a mesma mensagem
+// ser entregue de novo; mensagens que nunca serao aceitas devem ser
+// descartadas pelo handler, retornando nil.
+type Handler func(ctx context.Context, body []byte) error
+
+// Consumer recebe os eventos do servico de pedidos e publica as respostas. A
+// entrega e pelo menos uma vez, na ordem de chegada: a proxima mensagem so e
+// entregue depois que o handler aceitar a anterior.
+type Consumer interface {
+	// Consume entrega as mensagens ao handler ate o contexto ser cancelado
+	Consume(ctx context.Context, handle Handler) error
+	Reply(ctx context.Context, reply *order.Reply) error
+}
+
+// New cria o consumer escolhido em ORDER_CONSUMER
+func New() (Consumer, error) {
+	env := config.Env
+	if env.OrderConsumer == "file" {
+		return NewFile(env.OrderEventsFile, env.OrderRepliesFile)
+	}
+	return NewMemory(), nil
+}
+
+// deliver chama o handler ate ele aceitar a mensagem ou o contexto acabar
+func deliver(ctx context.Context, handle Handler, body []byte) error {
+	for {
+		if err := handle(ctx, body); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryDelay):
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package consumer
+
+import (
+	"api-estoque/internal/model/order"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// filePollInterval e a espera entre as leituras quando o arquivo nao tem
+// mensagens novas
+const filePollInterval = time.Second
+
+// File le os eventos de pedido de um arquivo com uma linha JSON por evento,
+// acompanhando o final do arquivo, e grava as respostas em outro arquivo no
+// mesmo formato. A posicao apos a ultima mensagem aceita fica em
+// <arquivo>.offset, para retomar dali depois de reiniciar.
+type File struct {
+	path       string
+	offsetPath string
+	mu         sync.Mutex
+	replies    *os.File
+}
+
+func NewFile(path string, repliesPath string) (*File, error) {
+	for _, p := range []string{path, repliesPath} {
+		if dir := filepath.Dir(p); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return nil, fmt.Errorf("criar diretorio de mensagens: %w", err)
+			}
+		}
+	}
+	replies, err := os.OpenFile(repliesPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("abrir arquivo de respostas: %w", err)
+	}
+	return &File{path: path, offsetPath: path + ".offset", replies: replies}, nil
+}
+
+func (f *File) Consume(ctx context.Context, handle Handler) error {
+	offset, err := f.readOffset()
+	if err != nil {
+		return err
+	}
+
+	for {
+		offset, err = f.consumeFrom(ctx, offset, handle)
+		if err != nil || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(filePollInterval):
+		}
+	}
+}
+
+// consumeFrom entrega as linhas completas a partir de offset e retorna a
+// posicao apos a ultima aceita. Uma linha sem o '\n' final ainda esta sendo
+// escrita e fica para a proxima leitura.
+func (f *File) consumeFrom(ctx context.Context, offset int64, handle Handler) (int64, error) {
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return offset, nil
+	}
+	if err != nil {
+		return offset, fmt.Errorf("abrir arquivo de mensagens: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return offset, err
+	}
+	if info.Size() < offset {
+		// Arquivo truncado ou trocado: recomeca do inicio
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+
+	reader := bufio.NewReader(file)
+	for ctx.Err() == nil {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return offset, nil
+		}
+		if err != nil {
+			return offset, fmt.Errorf("ler arquivo de mensagens: %w", err)
+		}
+
+		if body := bytes.TrimSpace(line); len(body) > 0 {
+			if err := deliver(ctx, handle, body); err != nil {
+				return offset, err
+			}
+		}
+		offset += int64(len(line))
+		if err := f.writeOffset(offset); err != nil {
+			return offset, err
+		}
+	}
+	return offset, nil
+}
+
+func (f *File) readOffset() (int64, error) {
+	raw, err := os.ReadFile(f.offsetPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ler posicao do arquivo de mensagens: %w", err)
+	}
+	offset, err := strconv.ParseInt(string(bytes.TrimSpace(raw)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("posicao invalida em %s: %w", f.offsetPath, err)
+	}
+	return offset, nil
+}
+
+// writeOffset grava a posicao num arquivo temporario e o renomeia, para que
+// uma queda no meio nao deixe a posicao corrompida
+func (f *File) writeOffset(offset int64) error {
+	tmp := f.offsetPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatInt(offset, 10)), 0o644); err != nil {
+		return fmt.Errorf("gravar posicao do arquivo de mensagens: %w", err)
+	}
+	return os.Rename(tmp, f.offsetPath)
+}
+
+func (f *File) Reply(ctx context.Context, reply *order.Reply) error {
+	line, err := json.Marshal(reply)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.replies.Write(line); err != nil {
+		return fmt.Errorf("gravar resposta: %w", err)
+	}
+	return nil
+}
+
+func (f *File) Close() error {
+	return f.replies.Close()
+}
@@ -0,0 +1,63 @@
+package consumer
+
+import (
+	"api-estoque/internal/model/order"
+	"context"
+	"errors"
+)
+
+// memoryBuffer e quantas mensagens e respostas ficam na fila em memoria
+const memoryBuffer = 256
+
+var ErrQueueFull = errors.New("fila de mensagens cheia")
+
+// Memory e a fila dentro do proprio processo, para desenvolvimento local e
+// testes: Send enfileira eventos de pedido e Replies entrega as respostas.
+// Com o buffer cheio, Send e Reply retornam ErrQueueFull.
+type Memory struct {
+	messages chan []byte
+	replies  chan *order.Reply
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		messages: make(chan []byte, memoryBuffer),
+		replies:  make(chan *order.Reply, memoryBuffer),
+	}
+}
+
+// Send enfileira o corpo de um evento de pedido
+func (m *Memory) Send(body []byte) error {
+	select {
+	case m.messages <- body:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (m *Memory) Consume(ctx context.Context, handle Handler) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case body := <-m.messages:
+			if err := deliver(ctx, handle, body); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (m *Memory) Reply(ctx context.Context, reply *order.Reply) error {
+	select {
+	case m.replies <- reply:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (m *Memory) Replies() <-chan *order.Reply {
+	return m.replies
+}
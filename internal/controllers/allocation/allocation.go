@@ -0,0 +1,63 @@
+package allocation
+
+import (
+	allocationModel "api-estoque/internal/model/allocation"
+	_ "api-estoque/internal/model/allocation/response/create"
+	httpresponse "api-estoque/internal/model/http_response"
+	allocationSrvc "api-estoque/internal/services/allocation"
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Controller struct {
+	Service *allocationSrvc.Service
+	Logger  *logrus.Logger
+}
+
+func New(service *allocationSrvc.Service, logger *logrus.Logger) *Controller {
+	return &Controller{
+		Service: service,
+		Logger:  logger,
+	}
+}
+
+// Create godoc
+// @Summary Propor alocação de pedido
+// @Description Propõe de quais galpões cada linha do pedido deve sair, considerando saldo disponível, distância e prioridade. Opcionalmente reserva o estoque escolhido
+// @Tags allocations
+// @Accept json
+// @Produce json
+// @Param order body allocationModel.Order true "Pedido"
+// @Param Idempotency-Key header string false "Chave de idempotência para repetições seguras"
+// @Success 200 {object} create.CreateResponse
+// @Failure 400 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
+// @Router /allocations [post]
+func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Allocation) Create - req recebida")
+
+	var order allocationModel.Order
+
+	err := json.NewDecoder(r.Body).Decode(&order)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "request invalido, falha ao decodificar body")
+		return
+	}
+
+	err = order.Validate()
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.Create(&order)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
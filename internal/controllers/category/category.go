@@ -0,0 +1,232 @@
+package category
+
+import (
+	categoryModel "api-estoque/internal/model/category"
+	httpresponse "api-estoque/internal/model/http_response"
+	categorySrvc "api-estoque/internal/services/category"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+type Controller struct {
+	Service *categorySrvc.Service
+	Logger  *logrus.Logger
+}
+
+func New(service *categorySrvc.Service, logger *logrus.Logger) *Controller {
+	return &Controller{
+		Service: service,
+		Logger:  logger,
+	}
+}
+
+// List godoc
+// @Summary Listar categorias
+// @Description Retorna a árvore completa de categorias, com as subcategorias aninhadas em 'children'
+// @Tags categories
+// @Produce json
+// @Success 200 {object} httpresponse.Response
+// @Failure 500 {object} httpresponse.Response
+// @Router /categories [get]
+func (c *Controller) List(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Category) List - req recebida")
+
+	res := c.Service.List()
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// Create godoc
+// @Summary Criar categoria
+// @Description Cria uma categoria, opcionalmente como subcategoria de 'parentId'. O nome é único entre irmãos, ignorando acentos, maiúsculas e espaços
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param category body categoryModel.Category true "Categoria"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
+// @Router /categories [post]
+func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Category) Create - req recebida")
+
+	var category categoryModel.Category
+
+	err := json.NewDecoder(r.Body).Decode(&category)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "request inválido, falha ao decodificar body")
+		return
+	}
+
+	err = category.ValidateCreate()
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.Create(&category)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// GetByID godoc
+// @Summary Buscar categoria por ID
+// @Description Retorna a categoria e o caminho da raiz até ela
+// @Tags categories
+// @Produce json
+// @Param id path string true "UUID da Categoria"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Router /categories/{id} [get]
+func (c *Controller) GetByID(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Category) GetByID - req recebida")
+
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "id precisa ser um UUID válido")
+		return
+	}
+
+	res := c.Service.GetByID(&id)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// Update godoc
+// @Summary Atualizar categoria
+// @Description Renomeia a categoria e/ou move para outro pai ('parentId') ou para a raiz ('moveToRoot': true). Não é possível mover para dentro da própria subárvore
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param category body categoryModel.Category true "Categoria"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
+// @Router /categories [put]
+func (c *Controller) Update(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Category) Update - req recebida")
+
+	var category categoryModel.Category
+
+	err := json.NewDecoder(r.Body).Decode(&category)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "request inválido, falha ao decodificar body")
+		return
+	}
+
+	err = category.ValidateUpdate()
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.Update(&category)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// Delete godoc
+// @Summary Remover categoria
+// @Description Remove uma categoria sem subcategorias e sem produtos vinculados
+// @Tags categories
+// @Produce json
+// @Param id path string true "UUID da Categoria"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
+// @Router /categories/{id} [delete]
+func (c *Controller) Delete(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Category) Delete - req recebida")
+
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "id precisa ser um UUID válido")
+		return
+	}
+
+	res := c.Service.Delete(&id)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// Report godoc
+// @Summary Relatório de estoque por categoria
+// @Description Quantidade, reservas e valor de estoque (preço atual x quantidade) por categoria. 'own' considera os produtos da própria categoria e 'total' soma todas as subcategorias
+// @Tags categories
+// @Produce json
+// @Param categoryId query string false "UUID da categoria raiz do relatório"
+// @Param warehouseId query string false "UUID do armazém"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Router /categories/report [get]
+func (c *Controller) Report(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Category) Report - req recebida")
+
+	var categoryId *uuid.UUID
+	if categoryStr := r.URL.Query().Get("categoryId"); categoryStr != "" {
+		id, err := uuid.FromString(categoryStr)
+		if err != nil {
+			httpresponse.JSONError(w, http.StatusBadRequest, "categoryId precisa ser um UUID válido")
+			return
+		}
+		categoryId = &id
+	}
+
+	var warehouseId *uuid.UUID
+	if warehouseStr := r.URL.Query().Get("warehouseId"); warehouseStr != "" {
+		id, err := uuid.FromString(warehouseStr)
+		if err != nil {
+			httpresponse.JSONError(w, http.StatusBadRequest, "warehouseId precisa ser um UUID válido")
+			return
+		}
+		warehouseId = &id
+	}
+
+	res := c.Service.Report(categoryId, warehouseId)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
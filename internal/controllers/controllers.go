@@ -1,10 +1,17 @@
 package controllers
 
 import (
+	"api-estoque/internal/controllers/allocation"
+	"api-estoque/internal/controllers/category"
+	"api-estoque/internal/controllers/graph"
+	"api-estoque/internal/controllers/imports"
 	"api-estoque/internal/controllers/product"
 	stockitems "api-estoque/internal/controllers/stock_items"
 	stockmoves "api-estoque/internal/controllers/stock_moves"
+	"api-estoque/internal/controllers/stream"
+	"api-estoque/internal/controllers/tcc"
 	"api-estoque/internal/controllers/warehouse"
+	"api-estoque/internal/controllers/webhook"
 	"api-estoque/internal/services"
 
 	"github.com/sirupsen/logrus"
@@ -15,13 +22,27 @@ type Controllers struct {
 	StockMovesController *stockmoves.Controller
 	WarehouseController  *warehouse.Controller
 	ProductController    *product.Controller
+	AllocationController *allocation.Controller
+	CategoryController   *category.Controller
+	ImportsController    *imports.Controller
+	WebhookController    *webhook.Controller
+	StreamController     *stream.Controller
+	GraphController      *graph.Controller
+	TccController        *tcc.Controller
 }
 
 func InstanciateControllers(services *services.Services, logger *logrus.Logger) *Controllers {
 	return &Controllers{
-		StockItemsController: stockitems.New(services.StockItemsService, services.StockMovesService, logger),
+		StockItemsController: stockitems.New(services.StockItemsService, logger),
 		StockMovesController: stockmoves.New(services.StockMovesService, logger),
 		WarehouseController:  warehouse.New(services.WarehouseService, logger),
 		ProductController:    product.New(services.ProductService, logger),
+		AllocationController: allocation.New(services.AllocationService, logger),
+		CategoryController:   category.New(services.CategoryService, logger),
+		ImportsController:    imports.New(services.ImportsService, logger),
+		WebhookController:    webhook.New(services.WebhookService, logger),
+		StreamController:     stream.New(services.StreamService, logger),
+		GraphController:      graph.New(services.GraphService, logger),
+		TccController:        tcc.New(services.TccService, logger),
 	}
 }
@@ -0,0 +1,55 @@
+package graph
+
+import (
+	graphModel "api-estoque/internal/model/graph"
+	httpresponse "api-estoque/internal/model/http_response"
+	graphSrvc "api-estoque/internal/services/graph"
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Controller struct {
+	Service *graphSrvc.Service
+	Logger  *logrus.Logger
+}
+
+func New(service *graphSrvc.Service, logger *logrus.Logger) *Controller {
+	return &Controller{
+		Service: service,
+		Logger:  logger,
+	}
+}
+
+// Query godoc
+// @Summary Consulta GraphQL
+// @Description Consulta produtos, galpões, itens de estoque e movimentações com suas relações numa única requisição. Somente leitura. O schema está disponível por introspecção. Erros de consulta voltam com status 200 no campo 'errors', como define o GraphQL
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param query body graphModel.Request true "Consulta"
+// @Success 200 {object} object
+// @Failure 400 {object} httpresponse.Response
+// @Router /graphql [post]
+func (c *Controller) Query(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Graph) Query - req recebida")
+
+	var req graphModel.Request
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "request inválido, falha ao decodificar body")
+		return
+	}
+
+	err = req.Validate()
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.Execute(r.Context(), &req)
+
+	httpresponse.JSONSuccess(w, res)
+}
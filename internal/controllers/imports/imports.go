@@ -0,0 +1,153 @@
+package imports
+
+import (
+	"api-estoque/internal/config"
+	middleware "api-estoque/internal/middleware/auth"
+	httpresponse "api-estoque/internal/model/http_response"
+	importsModel "api-estoque/internal/model/imports"
+	importfile "api-estoque/internal/model/imports/response/import_file"
+	importsSrvc "api-estoque/internal/services/imports"
+	"bufio"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Controller struct {
+	Service *importsSrvc.Service
+	Logger  *logrus.Logger
+}
+
+func New(service *importsSrvc.Service, logger *logrus.Logger) *Controller {
+	return &Controller{
+		Service: service,
+		Logger:  logger,
+	}
+}
+
+// Products godoc
+// @Summary Importar produtos de planilha
+// @Description Importa produtos de um CSV (separado por ',' ou ';') ou XLSX. Colunas: sku (opcional), name, description, price, categoryId, status, lengthMm, widthMm, heightMm, weightGrams e, opcionalmente, price_<moeda> (ex: price_ARS). Preços inteiros na menor unidade da moeda. Cada linha passa pelas mesmas validações do POST /products; a importação é tudo ou nada. Com dryRun=true apenas valida e devolve o relatório
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Planilha CSV ou XLSX"
+// @Param dryRun query bool false "Apenas validar, sem gravar"
+// @Param Idempotency-Key header string false "Chave de idempotência para repetições seguras"
+// @Success 200 {object} importfile.ImportFileResponse
+// @Failure 400 {object} httpresponse.Response
+// @Failure 413 {object} httpresponse.Response
+// @Failure 422 {object} importfile.ImportFileResponse
+// @Router /import/products [post]
+func (c *Controller) Products(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Imports) Products - req recebida")
+
+	table, dryRun, ok := c.readTable(w, r)
+	if !ok {
+		return
+	}
+
+	var changedBy *string
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		changedBy = &claims.Email
+	}
+
+	res := c.Service.Products(table, dryRun, changedBy)
+
+	c.respond(w, res)
+}
+
+// StockItems godoc
+// @Summary Importar saldos iniciais de planilha
+// @Description Importa saldos iniciais de estoque de um CSV (separado por ',' ou ';') ou XLSX. Colunas: warehouseId, productId, quantity e reserved (opcional, padrão 0). Cada linha passa pelas mesmas validações do POST /stock-items; a importação é tudo ou nada. Com dryRun=true apenas valida e devolve o relatório
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Planilha CSV ou XLSX"
+// @Param dryRun query bool false "Apenas validar, sem gravar"
+// @Param Idempotency-Key header string false "Chave de idempotência para repetições seguras"
+// @Success 200 {object} importfile.ImportFileResponse
+// @Failure 400 {object} httpresponse.Response
+// @Failure 413 {object} httpresponse.Response
+// @Failure 422 {object} importfile.ImportFileResponse
+// @Router /import/stock-items [post]
+func (c *Controller) StockItems(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Imports) StockItems - req recebida")
+
+	table, dryRun, ok := c.readTable(w, r)
+	if !ok {
+		return
+	}
+
+	res := c.Service.StockItems(table, dryRun)
+
+	c.respond(w, res)
+}
+
+// readTable le o parametro dryRun e a planilha do campo 'file'. Em caso de
+// erro ja responde ao cliente e retorna ok=false.
+func (c *Controller) readTable(w http.ResponseWriter, r *http.Request) (table *importsModel.Table, dryRun bool, ok bool) {
+	if dryRunStr := r.URL.Query().Get("dryRun"); dryRunStr != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			httpresponse.JSONError(w, http.StatusBadRequest, "parametro 'dryRun' deve ser true ou false")
+			return nil, false, false
+		}
+	}
+
+	maxBytes := config.Env.ImportMaxBytes
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+64<<10)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpresponse.JSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("arquivo excede o limite de %d bytes", maxBytes))
+			return nil, false, false
+		}
+		httpresponse.JSONError(w, http.StatusBadRequest, "request inválido, envie a planilha em multipart/form-data")
+		return nil, false, false
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "campo 'file' faltando")
+		return nil, false, false
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	head, _ := reader.Peek(4)
+	format, err := importsModel.DetectFormat(header.Filename, head)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return nil, false, false
+	}
+
+	table, err = importsModel.ReadTable(reader, format)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return nil, false, false
+	}
+
+	return table, dryRun, true
+}
+
+// respond envia o relatorio tambem quando a importacao e cancelada por erros
+// nas linhas
+func (c *Controller) respond(w http.ResponseWriter, res *importfile.ImportFileResponse) {
+	if res.Status == http.StatusUnprocessableEntity {
+		httpresponse.JSON(w, res.Status, res)
+		return
+	}
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
@@ -1,11 +1,22 @@
 package product
 
 import (
+	"api-estoque/internal/config"
+	middleware "api-estoque/internal/middleware/auth"
+	currencyModel "api-estoque/internal/model/currency"
+	"api-estoque/internal/model/export"
 	httpresponse "api-estoque/internal/model/http_response"
+	"api-estoque/internal/model/pagination"
 	productModel "api-estoque/internal/model/product"
 	productSrvc "api-estoque/internal/services/product"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/gorilla/mux"
@@ -29,13 +40,113 @@ func New(service *productSrvc.Service, logger *logrus.Logger) *Controller {
 // @Description Retorna todos os produtos cadastrados
 // @Tags products
 // @Produce json
+// @Param limit query int false "Itens por página (padrão 50, máximo 200)"
+// @Param cursor query string false "next_cursor retornado pela página anterior"
+// @Param currency query string false "Moeda ISO 4217 do preço, ex: BRL, ARS, UYU, PYG (padrão: moeda configurada na API)"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
 // @Router /products [get]
 func (c *Controller) List(w http.ResponseWriter, r *http.Request) {
 	c.Logger.Info("(Product) List - req recebida")
 
-	res := c.Service.List()
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	currency, err := parseCurrency(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.List(page, currency)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// Export godoc
+// @Summary Exportar catálogo de produtos
+// @Description Exporta todos os produtos em CSV ou XLSX, com as colunas aceitas pela importação. O arquivo é enviado conforme as linhas são lidas
+// @Tags products
+// @Produce text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Formato do arquivo (padrão csv)" Enums(csv, xlsx)
+// @Param currency query string false "Moeda ISO 4217 do preço, ex: BRL, ARS, UYU, PYG (padrão: moeda configurada na API)"
+// @Success 200 {file} file
+// @Failure 400 {object} httpresponse.Response
+// @Router /products/export [get]
+func (c *Controller) Export(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Product) Export - req recebida")
+
+	format, err := export.ParseFormat(r.URL.Query())
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	currency, err := parseCurrency(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writer, err := export.NewWriter(w, format, "products", productModel.ExportColumns)
+	if err != nil {
+		c.Logger.Errorf("(Product) Export - %v", err)
+		httpresponse.JSONError(w, http.StatusInternalServerError, "falha ao iniciar exportacao")
+		return
+	}
+
+	res := c.Service.Export(writer, currency)
+
+	if res.Status != http.StatusOK {
+		export.Fail(w, writer, res.Status, res.Msg)
+		return
+	}
+}
+
+// Search godoc
+// @Summary Buscar produtos por texto
+// @Description Busca textual em português por nome, categoria e descrição, ignorando acentos. O último termo casa por prefixo, para uso em autocompletar. Resultados ordenados por relevância
+// @Tags products
+// @Produce json
+// @Param q query string true "Texto da busca"
+// @Param active query bool false "Apenas produtos ativos"
+// @Param inStock query bool false "Apenas produtos com saldo disponível em algum armazém"
+// @Param limit query int false "Itens por página (padrão 50, máximo 200)"
+// @Param cursor query string false "next_cursor retornado pela página anterior"
+// @Param currency query string false "Moeda ISO 4217 do preço, ex: BRL, ARS, UYU, PYG (padrão: moeda configurada na API)"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Router /products/search [get]
+func (c *Controller) Search(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Product) Search - req recebida")
+
+	query, err := productModel.ParseSearch(r.URL.Query())
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	currency, err := parseCurrency(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.Search(query, page, currency)
 
 	if res.Status != http.StatusOK {
 		httpresponse.JSONError(w, res.Status, res.Msg)
@@ -54,6 +165,7 @@ func (c *Controller) List(w http.ResponseWriter, r *http.Request) {
 // @Param product body productModel.Product true "Produto"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
 // @Router /products [post]
 func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
 	c.Logger.Info("(Product) Create - req recebida")
@@ -72,6 +184,10 @@ func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		product.ChangedBy = &claims.Email
+	}
+
 	res := c.Service.Create(&product)
 
 	if res.Status != http.StatusOK {
@@ -82,13 +198,59 @@ func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
 	httpresponse.JSONSuccess(w, res)
 }
 
+// BulkUpsert godoc
+// @Summary Criar ou atualizar produtos em lote
+// @Description Recebe até 500 produtos identificados pelo 'sku': cria os que não existem e atualiza os existentes, em uma única transação. Cada item segue as regras do POST /products; em produtos existentes, 'images' e dimensões omitidas são mantidas e 'status' segue as transições permitidas. O resultado de cada item (created, updated, unchanged ou error) volta na mesma posição do lote; itens com erro não impedem a gravação dos demais
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param products body productModel.BulkUpsert true "Lote de produtos"
+// @Param Idempotency-Key header string false "Chave de idempotência para repetições seguras"
+// @Success 200 {object} bulkupsert.BulkUpsertResponse
+// @Failure 400 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
+// @Router /products/bulk [post]
+func (c *Controller) BulkUpsert(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Product) BulkUpsert - req recebida")
+
+	var bulk productModel.BulkUpsert
+
+	err := json.NewDecoder(r.Body).Decode(&bulk)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "request inválido, falha ao decodificar body")
+		return
+	}
+
+	err = bulk.Validate()
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var changedBy *string
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		changedBy = &claims.Email
+	}
+
+	res := c.Service.BulkUpsert(&bulk, changedBy)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
 // GetByID godoc
 // @Summary Buscar produto por ID
 // @Description Retorna um produto específico pelo seu ID
 // @Tags products
 // @Produce json
 // @Param id path string true "UUID do Produto"
+// @Param currency query string false "Moeda ISO 4217 do preço, ex: BRL, ARS, UYU, PYG (padrão: moeda configurada na API)"
 // @Success 200 {object} httpresponse.Response
+// @Header 200 {string} ETag "Versão do produto, para uso no If-Match"
 // @Failure 400 {object} httpresponse.Response
 // @Failure 404 {object} httpresponse.Response
 // @Router /products/{id} [get]
@@ -104,26 +266,36 @@ func (c *Controller) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res := c.Service.GetByID(&id)
+	currency, err := parseCurrency(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.GetByID(&id, currency)
 
 	if res.Status != http.StatusOK {
 		httpresponse.JSONError(w, res.Status, res.Msg)
 		return
 	}
 
+	w.Header().Set("ETag", httpresponse.ETag(res.Version))
 	httpresponse.JSONSuccess(w, res)
 }
 
 // Update godoc
 // @Summary Atualizar produto
-// @Description Atualiza os dados de um produto existente
+// @Description Atualiza os dados de um produto existente. Com If-Match, só atualiza se o produto não mudou desde a leitura
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param product body productModel.Product true "Produto"
+// @Param If-Match header string false "ETag forte retornado pelo GET; um único valor ou *"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
 // @Failure 404 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
+// @Failure 412 {object} httpresponse.Response
 // @Router /products/{id} [put]
 func (c *Controller) Update(w http.ResponseWriter, r *http.Request) {
 	c.Logger.Info("(Product) Update - req recebida")
@@ -142,13 +314,31 @@ func (c *Controller) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res := c.Service.Update(&product)
+	expectedVersion, err := httpresponse.ParseIfMatch(r)
+	if errors.Is(err, httpresponse.ErrWeakETag) {
+		httpresponse.JSONError(w, http.StatusPreconditionFailed, err.Error())
+		return
+	}
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		product.ChangedBy = &claims.Email
+	}
+
+	res := c.Service.Update(&product, expectedVersion)
 
 	if res.Status != http.StatusOK {
 		httpresponse.JSONError(w, res.Status, res.Msg)
 		return
 	}
 
+	if res.Version != nil {
+		w.Header().Set("ETag", httpresponse.ETag(*res.Version))
+	}
+
 	httpresponse.JSONSuccess(w, res)
 }
 
@@ -183,3 +373,302 @@ func (c *Controller) Delete(w http.ResponseWriter, r *http.Request) {
 
 	httpresponse.JSONSuccess(w, res)
 }
+
+// UploadImage godoc
+// @Summary Enviar imagem do produto
+// @Description Envia uma imagem (JPEG, PNG, GIF ou WebP) em multipart/form-data. A API grava o arquivo, gera uma miniatura de até 320px e acrescenta a imagem ao produto. Com 'position', as imagens a partir daquela posição são deslocadas; com 'primary', a imagem passa a ser a principal
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "UUID do Produto"
+// @Param file formData file true "Arquivo da imagem"
+// @Param alt formData string false "Texto alternativo"
+// @Param position formData int false "Posição da imagem (padrão: ao final)"
+// @Param primary formData bool false "Marcar como imagem principal"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Failure 413 {object} httpresponse.Response
+// @Failure 415 {object} httpresponse.Response
+// @Router /products/{id}/images [post]
+func (c *Controller) UploadImage(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Product) UploadImage - req recebida")
+
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "id precisa ser um UUID válido")
+		return
+	}
+
+	// folga para os demais campos e cabecalhos do multipart
+	maxBytes := config.Env.ImageMaxBytes
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+64<<10)
+	if err := r.ParseMultipartForm(maxBytes); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpresponse.JSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("imagem excede o limite de %d bytes", maxBytes))
+			return
+		}
+		httpresponse.JSONError(w, http.StatusBadRequest, "request inválido, envie a imagem em multipart/form-data")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "campo 'file' faltando")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxBytes {
+		httpresponse.JSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("imagem excede o limite de %d bytes", maxBytes))
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "falha ao ler arquivo enviado")
+		return
+	}
+
+	upload := productModel.ImageUpload{Alt: strings.TrimSpace(r.FormValue("alt"))}
+	if positionStr := r.FormValue("position"); positionStr != "" {
+		position, err := strconv.Atoi(positionStr)
+		if err != nil {
+			httpresponse.JSONError(w, http.StatusBadRequest, "campo 'position' deve ser um inteiro")
+			return
+		}
+		upload.Position = &position
+	}
+	if primaryStr := r.FormValue("primary"); primaryStr != "" {
+		upload.Primary, err = strconv.ParseBool(primaryStr)
+		if err != nil {
+			httpresponse.JSONError(w, http.StatusBadRequest, "campo 'primary' deve ser true ou false")
+			return
+		}
+	}
+
+	err = upload.Validate()
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.UploadImage(&id, data, &upload)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// PriceHistory godoc
+// @Summary Histórico de preços do produto
+// @Description Retorna as alterações de preço do produto na moeda pedida, da mais recente à mais antiga, com o momento em que passaram a valer e o usuário responsável. Com 'at', retorna apenas o preço vigente naquele momento
+// @Tags products
+// @Produce json
+// @Param id path string true "UUID do Produto"
+// @Param currency query string false "Moeda ISO 4217 do preço, ex: BRL, ARS, UYU, PYG (padrão: moeda configurada na API)"
+// @Param at query string false "Data RFC 3339 para consultar o preço vigente, ex: 2024-11-29T12:00:00-03:00"
+// @Param limit query int false "Itens por página (padrão 50, máximo 200)"
+// @Param cursor query string false "next_cursor retornado pela página anterior"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Router /products/{id}/price-history [get]
+func (c *Controller) PriceHistory(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Product) PriceHistory - req recebida")
+
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "id precisa ser um UUID válido")
+		return
+	}
+
+	var at *time.Time
+	if atStr := r.URL.Query().Get("at"); atStr != "" {
+		t, err := time.Parse(time.RFC3339, atStr)
+		if err != nil {
+			httpresponse.JSONError(w, http.StatusBadRequest, "parametro 'at' deve ser uma data RFC 3339, ex: 2024-01-31T00:00:00Z")
+			return
+		}
+		at = &t
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	currency, err := parseCurrency(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.PriceHistory(&id, currency, at, page)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// CreatePriceSchedule godoc
+// @Summary Agendar alteração de preço
+// @Description Agenda um novo preço para o produto a partir de 'effectiveAt'. Um worker aplica o preço quando a data chega e o registra no histórico
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "UUID do Produto"
+// @Param schedule body productModel.PriceSchedule true "Agendamento"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Router /products/{id}/price-schedule [post]
+func (c *Controller) CreatePriceSchedule(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Product) CreatePriceSchedule - req recebida")
+
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "id precisa ser um UUID válido")
+		return
+	}
+
+	var schedule productModel.PriceSchedule
+
+	err = json.NewDecoder(r.Body).Decode(&schedule)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "request inválido, falha ao decodificar body")
+		return
+	}
+
+	err = schedule.ValidateCreate()
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	schedule.ProductId = &id
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		schedule.CreatedBy = &claims.Email
+	}
+
+	res := c.Service.CreatePriceSchedule(&schedule)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// ListPriceSchedules godoc
+// @Summary Listar agendamentos de preço
+// @Description Retorna os agendamentos de preço do produto, pela data de vigência. Por padrão apenas os pendentes; com 'all=true', inclui aplicados e cancelados
+// @Tags products
+// @Produce json
+// @Param id path string true "UUID do Produto"
+// @Param all query bool false "Incluir agendamentos aplicados e cancelados"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Router /products/{id}/price-schedule [get]
+func (c *Controller) ListPriceSchedules(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Product) ListPriceSchedules - req recebida")
+
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "id precisa ser um UUID válido")
+		return
+	}
+
+	all := false
+	if allStr := r.URL.Query().Get("all"); allStr != "" {
+		all, err = strconv.ParseBool(allStr)
+		if err != nil {
+			httpresponse.JSONError(w, http.StatusBadRequest, "parametro 'all' deve ser true ou false")
+			return
+		}
+	}
+
+	res := c.Service.ListPriceSchedules(&id, !all)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// CancelPriceSchedule godoc
+// @Summary Cancelar agendamento de preço
+// @Description Cancela um agendamento de preço ainda pendente
+// @Tags products
+// @Produce json
+// @Param id path string true "UUID do Produto"
+// @Param scheduleId path string true "UUID do Agendamento"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
+// @Router /products/{id}/price-schedule/{scheduleId} [delete]
+func (c *Controller) CancelPriceSchedule(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Product) CancelPriceSchedule - req recebida")
+
+	vars := mux.Vars(r)
+
+	id, err := uuid.FromString(vars["id"])
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "id precisa ser um UUID válido")
+		return
+	}
+
+	scheduleId, err := uuid.FromString(vars["scheduleId"])
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "scheduleId precisa ser um UUID válido")
+		return
+	}
+
+	res := c.Service.CancelPriceSchedule(&id, &scheduleId)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// parseCurrency le o parametro 'currency', usando a moeda padrao quando ausente
+func parseCurrency(r *http.Request) (string, error) {
+	code := r.URL.Query().Get("currency")
+	if code == "" {
+		return config.Env.DefaultCurrency, nil
+	}
+	c, ok := currencyModel.Lookup(code)
+	if !ok {
+		return "", errors.New("parametro 'currency' deve ser um código ISO 4217 suportado, ex: BRL, ARS, UYU, PYG")
+	}
+	return c.Code, nil
+}
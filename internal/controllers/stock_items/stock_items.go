@@ -1,12 +1,14 @@
 package stockitems
 
 import (
+	middleware "api-estoque/internal/middleware/auth"
+	"api-estoque/internal/model/export"
 	httpresponse "api-estoque/internal/model/http_response"
+	"api-estoque/internal/model/pagination"
 	stockitemsModel "api-estoque/internal/model/stock_items"
-	stockmoves "api-estoque/internal/model/stock_moves"
 	stockitemsSrvc "api-estoque/internal/services/stock_items"
-	stockmovesSrvc "api-estoque/internal/services/stock_moves"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/gofrs/uuid"
@@ -15,16 +17,14 @@ import (
 )
 
 type Controller struct {
-	Service           *stockitemsSrvc.Service
-	StockMovesService *stockmovesSrvc.Service
-	Logger            *logrus.Logger
+	Service *stockitemsSrvc.Service
+	Logger  *logrus.Logger
 }
 
-func New(service *stockitemsSrvc.Service, stockMovesService *stockmovesSrvc.Service, logger *logrus.Logger) *Controller {
+func New(service *stockitemsSrvc.Service, logger *logrus.Logger) *Controller {
 	return &Controller{
-		Service:           service,
-		StockMovesService: stockMovesService,
-		Logger:            logger,
+		Service: service,
+		Logger:  logger,
 	}
 }
 
@@ -33,13 +33,21 @@ func New(service *stockitemsSrvc.Service, stockMovesService *stockmovesSrvc.Serv
 // @Description Pega todos os registros de item de estoque
 // @Tags stock-items
 // @Produce json
+// @Param limit query int false "Itens por página (padrão 50, máximo 200)"
+// @Param cursor query string false "next_cursor retornado pela página anterior"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
 // @Router /stock-items [get]
 func (c *Controller) List(w http.ResponseWriter, r *http.Request) {
 	c.Logger.Info("(StockItem) List - req recebida")
 
-	res := c.Service.List()
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.List(page)
 
 	if res.Status != http.StatusOK {
 		httpresponse.JSONError(w, res.Status, res.Msg)
@@ -49,6 +57,39 @@ func (c *Controller) List(w http.ResponseWriter, r *http.Request) {
 	httpresponse.JSONSuccess(w, res)
 }
 
+// Export godoc
+// @Summary Exportar posições de estoque
+// @Description Exporta todos os itens de estoque, com os nomes do produto e do armazém, em CSV ou XLSX. O arquivo é enviado conforme as linhas são lidas
+// @Tags stock-items
+// @Produce text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Formato do arquivo (padrão csv)" Enums(csv, xlsx)
+// @Success 200 {file} file
+// @Failure 400 {object} httpresponse.Response
+// @Router /stock-items/export [get]
+func (c *Controller) Export(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(StockItem) Export - req recebida")
+
+	format, err := export.ParseFormat(r.URL.Query())
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writer, err := export.NewWriter(w, format, "stock-items", stockitemsModel.ExportColumns)
+	if err != nil {
+		c.Logger.Errorf("(StockItem) Export - %v", err)
+		httpresponse.JSONError(w, http.StatusInternalServerError, "falha ao iniciar exportacao")
+		return
+	}
+
+	res := c.Service.Export(writer)
+
+	if res.Status != http.StatusOK {
+		export.Fail(w, writer, res.Status, res.Msg)
+		return
+	}
+}
+
 // Create godoc
 // @Summary Cria item de estoque
 // @Description Faz a criação de item de estoque
@@ -56,6 +97,7 @@ func (c *Controller) List(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param stockItem body stockitemsModel.StockItems true "Stock Item"
+// @Param Idempotency-Key header string false "Chave de idempotência para repetições seguras"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
 // @Router /stock-items [post]
@@ -94,6 +136,7 @@ func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
 // @Param idWarehouse path string true "UUID do Warehouse"
 // @Param idProduct path string true "UUID do Produto"
 // @Success 200 {object} httpresponse.Response
+// @Header 200 {string} ETag "Versão do item, para uso no If-Match"
 // @Failure 400 {object} httpresponse.Response
 // @Failure 404 {object} httpresponse.Response
 // @Router /stock-items/{idWarehouse}/{idProduct} [get]
@@ -122,19 +165,23 @@ func (c *Controller) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", httpresponse.ETag(res.Version))
 	httpresponse.JSONSuccess(w, res)
 }
 
 // Update godoc
 // @Summary Atualizar item de estoque
-// @Description Atualiza os dados de um item de estoque existente
+// @Description Atualiza os dados de um item de estoque existente. Com If-Match, só atualiza se o item não mudou desde a leitura
 // @Tags stock-items
 // @Accept json
 // @Produce json
 // @Param stockItem body stockitemsModel.StockItems true "Stock Item"
+// @Param Idempotency-Key header string false "Chave de idempotência para repetições seguras"
+// @Param If-Match header string false "ETag forte retornado pelo GET; um único valor ou *"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
 // @Failure 404 {object} httpresponse.Response
+// @Failure 412 {object} httpresponse.Response
 // @Router /stock-items/{idWarehouse}/{idProduct} [put]
 func (c *Controller) Update(w http.ResponseWriter, r *http.Request) {
 	c.Logger.Info("(StockItem) Update - req recebida")
@@ -153,13 +200,27 @@ func (c *Controller) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res := c.Service.Update(&stockItems)
+	expectedVersion, err := httpresponse.ParseIfMatch(r)
+	if errors.Is(err, httpresponse.ErrWeakETag) {
+		httpresponse.JSONError(w, http.StatusPreconditionFailed, err.Error())
+		return
+	}
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.Update(&stockItems, expectedVersion)
 
 	if res.Status != http.StatusOK {
 		httpresponse.JSONError(w, res.Status, res.Msg)
 		return
 	}
 
+	if res.Version != nil {
+		w.Header().Set("ETag", httpresponse.ETag(*res.Version))
+	}
+
 	httpresponse.JSONSuccess(w, res)
 }
 
@@ -170,9 +231,11 @@ func (c *Controller) Update(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param stockItem body stockitemsModel.StockItemsBaixa true "Stock Item"
+// @Param Idempotency-Key header string false "Chave de idempotência para repetições seguras"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
 // @Failure 404 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
 // @Router /stock-items/baixa [post]
 func (c *Controller) DeductQuantity(w http.ResponseWriter, r *http.Request) {
 	c.Logger.Info("(StockItem) DeductQuantity - req recebida")
@@ -191,20 +254,12 @@ func (c *Controller) DeductQuantity(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	update := c.Service.DeductQuantity(&baixa)
-
-	if update.Status != http.StatusOK {
-		httpresponse.JSONError(w, update.Status, update.Msg)
-		return
+	var createdBy *string
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		createdBy = &claims.Email
 	}
 
-	reason := "Baixa de estoque"
-	res := c.StockMovesService.Create(&stockmoves.StockMove{
-		ProductId:   baixa.ProductId,
-		WarehouseId: baixa.WarehouseId,
-		QtyMoved:    baixa.Quantity,
-		Reason:      &reason,
-	})
+	res := c.Service.DeductQuantity(&baixa, createdBy)
 
 	if res.Status != http.StatusOK {
 		httpresponse.JSONError(w, res.Status, res.Msg)
@@ -1,10 +1,15 @@
 package stockmoves
 
 import (
+	middleware "api-estoque/internal/middleware/auth"
+	"api-estoque/internal/model/export"
 	httpresponse "api-estoque/internal/model/http_response"
+	"api-estoque/internal/model/pagination"
 	stockmoves "api-estoque/internal/model/stock_moves"
 	stockmovesSrvc "api-estoque/internal/services/stock_moves"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/gofrs/uuid"
@@ -25,17 +30,42 @@ func New(service *stockmovesSrvc.Service, logger *logrus.Logger) *Controller {
 }
 
 // List godoc
-// @Summary Listar movimentações de estoque
-// @Description Retorna todas as movimentações de estoque
+// @Summary Consultar movimentações de estoque
+// @Description Consulta o razão de estoque com filtros combináveis e ordenação, para auditoria
 // @Tags stock-moves
 // @Produce json
+// @Param from query string false "Início do período, inclusivo (RFC 3339)"
+// @Param to query string false "Fim do período, exclusivo (RFC 3339)"
+// @Param type query string false "Tipo da movimentação" Enums(in, out, reversal)
+// @Param reason query string false "Trecho do motivo, sem diferenciar maiúsculas"
+// @Param minQty query int false "Quantidade absoluta mínima"
+// @Param maxQty query int false "Quantidade absoluta máxima"
+// @Param categoryId query string false "UUID da categoria do produto, incluindo subcategorias"
+// @Param warehouseId query []string false "UUIDs dos armazéns" collectionFormat(csv)
+// @Param productId query []string false "UUIDs dos produtos" collectionFormat(csv)
+// @Param createdBy query string false "Usuário que registrou a movimentação"
+// @Param sort query string false "Ordenação (padrão -createdAt)" Enums(createdAt, -createdAt, qtyMoved, -qtyMoved)
+// @Param limit query int false "Itens por página (padrão 50, máximo 200)"
+// @Param cursor query string false "next_cursor retornado pela página anterior"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
 // @Router /stock-move [get]
 func (c *Controller) List(w http.ResponseWriter, r *http.Request) {
 	c.Logger.Info("(StockMove) List - req recebida")
 
-	res := c.Service.List()
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query, err := stockmoves.ParseQuery(r.URL.Query())
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.List(query, page)
 
 	if res.Status != http.StatusOK {
 		httpresponse.JSONError(w, res.Status, res.Msg)
@@ -45,12 +75,64 @@ func (c *Controller) List(w http.ResponseWriter, r *http.Request) {
 	httpresponse.JSONSuccess(w, res)
 }
 
+// Export godoc
+// @Summary Exportar movimentações de estoque
+// @Description Exporta o razão de estoque em CSV ou XLSX, com os nomes do produto e do armazém. Aceita os mesmos filtros e ordenação da consulta, sem paginação. O arquivo é enviado conforme as linhas são lidas
+// @Tags stock-moves
+// @Produce text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Formato do arquivo (padrão csv)" Enums(csv, xlsx)
+// @Param from query string false "Início do período, inclusivo (RFC 3339)"
+// @Param to query string false "Fim do período, exclusivo (RFC 3339)"
+// @Param type query string false "Tipo da movimentação" Enums(in, out, reversal)
+// @Param reason query string false "Trecho do motivo, sem diferenciar maiúsculas"
+// @Param minQty query int false "Quantidade absoluta mínima"
+// @Param maxQty query int false "Quantidade absoluta máxima"
+// @Param categoryId query string false "UUID da categoria do produto, incluindo subcategorias"
+// @Param warehouseId query []string false "UUIDs dos armazéns" collectionFormat(csv)
+// @Param productId query []string false "UUIDs dos produtos" collectionFormat(csv)
+// @Param createdBy query string false "Usuário que registrou a movimentação"
+// @Param sort query string false "Ordenação (padrão -createdAt)" Enums(createdAt, -createdAt, qtyMoved, -qtyMoved)
+// @Success 200 {file} file
+// @Failure 400 {object} httpresponse.Response
+// @Router /stock-move/export [get]
+func (c *Controller) Export(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(StockMove) Export - req recebida")
+
+	format, err := export.ParseFormat(r.URL.Query())
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query, err := stockmoves.ParseQuery(r.URL.Query())
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writer, err := export.NewWriter(w, format, "stock-moves", stockmoves.ExportColumns)
+	if err != nil {
+		c.Logger.Errorf("(StockMove) Export - %v", err)
+		httpresponse.JSONError(w, http.StatusInternalServerError, "falha ao iniciar exportacao")
+		return
+	}
+
+	res := c.Service.Export(query, writer)
+
+	if res.Status != http.StatusOK {
+		export.Fail(w, writer, res.Status, res.Msg)
+		return
+	}
+}
+
 // ListByProduct godoc
 // @Summary Listar movimentações por produto
 // @Description Retorna todas as movimentações de estoque de um produto específico
 // @Tags stock-moves
 // @Produce json
 // @Param idProduct path string true "UUID do Produto"
+// @Param limit query int false "Itens por página (padrão 50, máximo 200)"
+// @Param cursor query string false "next_cursor retornado pela página anterior"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
 // @Router /stock-move/by-product/{idProduct} [get]
@@ -66,7 +148,13 @@ func (c *Controller) ListByProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res := c.Service.ListByProduct(&idProduct)
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.ListByProduct(&idProduct, page)
 
 	if res.Status != http.StatusOK {
 		httpresponse.JSONError(w, res.Status, res.Msg)
@@ -82,6 +170,8 @@ func (c *Controller) ListByProduct(w http.ResponseWriter, r *http.Request) {
 // @Tags stock-moves
 // @Produce json
 // @Param idWarehouse path string true "UUID do Armazém"
+// @Param limit query int false "Itens por página (padrão 50, máximo 200)"
+// @Param cursor query string false "next_cursor retornado pela página anterior"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
 // @Router /stock-move/by-warehouse/{idWarehouse} [get]
@@ -97,7 +187,13 @@ func (c *Controller) ListByWarehouse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res := c.Service.ListByWarehouse(&idWarehouse)
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.ListByWarehouse(&idWarehouse, page)
 
 	if res.Status != http.StatusOK {
 		httpresponse.JSONError(w, res.Status, res.Msg)
@@ -114,6 +210,8 @@ func (c *Controller) ListByWarehouse(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param idWarehouse path string true "UUID do Armazém"
 // @Param idProduct path string true "UUID do Produto"
+// @Param limit query int false "Itens por página (padrão 50, máximo 200)"
+// @Param cursor query string false "next_cursor retornado pela página anterior"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
 // @Router /stock-move/by-warehouse-product/{idWarehouse}/{idProduct} [get]
@@ -135,7 +233,13 @@ func (c *Controller) ListByWarehouseAndProduct(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	res := c.Service.ListByWarehouseAndProduct(&idWarehouse, &idProduct)
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.ListByWarehouseAndProduct(&idWarehouse, &idProduct, page)
 
 	if res.Status != http.StatusOK {
 		httpresponse.JSONError(w, res.Status, res.Msg)
@@ -147,13 +251,16 @@ func (c *Controller) ListByWarehouseAndProduct(w http.ResponseWriter, r *http.Re
 
 // Create godoc
 // @Summary Criar movimentação de estoque
-// @Description Cria uma nova movimentação de estoque
+// @Description Registra uma movimentação no razão de estoque, sem alterar o saldo do item de estoque
 // @Tags stock-moves
 // @Accept json
 // @Produce json
 // @Param stockMove body stockmoves.StockMove true "Movimentação de Estoque"
+// @Param Idempotency-Key header string false "Chave de idempotência para repetições seguras"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
 // @Router /stock-move [post]
 func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
 	c.Logger.Info("(StockMove) Create - req recebida")
@@ -172,6 +279,10 @@ func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		stockMove.CreatedBy = &claims.Email
+	}
+
 	res := c.Service.Create(&stockMove)
 
 	if res.Status != http.StatusOK {
@@ -213,3 +324,51 @@ func (c *Controller) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	httpresponse.JSONSuccess(w, res)
 }
+
+// Reverse godoc
+// @Summary Estornar movimentação de estoque
+// @Description Cria uma movimentação com a quantidade oposta vinculada à original. Se a original alterou o saldo (baixas, transferências), a quantidade oposta é aplicada no item de estoque; movimentações criadas só no razão são estornadas só no razão. Cada movimentação só pode ser estornada uma vez, e movimentações que alteraram o saldo de um galpão encerrado não podem ser estornadas
+// @Tags stock-moves
+// @Accept json
+// @Produce json
+// @Param id path string true "UUID da Movimentação"
+// @Param reversal body stockmoves.Reversal false "Motivo do estorno"
+// @Param Idempotency-Key header string false "Chave de idempotência para repetições seguras"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
+// @Router /stock-move/{id}/reverse [post]
+func (c *Controller) Reverse(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(StockMove) Reverse - req recebida")
+
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "id precisa ser um UUID válido")
+		return
+	}
+
+	// body opcional, apenas com o motivo do estorno
+	var reversal stockmoves.Reversal
+	err = json.NewDecoder(r.Body).Decode(&reversal)
+	if err != nil && !errors.Is(err, io.EOF) {
+		httpresponse.JSONError(w, http.StatusBadRequest, "request invalido, falha ao decodificar body")
+		return
+	}
+
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		reversal.CreatedBy = &claims.Email
+	}
+
+	res := c.Service.Reverse(&id, &reversal)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
@@ -0,0 +1,201 @@
+package stream
+
+import (
+	httpresponse "api-estoque/internal/model/http_response"
+	"api-estoque/internal/model/outbox"
+	streamModel "api-estoque/internal/model/stream"
+	streamSrvc "api-estoque/internal/services/stream"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// writeTimeout limita cada escrita no cliente, para um cliente travado nao
+// segurar o stream
+const writeTimeout = 10 * time.Second
+
+type Controller struct {
+	Service  *streamSrvc.Service
+	Logger   *logrus.Logger
+	Upgrader websocket.Upgrader
+}
+
+func New(service *streamSrvc.Service, logger *logrus.Logger) *Controller {
+	return &Controller{
+		Service: service,
+		Logger:  logger,
+		Upgrader: websocket.Upgrader{
+			// a autenticacao e pelo token, entao paineis em outros dominios podem conectar
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// SSE godoc
+// @Summary Stream de saldos de estoque (Server-Sent Events)
+// @Description Envia um evento a cada alteração de saldo dos itens de estoque filtrados. Cada evento traz 'id' (sequência de publicação, crescente e com possíveis lacunas), 'event' (tipo, ex: stock.deducted) e em 'data' o evento com o saldo após a alteração. Para retomar sem perder eventos, reconecte com o header Last-Event-ID (o EventSource faz isso sozinho) ou o parâmetro 'lastEventId'. Se não for possível retomar, o evento 'stream.reset' indica que os saldos devem ser recarregados. Como o EventSource não envia headers, o token pode ir no parâmetro 'access_token'
+// @Tags stream
+// @Produce text/event-stream
+// @Param warehouseId query string false "UUIDs dos armazéns, separados por vírgula"
+// @Param productId query string false "UUIDs dos produtos, separados por vírgula"
+// @Param lastEventId query int false "Último 'id' recebido, alternativa ao header Last-Event-ID"
+// @Param access_token query string false "Token JWT, alternativa ao header Authorization"
+// @Success 200 {string} string "text/event-stream"
+// @Failure 400 {object} httpresponse.Response
+// @Router /stream/stock [get]
+func (c *Controller) SSE(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Stream) SSE - req recebida")
+
+	filter, err := streamModel.ParseFilter(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lastEventId, err := streamModel.ParseLastEventId(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpresponse.JSONError(w, http.StatusInternalServerError, "servidor não suporta streaming")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sink := &sseSink{w: w, flusher: flusher, rc: http.NewResponseController(w)}
+	if err := sink.write("retry: 3000\n\n"); err != nil {
+		return
+	}
+
+	err = c.Service.Stream(r.Context(), filter, lastEventId, sink)
+	if err != nil && !errors.Is(err, streamSrvc.ErrLagging) {
+		c.Logger.Infof("(Stream) SSE - conexao encerrada: %v", err)
+	}
+}
+
+// WebSocket godoc
+// @Summary Stream de saldos de estoque (WebSocket)
+// @Description Mesmo conteúdo do stream SSE em um WebSocket: cada mensagem de texto é o evento em JSON, com 'sequence' servindo de Last-Event-ID. Para retomar, reconecte com 'lastEventId'. A mensagem {"type":"stream.reset"} indica que os saldos devem ser recarregados. O servidor envia ping a cada 15 segundos
+// @Tags stream
+// @Param warehouseId query string false "UUIDs dos armazéns, separados por vírgula"
+// @Param productId query string false "UUIDs dos produtos, separados por vírgula"
+// @Param lastEventId query int false "Última 'sequence' recebida"
+// @Param access_token query string false "Token JWT, alternativa ao header Authorization"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} httpresponse.Response
+// @Router /stream/stock/ws [get]
+func (c *Controller) WebSocket(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Stream) WebSocket - req recebida")
+
+	filter, err := streamModel.ParseFilter(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lastEventId, err := streamModel.ParseLastEventId(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	conn, err := c.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade ja respondeu ao cliente
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// o cliente nao envia mensagens; a leitura processa pong e close e
+	// encerra o stream quando a conexao cai
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	sink := &wsSink{conn: conn}
+	err = c.Service.Stream(ctx, filter, lastEventId, sink)
+	switch {
+	case errors.Is(err, streamSrvc.ErrLagging):
+		sink.close(websocket.CloseTryAgainLater, "cliente atrasado, reconecte com lastEventId")
+	case err != nil:
+		c.Logger.Infof("(Stream) WebSocket - conexao encerrada: %v", err)
+	default:
+		sink.close(websocket.CloseGoingAway, "")
+	}
+}
+
+type sseSink struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	rc      *http.ResponseController
+}
+
+func (s *sseSink) write(text string) error {
+	_ = s.rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := fmt.Fprint(s.w, text); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+func (s *sseSink) Send(event *outbox.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.write("id: " + strconv.FormatInt(event.Sequence, 10) + "\nevent: " + event.Type + "\ndata: " + string(data) + "\n\n")
+}
+
+func (s *sseSink) Reset() error {
+	return s.write("event: " + streamModel.ResetEvent + "\ndata: {}\n\n")
+}
+
+func (s *sseSink) Ping() error {
+	return s.write(": ping\n\n")
+}
+
+type wsSink struct {
+	conn *websocket.Conn
+}
+
+func (s *wsSink) Send(event *outbox.Event) error {
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return s.conn.WriteJSON(event)
+}
+
+func (s *wsSink) Reset() error {
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return s.conn.WriteJSON(map[string]string{"type": streamModel.ResetEvent})
+}
+
+func (s *wsSink) Ping() error {
+	return s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout))
+}
+
+func (s *wsSink) close(code int, text string) {
+	_ = s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(time.Second))
+}
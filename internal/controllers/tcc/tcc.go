@@ -0,0 +1,168 @@
+package tcc
+
+import (
+	"api-estoque/internal/config"
+	middleware "api-estoque/internal/middleware/auth"
+	httpresponse "api-estoque/internal/model/http_response"
+	tccModel "api-estoque/internal/model/tcc"
+	tccSrvc "api-estoque/internal/services/tcc"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+type Controller struct {
+	Service *tccSrvc.Service
+	Logger  *logrus.Logger
+}
+
+func New(service *tccSrvc.Service, logger *logrus.Logger) *Controller {
+	return &Controller{
+		Service: service,
+		Logger:  logger,
+	}
+}
+
+// Try godoc
+// @Summary Fase Try de uma transação TCC
+// @Description Reserva as linhas para a transação global até o prazo 'timeout_seconds' (ou o padrão da configuração). Todas as linhas são reservadas ou nenhuma. Repetir o Try com as mesmas linhas devolve a transação sem reservar de novo; uma transação já cancelada, inclusive por Cancel recebido antes do Try, é recusada. Sem Confirm dentro do prazo a reserva é liberada automaticamente
+// @Tags tcc
+// @Accept json
+// @Produce json
+// @Param txId path string true "ID global da transação"
+// @Param try body tccModel.Try true "Linhas a reservar e prazo"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
+// @Router /tcc/{txId}/try [post]
+func (c *Controller) Try(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Tcc) Try - req recebida")
+
+	txId := mux.Vars(r)["txId"]
+	if err := tccModel.ValidateTxId(txId); err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var try tccModel.Try
+	err := json.NewDecoder(r.Body).Decode(&try)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "request invalido, falha ao decodificar body")
+		return
+	}
+
+	err = try.Validate(config.Env.TccMaxTimeout)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		try.CreatedBy = &claims.Email
+	}
+
+	res := c.Service.Try(txId, &try)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// Confirm godoc
+// @Summary Fase Confirm de uma transação TCC
+// @Description Transforma a reserva do Try em baixa de estoque, registrada no razão com o ID da transação no motivo e o usuário do token como autor. Repetir o Confirm não dá baixa de novo. Uma transação cancelada ou com o prazo vencido é recusada
+// @Tags tcc
+// @Produce json
+// @Param txId path string true "ID global da transação"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
+// @Router /tcc/{txId}/confirm [post]
+func (c *Controller) Confirm(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Tcc) Confirm - req recebida")
+
+	txId := mux.Vars(r)["txId"]
+	if err := tccModel.ValidateTxId(txId); err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var confirmedBy *string
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		confirmedBy = &claims.Email
+	}
+
+	res := c.Service.Confirm(txId, confirmedBy)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// Cancel godoc
+// @Summary Fase Cancel de uma transação TCC
+// @Description Libera a reserva do Try. Repetir o Cancel não libera de novo. Um Cancel recebido antes do Try registra a transação como cancelada, e o Try que chegar depois é recusado. Uma transação já confirmada é recusada
+// @Tags tcc
+// @Produce json
+// @Param txId path string true "ID global da transação"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
+// @Router /tcc/{txId}/cancel [post]
+func (c *Controller) Cancel(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Tcc) Cancel - req recebida")
+
+	txId := mux.Vars(r)["txId"]
+	if err := tccModel.ValidateTxId(txId); err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.Cancel(txId)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// GetByID godoc
+// @Summary Buscar transação TCC
+// @Description Retorna o estado da transação, as linhas reservadas e o prazo. Um Try vencido continua 'tried' até ser cancelado pela rotina de expiração
+// @Tags tcc
+// @Produce json
+// @Param txId path string true "ID global da transação"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Router /tcc/{txId} [get]
+func (c *Controller) GetByID(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Tcc) GetByID - req recebida")
+
+	txId := mux.Vars(r)["txId"]
+	if err := tccModel.ValidateTxId(txId); err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.GetByID(txId)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
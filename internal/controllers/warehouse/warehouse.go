@@ -1,11 +1,15 @@
 package warehouse
 
 import (
+	middleware "api-estoque/internal/middleware/auth"
 	httpresponse "api-estoque/internal/model/http_response"
+	"api-estoque/internal/model/pagination"
 	warehouseModel "api-estoque/internal/model/warehouse"
 	warehouseSrvc "api-estoque/internal/services/warehouse"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gofrs/uuid"
 	"github.com/gorilla/mux"
@@ -26,16 +30,28 @@ func New(service *warehouseSrvc.Service, logger *logrus.Logger) *Controller {
 
 // List godoc
 // @Summary Listar armazéns
-// @Description Retorna a lista de todos os armazéns cadastrados
+// @Description Retorna a lista dos armazéns ativos cadastrados
 // @Tags warehouse
 // @Produce json
+// @Param includeClosed query bool false "Incluir armazéns encerrados"
+// @Param limit query int false "Itens por página (padrão 50, máximo 200)"
+// @Param cursor query string false "next_cursor retornado pela página anterior"
 // @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
 // @Failure 500 {object} httpresponse.Response
 // @Router /warehouses [get]
 func (c *Controller) List(w http.ResponseWriter, r *http.Request) {
 	c.Logger.Info("(Warehouse) List - req recebida")
 
-	res := c.Service.List()
+	includeClosed := r.URL.Query().Get("includeClosed") == "true"
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.List(includeClosed, page)
 
 	if res.Status != http.StatusOK {
 		httpresponse.JSONError(w, res.Status, res.Msg)
@@ -47,7 +63,7 @@ func (c *Controller) List(w http.ResponseWriter, r *http.Request) {
 
 // Create godoc
 // @Summary Criar armazém
-// @Description Cria um novo armazém no sistema
+// @Description Cria um novo armazém no sistema. 'region' e 'state' são a mesma UF: informe uma delas, ou as duas com o mesmo valor
 // @Tags warehouse
 // @Accept json
 // @Produce json
@@ -90,6 +106,7 @@ func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path string true "UUID do Armazém"
 // @Success 200 {object} httpresponse.Response
+// @Header 200 {string} ETag "Versão do armazém, para uso no If-Match"
 // @Failure 400 {object} httpresponse.Response
 // @Failure 404 {object} httpresponse.Response
 // @Router /warehouses/{id} [get]
@@ -112,19 +129,113 @@ func (c *Controller) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", httpresponse.ETag(res.Version))
+	httpresponse.JSONSuccess(w, res)
+}
+
+// Nearest godoc
+// @Summary Buscar armazéns mais próximos
+// @Description Retorna os armazéns com saldo disponível do produto, ordenados pela distância (great-circle) até o ponto informado
+// @Tags warehouse
+// @Produce json
+// @Param lat query number true "Latitude"
+// @Param lng query number true "Longitude"
+// @Param productId query string true "UUID do Produto"
+// @Param limit query int false "Quantidade máxima de armazéns (padrão 5, máximo 50)"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 500 {object} httpresponse.Response
+// @Router /warehouses/nearest [get]
+func (c *Controller) Nearest(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Warehouse) Nearest - req recebida")
+
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "lat precisa ser um número válido")
+		return
+	}
+	lng, err := strconv.ParseFloat(query.Get("lng"), 64)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "lng precisa ser um número válido")
+		return
+	}
+	err = warehouseModel.ValidateCoordinates(&lat, &lng)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	productId, err := uuid.FromString(query.Get("productId"))
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "productId precisa ser um UUID válido")
+		return
+	}
+
+	limit := 5
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > 50 {
+			httpresponse.JSONError(w, http.StatusBadRequest, "limit precisa ser um inteiro entre 1 e 50")
+			return
+		}
+	}
+
+	res := c.Service.Nearest(lat, lng, &productId, limit)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// Utilization godoc
+// @Summary Ocupação do armazém
+// @Description Retorna o volume e o peso ocupados pelo estoque do armazém em relação às capacidades cadastradas
+// @Tags warehouse
+// @Produce json
+// @Param id path string true "UUID do Armazém"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Router /warehouses/{id}/utilization [get]
+func (c *Controller) Utilization(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Warehouse) Utilization - req recebida")
+
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "id precisa ser um UUID válido")
+		return
+	}
+
+	res := c.Service.Utilization(&id)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
 	httpresponse.JSONSuccess(w, res)
 }
 
 // Update godoc
 // @Summary Atualizar armazém
-// @Description Atualiza os dados de um armazém existente
+// @Description Atualiza os dados de um armazém existente. Atributos omitidos ficam como estão; string vazia limpa os campos opcionais do endereço e a UF. 'region' e 'state' são a mesma UF. Com If-Match, só atualiza se o armazém não mudou desde a leitura
 // @Tags warehouse
 // @Accept json
 // @Produce json
 // @Param warehouse body warehouseModel.Warehouse true "Warehouse"
+// @Param If-Match header string false "ETag forte retornado pelo GET; um único valor ou *"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
 // @Failure 404 {object} httpresponse.Response
+// @Failure 412 {object} httpresponse.Response
 // @Router /warehouses [put]
 func (c *Controller) Update(w http.ResponseWriter, r *http.Request) {
 	c.Logger.Info("(Warehouse) Update - req recebida")
@@ -143,28 +254,44 @@ func (c *Controller) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res := c.Service.Update(&warehouse)
+	expectedVersion, err := httpresponse.ParseIfMatch(r)
+	if errors.Is(err, httpresponse.ErrWeakETag) {
+		httpresponse.JSONError(w, http.StatusPreconditionFailed, err.Error())
+		return
+	}
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.Update(&warehouse, expectedVersion)
 
 	if res.Status != http.StatusOK {
 		httpresponse.JSONError(w, res.Status, res.Msg)
 		return
 	}
 
+	if res.Version != nil {
+		w.Header().Set("ETag", httpresponse.ETag(*res.Version))
+	}
+
 	httpresponse.JSONSuccess(w, res)
 }
 
-// Delete godoc
-// @Summary Remover armazém
-// @Description Exclui um armazém específico pelo seu UUID
+// Close godoc
+// @Summary Encerrar armazém
+// @Description Encerra um armazém. Sem destino, é recusado enquanto houver estoque ou reservas; com destino, todo o estoque restante é transferido para ele
 // @Tags warehouse
 // @Produce json
 // @Param id path string true "UUID do Armazém"
+// @Param targetWarehouseId query string false "UUID do Armazém de destino do estoque restante"
 // @Success 200 {object} httpresponse.Response
 // @Failure 400 {object} httpresponse.Response
 // @Failure 404 {object} httpresponse.Response
+// @Failure 409 {object} httpresponse.Response
 // @Router /warehouses/{id} [delete]
-func (c *Controller) Delete(w http.ResponseWriter, r *http.Request) {
-	c.Logger.Info("(Warehouse) Delete - req recebida")
+func (c *Controller) Close(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Warehouse) Close - req recebida")
 
 	vars := mux.Vars(r)
 	idStr := vars["id"]
@@ -175,7 +302,22 @@ func (c *Controller) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res := c.Service.Delete(&id)
+	var targetId *uuid.UUID
+	if targetStr := r.URL.Query().Get("targetWarehouseId"); targetStr != "" {
+		target, err := uuid.FromString(targetStr)
+		if err != nil {
+			httpresponse.JSONError(w, http.StatusBadRequest, "targetWarehouseId precisa ser um UUID válido")
+			return
+		}
+		targetId = &target
+	}
+
+	var closedBy *string
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		closedBy = &claims.Email
+	}
+
+	res := c.Service.Close(&id, targetId, closedBy)
 
 	if res.Status != http.StatusOK {
 		httpresponse.JSONError(w, res.Status, res.Msg)
@@ -0,0 +1,243 @@
+package webhook
+
+import (
+	middleware "api-estoque/internal/middleware/auth"
+	httpresponse "api-estoque/internal/model/http_response"
+	"api-estoque/internal/model/pagination"
+	webhookModel "api-estoque/internal/model/webhook"
+	webhookSrvc "api-estoque/internal/services/webhook"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+type Controller struct {
+	Service *webhookSrvc.Service
+	Logger  *logrus.Logger
+}
+
+func New(service *webhookSrvc.Service, logger *logrus.Logger) *Controller {
+	return &Controller{
+		Service: service,
+		Logger:  logger,
+	}
+}
+
+// List godoc
+// @Summary Listar assinaturas de webhook
+// @Description Retorna todas as assinaturas, da mais recente à mais antiga. O segredo não é retornado
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} httpresponse.Response
+// @Failure 500 {object} httpresponse.Response
+// @Router /webhooks [get]
+func (c *Controller) List(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Webhook) List - req recebida")
+
+	res := c.Service.List()
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// Create godoc
+// @Summary Criar assinatura de webhook
+// @Description Cadastra uma URL para receber os eventos de estoque e de produto por POST. 'eventTypes', 'warehouseIds' e 'productIds' filtram os eventos; vazios recebem todos. Cada entrega é assinada com HMAC-SHA256 do segredo sobre "<X-Webhook-Timestamp>.<body>", enviado em X-Webhook-Signature como "sha256=<hex>". Sem 'secret', um segredo é gerado e retornado apenas nesta resposta. Entregas sem resposta 2xx são repetidas com espera exponencial, e a assinatura é desativada após falhas seguidas. URLs da rede interna (loopback, redes privadas, link-local) são recusadas, também quando o nome passa a resolver para uma delas na entrega
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param subscription body webhookModel.Subscription true "Assinatura"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Router /webhooks [post]
+func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Webhook) Create - req recebida")
+
+	var subscription webhookModel.Subscription
+
+	err := json.NewDecoder(r.Body).Decode(&subscription)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "request inválido, falha ao decodificar body")
+		return
+	}
+
+	err = subscription.ValidateCreate()
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		subscription.CreatedBy = &claims.Email
+	}
+
+	res := c.Service.Create(&subscription)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// GetByID godoc
+// @Summary Buscar assinatura de webhook por ID
+// @Description Retorna a assinatura, sem o segredo, com a contagem de falhas seguidas e o motivo caso esteja desativada
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "UUID da Assinatura"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Router /webhooks/{id} [get]
+func (c *Controller) GetByID(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Webhook) GetByID - req recebida")
+
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "id precisa ser um UUID válido")
+		return
+	}
+
+	res := c.Service.GetByID(&id)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// Update godoc
+// @Summary Atualizar assinatura de webhook
+// @Description Altera URL, segredo, tipos de evento e filtros. Atributos omitidos ficam como estão; uma lista vazia remove o filtro. 'isActive' true reativa uma assinatura desativada e zera as falhas; as entregas pendentes voltam a ser enviadas. Eventos ocorridos enquanto desativada não são entregues
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param subscription body webhookModel.Subscription true "Assinatura"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Router /webhooks [put]
+func (c *Controller) Update(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Webhook) Update - req recebida")
+
+	var subscription webhookModel.Subscription
+
+	err := json.NewDecoder(r.Body).Decode(&subscription)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "request inválido, falha ao decodificar body")
+		return
+	}
+
+	err = subscription.ValidateUpdate()
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var changedBy *string
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		changedBy = &claims.Email
+	}
+
+	res := c.Service.Update(&subscription, changedBy)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// Delete godoc
+// @Summary Remover assinatura de webhook
+// @Description Remove a assinatura e o log de entregas dela
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "UUID da Assinatura"
+// @Success 200 {object} httpresponse.Response
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Router /webhooks/{id} [delete]
+func (c *Controller) Delete(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Webhook) Delete - req recebida")
+
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "id precisa ser um UUID válido")
+		return
+	}
+
+	res := c.Service.Delete(&id)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
+
+// Deliveries godoc
+// @Summary Log de entregas do webhook
+// @Description Retorna as entregas da assinatura, da mais recente à mais antiga, com o evento enviado, o número de tentativas, o status HTTP e o erro da última tentativa (apenas a linha de status, sem o corpo da resposta) e, se pendente, quando será a próxima
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "UUID da Assinatura"
+// @Param status query string false "Filtra por situação: pending, delivered ou failed"
+// @Param limit query int false "Itens por página (padrão 50, máximo 200)"
+// @Param cursor query string false "next_cursor retornado pela página anterior"
+// @Success 200 {object} deliveries.DeliveriesResponse
+// @Failure 400 {object} httpresponse.Response
+// @Failure 404 {object} httpresponse.Response
+// @Router /webhooks/{id}/deliveries [get]
+func (c *Controller) Deliveries(w http.ResponseWriter, r *http.Request) {
+	c.Logger.Info("(Webhook) Deliveries - req recebida")
+
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, "id precisa ser um UUID válido")
+		return
+	}
+
+	status, err := webhookModel.ParseDeliveryStatus(r.URL.Query().Get("status"))
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := pagination.FromRequest(r)
+	if err != nil {
+		httpresponse.JSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := c.Service.Deliveries(&id, status, page)
+
+	if res.Status != http.StatusOK {
+		httpresponse.JSONError(w, res.Status, res.Msg)
+		return
+	}
+
+	httpresponse.JSONSuccess(w, res)
+}
@@ -0,0 +1,70 @@
+package grpcserver
+
+import (
+	middleware "api-estoque/internal/middleware/auth"
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// allowedRoles sao as roles aceitas em todas as chamadas, as mesmas das rotas
+// de estoque da API HTTP
+var allowedRoles = []string{"Administrador", "Manager"}
+
+// authenticate valida o JWT enviado no metadata 'authorization' e guarda as
+// claims no contexto, como o JWTAuthMiddleware faz nas requisicoes HTTP
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		s.Logger.Warn("(Grpc) Acesso negado por: metadata authorization faltando")
+		return nil, status.Error(codes.Unauthenticated, "metadata 'authorization' faltando ou invalido")
+	}
+	parts := strings.Split(values[0], " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		s.Logger.Warn("(Grpc) Acesso negado por: metadata authorization invalido")
+		return nil, status.Error(codes.Unauthenticated, "metadata 'authorization' faltando ou invalido")
+	}
+
+	claims, err := middleware.ParseToken(parts[1])
+	if err != nil {
+		s.Logger.Warn("(Grpc) Acesso negado por: token invalido ou expirado")
+		return nil, status.Error(codes.Unauthenticated, "token invalido ou expirado")
+	}
+	if !middleware.HasAllowedRole(claims.Role, allowedRoles) {
+		s.Logger.Warn("(Grpc) Acesso negado por: role incompativel")
+		return nil, status.Error(codes.PermissionDenied, "forbidden: role incompativel")
+	}
+
+	return middleware.WithClaims(ctx, claims), nil
+}
+
+func (s *Server) unaryAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) streamAuth(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticatedStream troca o contexto do stream pelo que leva as claims
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (a *authenticatedStream) Context() context.Context {
+	return a.ctx
+}
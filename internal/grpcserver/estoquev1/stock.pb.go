@@ -0,0 +1,1044 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: estoque/v1/stock.proto
+
+package estoquev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StockLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	WarehouseId   string                 `protobuf:"bytes,2,opt,name=warehouse_id,json=warehouseId,proto3" json:"warehouse_id,omitempty"`
+	Quantity      int64                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StockLine) Reset() {
+	*x = StockLine{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StockLine) ProtoMessage() {}
+
+func (x *StockLine) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StockLine.ProtoReflect.Descriptor instead.
+func (*StockLine) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StockLine) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *StockLine) GetWarehouseId() string {
+	if x != nil {
+		return x.WarehouseId
+	}
+	return ""
+}
+
+func (x *StockLine) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type Availability struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ProductId   string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	WarehouseId string                 `protobuf:"bytes,2,opt,name=warehouse_id,json=warehouseId,proto3" json:"warehouse_id,omitempty"`
+	Requested   int64                  `protobuf:"varint,3,opt,name=requested,proto3" json:"requested,omitempty"`
+	// Saldo disponivel; zero quando o item de estoque nao existe.
+	Available  int64 `protobuf:"varint,4,opt,name=available,proto3" json:"available,omitempty"`
+	Sufficient bool  `protobuf:"varint,5,opt,name=sufficient,proto3" json:"sufficient,omitempty"`
+	Found      bool  `protobuf:"varint,6,opt,name=found,proto3" json:"found,omitempty"`
+	// Preenchido quando a linha e invalida; as demais linhas seguem sendo verificadas.
+	Error         string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Availability) Reset() {
+	*x = Availability{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Availability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Availability) ProtoMessage() {}
+
+func (x *Availability) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Availability.ProtoReflect.Descriptor instead.
+func (*Availability) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Availability) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *Availability) GetWarehouseId() string {
+	if x != nil {
+		return x.WarehouseId
+	}
+	return ""
+}
+
+func (x *Availability) GetRequested() int64 {
+	if x != nil {
+		return x.Requested
+	}
+	return 0
+}
+
+func (x *Availability) GetAvailable() int64 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+func (x *Availability) GetSufficient() bool {
+	if x != nil {
+		return x.Sufficient
+	}
+	return false
+}
+
+func (x *Availability) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *Availability) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CheckAvailabilityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lines         []*StockLine           `protobuf:"bytes,1,rep,name=lines,proto3" json:"lines,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckAvailabilityRequest) Reset() {
+	*x = CheckAvailabilityRequest{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckAvailabilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckAvailabilityRequest) ProtoMessage() {}
+
+func (x *CheckAvailabilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckAvailabilityRequest.ProtoReflect.Descriptor instead.
+func (*CheckAvailabilityRequest) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CheckAvailabilityRequest) GetLines() []*StockLine {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+type CheckAvailabilityResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lines         []*Availability        `protobuf:"bytes,1,rep,name=lines,proto3" json:"lines,omitempty"`
+	AllSufficient bool                   `protobuf:"varint,2,opt,name=all_sufficient,json=allSufficient,proto3" json:"all_sufficient,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckAvailabilityResponse) Reset() {
+	*x = CheckAvailabilityResponse{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckAvailabilityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckAvailabilityResponse) ProtoMessage() {}
+
+func (x *CheckAvailabilityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckAvailabilityResponse.ProtoReflect.Descriptor instead.
+func (*CheckAvailabilityResponse) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CheckAvailabilityResponse) GetLines() []*Availability {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *CheckAvailabilityResponse) GetAllSufficient() bool {
+	if x != nil {
+		return x.AllSufficient
+	}
+	return false
+}
+
+type StreamAvailabilityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Line          *StockLine             `protobuf:"bytes,1,opt,name=line,proto3" json:"line,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamAvailabilityRequest) Reset() {
+	*x = StreamAvailabilityRequest{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamAvailabilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamAvailabilityRequest) ProtoMessage() {}
+
+func (x *StreamAvailabilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamAvailabilityRequest.ProtoReflect.Descriptor instead.
+func (*StreamAvailabilityRequest) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StreamAvailabilityRequest) GetLine() *StockLine {
+	if x != nil {
+		return x.Line
+	}
+	return nil
+}
+
+type StreamAvailabilityResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Availability  *Availability          `protobuf:"bytes,1,opt,name=availability,proto3" json:"availability,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamAvailabilityResponse) Reset() {
+	*x = StreamAvailabilityResponse{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamAvailabilityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamAvailabilityResponse) ProtoMessage() {}
+
+func (x *StreamAvailabilityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamAvailabilityResponse.ProtoReflect.Descriptor instead.
+func (*StreamAvailabilityResponse) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StreamAvailabilityResponse) GetAvailability() *Availability {
+	if x != nil {
+		return x.Availability
+	}
+	return nil
+}
+
+type ReserveRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Lines []*StockLine           `protobuf:"bytes,1,rep,name=lines,proto3" json:"lines,omitempty"`
+	// Identificador unico da reserva, obrigatorio, com o mesmo papel do
+	// request_id de DeductRequest.
+	RequestId     string `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReserveRequest) Reset() {
+	*x = ReserveRequest{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveRequest) ProtoMessage() {}
+
+func (x *ReserveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveRequest.ProtoReflect.Descriptor instead.
+func (*ReserveRequest) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ReserveRequest) GetLines() []*StockLine {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *ReserveRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type ReserveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReserveResponse) Reset() {
+	*x = ReserveResponse{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveResponse) ProtoMessage() {}
+
+func (x *ReserveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveResponse.ProtoReflect.Descriptor instead.
+func (*ReserveResponse) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{7}
+}
+
+type ReleaseRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Lines []*StockLine           `protobuf:"bytes,1,rep,name=lines,proto3" json:"lines,omitempty"`
+	// Identificador unico da liberacao, obrigatorio, com o mesmo papel do
+	// request_id de DeductRequest.
+	RequestId     string `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseRequest) Reset() {
+	*x = ReleaseRequest{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseRequest) ProtoMessage() {}
+
+func (x *ReleaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseRequest) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReleaseRequest) GetLines() []*StockLine {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *ReleaseRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type ReleaseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseResponse) Reset() {
+	*x = ReleaseResponse{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseResponse) ProtoMessage() {}
+
+func (x *ReleaseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseResponse) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{9}
+}
+
+type DeductRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Line  *StockLine             `protobuf:"bytes,1,opt,name=line,proto3" json:"line,omitempty"`
+	// Identificador unico da baixa, obrigatorio, com o mesmo papel do header
+	// Idempotency-Key da API HTTP: vale por usuario e reusa-lo com outra linha e
+	// recusado.
+	RequestId     string `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeductRequest) Reset() {
+	*x = DeductRequest{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeductRequest) ProtoMessage() {}
+
+func (x *DeductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeductRequest.ProtoReflect.Descriptor instead.
+func (*DeductRequest) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeductRequest) GetLine() *StockLine {
+	if x != nil {
+		return x.Line
+	}
+	return nil
+}
+
+func (x *DeductRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type DeductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeductResponse) Reset() {
+	*x = DeductResponse{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeductResponse) ProtoMessage() {}
+
+func (x *DeductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeductResponse.ProtoReflect.Descriptor instead.
+func (*DeductResponse) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{11}
+}
+
+type GetStockRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	ProductId   string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	WarehouseId string                 `protobuf:"bytes,2,opt,name=warehouse_id,json=warehouseId,proto3" json:"warehouse_id,omitempty"`
+	// Quantas movimentacoes recentes do razao incluir; zero nao inclui nenhuma.
+	MovesLimit    int32 `protobuf:"varint,3,opt,name=moves_limit,json=movesLimit,proto3" json:"moves_limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStockRequest) Reset() {
+	*x = GetStockRequest{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStockRequest) ProtoMessage() {}
+
+func (x *GetStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStockRequest.ProtoReflect.Descriptor instead.
+func (*GetStockRequest) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetStockRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *GetStockRequest) GetWarehouseId() string {
+	if x != nil {
+		return x.WarehouseId
+	}
+	return ""
+}
+
+func (x *GetStockRequest) GetMovesLimit() int32 {
+	if x != nil {
+		return x.MovesLimit
+	}
+	return 0
+}
+
+type StockMove struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	QtyMoved      int64                  `protobuf:"varint,2,opt,name=qty_moved,json=qtyMoved,proto3" json:"qty_moved,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	ReversalOf    string                 `protobuf:"bytes,4,opt,name=reversal_of,json=reversalOf,proto3" json:"reversal_of,omitempty"`
+	ReversedBy    string                 `protobuf:"bytes,5,opt,name=reversed_by,json=reversedBy,proto3" json:"reversed_by,omitempty"`
+	CreatedBy     string                 `protobuf:"bytes,6,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StockMove) Reset() {
+	*x = StockMove{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockMove) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StockMove) ProtoMessage() {}
+
+func (x *StockMove) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StockMove.ProtoReflect.Descriptor instead.
+func (*StockMove) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StockMove) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *StockMove) GetQtyMoved() int64 {
+	if x != nil {
+		return x.QtyMoved
+	}
+	return 0
+}
+
+func (x *StockMove) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *StockMove) GetReversalOf() string {
+	if x != nil {
+		return x.ReversalOf
+	}
+	return ""
+}
+
+func (x *StockMove) GetReversedBy() string {
+	if x != nil {
+		return x.ReversedBy
+	}
+	return ""
+}
+
+func (x *StockMove) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *StockMove) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type GetStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	WarehouseId   string                 `protobuf:"bytes,2,opt,name=warehouse_id,json=warehouseId,proto3" json:"warehouse_id,omitempty"`
+	Quantity      int64                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Reserved      int64                  `protobuf:"varint,4,opt,name=reserved,proto3" json:"reserved,omitempty"`
+	Available     int64                  `protobuf:"varint,5,opt,name=available,proto3" json:"available,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Moves         []*StockMove           `protobuf:"bytes,7,rep,name=moves,proto3" json:"moves,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStockResponse) Reset() {
+	*x = GetStockResponse{}
+	mi := &file_estoque_v1_stock_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStockResponse) ProtoMessage() {}
+
+func (x *GetStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_estoque_v1_stock_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStockResponse.ProtoReflect.Descriptor instead.
+func (*GetStockResponse) Descriptor() ([]byte, []int) {
+	return file_estoque_v1_stock_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetStockResponse) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *GetStockResponse) GetWarehouseId() string {
+	if x != nil {
+		return x.WarehouseId
+	}
+	return ""
+}
+
+func (x *GetStockResponse) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *GetStockResponse) GetReserved() int64 {
+	if x != nil {
+		return x.Reserved
+	}
+	return 0
+}
+
+func (x *GetStockResponse) GetAvailable() int64 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+func (x *GetStockResponse) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *GetStockResponse) GetMoves() []*StockMove {
+	if x != nil {
+		return x.Moves
+	}
+	return nil
+}
+
+var File_estoque_v1_stock_proto protoreflect.FileDescriptor
+
+const file_estoque_v1_stock_proto_rawDesc = "" +
+	"\n" +
+	"\x16estoque/v1/stock.proto\x12\n" +
+	"estoque.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"i\n" +
+	"\tStockLine\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12!\n" +
+	"\fwarehouse_id\x18\x02 \x01(\tR\vwarehouseId\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x03R\bquantity\"\xd8\x01\n" +
+	"\fAvailability\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12!\n" +
+	"\fwarehouse_id\x18\x02 \x01(\tR\vwarehouseId\x12\x1c\n" +
+	"\trequested\x18\x03 \x01(\x03R\trequested\x12\x1c\n" +
+	"\tavailable\x18\x04 \x01(\x03R\tavailable\x12\x1e\n" +
+	"\n" +
+	"sufficient\x18\x05 \x01(\bR\n" +
+	"sufficient\x12\x14\n" +
+	"\x05found\x18\x06 \x01(\bR\x05found\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"G\n" +
+	"\x18CheckAvailabilityRequest\x12+\n" +
+	"\x05lines\x18\x01 \x03(\v2\x15.estoque.v1.StockLineR\x05lines\"r\n" +
+	"\x19CheckAvailabilityResponse\x12.\n" +
+	"\x05lines\x18\x01 \x03(\v2\x18.estoque.v1.AvailabilityR\x05lines\x12%\n" +
+	"\x0eall_sufficient\x18\x02 \x01(\bR\rallSufficient\"F\n" +
+	"\x19StreamAvailabilityRequest\x12)\n" +
+	"\x04line\x18\x01 \x01(\v2\x15.estoque.v1.StockLineR\x04line\"Z\n" +
+	"\x1aStreamAvailabilityResponse\x12<\n" +
+	"\favailability\x18\x01 \x01(\v2\x18.estoque.v1.AvailabilityR\favailability\"\\\n" +
+	"\x0eReserveRequest\x12+\n" +
+	"\x05lines\x18\x01 \x03(\v2\x15.estoque.v1.StockLineR\x05lines\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\"\x11\n" +
+	"\x0fReserveResponse\"\\\n" +
+	"\x0eReleaseRequest\x12+\n" +
+	"\x05lines\x18\x01 \x03(\v2\x15.estoque.v1.StockLineR\x05lines\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\"\x11\n" +
+	"\x0fReleaseResponse\"Y\n" +
+	"\rDeductRequest\x12)\n" +
+	"\x04line\x18\x01 \x01(\v2\x15.estoque.v1.StockLineR\x04line\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\"\x10\n" +
+	"\x0eDeductResponse\"t\n" +
+	"\x0fGetStockRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12!\n" +
+	"\fwarehouse_id\x18\x02 \x01(\tR\vwarehouseId\x12\x1f\n" +
+	"\vmoves_limit\x18\x03 \x01(\x05R\n" +
+	"movesLimit\"\xec\x01\n" +
+	"\tStockMove\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tqty_moved\x18\x02 \x01(\x03R\bqtyMoved\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x1f\n" +
+	"\vreversal_of\x18\x04 \x01(\tR\n" +
+	"reversalOf\x12\x1f\n" +
+	"\vreversed_by\x18\x05 \x01(\tR\n" +
+	"reversedBy\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x06 \x01(\tR\tcreatedBy\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x92\x02\n" +
+	"\x10GetStockResponse\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12!\n" +
+	"\fwarehouse_id\x18\x02 \x01(\tR\vwarehouseId\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x03R\bquantity\x12\x1a\n" +
+	"\breserved\x18\x04 \x01(\x03R\breserved\x12\x1c\n" +
+	"\tavailable\x18\x05 \x01(\x03R\tavailable\x129\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12+\n" +
+	"\x05moves\x18\a \x03(\v2\x15.estoque.v1.StockMoveR\x05moves2\xe9\x03\n" +
+	"\fStockService\x12`\n" +
+	"\x11CheckAvailability\x12$.estoque.v1.CheckAvailabilityRequest\x1a%.estoque.v1.CheckAvailabilityResponse\x12g\n" +
+	"\x12StreamAvailability\x12%.estoque.v1.StreamAvailabilityRequest\x1a&.estoque.v1.StreamAvailabilityResponse(\x010\x01\x12B\n" +
+	"\aReserve\x12\x1a.estoque.v1.ReserveRequest\x1a\x1b.estoque.v1.ReserveResponse\x12B\n" +
+	"\aRelease\x12\x1a.estoque.v1.ReleaseRequest\x1a\x1b.estoque.v1.ReleaseResponse\x12?\n" +
+	"\x06Deduct\x12\x19.estoque.v1.DeductRequest\x1a\x1a.estoque.v1.DeductResponse\x12E\n" +
+	"\bGetStock\x12\x1b.estoque.v1.GetStockRequest\x1a\x1c.estoque.v1.GetStockResponseB5Z3api-estoque/internal/grpcserver/estoquev1;estoquev1b\x06proto3"
+
+var (
+	file_estoque_v1_stock_proto_rawDescOnce sync.Once
+	file_estoque_v1_stock_proto_rawDescData []byte
+)
+
+func file_estoque_v1_stock_proto_rawDescGZIP() []byte {
+	file_estoque_v1_stock_proto_rawDescOnce.Do(func() {
+		file_estoque_v1_stock_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_estoque_v1_stock_proto_rawDesc), len(file_estoque_v1_stock_proto_rawDesc)))
+	})
+	return file_estoque_v1_stock_proto_rawDescData
+}
+
+var file_estoque_v1_stock_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_estoque_v1_stock_proto_goTypes = []any{
+	(*StockLine)(nil),                  // 0: estoque.v1.StockLine
+	(*Availability)(nil),               // 1: estoque.v1.Availability
+	(*CheckAvailabilityRequest)(nil),   // 2: estoque.v1.CheckAvailabilityRequest
+	(*CheckAvailabilityResponse)(nil),  // 3: estoque.v1.CheckAvailabilityResponse
+	(*StreamAvailabilityRequest)(nil),  // 4: estoque.v1.StreamAvailabilityRequest
+	(*StreamAvailabilityResponse)(nil), // 5: estoque.v1.StreamAvailabilityResponse
+	(*ReserveRequest)(nil),             // 6: estoque.v1.ReserveRequest
+	(*ReserveResponse)(nil),            // 7: estoque.v1.ReserveResponse
+	(*ReleaseRequest)(nil),             // 8: estoque.v1.ReleaseRequest
+	(*ReleaseResponse)(nil),            // 9: estoque.v1.ReleaseResponse
+	(*DeductRequest)(nil),              // 10: estoque.v1.DeductRequest
+	(*DeductResponse)(nil),             // 11: estoque.v1.DeductResponse
+	(*GetStockRequest)(nil),            // 12: estoque.v1.GetStockRequest
+	(*StockMove)(nil),                  // 13: estoque.v1.StockMove
+	(*GetStockResponse)(nil),           // 14: estoque.v1.GetStockResponse
+	(*timestamppb.Timestamp)(nil),      // 15: google.protobuf.Timestamp
+}
+var file_estoque_v1_stock_proto_depIdxs = []int32{
+	0,  // 0: estoque.v1.CheckAvailabilityRequest.lines:type_name -> estoque.v1.StockLine
+	1,  // 1: estoque.v1.CheckAvailabilityResponse.lines:type_name -> estoque.v1.Availability
+	0,  // 2: estoque.v1.StreamAvailabilityRequest.line:type_name -> estoque.v1.StockLine
+	1,  // 3: estoque.v1.StreamAvailabilityResponse.availability:type_name -> estoque.v1.Availability
+	0,  // 4: estoque.v1.ReserveRequest.lines:type_name -> estoque.v1.StockLine
+	0,  // 5: estoque.v1.ReleaseRequest.lines:type_name -> estoque.v1.StockLine
+	0,  // 6: estoque.v1.DeductRequest.line:type_name -> estoque.v1.StockLine
+	15, // 7: estoque.v1.StockMove.created_at:type_name -> google.protobuf.Timestamp
+	15, // 8: estoque.v1.GetStockResponse.updated_at:type_name -> google.protobuf.Timestamp
+	13, // 9: estoque.v1.GetStockResponse.moves:type_name -> estoque.v1.StockMove
+	2,  // 10: estoque.v1.StockService.CheckAvailability:input_type -> estoque.v1.CheckAvailabilityRequest
+	4,  // 11: estoque.v1.StockService.StreamAvailability:input_type -> estoque.v1.StreamAvailabilityRequest
+	6,  // 12: estoque.v1.StockService.Reserve:input_type -> estoque.v1.ReserveRequest
+	8,  // 13: estoque.v1.StockService.Release:input_type -> estoque.v1.ReleaseRequest
+	10, // 14: estoque.v1.StockService.Deduct:input_type -> estoque.v1.DeductRequest
+	12, // 15: estoque.v1.StockService.GetStock:input_type -> estoque.v1.GetStockRequest
+	3,  // 16: estoque.v1.StockService.CheckAvailability:output_type -> estoque.v1.CheckAvailabilityResponse
+	5,  // 17: estoque.v1.StockService.StreamAvailability:output_type -> estoque.v1.StreamAvailabilityResponse
+	7,  // 18: estoque.v1.StockService.Reserve:output_type -> estoque.v1.ReserveResponse
+	9,  // 19: estoque.v1.StockService.Release:output_type -> estoque.v1.ReleaseResponse
+	11, // 20: estoque.v1.StockService.Deduct:output_type -> estoque.v1.DeductResponse
+	14, // 21: estoque.v1.StockService.GetStock:output_type -> estoque.v1.GetStockResponse
+	16, // [16:22] is the sub-list for method output_type
+	10, // [10:16] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_estoque_v1_stock_proto_init() }
+func file_estoque_v1_stock_proto_init() {
+	if File_estoque_v1_stock_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_estoque_v1_stock_proto_rawDesc), len(file_estoque_v1_stock_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_estoque_v1_stock_proto_goTypes,
+		DependencyIndexes: file_estoque_v1_stock_proto_depIdxs,
+		MessageInfos:      file_estoque_v1_stock_proto_msgTypes,
+	}.Build()
+	File_estoque_v1_stock_proto = out.File
+	file_estoque_v1_stock_proto_goTypes = nil
+	file_estoque_v1_stock_proto_depIdxs = nil
+}
@@ -0,0 +1,91 @@
+package grpcserver
+
+import (
+	"api-estoque/internal/config"
+	"api-estoque/internal/middleware/idempotency"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxRequestIdLength e o mesmo limite do header Idempotency-Key
+const maxRequestIdLength = 255
+
+// checkRequestId valida o request_id obrigatorio das mutacoes de estoque
+func checkRequestId(requestId string) error {
+	if requestId == "" {
+		return status.Error(codes.InvalidArgument, "atributo 'request_id' faltando")
+	}
+	if len(requestId) > maxRequestIdLength {
+		return status.Errorf(codes.InvalidArgument, "atributo 'request_id' excede %d caracteres", maxRequestIdLength)
+	}
+	return nil
+}
+
+// once executa fn uma vez por requestId no escopo, como o middleware de
+// Idempotency-Key da API HTTP: a repeticao com a mesma mensagem devolve o
+// resultado gravado, e com outra mensagem e recusada. fn retorna o status HTTP
+// e a mensagem do service; erros 5xx liberam o requestId para nova tentativa, e
+// o prazo de processamento e renovado enquanto fn roda.
+func (s *Server) once(scope string, requestId string, msg proto.Message, fn func() (int, string)) error {
+	raw, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "request invalido")
+	}
+	sum := sha256.Sum256(raw)
+	hash := hex.EncodeToString(sum[:])
+
+	claimed, err := s.Idempotency.Claim(scope, requestId, hash, config.Env.IdempotencyKeyTTL, config.Env.IdempotencyLockTimeout)
+	if err != nil {
+		s.Logger.Errorf("(Grpc) Idempotency Claim - %v", err)
+		return status.Error(codes.Internal, "falha ao registrar request_id")
+	}
+
+	if !claimed {
+		stored, err := s.Idempotency.Get(scope, requestId)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return status.Error(codes.Aborted, "requisicao com este request_id foi liberada, tente novamente")
+		}
+		if err != nil {
+			s.Logger.Errorf("(Grpc) Idempotency Get - %v", err)
+			return status.Error(codes.Internal, "falha ao consultar request_id")
+		}
+		if stored.RequestHash != hash {
+			return status.Error(codes.InvalidArgument, "request_id ja utilizado com outra requisicao")
+		}
+		if stored.StatusCode == nil {
+			return status.Error(codes.Aborted, "requisicao com este request_id ainda em processamento")
+		}
+		return statusOf(*stored.StatusCode, string(stored.ResponseBody))
+	}
+
+	code, text := func() (int, string) {
+		defer idempotency.Hold(s.Idempotency, scope, requestId, config.Env.IdempotencyLockTimeout, s.Logger)()
+		return fn()
+	}()
+	if code >= http.StatusInternalServerError {
+		if err := s.Idempotency.Release(scope, requestId); err != nil {
+			s.Logger.Errorf("(Grpc) Idempotency Release - %v", err)
+		}
+		return toStatus(code, text)
+	}
+
+	if err := s.Idempotency.Complete(scope, requestId, code, "text/plain", "", []byte(text)); err != nil {
+		s.Logger.Errorf("(Grpc) Idempotency Complete - %v", err)
+	}
+	return statusOf(code, text)
+}
+
+// statusOf e o toStatus que aceita sucesso: 2xx vira nil
+func statusOf(code int, msg string) error {
+	if code >= 200 && code <= 299 {
+		return nil
+	}
+	return toStatus(code, msg)
+}
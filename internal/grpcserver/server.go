@@ -0,0 +1,62 @@
+package grpcserver
+
+import (
+	"api-estoque/internal/grpcserver/estoquev1"
+	idempotencyRepo "api-estoque/internal/repositories/idempotency"
+	"api-estoque/internal/services"
+	stockitems "api-estoque/internal/services/stock_items"
+	stockmoves "api-estoque/internal/services/stock_moves"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server expoe as operacoes de estoque via gRPC para outros servicos. As
+// regras ficam nos mesmos services usados pelos controllers HTTP.
+type Server struct {
+	estoquev1.UnimplementedStockServiceServer
+	StockItemsService *stockitems.Service
+	StockMovesService *stockmoves.Service
+	// Idempotency guarda o resultado das baixas pelo request_id, como o
+	// middleware de Idempotency-Key da API HTTP
+	Idempotency *idempotencyRepo.Repository
+	Logger      *logrus.Logger
+}
+
+func New(services *services.Services, idempotency *idempotencyRepo.Repository, logger *logrus.Logger) *grpc.Server {
+	s := &Server{
+		StockItemsService: services.StockItemsService,
+		StockMovesService: services.StockMovesService,
+		Idempotency:       idempotency,
+		Logger:            logger,
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryAuth),
+		grpc.ChainStreamInterceptor(s.streamAuth),
+	)
+	estoquev1.RegisterStockServiceServer(server, s)
+	return server
+}
+
+// toStatus converte o status HTTP devolvido pelos services no codigo gRPC
+// equivalente
+func toStatus(httpStatus int, msg string) error {
+	code := codes.Internal
+	switch httpStatus {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		code = codes.FailedPrecondition
+	}
+	return status.Error(code, msg)
+}
@@ -0,0 +1,279 @@
+package grpcserver
+
+import (
+	"api-estoque/internal/grpcserver/estoquev1"
+	middleware "api-estoque/internal/middleware/auth"
+	"api-estoque/internal/model/pagination"
+	stockitemsModel "api-estoque/internal/model/stock_items"
+	stockmovesModel "api-estoque/internal/model/stock_moves"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) CheckAvailability(ctx context.Context, req *estoquev1.CheckAvailabilityRequest) (*estoquev1.CheckAvailabilityResponse, error) {
+	s.Logger.Info("(Grpc) CheckAvailability - req recebida")
+
+	lines, err := toLines(req.GetLines())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res := s.StockItemsService.CheckAvailability(lines)
+	if res.Status != http.StatusOK {
+		return nil, toStatus(res.Status, res.Msg)
+	}
+
+	out := &estoquev1.CheckAvailabilityResponse{AllSufficient: res.AllSufficient}
+	for i := range res.Lines {
+		out.Lines = append(out.Lines, toAvailability(&res.Lines[i]))
+	}
+	return out, nil
+}
+
+// StreamAvailability responde cada linha recebida assim que ela chega. Linhas
+// invalidas recebem o erro na propria resposta, sem encerrar o stream.
+func (s *Server) StreamAvailability(stream estoquev1.StockService_StreamAvailabilityServer) error {
+	s.Logger.Info("(Grpc) StreamAvailability - req recebida")
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line := req.GetLine()
+		baixa, err := toBaixa(line)
+		if err == nil {
+			err = baixa.ValidateBaixa()
+		}
+		if err != nil {
+			err = stream.Send(&estoquev1.StreamAvailabilityResponse{Availability: &estoquev1.Availability{
+				ProductId:   line.GetProductId(),
+				WarehouseId: line.GetWarehouseId(),
+				Requested:   line.GetQuantity(),
+				Error:       err.Error(),
+			}})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		res := s.StockItemsService.CheckAvailability([]stockitemsModel.StockItemsBaixa{*baixa})
+		if res.Status != http.StatusOK {
+			return toStatus(res.Status, res.Msg)
+		}
+		if err := stream.Send(&estoquev1.StreamAvailabilityResponse{Availability: toAvailability(&res.Lines[0])}); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) Reserve(ctx context.Context, req *estoquev1.ReserveRequest) (*estoquev1.ReserveResponse, error) {
+	s.Logger.Info("(Grpc) Reserve - req recebida")
+
+	lines, err := toLines(req.GetLines())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	requestId := req.GetRequestId()
+	if err := checkRequestId(requestId); err != nil {
+		return nil, err
+	}
+
+	user := ""
+	if claims := middleware.ClaimsFromContext(ctx); claims != nil {
+		user = claims.Email
+	}
+
+	// o hash cobre so as linhas, como na baixa
+	err = s.once(user+" grpc Reserve", requestId, &estoquev1.ReserveRequest{Lines: req.GetLines()}, func() (int, string) {
+		res := s.StockItemsService.Reserve(&lines)
+		return res.Status, res.Msg
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &estoquev1.ReserveResponse{}, nil
+}
+
+func (s *Server) Release(ctx context.Context, req *estoquev1.ReleaseRequest) (*estoquev1.ReleaseResponse, error) {
+	s.Logger.Info("(Grpc) Release - req recebida")
+
+	lines, err := toLines(req.GetLines())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	requestId := req.GetRequestId()
+	if err := checkRequestId(requestId); err != nil {
+		return nil, err
+	}
+
+	user := ""
+	if claims := middleware.ClaimsFromContext(ctx); claims != nil {
+		user = claims.Email
+	}
+
+	// o hash cobre so as linhas, como na baixa
+	err = s.once(user+" grpc Release", requestId, &estoquev1.ReleaseRequest{Lines: req.GetLines()}, func() (int, string) {
+		res := s.StockItemsService.Release(&lines)
+		return res.Status, res.Msg
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &estoquev1.ReleaseResponse{}, nil
+}
+
+func (s *Server) Deduct(ctx context.Context, req *estoquev1.DeductRequest) (*estoquev1.DeductResponse, error) {
+	s.Logger.Info("(Grpc) Deduct - req recebida")
+
+	if req.GetLine() == nil {
+		return nil, status.Error(codes.InvalidArgument, "atributo 'line' faltando")
+	}
+	baixa, err := toBaixa(req.GetLine())
+	if err == nil {
+		err = baixa.ValidateBaixa()
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	requestId := req.GetRequestId()
+	if err := checkRequestId(requestId); err != nil {
+		return nil, err
+	}
+
+	var createdBy *string
+	user := ""
+	if claims := middleware.ClaimsFromContext(ctx); claims != nil {
+		createdBy = &claims.Email
+		user = claims.Email
+	}
+
+	err = s.once(user+" grpc Deduct", requestId, req.GetLine(), func() (int, string) {
+		res := s.StockItemsService.DeductQuantity(baixa, createdBy)
+		return res.Status, res.Msg
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &estoquev1.DeductResponse{}, nil
+}
+
+func (s *Server) GetStock(ctx context.Context, req *estoquev1.GetStockRequest) (*estoquev1.GetStockResponse, error) {
+	s.Logger.Info("(Grpc) GetStock - req recebida")
+
+	idProduct, err := uuid.FromString(req.GetProductId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "atributo 'product_id' invalido")
+	}
+	idWarehouse, err := uuid.FromString(req.GetWarehouseId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "atributo 'warehouse_id' invalido")
+	}
+	movesLimit := int(req.GetMovesLimit())
+	if movesLimit < 0 || movesLimit > pagination.MaxLimit {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("atributo 'moves_limit' deve ser um inteiro entre 0 e %d", pagination.MaxLimit))
+	}
+
+	res := s.StockItemsService.GetByID(&idWarehouse, &idProduct)
+	if res.Status != http.StatusOK {
+		return nil, toStatus(res.Status, res.Msg)
+	}
+
+	out := &estoquev1.GetStockResponse{
+		ProductId:   res.ProductId.String(),
+		WarehouseId: res.WarehouseId.String(),
+		Quantity:    res.Quantity,
+		Reserved:    res.Reserved,
+		Available:   res.Quantity - res.Reserved,
+		UpdatedAt:   timestamppb.New(res.UpdatedAt),
+	}
+
+	if movesLimit > 0 {
+		moves := s.StockMovesService.ListByWarehouseAndProduct(&idWarehouse, &idProduct, &pagination.Page{Limit: movesLimit})
+		if moves.Status != http.StatusOK {
+			return nil, toStatus(moves.Status, moves.Msg)
+		}
+		for i := range *moves.StockMoves {
+			out.Moves = append(out.Moves, toStockMove(&(*moves.StockMoves)[i]))
+		}
+	}
+	return out, nil
+}
+
+func toBaixa(line *estoquev1.StockLine) (*stockitemsModel.StockItemsBaixa, error) {
+	idProduct, err := uuid.FromString(line.GetProductId())
+	if err != nil {
+		return nil, errors.New("atributo 'product_id' invalido")
+	}
+	idWarehouse, err := uuid.FromString(line.GetWarehouseId())
+	if err != nil {
+		return nil, errors.New("atributo 'warehouse_id' invalido")
+	}
+	quantity := line.GetQuantity()
+	return &stockitemsModel.StockItemsBaixa{
+		ProductId:   &idProduct,
+		WarehouseId: &idWarehouse,
+		Quantity:    &quantity,
+	}, nil
+}
+
+func toLines(lines []*estoquev1.StockLine) ([]stockitemsModel.StockItemsBaixa, error) {
+	out := make([]stockitemsModel.StockItemsBaixa, 0, len(lines))
+	for i, line := range lines {
+		baixa, err := toBaixa(line)
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: %w", i+1, err)
+		}
+		out = append(out, *baixa)
+	}
+	if err := stockitemsModel.ValidateLines(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func toAvailability(a *stockitemsModel.Availability) *estoquev1.Availability {
+	return &estoquev1.Availability{
+		ProductId:   a.ProductId.String(),
+		WarehouseId: a.WarehouseId.String(),
+		Requested:   a.Requested,
+		Available:   a.Available,
+		Sufficient:  a.Sufficient,
+		Found:       a.Found,
+	}
+}
+
+func toStockMove(m *stockmovesModel.StockMove) *estoquev1.StockMove {
+	out := &estoquev1.StockMove{
+		Id:        m.Id.String(),
+		QtyMoved:  *m.QtyMoved,
+		Reason:    *m.Reason,
+		CreatedAt: timestamppb.New(*m.CreatedAt),
+	}
+	if m.ReversalOf != nil {
+		out.ReversalOf = m.ReversalOf.String()
+	}
+	if m.ReversedBy != nil {
+		out.ReversedBy = m.ReversedBy.String()
+	}
+	if m.CreatedBy != nil {
+		out.CreatedBy = *m.CreatedBy
+	}
+	return out
+}
@@ -35,38 +35,42 @@ func JWTAuthMiddleware(allowedRoles ...string) func(http.Handler) http.Handler {
 				return
 			}
 
-			claims := &Claims{}
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					logger.Warn("Acesso negado por: metodo de assinatura invalido")
-					return nil, errors.New("metodo de assinatura invalido")
-				}
-				return []byte(config.Env.JwtSecret), nil
-			})
-
-			if err != nil || !token.Valid {
+			claims, err := ParseToken(tokenString)
+			if err != nil {
 				logger.Warn("Acesso negado por: token invalido ou expirado")
 				httpresponse.JSONError(w, http.StatusUnauthorized, "token invalido ou expirado")
 				return
 			}
 
-			if len(allowedRoles) > 0 && !hasAllowedRole(claims.Role, allowedRoles) {
+			if !HasAllowedRole(claims.Role, allowedRoles) {
 				logger.Warn("Acesso negado por: role incompativel")
 				httpresponse.JSONError(w, http.StatusForbidden, "forbidden: role incompativel")
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), userContextKey, claims)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), claims)))
 		})
 	}
 }
 
+// StreamTokenMiddleware aceita o token no query param 'access_token' quando
+// o header Authorization nao vem, ja que o EventSource e o WebSocket do
+// navegador nao enviam headers customizados. Deve envolver o JWTAuthMiddleware
+// apenas nas rotas de stream.
+func StreamTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("access_token")
+		if r.Header.Get("Authorization") == "" && token != "" {
+			r = r.Clone(r.Context())
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// extractToken le o token do header Authorization.
 func extractToken(r *http.Request) string {
 	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		return ""
-	}
 	parts := strings.Split(authHeader, " ")
 	if len(parts) != 2 || parts[0] != "Bearer" {
 		return ""
@@ -74,7 +78,32 @@ func extractToken(r *http.Request) string {
 	return parts[1]
 }
 
-func hasAllowedRole(userRole string, allowed []string) bool {
+// ParseToken valida a assinatura e a expiracao do token e retorna suas claims.
+// Tambem e usado pelo servidor gRPC, que recebe o token no metadata.
+func ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			logger.Warn("Acesso negado por: metodo de assinatura invalido")
+			return nil, errors.New("metodo de assinatura invalido")
+		}
+		return []byte(config.Env.JwtSecret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("token invalido")
+	}
+	return claims, nil
+}
+
+// HasAllowedRole informa se a role esta entre as permitidas. Sem roles
+// informadas qualquer usuario autenticado e aceito.
+func HasAllowedRole(userRole string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
 	for _, ar := range allowed {
 		if userRole == ar {
 			return true
@@ -84,7 +113,16 @@ func hasAllowedRole(userRole string, allowed []string) bool {
 }
 
 func GetUserClaims(r *http.Request) *Claims {
-	if claims, ok := r.Context().Value(userContextKey).(*Claims); ok {
+	return ClaimsFromContext(r.Context())
+}
+
+// WithClaims guarda as claims do usuario autenticado no contexto
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, userContextKey, claims)
+}
+
+func ClaimsFromContext(ctx context.Context) *Claims {
+	if claims, ok := ctx.Value(userContextKey).(*Claims); ok {
 		return claims
 	}
 	return nil
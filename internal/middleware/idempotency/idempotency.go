@@ -0,0 +1,229 @@
+package idempotency
+
+import (
+	"api-estoque/internal/config"
+	middleware "api-estoque/internal/middleware/auth"
+	httpresponse "api-estoque/internal/model/http_response"
+	idempotencyRepo "api-estoque/internal/repositories/idempotency"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+	maxKeyLength   = 255
+)
+
+type Middleware struct {
+	Repository *idempotencyRepo.Repository
+	TTL        time.Duration
+	Lease      time.Duration
+	Logger     *logrus.Logger
+}
+
+func New(repository *idempotencyRepo.Repository, ttl time.Duration, lease time.Duration, logger *logrus.Logger) *Middleware {
+	return &Middleware{
+		Repository: repository,
+		TTL:        ttl,
+		Lease:      lease,
+		Logger:     logger,
+	}
+}
+
+// Handle torna a rota idempotente quando o cliente envia o header
+// Idempotency-Key: a primeira resposta e gravada e devolvida novamente nas
+// repeticoes com o mesmo body. A chave vale por usuario, metodo e path, e
+// respostas 5xx liberam a chave para uma nova tentativa. Enquanto a requisicao
+// roda a chave fica presa por Lease, renovado ate a resposta; se o processo cair
+// no meio, a chave e liberada quando o prazo vencer.
+func (m *Middleware) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(HeaderKey)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxKeyLength {
+			httpresponse.JSONError(w, http.StatusBadRequest, "header 'Idempotency-Key' excede 255 caracteres")
+			return
+		}
+
+		// o body inteiro fica em memoria para o hash, entao e limitado ao maior
+		// aceito pelas rotas, o das importacoes
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, config.Env.ImportMaxBytes+64<<10))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpresponse.JSONError(w, http.StatusRequestEntityTooLarge, "request excede o tamanho maximo")
+			return
+		}
+		if err != nil {
+			httpresponse.JSONError(w, http.StatusBadRequest, "request invalido, falha ao ler body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := scopeOf(r)
+		hash := hashRequest(r, body)
+
+		claimed, err := m.Repository.Claim(scope, key, hash, m.TTL, m.Lease)
+		if err != nil {
+			m.Logger.Errorf("(Idempotency) Claim - %v", err)
+			httpresponse.JSONError(w, http.StatusInternalServerError, "falha ao registrar chave de idempotencia")
+			return
+		}
+
+		if !claimed {
+			m.replay(w, scope, key, hash)
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		func() {
+			defer Hold(m.Repository, scope, key, m.Lease, m.Logger)()
+			next.ServeHTTP(rec, r)
+		}()
+
+		if rec.status >= http.StatusInternalServerError {
+			if err := m.Repository.Release(scope, key); err != nil {
+				m.Logger.Errorf("(Idempotency) Release - %v", err)
+			}
+			return
+		}
+
+		if err := m.Repository.Complete(scope, key, rec.status, rec.Header().Get("Content-Type"), rec.Header().Get("ETag"), rec.body.Bytes()); err != nil {
+			m.Logger.Errorf("(Idempotency) Complete - %v", err)
+		}
+	})
+}
+
+func (m *Middleware) replay(w http.ResponseWriter, scope string, key string, hash string) {
+	stored, err := m.Repository.Get(scope, key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// a requisicao original falhou e liberou a chave depois do Claim
+		httpresponse.JSONError(w, http.StatusConflict, "requisicao com esta chave de idempotencia foi liberada, tente novamente")
+		return
+	}
+	if err != nil {
+		m.Logger.Errorf("(Idempotency) Get - %v", err)
+		httpresponse.JSONError(w, http.StatusInternalServerError, "falha ao consultar chave de idempotencia")
+		return
+	}
+
+	if stored.RequestHash != hash {
+		httpresponse.JSONError(w, http.StatusUnprocessableEntity, "chave de idempotencia ja utilizada com outro body")
+		return
+	}
+
+	if stored.StatusCode == nil {
+		httpresponse.JSONError(w, http.StatusConflict, "requisicao com esta chave de idempotencia ainda em processamento")
+		return
+	}
+
+	if stored.ContentType != nil && *stored.ContentType != "" {
+		w.Header().Set("Content-Type", *stored.ContentType)
+	}
+	if stored.ETag != nil {
+		w.Header().Set("ETag", *stored.ETag)
+	}
+	w.Header().Set(HeaderReplayed, "true")
+	w.WriteHeader(*stored.StatusCode)
+	_, _ = w.Write(stored.ResponseBody)
+}
+
+// Hold renova o prazo da chave em processamento a cada terco de lease, ate a
+// funcao devolvida ser chamada.
+func Hold(repository *idempotencyRepo.Repository, scope string, key string, lease time.Duration, logger *logrus.Logger) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(lease / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := repository.Extend(scope, key, lease); err != nil {
+					logger.Errorf("(Idempotency) Extend - %v", err)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// StartCleanup remove periodicamente as chaves expiradas ate o contexto ser cancelado.
+func (m *Middleware) StartCleanup(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := m.Repository.DeleteExpired()
+				if err != nil {
+					m.Logger.Errorf("(Idempotency) Cleanup - %v", err)
+					continue
+				}
+				if deleted > 0 {
+					m.Logger.Infof("(Idempotency) Cleanup - %d chaves expiradas removidas", deleted)
+				}
+			}
+		}
+	}()
+}
+
+func scopeOf(r *http.Request) string {
+	user := ""
+	if claims := middleware.GetUserClaims(r); claims != nil {
+		user = claims.Email
+	}
+	return user + " " + r.Method + " " + r.URL.Path
+}
+
+// hashRequest identifica a requisicao pelo metodo, URI e body. Em uploads
+// multipart o boundary e tirado do body, ja que o cliente costuma gerar outro
+// a cada tentativa.
+func hashRequest(r *http.Request, body []byte) string {
+	if mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil &&
+		strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		body = bytes.ReplaceAll(body, []byte(params["boundary"]), nil)
+	}
+
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder repassa a resposta ao cliente e guarda uma copia para o replay.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,73 @@
+package allocation
+
+import (
+	"api-estoque/internal/model/warehouse"
+	"errors"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+)
+
+type OrderLine struct {
+	ProductId *uuid.UUID `json:"product_id"`
+	Quantity  *int64     `json:"quantity"`
+}
+
+type Order struct {
+	Region    *string     `json:"region"`
+	Latitude  *float64    `json:"latitude"`
+	Longitude *float64    `json:"longitude"`
+	Lines     []OrderLine `json:"lines"`
+	Reserve   bool        `json:"reserve"`
+}
+
+// Candidate e um item de estoque com saldo disponivel, junto com os dados do
+// galpao usados para ordenar as propostas. Priority menor tem preferencia.
+type Candidate struct {
+	ProductId   uuid.UUID
+	WarehouseId uuid.UUID
+	Region      *string
+	Latitude    *float64
+	Longitude   *float64
+	Priority    int
+	Available   int64
+}
+
+type Allocation struct {
+	WarehouseId uuid.UUID `json:"warehouse_id"`
+	Quantity    int64     `json:"quantity"`
+}
+
+type LineProposal struct {
+	ProductId   uuid.UUID    `json:"product_id"`
+	Requested   int64        `json:"requested"`
+	Allocated   int64        `json:"allocated"`
+	Unallocated int64        `json:"unallocated"`
+	Split       bool         `json:"split"`
+	Allocations []Allocation `json:"allocations"`
+}
+
+func (o *Order) Validate() error {
+	if o.Region != nil && !warehouse.IsValidUF(*o.Region) {
+		return errors.New("atributo 'region' deve ser uma UF valida")
+	}
+
+	if err := warehouse.ValidateCoordinates(o.Latitude, o.Longitude); err != nil {
+		return err
+	}
+
+	if len(o.Lines) == 0 {
+		return errors.New("atributo 'lines' faltando ou vazio")
+	}
+
+	for i, line := range o.Lines {
+		if line.ProductId == nil {
+			return fmt.Errorf("atributo 'product_id' faltando na linha %d", i)
+		}
+		if line.Quantity == nil || *line.Quantity <= 0 {
+			return fmt.Errorf("atributo 'quantity' deve ser maior que zero na linha %d", i)
+		}
+	}
+
+	return nil
+}
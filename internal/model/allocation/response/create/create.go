@@ -0,0 +1,13 @@
+package create
+
+import (
+	"api-estoque/internal/model/allocation"
+)
+
+type CreateResponse struct {
+	Status      int                       `json:"-"`
+	Msg         string                    `json:"-"`
+	Fulfillable bool                      `json:"fulfillable"`
+	Reserved    bool                      `json:"reserved"`
+	Lines       []allocation.LineProposal `json:"lines"`
+}
@@ -0,0 +1,103 @@
+package category
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Category e um no da arvore de categorias. ParentId nulo indica raiz. Na
+// atualizacao, ParentId omitido mantem o pai e MoveToRoot true move a
+// categoria para a raiz.
+type Category struct {
+	Id         *uuid.UUID  `db:"Id" json:"id"`
+	Name       *string     `db:"Name" json:"name"`
+	Slug       *string     `db:"Slug" json:"slug"`
+	ParentId   *uuid.UUID  `db:"ParentId" json:"parentId"`
+	MoveToRoot bool        `db:"-" json:"moveToRoot,omitempty"`
+	CreatedAt  *time.Time  `db:"CreatedAt" json:"createdAt"`
+	Children   []*Category `db:"-" json:"children,omitempty"`
+}
+
+func (c *Category) ValidateCreate() error {
+	if c.Id != nil {
+		return errors.New("atributo 'id' é controlado pela API")
+	}
+
+	if c.Slug != nil || c.CreatedAt != nil || c.Children != nil {
+		return errors.New("atributos 'slug', 'createdAt' e 'children' são controlados pela API")
+	}
+
+	if c.MoveToRoot {
+		return errors.New("atributo 'moveToRoot' só é aceito na atualização; omita 'parentId' para criar na raiz")
+	}
+
+	return c.validateName(true)
+}
+
+func (c *Category) ValidateUpdate() error {
+	if c.Id == nil {
+		return errors.New("atributo 'id' faltando, necessário para atualizar a categoria")
+	}
+
+	if c.Slug != nil || c.CreatedAt != nil || c.Children != nil {
+		return errors.New("atributos 'slug', 'createdAt' e 'children' são controlados pela API")
+	}
+
+	if c.Name == nil && c.ParentId == nil && !c.MoveToRoot {
+		return errors.New("nenhum atributo informado para atualização")
+	}
+
+	if c.MoveToRoot && c.ParentId != nil {
+		return errors.New("informe 'parentId' ou 'moveToRoot', não ambos")
+	}
+
+	if c.ParentId != nil && *c.ParentId == *c.Id {
+		return errors.New("categoria não pode ser pai de si mesma")
+	}
+
+	return c.validateName(false)
+}
+
+func (c *Category) validateName(required bool) error {
+	if c.Name == nil {
+		if required {
+			return errors.New("atributo 'name' faltando ou vazio")
+		}
+		return nil
+	}
+
+	name := strings.Join(strings.Fields(*c.Name), " ")
+	if name == "" {
+		return errors.New("atributo 'name' faltando ou vazio")
+	}
+	if len(name) > 100 {
+		return errors.New("atributo 'name' deve ter no máximo 100 caracteres")
+	}
+	c.Name = &name
+	return nil
+}
+
+// BuildTree monta a arvore a partir da lista plana, preservando a ordem da lista
+// entre irmaos. Categorias cujo pai nao esta na lista viram raizes.
+func BuildTree(categories []Category) []*Category {
+	byId := make(map[uuid.UUID]*Category, len(categories))
+	for i := range categories {
+		byId[*categories[i].Id] = &categories[i]
+	}
+
+	var roots []*Category
+	for i := range categories {
+		c := &categories[i]
+		if c.ParentId != nil {
+			if parent, ok := byId[*c.ParentId]; ok {
+				parent.Children = append(parent.Children, c)
+				continue
+			}
+		}
+		roots = append(roots, c)
+	}
+	return roots
+}
@@ -0,0 +1,79 @@
+package category
+
+import (
+	"github.com/gofrs/uuid"
+)
+
+// StockTotals sao as quantidades e o valor de estoque (em centavos, pelo preco
+// atual do produto) de uma categoria.
+type StockTotals struct {
+	Products   int64 `json:"products"`
+	Quantity   int64 `json:"quantity"`
+	Reserved   int64 `json:"reserved"`
+	StockValue int64 `json:"stockValue"`
+}
+
+func (t *StockTotals) add(o StockTotals) {
+	t.Products += o.Products
+	t.Quantity += o.Quantity
+	t.Reserved += o.Reserved
+	t.StockValue += o.StockValue
+}
+
+// ReportLine e o estoque de uma categoria: Own conta apenas os produtos ligados
+// diretamente a ela, Total soma tambem todas as subcategorias.
+type ReportLine struct {
+	CategoryId uuid.UUID     `json:"categoryId"`
+	Name       string        `json:"name"`
+	ParentId   *uuid.UUID    `json:"parentId"`
+	Own        StockTotals   `json:"own"`
+	Total      StockTotals   `json:"total"`
+	Children   []*ReportLine `json:"children,omitempty"`
+}
+
+// RollUp monta a arvore do relatorio e acumula o Total de cada categoria a
+// partir das folhas. lines deve conter todas as categorias, com Own preenchido.
+func RollUp(lines []ReportLine) []*ReportLine {
+	byId := make(map[uuid.UUID]*ReportLine, len(lines))
+	for i := range lines {
+		byId[lines[i].CategoryId] = &lines[i]
+	}
+
+	var roots []*ReportLine
+	for i := range lines {
+		line := &lines[i]
+		if line.ParentId != nil {
+			if parent, ok := byId[*line.ParentId]; ok {
+				parent.Children = append(parent.Children, line)
+				continue
+			}
+		}
+		roots = append(roots, line)
+	}
+
+	for _, root := range roots {
+		accumulate(root)
+	}
+	return roots
+}
+
+func accumulate(line *ReportLine) StockTotals {
+	line.Total = line.Own
+	for _, child := range line.Children {
+		line.Total.add(accumulate(child))
+	}
+	return line.Total
+}
+
+// Find procura a categoria na arvore do relatorio
+func Find(roots []*ReportLine, id uuid.UUID) *ReportLine {
+	for _, line := range roots {
+		if line.CategoryId == id {
+			return line
+		}
+		if found := Find(line.Children, id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package getbyid
+
+import (
+	"api-estoque/internal/model/category"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type GetByIdResponse struct {
+	Status    int                 `json:"-"`
+	Msg       string              `json:"-"`
+	Id        uuid.UUID           `json:"id"`
+	Name      string              `json:"name"`
+	Slug      string              `json:"slug"`
+	ParentId  *uuid.UUID          `json:"parentId"`
+	CreatedAt time.Time           `json:"createdAt"`
+	Path      []category.Category `json:"path"`
+}
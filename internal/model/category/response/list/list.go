@@ -0,0 +1,11 @@
+package list
+
+import (
+	"api-estoque/internal/model/category"
+)
+
+type ListResponse struct {
+	Status     int                  `json:"-"`
+	Msg        string               `json:"-"`
+	Categories []*category.Category `json:"categories"`
+}
@@ -0,0 +1,11 @@
+package report
+
+import (
+	"api-estoque/internal/model/category"
+)
+
+type ReportResponse struct {
+	Status     int                    `json:"-"`
+	Msg        string                 `json:"-"`
+	Categories []*category.ReportLine `json:"categories"`
+}
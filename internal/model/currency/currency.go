@@ -0,0 +1,51 @@
+package currency
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Currency e uma moeda ISO 4217. MinorUnits e o numero de casas decimais da
+// menor unidade: valores sao sempre inteiros nessa unidade (centavos, por exemplo).
+type Currency struct {
+	Code       string `json:"code"`
+	MinorUnits int    `json:"minorUnits"`
+}
+
+// supported lista as moedas aceitas: Mercosul, associados e as de referencia
+var supported = map[string]Currency{
+	"BRL": {Code: "BRL", MinorUnits: 2},
+	"ARS": {Code: "ARS", MinorUnits: 2},
+	"UYU": {Code: "UYU", MinorUnits: 2},
+	"PYG": {Code: "PYG", MinorUnits: 0},
+	"BOB": {Code: "BOB", MinorUnits: 2},
+	"CLP": {Code: "CLP", MinorUnits: 0},
+	"USD": {Code: "USD", MinorUnits: 2},
+	"EUR": {Code: "EUR", MinorUnits: 2},
+}
+
+// Lookup retorna a moeda pelo codigo ISO 4217, sem diferenciar maiusculas
+func Lookup(code string) (Currency, bool) {
+	c, ok := supported[strings.ToUpper(strings.TrimSpace(code))]
+	return c, ok
+}
+
+// Format escreve o valor em unidades inteiras da moeda com ponto decimal,
+// ex: 12345 em BRL vira "123.45" e 12345 em PYG vira "12345"
+func (c Currency) Format(amount int64) string {
+	if c.MinorUnits == 0 {
+		return strconv.FormatInt(amount, 10)
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+	if len(digits) <= c.MinorUnits {
+		digits = strings.Repeat("0", c.MinorUnits-len(digits)+1) + digits
+	}
+	cut := len(digits) - c.MinorUnits
+	return sign + digits[:cut] + "." + digits[cut:]
+}
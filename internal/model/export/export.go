@@ -0,0 +1,282 @@
+package export
+
+import (
+	httpresponse "api-estoque/internal/model/http_response"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+
+	// flushEvery e a quantidade de linhas de CSV entre cada envio ao cliente
+	flushEvery = 500
+)
+
+// Writer escreve as linhas de uma exportacao direto na resposta HTTP. Nada e
+// enviado antes da primeira linha (CSV) ou do Close (XLSX), entao ate la um
+// erro ainda pode ser respondido em JSON; depois disso, Started retorna true.
+type Writer interface {
+	Write(values ...any) error
+	Close() error
+	// Discard libera os recursos sem enviar o arquivo, apos uma falha
+	Discard()
+	Started() bool
+}
+
+// Fail responde a falha de uma exportacao. Se o arquivo ja comecou a ser
+// enviado, a conexao e abortada para o cliente nao receber um arquivo truncado
+// como se estivesse completo.
+func Fail(w http.ResponseWriter, writer Writer, statusCode int, msg string) {
+	writer.Discard()
+	if writer.Started() {
+		panic(http.ErrAbortHandler)
+	}
+	httpresponse.JSONError(w, statusCode, msg)
+}
+
+// ParseFormat le o query param 'format', padrao csv
+func ParseFormat(values url.Values) (string, error) {
+	switch format := values.Get("format"); format {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	default:
+		return "", errors.New("parametro 'format' deve ser 'csv' ou 'xlsx'")
+	}
+}
+
+// NewWriter cria o writer do formato. name e o nome do arquivo sem extensao,
+// completado com a data; columns e o cabecalho.
+func NewWriter(w http.ResponseWriter, format string, name string, columns []string) (Writer, error) {
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102-150405"), format)
+	header := make([]any, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: w, filename: filename, header: header}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w, filename, header)
+	default:
+		return nil, fmt.Errorf("formato %q nao suportado", format)
+	}
+}
+
+func setHeaders(w http.ResponseWriter, contentType string, filename string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+// csvWriter envia as linhas conforme sao escritas, com BOM para o Excel
+// reconhecer o UTF-8
+type csvWriter struct {
+	w        http.ResponseWriter
+	filename string
+	header   []any
+	csv      *csv.Writer
+	rows     int
+}
+
+func (c *csvWriter) start() error {
+	setHeaders(c.w, "text/csv; charset=utf-8", c.filename)
+	c.w.WriteHeader(http.StatusOK)
+	if _, err := c.w.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return err
+	}
+	c.csv = csv.NewWriter(c.w)
+	return c.csv.Write(cells(c.header))
+}
+
+func (c *csvWriter) Write(values ...any) error {
+	if c.csv == nil {
+		if err := c.start(); err != nil {
+			return err
+		}
+	}
+	if err := c.csv.Write(cells(values)); err != nil {
+		return err
+	}
+
+	c.rows++
+	if c.rows%flushEvery == 0 {
+		c.csv.Flush()
+		if err := c.csv.Error(); err != nil {
+			return err
+		}
+		if f, ok := c.w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	if c.csv == nil {
+		if err := c.start(); err != nil {
+			return err
+		}
+	}
+	c.csv.Flush()
+	return c.csv.Error()
+}
+
+func (c *csvWriter) Discard() {}
+
+func (c *csvWriter) Started() bool {
+	return c.csv != nil
+}
+
+// xlsxWriter usa o StreamWriter do excelize, que passa as linhas para um
+// arquivo temporario em disco em vez de mante-las em memoria. O arquivo so e
+// enviado no Close, ja que o XLSX e um zip montado no final.
+type xlsxWriter struct {
+	w        http.ResponseWriter
+	filename string
+	file     *excelize.File
+	stream   *excelize.StreamWriter
+	row      int
+	started  bool
+}
+
+func newXLSXWriter(w http.ResponseWriter, filename string, header []any) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter(file.GetSheetName(0))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	x := &xlsxWriter{w: w, filename: filename, file: file, stream: stream, row: 1}
+	if err := stream.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := x.Write(header...); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *xlsxWriter) Write(values ...any) error {
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	row := make([]any, len(values))
+	for i, value := range values {
+		row[i] = xlsxValue(value)
+	}
+	if err := x.stream.SetRow(cell, row); err != nil {
+		return err
+	}
+	x.row++
+	return nil
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+
+	setHeaders(x.w, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", x.filename)
+	x.w.WriteHeader(http.StatusOK)
+	x.started = true
+	return x.file.Write(x.w)
+}
+
+func (x *xlsxWriter) Discard() {
+	x.file.Close()
+}
+
+func (x *xlsxWriter) Started() bool {
+	return x.started
+}
+
+func cells(values []any) []string {
+	out := make([]string, len(values))
+	for i, value := range values {
+		out[i] = cell(value)
+	}
+	return out
+}
+
+// cell converte os tipos usados pelos models em texto. Ponteiros nil viram
+// celula vazia e datas seguem RFC 3339.
+func cell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return *v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case *int64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatInt(*v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case *bool:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatBool(*v)
+	case *uuid.UUID:
+		if v == nil {
+			return ""
+		}
+		return v.String()
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// xlsxValue mantem numeros como numeros na planilha; o resto vai como texto
+func xlsxValue(value any) any {
+	switch v := value.(type) {
+	case int, int64, bool:
+		return v
+	case *int64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *bool:
+		if v == nil {
+			return nil
+		}
+		return *v
+	default:
+		return cell(value)
+	}
+}
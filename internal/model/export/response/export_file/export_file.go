@@ -0,0 +1,10 @@
+package exportfile
+
+// ExportFileResponse e o resultado de uma exportacao. As linhas ja foram
+// enviadas pelo writer; Status diferente de 200 indica falha, respondida em
+// JSON apenas se o envio ainda nao tiver comecado.
+type ExportFileResponse struct {
+	Status int
+	Msg    string
+	Rows   int
+}
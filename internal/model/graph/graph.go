@@ -0,0 +1,23 @@
+package graph
+
+import "errors"
+
+// MaxQueryLength limita o tamanho do texto de uma consulta GraphQL
+const MaxQueryLength = 16 * 1024
+
+// Request e o corpo de uma requisicao GraphQL via POST
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables" swaggertype:"object"`
+}
+
+func (r *Request) Validate() error {
+	if r.Query == "" {
+		return errors.New("atributo 'query' faltando ou vazio")
+	}
+	if len(r.Query) > MaxQueryLength {
+		return errors.New("atributo 'query' excede o tamanho maximo")
+	}
+	return nil
+}
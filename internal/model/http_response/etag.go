@@ -0,0 +1,44 @@
+package httpresponse
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrWeakETag indica um If-Match com ETag fraco (W/). If-Match usa comparacao
+// forte, entao um ETag fraco nunca casa e a resposta e 412.
+var ErrWeakETag = errors.New("header 'If-Match' com ETag fraco nunca corresponde, informe o ETag retornado pelo GET")
+
+// ETag formata a versao da linha como ETag forte.
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ParseIfMatch le o header If-Match. Retorna nil quando o header esta ausente
+// ou e "*", casos em que a atualizacao nao e condicional. So um ETag forte e
+// aceito: ETags fracos retornam ErrWeakETag, e listas separadas por virgula
+// sao recusadas como invalidas.
+func ParseIfMatch(r *http.Request) (*int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	if strings.Contains(header, ",") {
+		return nil, errors.New("header 'If-Match' aceita um unico ETag")
+	}
+	if strings.HasPrefix(header, "W/") {
+		return nil, ErrWeakETag
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return nil, errors.New("header 'If-Match' invalido, informe o ETag retornado pelo GET")
+	}
+
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil {
+		return nil, errors.New("header 'If-Match' invalido, informe o ETag retornado pelo GET")
+	}
+	return &version, nil
+}
@@ -0,0 +1,59 @@
+package httpresponse
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	version := func(v int64) *int64 { return &v }
+
+	tests := []struct {
+		name    string
+		header  string
+		want    *int64
+		wantErr error
+		invalid bool
+	}{
+		{name: "sem header", header: "", want: nil},
+		{name: "asterisco", header: "*", want: nil},
+		{name: "etag forte", header: `"7"`, want: version(7)},
+		{name: "etag forte com espacos", header: ` "42" `, want: version(42)},
+		{name: "etag do ETag()", header: ETag(123), want: version(123)},
+		{name: "etag fraco nunca casa", header: `W/"7"`, wantErr: ErrWeakETag},
+		{name: "lista de etags", header: `"7", "8"`, invalid: true},
+		{name: "sem aspas", header: `7`, invalid: true},
+		{name: "aspas so de um lado", header: `"7`, invalid: true},
+		{name: "aspas sozinhas", header: `"`, invalid: true},
+		{name: "nao numerico", header: `"abc"`, invalid: true},
+		{name: "vazio entre aspas", header: `""`, invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			got, err := ParseIfMatch(r)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseIfMatch(%q) erro = %v, want %v", tt.header, err, tt.wantErr)
+				}
+			case tt.invalid:
+				if err == nil || errors.Is(err, ErrWeakETag) {
+					t.Fatalf("ParseIfMatch(%q) erro = %v, want header invalido", tt.header, err)
+				}
+			case err != nil:
+				t.Fatalf("ParseIfMatch(%q) erro inesperado: %v", tt.header, err)
+			}
+
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("ParseIfMatch(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
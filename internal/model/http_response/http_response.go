@@ -6,8 +6,11 @@ import (
 )
 
 type Response struct {
-	Status int    `json:"status"`
-	Msg    string `json:"msg"`
+	Status   int      `json:"status"`
+	Msg      string   `json:"msg"`
+	Warnings []string `json:"warnings,omitempty"`
+	// Version e a nova versao da linha apos um update, enviada no header ETag.
+	Version *int64 `json:"-"`
 }
 
 func JSONError(w http.ResponseWriter, statusCode int, msg string) {
@@ -20,7 +23,13 @@ func JSONError(w http.ResponseWriter, statusCode int, msg string) {
 }
 
 func JSONSuccess(w http.ResponseWriter, payload any) {
+	JSON(w, http.StatusOK, payload)
+}
+
+// JSON envia payload com um status qualquer, para erros que carregam detalhes
+// alem da mensagem
+func JSON(w http.ResponseWriter, statusCode int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(statusCode)
 	_ = json.NewEncoder(w).Encode(payload)
 }
@@ -0,0 +1,18 @@
+package idempotency
+
+import (
+	"time"
+)
+
+// Record e uma chave de idempotencia ja vista. StatusCode nulo indica que a
+// requisicao original ainda esta em processamento.
+type Record struct {
+	Scope        string
+	Key          string
+	RequestHash  string
+	StatusCode   *int
+	ContentType  *string
+	ETag         *string
+	ResponseBody []byte
+	ExpiresAt    time.Time
+}
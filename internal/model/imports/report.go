@@ -0,0 +1,45 @@
+package imports
+
+import "sort"
+
+// RowError e um erro de uma linha do arquivo. Line e o numero da linha na
+// planilha, contando o cabecalho.
+type RowError struct {
+	Line int    `json:"line"`
+	Msg  string `json:"msg"`
+}
+
+// Report e o resultado de uma importacao. Sem erros e fora do modo dry-run,
+// Committed indica que todas as linhas foram gravadas; com qualquer erro,
+// nenhuma e.
+type Report struct {
+	DryRun    bool       `json:"dryRun"`
+	Total     int        `json:"total"`
+	Valid     int        `json:"valid"`
+	Committed bool       `json:"committed"`
+	Errors    []RowError `json:"errors"`
+	Warnings  []RowError `json:"warnings,omitempty"`
+}
+
+// Fail registra o erro da linha
+func (r *Report) Fail(line int, msg string) {
+	r.Errors = append(r.Errors, RowError{Line: line, Msg: msg})
+}
+
+// Warn registra um aviso da linha, que nao impede a importacao
+func (r *Report) Warn(line int, msg string) {
+	r.Warnings = append(r.Warnings, RowError{Line: line, Msg: msg})
+}
+
+// Finish ordena os erros pela linha e conta as linhas validas
+func (r *Report) Finish() {
+	sort.SliceStable(r.Errors, func(i, j int) bool { return r.Errors[i].Line < r.Errors[j].Line })
+	failed := map[int]bool{}
+	for _, e := range r.Errors {
+		failed[e.Line] = true
+	}
+	r.Valid = r.Total - len(failed)
+	if r.Errors == nil {
+		r.Errors = []RowError{}
+	}
+}
@@ -0,0 +1,11 @@
+package importfile
+
+import (
+	"api-estoque/internal/model/imports"
+)
+
+type ImportFileResponse struct {
+	Status int    `json:"-"`
+	Msg    string `json:"-"`
+	imports.Report
+}
@@ -0,0 +1,149 @@
+package imports
+
+import (
+	"api-estoque/internal/model/currency"
+	productModel "api-estoque/internal/model/product"
+	stockitemsModel "api-estoque/internal/model/stock_items"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// CheckColumns recusa arquivos sem as colunas obrigatorias
+func (t *Table) CheckColumns(required ...string) error {
+	var missing []string
+	for _, column := range required {
+		if !t.Has(column) {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("colunas obrigatorias ausentes: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Product monta o produto da linha a partir das colunas sku, name, description,
+// price, categoryId, status, lengthMm, widthMm, heightMm e weightGrams. Colunas
+// 'price_<moeda>' (ex: price_ARS) preenchem a lista de precos. Erros de
+// conversao apontam a coluna; as regras de negocio ficam com Product.ValidateCreate.
+func (t *Table) Product(row int) (*productModel.Product, error) {
+	p := &productModel.Product{}
+	var err error
+
+	p.Sku = t.optionalString(row, "sku")
+	p.Name = t.optionalString(row, "name")
+	p.Description = t.optionalString(row, "description")
+	p.Status = t.optionalString(row, "status")
+
+	if p.Price, err = t.optionalInt(row, "price"); err != nil {
+		return nil, err
+	}
+	if p.CategoryId, err = t.optionalUUID(row, "categoryId"); err != nil {
+		return nil, err
+	}
+	if p.LengthMm, err = t.optionalInt(row, "lengthMm"); err != nil {
+		return nil, err
+	}
+	if p.WidthMm, err = t.optionalInt(row, "widthMm"); err != nil {
+		return nil, err
+	}
+	if p.HeightMm, err = t.optionalInt(row, "heightMm"); err != nil {
+		return nil, err
+	}
+	if p.WeightGrams, err = t.optionalInt(row, "weightGrams"); err != nil {
+		return nil, err
+	}
+
+	var prices []productModel.Price
+	for _, column := range t.Columns("price") {
+		code := strings.TrimPrefix(column, "price")
+		if _, ok := currency.Lookup(code); !ok {
+			return nil, fmt.Errorf("coluna '%s': moeda '%s' nao suportada", column, strings.ToUpper(code))
+		}
+		amount, err := t.optionalInt(row, column)
+		if err != nil {
+			return nil, err
+		}
+		if amount != nil {
+			prices = append(prices, productModel.Price{Currency: code, Amount: *amount})
+		}
+	}
+	if len(prices) > 0 {
+		p.Prices = &prices
+	}
+
+	return p, nil
+}
+
+// StockItem monta o saldo inicial da linha a partir das colunas warehouseId,
+// productId, quantity e reserved. 'reserved' vazio vale zero.
+func (t *Table) StockItem(row int) (*stockitemsModel.StockItems, error) {
+	s := &stockitemsModel.StockItems{}
+	var err error
+
+	if s.WarehouseId, err = t.optionalUUID(row, "warehouseId"); err != nil {
+		return nil, err
+	}
+	if s.ProductId, err = t.optionalUUID(row, "productId"); err != nil {
+		return nil, err
+	}
+	if s.Quantity, err = t.optionalInt(row, "quantity"); err != nil {
+		return nil, err
+	}
+	if s.Reserved, err = t.optionalInt(row, "reserved"); err != nil {
+		return nil, err
+	}
+	if s.Reserved == nil {
+		zero := int64(0)
+		s.Reserved = &zero
+	}
+
+	if s.Quantity != nil && *s.Quantity < 0 {
+		return nil, errors.New("coluna 'quantity' nao pode ser negativa")
+	}
+	if s.Reserved != nil && *s.Reserved < 0 {
+		return nil, errors.New("coluna 'reserved' nao pode ser negativa")
+	}
+	if s.Quantity != nil && *s.Reserved > *s.Quantity {
+		return nil, errors.New("coluna 'reserved' nao pode ser maior que 'quantity'")
+	}
+
+	return s, nil
+}
+
+func (t *Table) optionalString(row int, column string) *string {
+	value := t.Value(row, column)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// optionalInt aceita apenas inteiros: precos vao na menor unidade da moeda
+func (t *Table) optionalInt(row int, column string) (*int64, error) {
+	value := t.Value(row, column)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("coluna '%s': '%s' nao e um numero inteiro", column, value)
+	}
+	return &n, nil
+}
+
+func (t *Table) optionalUUID(row int, column string) (*uuid.UUID, error) {
+	value := t.Value(row, column)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.FromString(value)
+	if err != nil {
+		return nil, fmt.Errorf("coluna '%s': '%s' nao e um UUID valido", column, value)
+	}
+	return &id, nil
+}
@@ -0,0 +1,210 @@
+package imports
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+
+	// MaxRows limita as linhas de dados de um arquivo, para que a importacao
+	// caiba em uma unica transacao
+	MaxRows = 5000
+)
+
+var ErrTooManyRows = fmt.Errorf("arquivo excede o limite de %d linhas", MaxRows)
+
+// Table e o conteudo de uma planilha: a primeira linha nao vazia e o cabecalho.
+// Lines guarda o numero da linha no arquivo, para o relatorio de erros.
+type Table struct {
+	Header map[string]int
+	Rows   [][]string
+	Lines  []int
+}
+
+// DetectFormat identifica o formato pela extensao do arquivo e, sem extensao
+// conhecida, pelo conteudo: XLSX e um zip.
+func DetectFormat(filename string, head []byte) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	case ".xls":
+		return "", errors.New("formato .xls nao suportado, salve a planilha como .xlsx ou .csv")
+	}
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		return FormatXLSX, nil
+	}
+	return FormatCSV, nil
+}
+
+// ReadTable le a planilha inteira. CSV aceita ',' ou ';' como separador,
+// detectado pelo cabecalho; XLSX usa a primeira aba.
+func ReadTable(r io.Reader, format string) (*Table, error) {
+	var records [][]string
+	var lines []int
+	var err error
+	switch format {
+	case FormatCSV:
+		records, lines, err = readCSV(r)
+	case FormatXLSX:
+		records, lines, err = readXLSX(r)
+	default:
+		return nil, fmt.Errorf("formato %q nao suportado", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{Header: map[string]int{}}
+	for i, record := range records {
+		if isBlank(record) {
+			continue
+		}
+		if len(t.Header) == 0 {
+			for col, name := range record {
+				if key := normalizeHeader(name); key != "" {
+					t.Header[key] = col
+				}
+			}
+			continue
+		}
+		if len(t.Rows) == MaxRows {
+			return nil, ErrTooManyRows
+		}
+		t.Rows = append(t.Rows, record)
+		t.Lines = append(t.Lines, lines[i])
+	}
+
+	if len(t.Header) == 0 {
+		return nil, errors.New("arquivo vazio, a primeira linha deve conter o cabecalho")
+	}
+	return t, nil
+}
+
+// Has informa se a coluna existe no cabecalho
+func (t *Table) Has(column string) bool {
+	_, ok := t.Header[normalizeHeader(column)]
+	return ok
+}
+
+// Value retorna o valor da coluna na linha, sem espacos nas pontas
+func (t *Table) Value(row int, column string) string {
+	col, ok := t.Header[normalizeHeader(column)]
+	if !ok || col >= len(t.Rows[row]) {
+		return ""
+	}
+	return strings.TrimSpace(t.Rows[row][col])
+}
+
+// Columns retorna as colunas do cabecalho que comecam com prefix, normalizadas
+func (t *Table) Columns(prefix string) []string {
+	prefix = normalizeHeader(prefix)
+	var columns []string
+	for name := range t.Header {
+		if strings.HasPrefix(name, prefix) && name != prefix {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+func readCSV(r io.Reader) ([][]string, []int, error) {
+	br := bufio.NewReader(r)
+
+	// descarta o BOM que o Excel grava em CSV UTF-8
+	if bom, err := br.Peek(3); err == nil && bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		br.Discard(3)
+	}
+
+	firstLine, _ := br.Peek(4096)
+	if i := bytes.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records, lines, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("CSV invalido: %v", err)
+		}
+		if len(records) > MaxRows {
+			return nil, nil, ErrTooManyRows
+		}
+		// o leitor pula linhas em branco, entao a linha vem do proprio leitor
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+}
+
+func readXLSX(r io.Reader) ([][]string, []int, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, nil, errors.New("XLSX invalido ou corrompido")
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil, errors.New("XLSX sem abas")
+	}
+
+	rows, err := f.Rows(sheets[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("XLSX invalido: %v", err)
+	}
+	defer rows.Close()
+
+	var records [][]string
+	var lines []int
+	for line := 1; rows.Next(); line++ {
+		// valores crus, sem a formatacao de exibicao (separador de milhar, moeda)
+		record, err := rows.Columns(excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, nil, fmt.Errorf("XLSX invalido: %v", err)
+		}
+		if len(records) > MaxRows {
+			return nil, nil, ErrTooManyRows
+		}
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	return records, lines, rows.Error()
+}
+
+// normalizeHeader deixa 'categoryId', 'category_id' e 'Category ID' iguais
+func normalizeHeader(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer("_", "", "-", "", " ", "").Replace(name)
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package order
+
+import (
+	stockitems "api-estoque/internal/model/stock_items"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Eventos recebidos do servico de pedidos
+const (
+	OrderPlaced    = "OrderPlaced"    // pedido criado, com as linhas a reservar
+	OrderPaid      = "OrderPaid"      // pagamento aprovado, a reserva vira baixa
+	OrderCancelled = "OrderCancelled" // pedido cancelado, a reserva e desfeita
+)
+
+// Respostas publicadas para o servico de pedidos, uma por evento recebido
+const (
+	InventoryReserved          = "InventoryReserved"
+	InventoryReservationFailed = "InventoryReservationFailed"
+	InventoryCommitted         = "InventoryCommitted"
+	InventoryCommitFailed      = "InventoryCommitFailed"
+	InventoryReleased          = "InventoryReleased"
+	InventoryReleaseFailed     = "InventoryReleaseFailed"
+)
+
+// Estados da reserva de um pedido
+const (
+	StatusReserved  = "reserved"
+	StatusCommitted = "committed"
+	StatusReleased  = "released"
+)
+
+// Event e uma mensagem do servico de pedidos. Id identifica a mensagem e e a
+// chave da deduplicacao; Lines so e usado no OrderPlaced e validado a parte,
+// ja que linhas invalidas sao respondidas com falha e nao descartadas.
+type Event struct {
+	Id         string                       `json:"id"`
+	Type       string                       `json:"type"`
+	OrderId    string                       `json:"orderId"`
+	Lines      []stockitems.StockItemsBaixa `json:"lines"`
+	OccurredAt *time.Time                   `json:"occurredAt,omitempty"`
+}
+
+func (e *Event) Validate() error {
+	if e.Id == "" {
+		return errors.New("atributo 'id' faltando")
+	}
+	if e.OrderId == "" {
+		return errors.New("atributo 'orderId' faltando")
+	}
+
+	switch e.Type {
+	case OrderPlaced, OrderPaid, OrderCancelled:
+		return nil
+	default:
+		return fmt.Errorf("tipo de evento '%s' nao suportado", e.Type)
+	}
+}
+
+// Reply e a resposta a um evento. CorrelationId e o id do evento respondido;
+// Reason explica as falhas.
+type Reply struct {
+	Id            uuid.UUID `json:"id"`
+	Type          string    `json:"type"`
+	OrderId       string    `json:"orderId"`
+	CorrelationId string    `json:"correlationId"`
+	Reason        string    `json:"reason,omitempty"`
+	OccurredAt    time.Time `json:"occurredAt"`
+}
+
+// NewReply monta a resposta de sucesso ao evento, ou a de falha quando reason
+// nao e vazio
+func NewReply(e *Event, reason string) *Reply {
+	success, failure := replyTypes(e.Type)
+	reply := &Reply{
+		Id:            uuid.Must(uuid.NewV4()),
+		Type:          success,
+		OrderId:       e.OrderId,
+		CorrelationId: e.Id,
+		OccurredAt:    time.Now().UTC(),
+	}
+	if reason != "" {
+		reply.Type = failure
+		reply.Reason = reason
+	}
+	return reply
+}
+
+func replyTypes(eventType string) (success string, failure string) {
+	switch eventType {
+	case OrderPlaced:
+		return InventoryReserved, InventoryReservationFailed
+	case OrderPaid:
+		return InventoryCommitted, InventoryCommitFailed
+	default:
+		return InventoryReleased, InventoryReleaseFailed
+	}
+}
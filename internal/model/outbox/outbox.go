@@ -0,0 +1,57 @@
+package outbox
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Tipos de evento. Os eventos de estoque levam o saldo do item apos a
+// alteracao; stock.moved leva o lancamento do razao; os de produto levam o
+// produto apos a alteracao.
+const (
+	StockReceived  = "stock.received"  // item de estoque criado com o saldo inicial
+	StockAdjusted  = "stock.adjusted"  // saldo alterado por atualizacao, estorno ou transferencia
+	StockDeducted  = "stock.deducted"  // baixa de estoque
+	StockReserved  = "stock.reserved"  // reserva de estoque
+	StockReleased  = "stock.released"  // reserva desfeita
+	StockRemoved   = "stock.removed"   // item de estoque excluido, com o ultimo saldo
+	StockMoved     = "stock.moved"     // lancamento no razao de estoque
+	ProductCreated = "product.created" // produto criado
+	ProductUpdated = "product.updated" // produto alterado, inclusive status, precos e imagens
+	ProductDeleted = "product.deleted" // produto excluido, com o ultimo estado
+)
+
+// Event e um evento de dominio gravado no outbox. Sequence e a sequencia de
+// publicacao: cresce na ordem em que os eventos sao publicados, pode ter
+// lacunas e muda quando a publicacao e repetida; consumidores devem usar Id
+// para descartar entregas repetidas.
+type Event struct {
+	Sequence    int64           `json:"sequence"`
+	Id          uuid.UUID       `json:"id"`
+	Type        string          `json:"type"`
+	ProductId   uuid.UUID       `json:"productId"`
+	WarehouseId *uuid.UUID      `json:"warehouseId,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+	OccurredAt  time.Time       `json:"occurredAt"`
+}
+
+// Types lista todos os tipos de evento
+var Types = []string{
+	StockReceived,
+	StockAdjusted,
+	StockDeducted,
+	StockReserved,
+	StockReleased,
+	StockRemoved,
+	StockMoved,
+	ProductCreated,
+	ProductUpdated,
+	ProductDeleted,
+}
+
+func IsType(eventType string) bool {
+	return slices.Contains(Types, eventType)
+}
@@ -0,0 +1,119 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+var ErrInvalidCursor = errors.New("cursor invalido, use o next_cursor retornado pela pagina anterior")
+
+// Cursor e a chave de ordenacao da ultima linha de uma pagina. Listas ordenadas
+// por data usam CreatedAt e Id; itens de estoque usam ProductId e WarehouseId.
+// Consultas com 'sort' guardam a ordenacao usada, para recusar o cursor se ela mudar.
+type Cursor struct {
+	CreatedAt   *time.Time `json:"c,omitempty"`
+	Id          *uuid.UUID `json:"i,omitempty"`
+	WarehouseId *uuid.UUID `json:"w,omitempty"`
+	ProductId   *uuid.UUID `json:"p,omitempty"`
+	QtyMoved    *int64     `json:"q,omitempty"`
+	Rank        *float32   `json:"r,omitempty"`
+	Sort        string     `json:"s,omitempty"`
+}
+
+type Page struct {
+	Limit int
+	After *Cursor
+}
+
+// FromRequest le os query params 'limit' e 'cursor'
+func FromRequest(r *http.Request) (*Page, error) {
+	page := &Page{Limit: DefaultLimit}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return nil, errors.New("parametro 'limit' deve ser um inteiro entre 1 e " + strconv.Itoa(MaxLimit))
+		}
+		page.Limit = limit
+	}
+
+	if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+		c, err := Decode(cursorStr)
+		if err != nil {
+			return nil, err
+		}
+		page.After = c
+	}
+
+	return page, nil
+}
+
+// Decode le o cursor opaco gerado por Encode
+func Decode(cursor string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// ByCreatedAt retorna a chave (CreatedAt, Id) a partir da qual a pagina comeca,
+// ou nils na primeira pagina
+func (p *Page) ByCreatedAt() (*time.Time, *uuid.UUID, error) {
+	if p.After == nil {
+		return nil, nil, nil
+	}
+	if p.After.CreatedAt == nil || p.After.Id == nil {
+		return nil, nil, ErrInvalidCursor
+	}
+	return p.After.CreatedAt, p.After.Id, nil
+}
+
+// ByStockItem retorna a chave (ProductId, WarehouseId) a partir da qual a pagina
+// comeca, ou nils na primeira pagina
+func (p *Page) ByStockItem() (*uuid.UUID, *uuid.UUID, error) {
+	if p.After == nil {
+		return nil, nil, nil
+	}
+	if p.After.ProductId == nil || p.After.WarehouseId == nil {
+		return nil, nil, ErrInvalidCursor
+	}
+	return p.After.ProductId, p.After.WarehouseId, nil
+}
+
+// Fetch e a quantidade de linhas a buscar: uma a mais que o limite, para saber
+// se existe proxima pagina
+func (p *Page) Fetch() int {
+	return p.Limit + 1
+}
+
+// Trim corta a linha extra buscada por Fetch e informa se ha proxima pagina
+func Trim[T any](items *[]T, limit int) bool {
+	if items == nil || len(*items) <= limit {
+		return false
+	}
+	*items = (*items)[:limit]
+	return true
+}
+
+// Encode serializa o cursor no formato opaco enviado como next_cursor
+func Encode(c *Cursor) *string {
+	raw, _ := json.Marshal(c)
+	encoded := base64.RawURLEncoding.EncodeToString(raw)
+	return &encoded
+}
@@ -0,0 +1,132 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/gofrs/uuid"
+)
+
+// MaxBulkItems limita o tamanho do lote, que e gravado em uma unica transacao
+const MaxBulkItems = 500
+
+const (
+	OutcomeCreated   = "created"
+	OutcomeUpdated   = "updated"
+	OutcomeUnchanged = "unchanged"
+	OutcomeError     = "error"
+)
+
+// BulkUpsert e o corpo do upsert em lote. Cada produto e identificado pelo
+// 'sku': se ja existe e atualizado, senao e criado.
+type BulkUpsert struct {
+	Products []Product `json:"products"`
+}
+
+func (b *BulkUpsert) Validate() error {
+	if len(b.Products) == 0 {
+		return errors.New("atributo 'products' faltando ou vazio")
+	}
+	if len(b.Products) > MaxBulkItems {
+		return fmt.Errorf("lote pode ter no maximo %d produtos", MaxBulkItems)
+	}
+	return nil
+}
+
+// BulkResult e o resultado de um item do lote. Index e a posicao do item em
+// 'products'; Msg explica o erro quando Outcome e 'error'.
+type BulkResult struct {
+	Index   int        `json:"index"`
+	Sku     *string    `json:"sku"`
+	Id      *uuid.UUID `json:"id,omitempty"`
+	Outcome string     `json:"outcome"`
+	Msg     string     `json:"msg,omitempty"`
+}
+
+// ValidateUpsert confere um item do lote. Vale o mesmo que na criacao, mas
+// 'status' aceita qualquer estado: contra o produto existente, a transicao e
+// conferida depois.
+func (p *Product) ValidateUpsert() error {
+	if p.Sku == nil {
+		return errors.New("atributo 'sku' faltando, ele identifica o produto no lote")
+	}
+
+	return p.validateComplete()
+}
+
+// KeepUnset completa o item do lote com os atributos opcionais que ele nao
+// informou, para que a atualizacao preserve imagens e dimensoes do produto atual
+func (p *Product) KeepUnset(current *Product) {
+	if p.Images == nil {
+		p.Images = current.Images
+	}
+	if p.LengthMm == nil {
+		p.LengthMm = current.LengthMm
+	}
+	if p.WidthMm == nil {
+		p.WidthMm = current.WidthMm
+	}
+	if p.HeightMm == nil {
+		p.HeightMm = current.HeightMm
+	}
+	if p.WeightGrams == nil {
+		p.WeightGrams = current.WeightGrams
+	}
+}
+
+// SameAs informa se gravar o item nao mudaria o produto atual. Sem 'prices',
+// apenas o preco na moeda padrao e comparado, como no PUT.
+func (p *Product) SameAs(current *Product) bool {
+	if !equal(p.Name, current.Name) ||
+		!equal(p.Description, current.Description) ||
+		!equal(p.Price, current.Price) ||
+		!equal(p.CategoryId, current.CategoryId) ||
+		!equal(p.Status, current.Status) ||
+		!equal(p.LengthMm, current.LengthMm) ||
+		!equal(p.WidthMm, current.WidthMm) ||
+		!equal(p.HeightMm, current.HeightMm) ||
+		!equal(p.WeightGrams, current.WeightGrams) {
+		return false
+	}
+
+	if !sameImages(p.Images, current.Images) {
+		return false
+	}
+
+	if p.Prices == nil {
+		return true
+	}
+	if current.Prices == nil || len(*p.Prices) != len(*current.Prices) {
+		return false
+	}
+	// as duas listas estao ordenadas pela moeda
+	for i, price := range *p.Prices {
+		other := (*current.Prices)[i]
+		if price.Currency != other.Currency || price.Amount != other.Amount {
+			return false
+		}
+	}
+	return true
+}
+
+func equal[T comparable](a *T, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameImages(a *[]Image, b *[]Image) bool {
+	var left, right []Image
+	if a != nil {
+		left = *a
+	}
+	if b != nil {
+		right = *b
+	}
+	if len(left) == 0 && len(right) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(left, right)
+}
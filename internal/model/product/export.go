@@ -0,0 +1,11 @@
+package product
+
+// ExportColumns e o cabecalho da exportacao do catalogo, na ordem de
+// ExportValues. Os nomes seguem as colunas aceitas pela importacao.
+var ExportColumns = []string{"id", "sku", "name", "description", "categoryId", "category", "status", "price", "currency", "lengthMm", "widthMm", "heightMm", "weightGrams", "createdAt"}
+
+// ExportValues retorna as celulas do produto exportado. 'price' e 'currency'
+// devem ja ter passado por SelectCurrency.
+func (p *Product) ExportValues() []any {
+	return []any{p.Id, p.Sku, p.Name, p.Description, p.CategoryId, p.Category, p.Status, p.Price, p.Currency, p.LengthMm, p.WidthMm, p.HeightMm, p.WeightGrams, p.CreatedAt}
+}
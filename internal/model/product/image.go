@@ -0,0 +1,108 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	MaxImages    = 20
+	MaxAltLength = 300
+)
+
+// Image descreve uma imagem do produto. ThumbnailUrl e preenchido pela API
+// quando a imagem e enviada pelo endpoint de upload.
+type Image struct {
+	Url          string `json:"url"`
+	ThumbnailUrl string `json:"thumbnailUrl,omitempty"`
+	Alt          string `json:"alt"`
+	Position     int    `json:"position"`
+	Primary      bool   `json:"primary"`
+}
+
+// NormalizeImages valida a lista de imagens e a ordena por posicao. Quando
+// nenhuma imagem e marcada como principal, a de menor posicao passa a ser.
+func NormalizeImages(images []Image) error {
+	if len(images) > MaxImages {
+		return fmt.Errorf("produto pode ter no maximo %d imagens", MaxImages)
+	}
+
+	positions := make(map[int]bool, len(images))
+	primaries := 0
+	for i := range images {
+		img := &images[i]
+		img.Url = strings.TrimSpace(img.Url)
+		img.Alt = strings.TrimSpace(img.Alt)
+
+		if !isValidImageURL(img.Url) {
+			return fmt.Errorf("imagem %d: atributo 'url' deve ser uma URL http(s) absoluta ou um caminho iniciado por '/'", i)
+		}
+		if img.ThumbnailUrl != "" && !isValidImageURL(img.ThumbnailUrl) {
+			return fmt.Errorf("imagem %d: atributo 'thumbnailUrl' inválido", i)
+		}
+		if len([]rune(img.Alt)) > MaxAltLength {
+			return fmt.Errorf("imagem %d: atributo 'alt' deve ter no maximo %d caracteres", i, MaxAltLength)
+		}
+		if img.Position < 0 {
+			return fmt.Errorf("imagem %d: atributo 'position' nao pode ser negativo", i)
+		}
+		if positions[img.Position] {
+			return fmt.Errorf("imagem %d: posicao %d repetida", i, img.Position)
+		}
+		positions[img.Position] = true
+		if img.Primary {
+			primaries++
+		}
+	}
+
+	if primaries > 1 {
+		return errors.New("apenas uma imagem pode ser a principal")
+	}
+
+	sort.Slice(images, func(i, j int) bool { return images[i].Position < images[j].Position })
+	if primaries == 0 && len(images) > 0 {
+		images[0].Primary = true
+	}
+
+	return nil
+}
+
+// NextPosition retorna a posicao seguinte a ultima imagem da lista
+func NextPosition(images []Image) int {
+	next := 0
+	for _, img := range images {
+		if img.Position >= next {
+			next = img.Position + 1
+		}
+	}
+	return next
+}
+
+func isValidImageURL(raw string) bool {
+	if strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//") {
+		return true
+	}
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// ImageUpload sao os metadados enviados junto com o arquivo no upload. Sem
+// Position, a imagem vai para o fim da lista.
+type ImageUpload struct {
+	Alt      string
+	Position *int
+	Primary  bool
+}
+
+func (u *ImageUpload) Validate() error {
+	if len([]rune(u.Alt)) > MaxAltLength {
+		return fmt.Errorf("campo 'alt' deve ter no maximo %d caracteres", MaxAltLength)
+	}
+	if u.Position != nil && *u.Position < 0 {
+		return errors.New("campo 'position' nao pode ser negativo")
+	}
+	return nil
+}
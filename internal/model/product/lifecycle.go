@@ -0,0 +1,67 @@
+package product
+
+import (
+	"fmt"
+)
+
+const (
+	StatusDraft        = "draft"
+	StatusActive       = "active"
+	StatusDiscontinued = "discontinued"
+	StatusArchived     = "archived"
+)
+
+type Operation string
+
+const (
+	OperationReceive Operation = "entrada"
+	OperationReserve Operation = "reserva"
+	OperationDeduct  Operation = "baixa"
+)
+
+// transitions lista, para cada estado, os estados para os quais o produto pode ir.
+var transitions = map[string][]string{
+	StatusDraft:        {StatusActive, StatusArchived},
+	StatusActive:       {StatusDiscontinued},
+	StatusDiscontinued: {StatusActive, StatusArchived},
+	StatusArchived:     {},
+}
+
+// rules define as operacoes de estoque permitidas em cada estado. Um produto
+// em rascunho pode ser abastecido antes do lancamento, e um descontinuado so
+// pode ter o saldo restante vendido.
+var rules = map[string]map[Operation]bool{
+	StatusDraft:        {OperationReceive: true},
+	StatusActive:       {OperationReceive: true, OperationReserve: true, OperationDeduct: true},
+	StatusDiscontinued: {OperationReserve: true, OperationDeduct: true},
+	StatusArchived:     {},
+}
+
+func IsValidStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+func ValidateTransition(from string, to string) error {
+	if from == to {
+		return nil
+	}
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("transicao de status '%s' para '%s' nao permitida", from, to)
+}
+
+// Allows retorna erro se o status atual do produto nao permite a operacao de estoque.
+func (p *Product) Allows(op Operation) error {
+	if p.Status == nil || !rules[*p.Status][op] {
+		status := "desconhecido"
+		if p.Status != nil {
+			status = *p.Status
+		}
+		return fmt.Errorf("operacao de %s nao permitida para produto com status '%s'", op, status)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package product
+
+import "testing"
+
+func TestValidateTransition(t *testing.T) {
+	statuses := []string{StatusDraft, StatusActive, StatusDiscontinued, StatusArchived}
+	allowed := map[[2]string]bool{
+		{StatusDraft, StatusActive}:          true,
+		{StatusDraft, StatusArchived}:        true,
+		{StatusActive, StatusDiscontinued}:   true,
+		{StatusDiscontinued, StatusActive}:   true,
+		{StatusDiscontinued, StatusArchived}: true,
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := from == to || allowed[[2]string{from, to}]
+			t.Run(from+"->"+to, func(t *testing.T) {
+				err := ValidateTransition(from, to)
+				if (err == nil) != want {
+					t.Errorf("ValidateTransition(%q, %q) = %v, want permitido %v", from, to, err, want)
+				}
+			})
+		}
+	}
+}
+
+func TestValidateTransitionUnknownStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+	}{
+		{name: "origem desconhecida", from: "deleted", to: StatusActive},
+		{name: "destino desconhecido", from: StatusActive, to: "deleted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateTransition(tt.from, tt.to); err == nil {
+				t.Errorf("ValidateTransition(%q, %q) = nil, want erro", tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		status string
+		op     Operation
+		want   bool
+	}{
+		{status: StatusDraft, op: OperationReceive, want: true},
+		{status: StatusDraft, op: OperationReserve, want: false},
+		{status: StatusDraft, op: OperationDeduct, want: false},
+		{status: StatusActive, op: OperationReceive, want: true},
+		{status: StatusActive, op: OperationReserve, want: true},
+		{status: StatusActive, op: OperationDeduct, want: true},
+		{status: StatusDiscontinued, op: OperationReceive, want: false},
+		{status: StatusDiscontinued, op: OperationReserve, want: true},
+		{status: StatusDiscontinued, op: OperationDeduct, want: true},
+		{status: StatusArchived, op: OperationReceive, want: false},
+		{status: StatusArchived, op: OperationReserve, want: false},
+		{status: StatusArchived, op: OperationDeduct, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status+" "+string(tt.op), func(t *testing.T) {
+			p := Product{Status: &tt.status}
+			if err := p.Allows(tt.op); (err == nil) != tt.want {
+				t.Errorf("Allows(%q) com status %q = %v, want permitido %v", tt.op, tt.status, err, tt.want)
+			}
+		})
+	}
+
+	if err := (&Product{}).Allows(OperationReceive); err == nil {
+		t.Error("Allows sem status = nil, want erro")
+	}
+}
@@ -0,0 +1,56 @@
+package product
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// PriceChange e uma linha do historico de precos. ScheduleId aponta o
+// agendamento que originou a alteracao, quando houver.
+type PriceChange struct {
+	Id          *uuid.UUID `json:"id"`
+	ProductId   *uuid.UUID `json:"productId"`
+	Currency    *string    `json:"currency"`
+	Price       *int64     `json:"price"`
+	EffectiveAt *time.Time `json:"effectiveAt"`
+	ChangedBy   *string    `json:"changedBy"`
+	ScheduleId  *uuid.UUID `json:"scheduleId,omitempty"`
+}
+
+// PriceSchedule e uma alteracao de preco agendada para EffectiveAt, na moeda padrao
+type PriceSchedule struct {
+	Id          *uuid.UUID `json:"id"`
+	ProductId   *uuid.UUID `json:"productId"`
+	Price       *int64     `json:"price"`
+	EffectiveAt *time.Time `json:"effectiveAt"`
+	CreatedBy   *string    `json:"createdBy"`
+	CreatedAt   *time.Time `json:"createdAt"`
+	AppliedAt   *time.Time `json:"appliedAt"`
+	CanceledAt  *time.Time `json:"canceledAt"`
+}
+
+func (s *PriceSchedule) ValidateCreate() error {
+	if s.Id != nil || s.ProductId != nil {
+		return errors.New("atributos 'id' e 'productId' são controlados pela API")
+	}
+
+	if s.Price == nil || *s.Price <= 0 {
+		return errors.New("atributo 'price' faltando ou inválido")
+	}
+
+	if s.EffectiveAt == nil {
+		return errors.New("atributo 'effectiveAt' faltando")
+	}
+
+	if !s.EffectiveAt.After(time.Now()) {
+		return errors.New("atributo 'effectiveAt' deve estar no futuro")
+	}
+
+	if s.CreatedBy != nil || s.CreatedAt != nil || s.AppliedAt != nil || s.CanceledAt != nil {
+		return errors.New("atributos 'createdBy', 'createdAt', 'appliedAt' e 'canceledAt' são controlados pela API")
+	}
+
+	return nil
+}
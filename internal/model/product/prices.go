@@ -0,0 +1,110 @@
+package product
+
+import (
+	"api-estoque/internal/model/currency"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Price e o preco do produto em uma moeda. Amount fica na menor unidade da
+// moeda; MinorUnits e Display sao preenchidos pela API nas respostas.
+type Price struct {
+	Currency   string `json:"currency"`
+	Amount     int64  `json:"amount"`
+	MinorUnits int    `json:"minorUnits"`
+	Display    string `json:"display"`
+}
+
+func (p *Product) validatePrices() error {
+	if p.Currency != nil {
+		return errors.New("atributo 'currency' é controlado pela API, use 'prices' para informar preços em outras moedas")
+	}
+
+	if p.Prices == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(*p.Prices))
+	for i := range *p.Prices {
+		price := &(*p.Prices)[i]
+		c, ok := currency.Lookup(price.Currency)
+		if !ok {
+			return fmt.Errorf("prices[%d]: moeda '%s' não suportada", i, price.Currency)
+		}
+		if seen[c.Code] {
+			return fmt.Errorf("prices[%d]: moeda '%s' repetida", i, c.Code)
+		}
+		seen[c.Code] = true
+		if price.Amount <= 0 {
+			return fmt.Errorf("prices[%d]: atributo 'amount' deve ser maior que zero", i)
+		}
+		price.Currency = c.Code
+	}
+
+	sort.Slice(*p.Prices, func(i, j int) bool { return (*p.Prices)[i].Currency < (*p.Prices)[j].Currency })
+	return nil
+}
+
+// ReconcilePrices mantem 'price' e o preco na moeda padrao de 'prices' iguais.
+// Quando 'prices' e informado ele substitui a lista inteira: precisa conter a
+// moeda padrao, ou 'price' a completa. Valores diferentes sao recusados.
+func (p *Product) ReconcilePrices(defaultCurrency string) error {
+	if p.Prices == nil {
+		return nil
+	}
+
+	for _, price := range *p.Prices {
+		if price.Currency != defaultCurrency {
+			continue
+		}
+		if p.Price != nil && *p.Price != price.Amount {
+			return fmt.Errorf("atributo 'price' difere do preço em %s informado em 'prices'", defaultCurrency)
+		}
+		amount := price.Amount
+		p.Price = &amount
+		return nil
+	}
+
+	if p.Price == nil {
+		return fmt.Errorf("'prices' deve incluir a moeda padrão (%s), ou informe 'price'", defaultCurrency)
+	}
+	prices := append(*p.Prices, Price{Currency: defaultCurrency, Amount: *p.Price})
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Currency < prices[j].Currency })
+	p.Prices = &prices
+	return nil
+}
+
+// SelectCurrency ajusta 'price' e 'currency' para a moeda pedida, ou para a
+// moeda padrao quando o produto nao tem preco na moeda pedida. Tambem
+// preenche MinorUnits e Display de cada preco da lista.
+func (p *Product) SelectCurrency(code string, defaultCurrency string) {
+	if p.Prices == nil {
+		p.Prices = &[]Price{}
+	}
+
+	var selected, fallback *Price
+	for i := range *p.Prices {
+		price := &(*p.Prices)[i]
+		c, _ := currency.Lookup(price.Currency)
+		price.MinorUnits = c.MinorUnits
+		price.Display = c.Format(price.Amount)
+		switch price.Currency {
+		case code:
+			selected = price
+		case defaultCurrency:
+			fallback = price
+		}
+	}
+	if selected == nil {
+		selected = fallback
+	}
+
+	if selected == nil {
+		p.Currency = &defaultCurrency
+		return
+	}
+	amount := selected.Amount
+	p.Price = &amount
+	p.Currency = &selected.Currency
+}
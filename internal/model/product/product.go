@@ -2,23 +2,54 @@ package product
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/gofrs/uuid"
 )
 
+const MaxSkuLength = 64
+
 type Product struct {
 	Id          *uuid.UUID `json:"id"`
+	Sku         *string    `json:"sku"` // codigo externo, unico quando informado
 	CreatedAt   *time.Time `json:"createdAt"`
 	Name        *string    `json:"name"`
 	Description *string    `json:"description"`
-	Price       *int64     `json:"price"`
-	Category    *string    `json:"category"`
-	ImagesJson  *any       `json:"imagesJson"`
+	Price       *int64     `json:"price"`    // na moeda de 'currency' nas respostas; na moeda padrao na escrita
+	Currency    *string    `json:"currency"` // moeda de 'price', somente leitura
+	Prices      *[]Price   `json:"prices"`
+	CategoryId  *uuid.UUID `json:"categoryId"`
+	Category    *string    `json:"category"` // nome da categoria, somente leitura
+	Images      *[]Image   `json:"images"`
+	ImagesJson  *any       `json:"imagesJson,omitempty" swaggerignore:"true"` // formato antigo, recusado
 	IsActive    *bool      `json:"isActive"`
+	Status      *string    `json:"status"`
+	LengthMm    *int64     `json:"lengthMm"`
+	WidthMm     *int64     `json:"widthMm"`
+	HeightMm    *int64     `json:"heightMm"`
+	WeightGrams *int64     `json:"weightGrams"`
+	Version     *int64     `json:"-"`
+	ChangedBy   *string    `json:"-"` // usuario que fez a alteracao, registrado no historico de precos
 }
 
 func (p *Product) ValidateCreate() error {
+	if err := p.validateComplete(); err != nil {
+		return err
+	}
+
+	if *p.Status != StatusDraft && *p.Status != StatusActive {
+		return errors.New("atributo 'status' deve ser 'draft' ou 'active' na criação")
+	}
+
+	return nil
+}
+
+// validateComplete confere um produto informado por inteiro, como na criacao,
+// e deixa 'status' e 'isActive' preenchidos
+func (p *Product) validateComplete() error {
 	if p.Name == nil || *p.Name == "" {
 		return errors.New("atributo 'name' faltando ou vazio")
 	}
@@ -27,22 +58,52 @@ func (p *Product) ValidateCreate() error {
 		return errors.New("atributo 'description' faltando ou vazio")
 	}
 
-	if p.Price == nil || *p.Price <= 0 {
-		return errors.New("atributo 'price' faltando ou inválido")
+	if err := p.validateSku(); err != nil {
+		return err
 	}
 
-	if p.Category == nil || *p.Category == "" {
-		return errors.New("atributo 'category' faltando ou vazio")
+	if p.Price != nil && *p.Price <= 0 {
+		return errors.New("atributo 'price' inválido")
 	}
 
-	if p.IsActive == nil {
-		return errors.New("atributo 'is_active' faltando")
+	if p.Price == nil && p.Prices == nil {
+		return errors.New("atributo 'price' faltando")
+	}
+
+	if err := p.validatePrices(); err != nil {
+		return err
+	}
+
+	if p.Category != nil {
+		return errors.New("atributo 'category' substituido por 'categoryId'")
+	}
+
+	if p.CategoryId == nil {
+		return errors.New("atributo 'categoryId' faltando")
+	}
+
+	if err := p.validateImages(); err != nil {
+		return err
 	}
 
+	if err := p.defaultStatus(); err != nil {
+		return err
+	}
+
+	if !IsValidStatus(*p.Status) {
+		return errors.New("atributo 'status' inválido")
+	}
+	isActive := *p.Status == StatusActive
+	p.IsActive = &isActive
+
 	if p.CreatedAt != nil {
 		return errors.New("atributo 'created_at' é controlado pela API")
 	}
 
+	if err := p.validateDimensions(); err != nil {
+		return err
+	}
+
 	if p.Id != nil {
 		return errors.New("atributo 'id' é controlado pela API")
 	}
@@ -50,6 +111,23 @@ func (p *Product) ValidateCreate() error {
 	return nil
 }
 
+// defaultStatus deriva 'status' de 'isActive' quando apenas ele e informado
+func (p *Product) defaultStatus() error {
+	if p.Status != nil {
+		return nil
+	}
+	// clientes antigos ainda informam apenas isActive
+	if p.IsActive == nil {
+		return errors.New("atributo 'status' faltando")
+	}
+	status := StatusDraft
+	if *p.IsActive {
+		status = StatusActive
+	}
+	p.Status = &status
+	return nil
+}
+
 func (p *Product) ValidateUpdate() error {
 	if p.Id == nil {
 		return errors.New("atributo 'id' faltando, necessário para atualizar o produto")
@@ -59,15 +137,111 @@ func (p *Product) ValidateUpdate() error {
 		return errors.New("atributo 'created_at' é controlado pela API")
 	}
 
+	if p.Category != nil {
+		return errors.New("atributo 'category' substituido por 'categoryId'")
+	}
+
 	// must have at least one field to update
 	if (p.Name == nil || *p.Name == "") &&
 		(p.Description == nil || *p.Description == "") &&
+		p.Sku == nil &&
 		p.Price == nil &&
-		(p.Category == nil || *p.Category == "") &&
-		p.ImagesJson == nil &&
-		p.IsActive == nil {
+		p.Prices == nil &&
+		p.CategoryId == nil &&
+		p.Images == nil &&
+		p.IsActive == nil &&
+		p.Status == nil &&
+		p.LengthMm == nil &&
+		p.WidthMm == nil &&
+		p.HeightMm == nil &&
+		p.WeightGrams == nil {
 		return errors.New("nenhum atributo informado para atualização")
 	}
 
+	if err := p.validateSku(); err != nil {
+		return err
+	}
+
+	if p.Price != nil && *p.Price <= 0 {
+		return errors.New("atributo 'price' inválido")
+	}
+
+	if err := p.validatePrices(); err != nil {
+		return err
+	}
+
+	if err := p.validateImages(); err != nil {
+		return err
+	}
+
+	if p.Status != nil && p.IsActive != nil {
+		return errors.New("informe apenas o atributo 'status'")
+	}
+
+	if p.IsActive != nil {
+		status := StatusDiscontinued
+		if *p.IsActive {
+			status = StatusActive
+		}
+		p.Status = &status
+	}
+
+	if p.Status != nil {
+		if !IsValidStatus(*p.Status) {
+			return errors.New("atributo 'status' inválido")
+		}
+		isActive := *p.Status == StatusActive
+		p.IsActive = &isActive
+	}
+
+	return p.validateDimensions()
+}
+
+// validateSku aceita de 1 a MaxSkuLength caracteres, sem espacos
+func (p *Product) validateSku() error {
+	if p.Sku == nil {
+		return nil
+	}
+	if *p.Sku == "" || len([]rune(*p.Sku)) > MaxSkuLength || strings.IndexFunc(*p.Sku, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("atributo 'sku' deve ter de 1 a %d caracteres, sem espacos", MaxSkuLength)
+	}
 	return nil
 }
+
+func (p *Product) validateImages() error {
+	if p.ImagesJson != nil {
+		return errors.New("atributo 'imagesJson' substituido por 'images'")
+	}
+
+	if p.Images == nil {
+		return nil
+	}
+	return NormalizeImages(*p.Images)
+}
+
+func (p *Product) validateDimensions() error {
+	names := []string{"lengthMm", "widthMm", "heightMm", "weightGrams"}
+	for i, value := range []*int64{p.LengthMm, p.WidthMm, p.HeightMm, p.WeightGrams} {
+		if value != nil && *value <= 0 {
+			return fmt.Errorf("atributo '%s' deve ser maior que zero", names[i])
+		}
+	}
+
+	return nil
+}
+
+// VolumeLiters retorna o volume de uma unidade do produto, ou false se alguma dimensao nao foi informada.
+func (p *Product) VolumeLiters() (float64, bool) {
+	if p.LengthMm == nil || p.WidthMm == nil || p.HeightMm == nil {
+		return 0, false
+	}
+	return float64(*p.LengthMm) * float64(*p.WidthMm) * float64(*p.HeightMm) / 1e6, true
+}
+
+// WeightKg retorna o peso de uma unidade do produto, ou false se nao foi informado.
+func (p *Product) WeightKg() (float64, bool) {
+	if p.WeightGrams == nil {
+		return 0, false
+	}
+	return float64(*p.WeightGrams) / 1e3, true
+}
@@ -0,0 +1,15 @@
+package bulkupsert
+
+import (
+	"api-estoque/internal/model/product"
+)
+
+type BulkUpsertResponse struct {
+	Status    int                  `json:"-"`
+	Msg       string               `json:"-"`
+	Created   int                  `json:"created"`
+	Updated   int                  `json:"updated"`
+	Unchanged int                  `json:"unchanged"`
+	Errors    int                  `json:"errors"`
+	Results   []product.BulkResult `json:"results"`
+}
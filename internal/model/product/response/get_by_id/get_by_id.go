@@ -1,20 +1,31 @@
 package getbyid
 
 import (
+	productModel "api-estoque/internal/model/product"
 	"time"
 
 	"github.com/gofrs/uuid"
 )
 
 type GetByIdResponse struct {
-	Status      int        `json:"-"`
-	Msg         string     `json:"-"`
-	Id          *uuid.UUID `json:"id"`
-	CreatedAt   *time.Time `json:"createdAt"`
-	Name        *string    `json:"name"`
-	Description *string    `json:"description"`
-	Price       *int64     `json:"price"`
-	Category    *string    `json:"category"`
-	ImagesJson  *any       `json:"imagesJson"`
-	IsActive    *bool      `json:"isActive"`
+	Status        int                   `json:"-"`
+	Msg           string                `json:"-"`
+	Id            *uuid.UUID            `json:"id"`
+	Sku           *string               `json:"sku"`
+	CreatedAt     *time.Time            `json:"createdAt"`
+	Name          *string               `json:"name"`
+	Description   *string               `json:"description"`
+	Price         *int64                `json:"price"`
+	Currency      *string               `json:"currency"`
+	Prices        *[]productModel.Price `json:"prices"`
+	CategoryId    *uuid.UUID            `json:"categoryId"`
+	Category      *string               `json:"category"`
+	Images        *[]productModel.Image `json:"images"`
+	IsActive      *bool                 `json:"isActive"`
+	ProductStatus *string               `json:"status"`
+	LengthMm      *int64                `json:"lengthMm"`
+	WidthMm       *int64                `json:"widthMm"`
+	HeightMm      *int64                `json:"heightMm"`
+	WeightGrams   *int64                `json:"weightGrams"`
+	Version       int64                 `json:"-"`
 }
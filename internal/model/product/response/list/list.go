@@ -5,7 +5,8 @@ import (
 )
 
 type ListResponse struct {
-	Status   int                `json:"-"`
-	Msg      string             `json:"-"`
-	Products *[]product.Product `json:"products"`
+	Status     int                `json:"-"`
+	Msg        string             `json:"-"`
+	Products   *[]product.Product `json:"products"`
+	NextCursor *string            `json:"next_cursor"`
 }
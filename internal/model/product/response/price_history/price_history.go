@@ -0,0 +1,12 @@
+package pricehistory
+
+import (
+	"api-estoque/internal/model/product"
+)
+
+type PriceHistoryResponse struct {
+	Status     int                    `json:"-"`
+	Msg        string                 `json:"-"`
+	History    *[]product.PriceChange `json:"history"`
+	NextCursor *string                `json:"next_cursor"`
+}
@@ -0,0 +1,11 @@
+package priceschedule
+
+import (
+	"api-estoque/internal/model/product"
+)
+
+type PriceScheduleResponse struct {
+	Status    int                      `json:"-"`
+	Msg       string                   `json:"-"`
+	Schedules *[]product.PriceSchedule `json:"schedules"`
+}
@@ -0,0 +1,12 @@
+package search
+
+import (
+	"api-estoque/internal/model/product"
+)
+
+type SearchResponse struct {
+	Status     int                     `json:"-"`
+	Msg        string                  `json:"-"`
+	Products   *[]product.SearchResult `json:"products"`
+	NextCursor *string                 `json:"next_cursor"`
+}
@@ -0,0 +1,12 @@
+package uploadimage
+
+import (
+	productModel "api-estoque/internal/model/product"
+)
+
+type UploadImageResponse struct {
+	Status int                  `json:"-"`
+	Msg    string               `json:"-"`
+	Image  productModel.Image   `json:"image"`
+	Images []productModel.Image `json:"images"`
+}
@@ -0,0 +1,53 @@
+package product
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// SearchQuery sao os parametros da busca textual de produtos
+type SearchQuery struct {
+	Terms      []string
+	ActiveOnly bool
+	InStock    bool
+}
+
+// SearchResult e um produto encontrado pela busca, com a relevancia calculada
+type SearchResult struct {
+	Product
+	Rank float32 `json:"rank"`
+}
+
+// ParseSearch le os query params da busca. O texto de 'q' e quebrado em termos
+// formados apenas por letras e digitos, evitando que pontuacao seja lida como
+// operador do tsquery.
+func ParseSearch(values url.Values) (*SearchQuery, error) {
+	terms := strings.FieldsFunc(values.Get("q"), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(terms) == 0 {
+		return nil, errors.New("parametro 'q' faltando ou sem termos pesquisaveis")
+	}
+	if len(terms) > 10 {
+		return nil, errors.New("parametro 'q' aceita no maximo 10 termos")
+	}
+
+	return &SearchQuery{
+		Terms:      terms,
+		ActiveOnly: values.Get("active") == "true",
+		InStock:    values.Get("inStock") == "true",
+	}, nil
+}
+
+// TSQuery monta a expressao do to_tsquery: todos os termos devem aparecer, e o
+// ultimo casa por prefixo para permitir a busca enquanto o usuario digita.
+func (q *SearchQuery) TSQuery() string {
+	parts := make([]string, len(q.Terms))
+	for i, term := range q.Terms {
+		parts[i] = strings.ToLower(term)
+	}
+	parts[len(parts)-1] += ":*"
+	return strings.Join(parts, " & ")
+}
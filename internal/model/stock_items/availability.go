@@ -0,0 +1,45 @@
+package stockitems
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+)
+
+// MaxLines limita as linhas de uma verificacao de disponibilidade, reserva ou
+// liberacao
+const MaxLines = 500
+
+// ItemKey identifica um item de estoque
+type ItemKey struct {
+	ProductId   uuid.UUID
+	WarehouseId uuid.UUID
+}
+
+// Availability e o resultado da verificacao de uma linha. Available e o saldo
+// disponivel (quantidade menos reservado), zero quando o item nao existe.
+type Availability struct {
+	ProductId   uuid.UUID `json:"product_id"`
+	WarehouseId uuid.UUID `json:"warehouse_id"`
+	Requested   int64     `json:"requested"`
+	Available   int64     `json:"available"`
+	Sufficient  bool      `json:"sufficient"`
+	Found       bool      `json:"found"`
+}
+
+// ValidateLines valida as linhas de uma reserva ou liberacao
+func ValidateLines(lines []StockItemsBaixa) error {
+	if len(lines) == 0 {
+		return errors.New("nenhuma linha informada")
+	}
+	if len(lines) > MaxLines {
+		return fmt.Errorf("maximo de %d linhas por requisicao", MaxLines)
+	}
+	for i := range lines {
+		if err := lines[i].ValidateBaixa(); err != nil {
+			return fmt.Errorf("linha %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
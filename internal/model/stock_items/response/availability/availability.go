@@ -0,0 +1,10 @@
+package availability
+
+import stockitems "api-estoque/internal/model/stock_items"
+
+type AvailabilityResponse struct {
+	Status        int                       `json:"-"`
+	Msg           string                    `json:"-"`
+	Lines         []stockitems.Availability `json:"lines"`
+	AllSufficient bool                      `json:"all_sufficient"`
+}
@@ -9,4 +9,5 @@ type CreateResponse struct {
 	Msg         string    `json:"-"`
 	ProductId   uuid.UUID `json:"product_id"`
 	WarehouseId uuid.UUID `json:"warehouse_id"`
+	Warnings    []string  `json:"warnings,omitempty"`
 }
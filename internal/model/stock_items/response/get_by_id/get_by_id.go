@@ -14,4 +14,5 @@ type GetByIdResponse struct {
 	Quantity    int64     `json:"quantity"`
 	Reserved    int64     `json:"reserved"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	Version     int64     `json:"-"`
 }
@@ -8,4 +8,5 @@ type ListResponse struct {
 	Status     int                      `json:"-"`
 	Msg        string                   `json:"-"`
 	StockItems *[]stockitems.StockItems `json:"stock_items"`
+	NextCursor *string                  `json:"next_cursor"`
 }
@@ -13,6 +13,28 @@ type StockItems struct {
 	Quantity    *int64     `db:"Quantity" json:"quantity"`
 	Reserved    *int64     `db:"Reserved" json:"reserved"`
 	UpdatedAt   *time.Time `db:"UpdatedAt" json:"updated_at"`
+	Version     *int64     `db:"Version" json:"-"`
+}
+
+// ExportRow e o item de estoque com os nomes do produto e do galpao, para a
+// exportacao das posicoes
+type ExportRow struct {
+	StockItems
+	ProductName   *string
+	WarehouseName *string
+}
+
+// ExportColumns e o cabecalho da exportacao, na ordem de ExportRow.Values
+var ExportColumns = []string{"productId", "productName", "warehouseId", "warehouseName", "quantity", "reserved", "available", "updatedAt"}
+
+// Values retorna as celulas da linha exportada; available e o saldo livre
+func (s *ExportRow) Values() []any {
+	var available *int64
+	if s.Quantity != nil && s.Reserved != nil {
+		free := *s.Quantity - *s.Reserved
+		available = &free
+	}
+	return []any{s.ProductId, s.ProductName, s.WarehouseId, s.WarehouseName, s.Quantity, s.Reserved, available, s.UpdatedAt}
 }
 
 type StockItemsBaixa struct {
@@ -41,6 +63,20 @@ func (s *StockItemsBaixa) ValidateBaixa() error {
 	return nil
 }
 
+// RetargetLines aponta para o galpao to as linhas reservadas no galpao from,
+// como no encerramento de um galpao com destino. Retorna se alguma linha mudou
+func RetargetLines(lines []StockItemsBaixa, from uuid.UUID, to uuid.UUID) bool {
+	changed := false
+	for i := range lines {
+		if lines[i].WarehouseId != nil && *lines[i].WarehouseId == from {
+			target := to
+			lines[i].WarehouseId = &target
+			changed = true
+		}
+	}
+	return changed
+}
+
 func (s *StockItems) ValidateCreate() error {
 	if s.ProductId == nil {
 		return errors.New("atributo 'product_id' faltando")
@@ -0,0 +1,90 @@
+package stockitems
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestRetargetLines(t *testing.T) {
+	closed := uuid.Must(uuid.NewV4())
+	target := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+	product := uuid.Must(uuid.NewV4())
+
+	line := func(warehouse uuid.UUID, quantity int64) StockItemsBaixa {
+		return StockItemsBaixa{ProductId: &product, WarehouseId: &warehouse, Quantity: &quantity}
+	}
+
+	tests := []struct {
+		name    string
+		lines   []StockItemsBaixa
+		want    []uuid.UUID
+		changed bool
+	}{
+		{
+			name:    "try cancelado depois do encerramento libera no destino",
+			lines:   []StockItemsBaixa{line(closed, 3)},
+			want:    []uuid.UUID{target},
+			changed: true,
+		},
+		{
+			name:    "so as linhas do galpao encerrado mudam",
+			lines:   []StockItemsBaixa{line(other, 1), line(closed, 2), line(target, 4)},
+			want:    []uuid.UUID{other, target, target},
+			changed: true,
+		},
+		{
+			name:    "reserva sem o galpao encerrado fica igual",
+			lines:   []StockItemsBaixa{line(other, 1)},
+			want:    []uuid.UUID{other},
+			changed: false,
+		},
+		{
+			name:    "linha sem galpao e ignorada",
+			lines:   []StockItemsBaixa{{ProductId: &product}},
+			want:    []uuid.UUID{uuid.Nil},
+			changed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RetargetLines(tt.lines, closed, target); got != tt.changed {
+				t.Errorf("RetargetLines() = %v, want %v", got, tt.changed)
+			}
+			for i, l := range tt.lines {
+				got := uuid.Nil
+				if l.WarehouseId != nil {
+					got = *l.WarehouseId
+				}
+				if got != tt.want[i] {
+					t.Errorf("linha %d: galpao %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// Linhas que compartilham o ponteiro do galpao nao podem ser alteradas juntas
+// por engano: cada linha trocada recebe o seu proprio valor.
+func TestRetargetLinesSharedPointer(t *testing.T) {
+	closed := uuid.Must(uuid.NewV4())
+	target := uuid.Must(uuid.NewV4())
+	quantity := int64(1)
+
+	lines := []StockItemsBaixa{
+		{WarehouseId: &closed, Quantity: &quantity},
+		{WarehouseId: &closed, Quantity: &quantity},
+	}
+	RetargetLines(lines, closed, target)
+
+	if closed == target {
+		t.Fatal("galpao original foi sobrescrito")
+	}
+	for i, l := range lines {
+		if *l.WarehouseId != target {
+			t.Errorf("linha %d: galpao %v, want %v", i, *l.WarehouseId, target)
+		}
+	}
+}
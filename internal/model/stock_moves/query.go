@@ -0,0 +1,162 @@
+package stockmoves
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	TypeIn       = "in"
+	TypeOut      = "out"
+	TypeReversal = "reversal"
+)
+
+const DefaultSort = "-createdAt"
+
+// SortColumns sao os campos aceitos no parametro 'sort'. Prefixo '-' ordena
+// de forma decrescente.
+var SortColumns = map[string]string{
+	"createdAt": `m."CreatedAt"`,
+	"qtyMoved":  `m."QtyMoved"`,
+}
+
+// Query sao os filtros da consulta do razao de estoque. Campos nil nao filtram.
+type Query struct {
+	From         *time.Time
+	To           *time.Time
+	Type         *string
+	Reason       *string
+	MinQty       *int64
+	MaxQty       *int64
+	CategoryId   *uuid.UUID
+	WarehouseIds []uuid.UUID
+	ProductIds   []uuid.UUID
+	CreatedBy    *string
+	Sort         string
+}
+
+// SortField retorna o campo de ordenacao sem o prefixo de direcao
+func (q *Query) SortField() string {
+	return strings.TrimPrefix(q.Sort, "-")
+}
+
+// SortDesc informa se a ordenacao e decrescente
+func (q *Query) SortDesc() bool {
+	return strings.HasPrefix(q.Sort, "-")
+}
+
+// ParseQuery le os filtros do razao a partir dos query params. Datas seguem
+// RFC 3339, 'from' inclusivo e 'to' exclusivo; minQty e maxQty comparam a
+// quantidade absoluta da movimentacao; categoryId inclui as subcategorias;
+// warehouseId e productId aceitam varios valores, repetindo o parametro ou
+// separando por virgula.
+func ParseQuery(values url.Values) (*Query, error) {
+	q := &Query{Sort: DefaultSort}
+
+	var err error
+	if q.From, err = parseTime(values, "from"); err != nil {
+		return nil, err
+	}
+	if q.To, err = parseTime(values, "to"); err != nil {
+		return nil, err
+	}
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return nil, errors.New("parametro 'from' deve ser anterior a 'to'")
+	}
+
+	if t := values.Get("type"); t != "" {
+		if t != TypeIn && t != TypeOut && t != TypeReversal {
+			return nil, errors.New("parametro 'type' deve ser 'in', 'out' ou 'reversal'")
+		}
+		q.Type = &t
+	}
+
+	if reason := strings.TrimSpace(values.Get("reason")); reason != "" {
+		q.Reason = &reason
+	}
+
+	if q.MinQty, err = parseQty(values, "minQty"); err != nil {
+		return nil, err
+	}
+	if q.MaxQty, err = parseQty(values, "maxQty"); err != nil {
+		return nil, err
+	}
+	if q.MinQty != nil && q.MaxQty != nil && *q.MinQty > *q.MaxQty {
+		return nil, errors.New("parametro 'minQty' deve ser menor ou igual a 'maxQty'")
+	}
+
+	if categoryStr := values.Get("categoryId"); categoryStr != "" {
+		categoryId, err := uuid.FromString(categoryStr)
+		if err != nil {
+			return nil, errors.New("parametro 'categoryId' precisa ser um UUID válido")
+		}
+		q.CategoryId = &categoryId
+	}
+
+	if q.WarehouseIds, err = parseIds(values, "warehouseId"); err != nil {
+		return nil, err
+	}
+	if q.ProductIds, err = parseIds(values, "productId"); err != nil {
+		return nil, err
+	}
+
+	if createdBy := strings.TrimSpace(values.Get("createdBy")); createdBy != "" {
+		q.CreatedBy = &createdBy
+	}
+
+	if sort := values.Get("sort"); sort != "" {
+		if _, ok := SortColumns[strings.TrimPrefix(sort, "-")]; !ok {
+			return nil, errors.New("parametro 'sort' invalido, use createdAt ou qtyMoved, com '-' para ordem decrescente")
+		}
+		q.Sort = sort
+	}
+
+	return q, nil
+}
+
+func parseTime(values url.Values, name string) (*time.Time, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, errors.New("parametro '" + name + "' deve ser uma data RFC 3339, ex: 2024-01-31T00:00:00Z")
+	}
+	return &t, nil
+}
+
+func parseQty(values url.Values, name string) (*int64, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	qty, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || qty < 0 {
+		return nil, errors.New("parametro '" + name + "' deve ser um inteiro nao negativo")
+	}
+	return &qty, nil
+}
+
+func parseIds(values url.Values, name string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, raw := range values[name] {
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			id, err := uuid.FromString(part)
+			if err != nil {
+				return nil, errors.New("parametro '" + name + "' precisa conter UUIDs válidos")
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
@@ -7,12 +7,15 @@ import (
 )
 
 type GetByIdResponse struct {
-	Status      int       `json:"-"`
-	Msg         string    `json:"-"`
-	Id          uuid.UUID `db:"Id" json:"id"`
-	ProductId   uuid.UUID `db:"ProductId" json:"product_id"`
-	WarehouseId uuid.UUID `db:"WarehouseId" json:"warehouse_id"`
-	QtyMoved    int64     `db:"QtyMoved" json:"qty_moved"`
-	Reason      string    `db:"Reason" json:"reason"`
-	CreatedAt   time.Time `db:"CreatedAt" json:"created_at"`
+	Status      int        `json:"-"`
+	Msg         string     `json:"-"`
+	Id          uuid.UUID  `db:"Id" json:"id"`
+	ProductId   uuid.UUID  `db:"ProductId" json:"product_id"`
+	WarehouseId uuid.UUID  `db:"WarehouseId" json:"warehouse_id"`
+	QtyMoved    int64      `db:"QtyMoved" json:"qty_moved"`
+	Reason      string     `db:"Reason" json:"reason"`
+	ReversalOf  *uuid.UUID `db:"ReversalOf" json:"reversal_of,omitempty"`
+	ReversedBy  *uuid.UUID `json:"reversed_by,omitempty"`
+	CreatedBy   *string    `db:"CreatedBy" json:"created_by,omitempty"`
+	CreatedAt   time.Time  `db:"CreatedAt" json:"created_at"`
 }
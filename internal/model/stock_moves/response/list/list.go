@@ -8,4 +8,5 @@ type ListResponse struct {
 	Status     int                     `json:"-"`
 	Msg        string                  `json:"-"`
 	StockMoves *[]stockmoves.StockMove `json:"stock_moves"`
+	NextCursor *string                 `json:"next_cursor"`
 }
@@ -7,15 +7,44 @@ import (
 	"github.com/gofrs/uuid"
 )
 
+// ReasonDeduct e o motivo das movimentacoes gravadas pela baixa de estoque
+const ReasonDeduct = "Baixa de estoque"
+
+// StockMove e uma linha do razao de estoque. QtyMoved positivo representa
+// entrada no galpao e negativo representa saida.
 type StockMove struct {
 	Id          *uuid.UUID `db:"Id" json:"id"`
 	ProductId   *uuid.UUID `db:"ProductId" json:"product_id"`
 	WarehouseId *uuid.UUID `db:"WarehouseId" json:"warehouse_id"`
 	QtyMoved    *int64     `db:"QtyMoved" json:"qty_moved"`
 	Reason      *string    `db:"Reason" json:"reason"`
+	ReversalOf  *uuid.UUID `db:"ReversalOf" json:"reversal_of,omitempty"`
+	ReversedBy  *uuid.UUID `db:"-" json:"reversed_by,omitempty"`
+	CreatedBy   *string    `db:"CreatedBy" json:"created_by,omitempty"`
 	CreatedAt   *time.Time `db:"CreatedAt" json:"created_at"`
 }
 
+// ExportRow e a movimentacao com os nomes do produto e do galpao, para a
+// exportacao do razao
+type ExportRow struct {
+	StockMove
+	ProductName   *string
+	WarehouseName *string
+}
+
+// ExportColumns e o cabecalho da exportacao, na ordem de ExportRow.Values
+var ExportColumns = []string{"id", "createdAt", "productId", "productName", "warehouseId", "warehouseName", "qtyMoved", "reason", "reversalOf", "createdBy"}
+
+// Values retorna as celulas da linha exportada
+func (m *ExportRow) Values() []any {
+	return []any{m.Id, m.CreatedAt, m.ProductId, m.ProductName, m.WarehouseId, m.WarehouseName, m.QtyMoved, m.Reason, m.ReversalOf, m.CreatedBy}
+}
+
+type Reversal struct {
+	Reason    *string `json:"reason"`
+	CreatedBy *string `json:"-"`
+}
+
 func (s *StockMove) ValidateCreate() error {
 	if s.ProductId == nil {
 		return errors.New("atributo 'product_id' faltando")
@@ -29,9 +58,21 @@ func (s *StockMove) ValidateCreate() error {
 		return errors.New("atributo 'qty_moved' faltando")
 	}
 
+	if *s.QtyMoved == 0 {
+		return errors.New("atributo 'qty_moved' nao pode ser zero")
+	}
+
 	if s.Reason == nil {
 		return errors.New("atributo 'reason' faltando")
 	}
 
+	if s.ReversalOf != nil || s.ReversedBy != nil {
+		return errors.New("atributo 'reversal_of' e controlado pela api, use o estorno da movimentacao")
+	}
+
+	if s.CreatedBy != nil {
+		return errors.New("atributo 'created_by' e preenchido pela api com o usuario do token")
+	}
+
 	return nil
 }
@@ -0,0 +1,107 @@
+package stream
+
+import (
+	"api-estoque/internal/model/outbox"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// ResetEvent avisa que nao foi possivel retomar do Last-Event-ID informado: o
+// cliente deve recarregar os saldos (GET /stock-items) e seguir com o stream.
+const ResetEvent = "stream.reset"
+
+// MaxFilterItems limita cada lista de filtros da assinatura
+const MaxFilterItems = 100
+
+// LevelTypes sao os eventos que alteram o saldo de um item de estoque. Os
+// lancamentos do razao (stock.moved) e os eventos de produto nao entram no stream.
+var LevelTypes = []string{
+	outbox.StockReceived,
+	outbox.StockAdjusted,
+	outbox.StockDeducted,
+	outbox.StockReserved,
+	outbox.StockReleased,
+	outbox.StockRemoved,
+}
+
+// Filter escolhe os itens de estoque acompanhados. Listas vazias aceitam todos.
+type Filter struct {
+	WarehouseIds []uuid.UUID
+	ProductIds   []uuid.UUID
+}
+
+// Matches informa se o evento interessa ao cliente
+func (f *Filter) Matches(e *outbox.Event) bool {
+	if !slices.Contains(LevelTypes, e.Type) || e.WarehouseId == nil {
+		return false
+	}
+	if len(f.WarehouseIds) > 0 && !slices.Contains(f.WarehouseIds, *e.WarehouseId) {
+		return false
+	}
+	if len(f.ProductIds) > 0 && !slices.Contains(f.ProductIds, e.ProductId) {
+		return false
+	}
+	return true
+}
+
+// ParseFilter le os query params 'warehouseId' e 'productId'. Cada um pode se
+// repetir ou trazer varios UUIDs separados por virgula.
+func ParseFilter(r *http.Request) (*Filter, error) {
+	warehouseIds, err := parseIds(r.URL.Query()["warehouseId"], "warehouseId")
+	if err != nil {
+		return nil, err
+	}
+	productIds, err := parseIds(r.URL.Query()["productId"], "productId")
+	if err != nil {
+		return nil, err
+	}
+	return &Filter{WarehouseIds: warehouseIds, ProductIds: productIds}, nil
+}
+
+func parseIds(values []string, name string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			id, err := uuid.FromString(part)
+			if err != nil {
+				return nil, fmt.Errorf("%s precisa ser um UUID válido", name)
+			}
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) > MaxFilterItems {
+		return nil, fmt.Errorf("parametro '%s' aceita no máximo %d UUIDs", name, MaxFilterItems)
+	}
+	return ids, nil
+}
+
+// ParseLastEventId le o ultimo evento recebido pelo cliente, do header
+// Last-Event-ID (enviado pelo EventSource ao reconectar) ou do query param
+// 'lastEventId'. Retorna nil quando o cliente nao esta retomando.
+func ParseLastEventId(r *http.Request) (*int64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return nil, errors.New("Last-Event-ID deve ser o 'id' de um evento recebido do stream")
+	}
+	return &id, nil
+}
@@ -0,0 +1,19 @@
+package transaction
+
+import (
+	stockitems "api-estoque/internal/model/stock_items"
+	"time"
+)
+
+type TransactionResponse struct {
+	Status            int                          `json:"-"`
+	Msg               string                       `json:"-"`
+	TxId              string                       `json:"tx_id"`
+	TransactionStatus string                       `json:"status"`
+	Lines             []stockitems.StockItemsBaixa `json:"lines"`
+	ExpiresAt         *time.Time                   `json:"expires_at,omitempty"`
+	CancelReason      *string                      `json:"cancel_reason,omitempty"`
+	CreatedBy         *string                      `json:"created_by,omitempty"`
+	CreatedAt         time.Time                    `json:"created_at"`
+	UpdatedAt         time.Time                    `json:"updated_at"`
+}
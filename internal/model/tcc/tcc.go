@@ -0,0 +1,106 @@
+package tcc
+
+import (
+	stockitems "api-estoque/internal/model/stock_items"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Estados de uma transacao TCC
+const (
+	StatusTried     = "tried"     // reserva feita, aguardando Confirm ou Cancel
+	StatusConfirmed = "confirmed" // reserva transformada em baixa
+	StatusCancelled = "cancelled" // reserva liberada, ou Cancel recebido antes do Try
+)
+
+// Motivos do cancelamento
+const (
+	CancelRequested = "requested" // Cancel enviado pelo coordenador
+	CancelExpired   = "expired"   // Try vencido sem Confirm
+)
+
+// MaxTxIdLength limita o tamanho do id global da transacao
+const MaxTxIdLength = 128
+
+// Transaction e a participacao do estoque numa transacao TCC. Lines sao as
+// linhas reservadas no Try, usadas depois no Confirm ou no Cancel.
+type Transaction struct {
+	TxId         string                       `json:"tx_id"`
+	Status       string                       `json:"status"`
+	Lines        []stockitems.StockItemsBaixa `json:"lines"`
+	ExpiresAt    *time.Time                   `json:"expires_at,omitempty"`
+	CancelReason *string                      `json:"cancel_reason,omitempty"`
+	CreatedBy    *string                      `json:"created_by,omitempty"`
+	CreatedAt    time.Time                    `json:"created_at"`
+	UpdatedAt    time.Time                    `json:"updated_at"`
+}
+
+// Try e o body da fase Try. TimeoutSeconds e o prazo da reserva; quando omitido
+// vale o padrao da configuracao.
+type Try struct {
+	Lines          []stockitems.StockItemsBaixa `json:"lines"`
+	TimeoutSeconds *int64                       `json:"timeout_seconds"`
+	CreatedBy      *string                      `json:"-"`
+}
+
+func (t *Try) Validate(maxTimeout time.Duration) error {
+	if err := stockitems.ValidateLines(t.Lines); err != nil {
+		return err
+	}
+
+	if t.TimeoutSeconds != nil {
+		if *t.TimeoutSeconds <= 0 {
+			return errors.New("atributo 'timeout_seconds' deve ser maior que zero")
+		}
+		if time.Duration(*t.TimeoutSeconds)*time.Second > maxTimeout {
+			return fmt.Errorf("atributo 'timeout_seconds' deve ser no maximo %d", int64(maxTimeout.Seconds()))
+		}
+	}
+
+	return nil
+}
+
+// Timeout e o prazo da reserva, ou defaultTimeout quando nao informado
+func (t *Try) Timeout(defaultTimeout time.Duration) time.Duration {
+	if t.TimeoutSeconds == nil {
+		return defaultTimeout
+	}
+	return time.Duration(*t.TimeoutSeconds) * time.Second
+}
+
+func ValidateTxId(txId string) error {
+	if txId == "" {
+		return errors.New("id da transacao faltando")
+	}
+	if len(txId) > MaxTxIdLength {
+		return fmt.Errorf("id da transacao deve ter no maximo %d caracteres", MaxTxIdLength)
+	}
+	return nil
+}
+
+// SameLines indica se as duas listas reservam as mesmas quantidades dos mesmos
+// itens, em qualquer ordem
+func SameLines(a []stockitems.StockItemsBaixa, b []stockitems.StockItemsBaixa) bool {
+	return equalTotals(sumLines(a), sumLines(b))
+}
+
+func sumLines(lines []stockitems.StockItemsBaixa) map[stockitems.ItemKey]int64 {
+	totals := make(map[stockitems.ItemKey]int64, len(lines))
+	for _, line := range lines {
+		totals[stockitems.ItemKey{ProductId: *line.ProductId, WarehouseId: *line.WarehouseId}] += *line.Quantity
+	}
+	return totals
+}
+
+func equalTotals(a map[stockitems.ItemKey]int64, b map[stockitems.ItemKey]int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, qty := range a {
+		if b[key] != qty {
+			return false
+		}
+	}
+	return true
+}
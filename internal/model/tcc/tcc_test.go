@@ -0,0 +1,85 @@
+package tcc
+
+import (
+	stockitems "api-estoque/internal/model/stock_items"
+	"testing"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestSameLines(t *testing.T) {
+	productA := uuid.Must(uuid.NewV4())
+	productB := uuid.Must(uuid.NewV4())
+	warehouse1 := uuid.Must(uuid.NewV4())
+	warehouse2 := uuid.Must(uuid.NewV4())
+
+	line := func(product uuid.UUID, warehouse uuid.UUID, quantity int64) stockitems.StockItemsBaixa {
+		return stockitems.StockItemsBaixa{ProductId: &product, WarehouseId: &warehouse, Quantity: &quantity}
+	}
+
+	tests := []struct {
+		name string
+		a, b []stockitems.StockItemsBaixa
+		want bool
+	}{
+		{
+			name: "iguais",
+			a:    []stockitems.StockItemsBaixa{line(productA, warehouse1, 2)},
+			b:    []stockitems.StockItemsBaixa{line(productA, warehouse1, 2)},
+			want: true,
+		},
+		{
+			name: "outra ordem",
+			a:    []stockitems.StockItemsBaixa{line(productA, warehouse1, 2), line(productB, warehouse2, 1)},
+			b:    []stockitems.StockItemsBaixa{line(productB, warehouse2, 1), line(productA, warehouse1, 2)},
+			want: true,
+		},
+		{
+			name: "mesmo item dividido em linhas",
+			a:    []stockitems.StockItemsBaixa{line(productA, warehouse1, 1), line(productA, warehouse1, 2)},
+			b:    []stockitems.StockItemsBaixa{line(productA, warehouse1, 3)},
+			want: true,
+		},
+		{
+			name: "quantidade diferente",
+			a:    []stockitems.StockItemsBaixa{line(productA, warehouse1, 2)},
+			b:    []stockitems.StockItemsBaixa{line(productA, warehouse1, 3)},
+			want: false,
+		},
+		{
+			name: "outro galpao",
+			a:    []stockitems.StockItemsBaixa{line(productA, warehouse1, 2)},
+			b:    []stockitems.StockItemsBaixa{line(productA, warehouse2, 2)},
+			want: false,
+		},
+		{
+			name: "outro produto",
+			a:    []stockitems.StockItemsBaixa{line(productA, warehouse1, 2)},
+			b:    []stockitems.StockItemsBaixa{line(productB, warehouse1, 2)},
+			want: false,
+		},
+		{
+			name: "linha a mais",
+			a:    []stockitems.StockItemsBaixa{line(productA, warehouse1, 2)},
+			b:    []stockitems.StockItemsBaixa{line(productA, warehouse1, 2), line(productB, warehouse1, 1)},
+			want: false,
+		},
+		{
+			name: "vazias",
+			a:    nil,
+			b:    []stockitems.StockItemsBaixa{},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameLines(tt.a, tt.b); got != tt.want {
+				t.Errorf("SameLines() = %v, want %v", got, tt.want)
+			}
+			if got := SameLines(tt.b, tt.a); got != tt.want {
+				t.Errorf("SameLines() invertido = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package warehouse
+
+import (
+	"fmt"
+
+	"github.com/gofrs/uuid"
+)
+
+type Utilization struct {
+	WarehouseId          uuid.UUID `json:"warehouse_id"`
+	VolumeCapacityLiters *int64    `json:"volume_capacity_liters"`
+	WeightCapacityKg     *int64    `json:"weight_capacity_kg"`
+	UsedVolumeLiters     float64   `json:"used_volume_liters"`
+	UsedWeightKg         float64   `json:"used_weight_kg"`
+	VolumeUsagePercent   *float64  `json:"volume_usage_percent"`
+	WeightUsagePercent   *float64  `json:"weight_usage_percent"`
+	// Itens com saldo cujo produto nao tem dimensoes ou peso cadastrados,
+	// e que portanto nao entram no calculo de ocupacao.
+	UnmeasuredItems int64 `json:"unmeasured_items"`
+}
+
+// Load e o volume e o peso de entradas ainda nao gravadas num galpao.
+type Load struct {
+	VolumeLiters float64
+	WeightKg     float64
+}
+
+// ComputeUsage preenche os percentuais de ocupacao das capacidades cadastradas.
+func (u *Utilization) ComputeUsage() {
+	if u.VolumeCapacityLiters != nil {
+		pct := u.UsedVolumeLiters / float64(*u.VolumeCapacityLiters) * 100
+		u.VolumeUsagePercent = &pct
+	}
+	if u.WeightCapacityKg != nil {
+		pct := u.UsedWeightKg / float64(*u.WeightCapacityKg) * 100
+		u.WeightUsagePercent = &pct
+	}
+}
+
+// CheckAddition retorna erro se adicionar o volume e o peso informados
+// ultrapassar alguma capacidade cadastrada do galpao.
+func (u *Utilization) CheckAddition(volumeLiters float64, weightKg float64) error {
+	if u.VolumeCapacityLiters != nil && u.UsedVolumeLiters+volumeLiters > float64(*u.VolumeCapacityLiters) {
+		return fmt.Errorf("capacidade volumetrica do galpao excedida: %.2f de %d litros",
+			u.UsedVolumeLiters+volumeLiters, *u.VolumeCapacityLiters)
+	}
+	if u.WeightCapacityKg != nil && u.UsedWeightKg+weightKg > float64(*u.WeightCapacityKg) {
+		return fmt.Errorf("capacidade de peso do galpao excedida: %.2f de %d kg",
+			u.UsedWeightKg+weightKg, *u.WeightCapacityKg)
+	}
+	return nil
+}
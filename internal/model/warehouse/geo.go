@@ -0,0 +1,50 @@
+package warehouse
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+)
+
+const earthRadiusKm = 6371.0
+
+var cepRegex = regexp.MustCompile(`^[0-9]{5}-?[0-9]{3}$`)
+
+// NormalizeCEP valida um CEP nos formatos "01310-100" ou "01310100" e
+// retorna somente os 8 digitos.
+func NormalizeCEP(cep string) (string, error) {
+	cep = strings.TrimSpace(cep)
+	if !cepRegex.MatchString(cep) {
+		return "", errors.New("atributo 'zip_code' deve ser um CEP valido (00000-000)")
+	}
+	return strings.ReplaceAll(cep, "-", ""), nil
+}
+
+func ValidateCoordinates(lat *float64, lng *float64) error {
+	if (lat == nil) != (lng == nil) {
+		return errors.New("atributos 'latitude' e 'longitude' devem ser informados juntos")
+	}
+	if lat == nil {
+		return nil
+	}
+	if *lat < -90 || *lat > 90 {
+		return errors.New("atributo 'latitude' deve estar entre -90 e 90")
+	}
+	if *lng < -180 || *lng > 180 {
+		return errors.New("atributo 'longitude' deve estar entre -180 e 180")
+	}
+	return nil
+}
+
+// GreatCircleDistance retorna a distancia em km entre dois pontos usando a formula de haversine.
+func GreatCircleDistance(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
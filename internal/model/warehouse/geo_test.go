@@ -0,0 +1,63 @@
+package warehouse
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeCEP(t *testing.T) {
+	tests := []struct {
+		name    string
+		cep     string
+		want    string
+		wantErr bool
+	}{
+		{name: "com hifen", cep: "01310-100", want: "01310100"},
+		{name: "so digitos", cep: "01310100", want: "01310100"},
+		{name: "espacos nas pontas", cep: " 01310-100 ", want: "01310100"},
+		{name: "digitos a menos", cep: "0131010", wantErr: true},
+		{name: "digitos a mais", cep: "013101000", wantErr: true},
+		{name: "hifen fora do lugar", cep: "0131-0100", wantErr: true},
+		{name: "letras", cep: "01310-10a", wantErr: true},
+		{name: "vazio", cep: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeCEP(tt.cep)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeCEP(%q) erro = %v, wantErr %v", tt.cep, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeCEP(%q) = %q, want %q", tt.cep, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGreatCircleDistance(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+	}{
+		{name: "mesmo ponto", lat1: -23.5505, lng1: -46.6333, lat2: -23.5505, lng2: -46.6333, want: 0},
+		{name: "sao paulo a rio de janeiro", lat1: -23.5505, lng1: -46.6333, lat2: -22.9068, lng2: -43.1729, want: 361},
+		{name: "sao paulo a porto alegre", lat1: -23.5505, lng1: -46.6333, lat2: -30.0346, lng2: -51.2177, want: 852},
+		{name: "um grau no equador", lat1: 0, lng1: 0, lat2: 0, lng2: 1, want: 111.2},
+		{name: "antipodas", lat1: 0, lng1: 0, lat2: 0, lng2: 180, want: math.Pi * earthRadiusKm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GreatCircleDistance(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+			if math.Abs(got-tt.want) > 1 {
+				t.Errorf("GreatCircleDistance() = %.1f km, want %.1f km", got, tt.want)
+			}
+			back := GreatCircleDistance(tt.lat2, tt.lng2, tt.lat1, tt.lng1)
+			if math.Abs(got-back) > 1e-9 {
+				t.Errorf("distancia nao e simetrica: %.6f e %.6f", got, back)
+			}
+		})
+	}
+}
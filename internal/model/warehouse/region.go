@@ -0,0 +1,27 @@
+package warehouse
+
+// macroRegions mapeia cada UF para a sua grande regiao (IBGE).
+var macroRegions = map[string]string{
+	"AC": "N", "AM": "N", "AP": "N", "PA": "N", "RO": "N", "RR": "N", "TO": "N",
+	"AL": "NE", "BA": "NE", "CE": "NE", "MA": "NE", "PB": "NE", "PE": "NE", "PI": "NE", "RN": "NE", "SE": "NE",
+	"DF": "CO", "GO": "CO", "MS": "CO", "MT": "CO",
+	"ES": "SE", "MG": "SE", "RJ": "SE", "SP": "SE",
+	"PR": "S", "RS": "S", "SC": "S",
+}
+
+func IsValidUF(uf string) bool {
+	_, ok := macroRegions[uf]
+	return ok
+}
+
+// RegionDistance retorna uma distancia aproximada entre duas UFs:
+// 0 para a mesma UF, 1 para a mesma grande regiao e 2 caso contrario.
+func RegionDistance(a string, b string) int {
+	if a == b {
+		return 0
+	}
+	if macroRegions[a] != "" && macroRegions[a] == macroRegions[b] {
+		return 1
+	}
+	return 2
+}
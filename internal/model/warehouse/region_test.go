@@ -0,0 +1,55 @@
+package warehouse
+
+import "testing"
+
+func TestIsValidUF(t *testing.T) {
+	ufs := []string{
+		"AC", "AL", "AM", "AP", "BA", "CE", "DF", "ES", "GO", "MA", "MG", "MS", "MT", "PA",
+		"PB", "PE", "PI", "PR", "RJ", "RN", "RO", "RR", "RS", "SC", "SE", "SP", "TO",
+	}
+	for _, uf := range ufs {
+		if !IsValidUF(uf) {
+			t.Errorf("IsValidUF(%q) = false, want true", uf)
+		}
+	}
+	if len(macroRegions) != len(ufs) {
+		t.Errorf("macroRegions tem %d UFs, want %d", len(macroRegions), len(ufs))
+	}
+
+	for _, uf := range []string{"", "sp", "XX", "SPA"} {
+		if IsValidUF(uf) {
+			t.Errorf("IsValidUF(%q) = true, want false", uf)
+		}
+	}
+}
+
+func TestRegionDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want int
+	}{
+		{name: "mesma UF", a: "SP", b: "SP", want: 0},
+		{name: "sudeste", a: "SP", b: "RJ", want: 1},
+		{name: "sul", a: "PR", b: "RS", want: 1},
+		{name: "nordeste", a: "BA", b: "PE", want: 1},
+		{name: "norte", a: "AM", b: "TO", want: 1},
+		{name: "centro-oeste", a: "DF", b: "MT", want: 1},
+		{name: "regioes diferentes", a: "SP", b: "BA", want: 2},
+		{name: "ES e sudeste, nao nordeste", a: "ES", b: "BA", want: 2},
+		{name: "TO e norte, nao centro-oeste", a: "TO", b: "GO", want: 2},
+		{name: "UFs desconhecidas nao sao vizinhas", a: "XX", b: "YY", want: 2},
+		{name: "UF desconhecida", a: "SP", b: "XX", want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RegionDistance(tt.a, tt.b); got != tt.want {
+				t.Errorf("RegionDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := RegionDistance(tt.b, tt.a); got != tt.want {
+				t.Errorf("RegionDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
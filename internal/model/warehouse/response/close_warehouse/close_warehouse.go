@@ -0,0 +1,14 @@
+package closewarehouse
+
+import (
+	"github.com/gofrs/uuid"
+)
+
+type CloseResponse struct {
+	Status            int        `json:"-"`
+	Msg               string     `json:"-"`
+	Id                uuid.UUID  `json:"id"`
+	TargetWarehouseId *uuid.UUID `json:"target_warehouse_id,omitempty"`
+	TransferredItems  int        `json:"transferred_items"`
+	Warnings          []string   `json:"warnings,omitempty"`
+}
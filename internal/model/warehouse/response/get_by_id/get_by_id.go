@@ -7,10 +7,26 @@ import (
 )
 
 type GetByIdResponse struct {
-	Status    int        `json:"-"`
-	Msg       string     `json:"-"`
-	Id        uuid.UUID  `db:"Id" json:"id"`
-	Name      string     `db:"Name" json:"name"`
-	Location  string     `db:"Location" json:"location"`
-	CreatedAt *time.Time `db:"CreatedAt" json:"created_at,omitempty"`
+	Status               int        `json:"-"`
+	Msg                  string     `json:"-"`
+	Id                   uuid.UUID  `db:"Id" json:"id"`
+	Name                 string     `db:"Name" json:"name"`
+	Location             string     `db:"Location" json:"location"`
+	Region               *string    `db:"Region" json:"region"`
+	Priority             int        `db:"Priority" json:"priority"`
+	Street               *string    `db:"Street" json:"street"`
+	Number               *string    `db:"Number" json:"number"`
+	Complement           *string    `db:"Complement" json:"complement"`
+	District             *string    `db:"District" json:"district"`
+	City                 *string    `db:"City" json:"city"`
+	State                *string    `db:"State" json:"state"`
+	ZipCode              *string    `db:"ZipCode" json:"zip_code"`
+	Latitude             *float64   `db:"Latitude" json:"latitude"`
+	Longitude            *float64   `db:"Longitude" json:"longitude"`
+	VolumeCapacityLiters *int64     `db:"VolumeCapacityLiters" json:"volume_capacity_liters"`
+	WeightCapacityKg     *int64     `db:"WeightCapacityKg" json:"weight_capacity_kg"`
+	WarehouseStatus      string     `db:"Status" json:"status"`
+	ClosedAt             *time.Time `db:"ClosedAt" json:"closed_at,omitempty"`
+	CreatedAt            *time.Time `db:"CreatedAt" json:"created_at,omitempty"`
+	Version              int64      `json:"-"`
 }
@@ -8,4 +8,5 @@ type ListResponse struct {
 	Status     int                    `json:"-"`
 	Msg        string                 `json:"-"`
 	Warehouses *[]warehouse.Warehouse `json:"warehouses"`
+	NextCursor *string                `json:"next_cursor"`
 }
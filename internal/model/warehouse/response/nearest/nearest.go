@@ -0,0 +1,11 @@
+package nearest
+
+import (
+	"api-estoque/internal/model/warehouse"
+)
+
+type NearestResponse struct {
+	Status     int                          `json:"-"`
+	Msg        string                       `json:"-"`
+	Warehouses []warehouse.NearestWarehouse `json:"warehouses"`
+}
@@ -0,0 +1,11 @@
+package utilization
+
+import (
+	"api-estoque/internal/model/warehouse"
+)
+
+type UtilizationResponse struct {
+	Status int    `json:"-"`
+	Msg    string `json:"-"`
+	warehouse.Utilization
+}
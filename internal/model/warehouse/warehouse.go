@@ -7,21 +7,65 @@ import (
 	"github.com/gofrs/uuid"
 )
 
+// Warehouse e um galpao. Region e State sao a mesma UF: o banco deriva "State"
+// de "Region", e informar as duas com valores diferentes e recusado.
 type Warehouse struct {
-	Id        *uuid.UUID `db:"Id" json:"id"`
-	Name      *string    `db:"Name" json:"name"`
-	Location  *string    `db:"Location" json:"location"`
-	CreatedAt *time.Time `db:"CreatedAt" json:"created_at,omitempty"`
+	Id         *uuid.UUID `db:"Id" json:"id"`
+	Name       *string    `db:"Name" json:"name"`
+	Location   *string    `db:"Location" json:"location"`
+	Region     *string    `db:"Region" json:"region"`
+	Priority   *int       `db:"Priority" json:"priority"`
+	Street     *string    `db:"Street" json:"street"`
+	Number     *string    `db:"Number" json:"number"`
+	Complement *string    `db:"Complement" json:"complement"`
+	District   *string    `db:"District" json:"district"`
+	City       *string    `db:"City" json:"city"`
+	State      *string    `db:"State" json:"state"`
+	ZipCode    *string    `db:"ZipCode" json:"zip_code"`
+	Latitude   *float64   `db:"Latitude" json:"latitude"`
+	Longitude  *float64   `db:"Longitude" json:"longitude"`
+	// Capacidades nulas indicam galpao sem limite cadastrado.
+	VolumeCapacityLiters *int64     `db:"VolumeCapacityLiters" json:"volume_capacity_liters"`
+	WeightCapacityKg     *int64     `db:"WeightCapacityKg" json:"weight_capacity_kg"`
+	Status               *string    `db:"Status" json:"status"`
+	ClosedAt             *time.Time `db:"ClosedAt" json:"closed_at,omitempty"`
+	CreatedAt            *time.Time `db:"CreatedAt" json:"created_at,omitempty"`
+	Version              *int64     `db:"Version" json:"-"`
+}
+
+const (
+	StatusActive = "active"
+	StatusClosed = "closed"
+)
+
+// NearestWarehouse e um galpao com saldo disponivel de um produto e a sua
+// distancia ate o ponto consultado.
+type NearestWarehouse struct {
+	Warehouse
+	Available  int64   `json:"available"`
+	DistanceKm float64 `json:"distance_km"`
 }
 
 func (w *Warehouse) ValidateCreate() error {
 	if w.Name == nil {
 		return errors.New("atributo 'name' faltando")
 	}
+	if w.Status != nil || w.ClosedAt != nil {
+		return errors.New("atributo 'status' e controlado pela api, use o encerramento do galpao")
+	}
 	if w.Location == nil {
 		return errors.New("atributo 'location' faltando")
 	}
-	return nil
+	if err := w.mergeState(); err != nil {
+		return err
+	}
+	if w.Priority != nil && *w.Priority < 0 {
+		return errors.New("atributo 'priority' nao pode ser negativo")
+	}
+	if err := w.validateCapacity(); err != nil {
+		return err
+	}
+	return w.validateAddress()
 }
 
 func (w *Warehouse) ValidateUpdate() error {
@@ -31,8 +75,12 @@ func (w *Warehouse) ValidateUpdate() error {
 	if w.Id == nil {
 		return errors.New("atributo 'id' faltando")
 	}
-	if w.Location == nil && w.Name == nil {
-		return errors.New("atributo 'location' e 'name' faltando, nada para alterar")
+	if w.Status != nil || w.ClosedAt != nil {
+		return errors.New("atributo 'status' e controlado pela api, use o encerramento do galpao")
+	}
+	if w.Location == nil && w.Name == nil && w.Region == nil && w.Priority == nil && !w.hasAddress() &&
+		w.VolumeCapacityLiters == nil && w.WeightCapacityKg == nil {
+		return errors.New("nenhum atributo informado para atualizacao")
 	}
 	if w.Location != nil {
 		if *w.Location == "" {
@@ -44,5 +92,58 @@ func (w *Warehouse) ValidateUpdate() error {
 			return errors.New("atributo 'name' nao pode ser vazio")
 		}
 	}
+	if err := w.mergeState(); err != nil {
+		return err
+	}
+	if w.Priority != nil && *w.Priority < 0 {
+		return errors.New("atributo 'priority' nao pode ser negativo")
+	}
+	if err := w.validateCapacity(); err != nil {
+		return err
+	}
+	return w.validateAddress()
+}
+
+// mergeState unifica 'region' e 'state', que sao a mesma UF, e a valida. A
+// string vazia limpa a UF na alteracao.
+func (w *Warehouse) mergeState() error {
+	if w.Region != nil && w.State != nil && *w.Region != *w.State {
+		return errors.New("atributos 'region' e 'state' devem ter a mesma UF")
+	}
+	if w.Region == nil {
+		w.Region = w.State
+	}
+	w.State = w.Region
+	if w.Region != nil && *w.Region != "" && !IsValidUF(*w.Region) {
+		return errors.New("atributo 'region' deve ser uma UF valida")
+	}
+	return nil
+}
+
+func (w *Warehouse) validateCapacity() error {
+	if w.VolumeCapacityLiters != nil && *w.VolumeCapacityLiters <= 0 {
+		return errors.New("atributo 'volume_capacity_liters' deve ser maior que zero")
+	}
+	if w.WeightCapacityKg != nil && *w.WeightCapacityKg <= 0 {
+		return errors.New("atributo 'weight_capacity_kg' deve ser maior que zero")
+	}
 	return nil
 }
+
+func (w *Warehouse) hasAddress() bool {
+	return w.Street != nil || w.Number != nil || w.Complement != nil || w.District != nil ||
+		w.City != nil || w.State != nil || w.ZipCode != nil || w.Latitude != nil || w.Longitude != nil
+}
+
+// validateAddress valida os campos de endereco informados e normaliza o CEP
+// para 8 digitos. A UF e validada por mergeState.
+func (w *Warehouse) validateAddress() error {
+	if w.ZipCode != nil && *w.ZipCode != "" {
+		cep, err := NormalizeCEP(*w.ZipCode)
+		if err != nil {
+			return err
+		}
+		w.ZipCode = &cep
+	}
+	return ValidateCoordinates(w.Latitude, w.Longitude)
+}
@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	DeliveryPending   = "pending"   // aguardando envio ou nova tentativa
+	DeliveryDelivered = "delivered" // receptor respondeu 2xx
+	DeliveryFailed    = "failed"    // tentativas esgotadas
+)
+
+// Delivery e o envio de um evento a uma assinatura. LastStatusCode e LastError
+// descrevem a ultima tentativa; LastError guarda a linha de status ou o erro de
+// conexao, nunca o corpo da resposta. NextAttemptAt so vale enquanto pendente.
+type Delivery struct {
+	Id             *uuid.UUID      `json:"id"`
+	SubscriptionId *uuid.UUID      `json:"subscriptionId"`
+	EventId        *uuid.UUID      `json:"eventId"`
+	EventType      *string         `json:"eventType"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         *string         `json:"status"`
+	Attempts       *int            `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt"`
+	LastStatusCode *int            `json:"lastStatusCode"`
+	LastError      *string         `json:"lastError"`
+	DeliveredAt    *time.Time      `json:"deliveredAt"`
+	CreatedAt      *time.Time      `json:"createdAt"`
+}
+
+// ParseDeliveryStatus le o filtro 'status' do log de entregas; vazio lista todas
+func ParseDeliveryStatus(status string) (*string, error) {
+	switch status {
+	case "":
+		return nil, nil
+	case DeliveryPending, DeliveryDelivered, DeliveryFailed:
+		return &status, nil
+	default:
+		return nil, errors.New("parametro 'status' deve ser 'pending', 'delivered' ou 'failed'")
+	}
+}
@@ -0,0 +1,13 @@
+package create
+
+import (
+	"github.com/gofrs/uuid"
+)
+
+// CreateResponse traz o segredo da assinatura, que nao e retornado depois
+type CreateResponse struct {
+	Status int       `json:"-"`
+	Msg    string    `json:"-"`
+	Id     uuid.UUID `json:"id"`
+	Secret string    `json:"secret"`
+}
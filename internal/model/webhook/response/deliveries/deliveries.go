@@ -0,0 +1,12 @@
+package deliveries
+
+import (
+	"api-estoque/internal/model/webhook"
+)
+
+type DeliveriesResponse struct {
+	Status     int                 `json:"-"`
+	Msg        string              `json:"-"`
+	Deliveries *[]webhook.Delivery `json:"deliveries"`
+	NextCursor *string             `json:"next_cursor"`
+}
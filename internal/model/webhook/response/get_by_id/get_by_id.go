@@ -0,0 +1,24 @@
+package getbyid
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type GetByIdResponse struct {
+	Status              int         `json:"-"`
+	Msg                 string      `json:"-"`
+	Id                  *uuid.UUID  `json:"id"`
+	Url                 *string     `json:"url"`
+	EventTypes          []string    `json:"eventTypes"`
+	WarehouseIds        []uuid.UUID `json:"warehouseIds"`
+	ProductIds          []uuid.UUID `json:"productIds"`
+	IsActive            *bool       `json:"isActive"`
+	ConsecutiveFailures *int        `json:"consecutiveFailures"`
+	DisabledAt          *time.Time  `json:"disabledAt"`
+	DisabledReason      *string     `json:"disabledReason"`
+	CreatedBy           *string     `json:"createdBy"`
+	CreatedAt           *time.Time  `json:"createdAt"`
+	UpdatedAt           *time.Time  `json:"updatedAt"`
+}
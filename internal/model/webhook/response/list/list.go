@@ -0,0 +1,11 @@
+package list
+
+import (
+	"api-estoque/internal/model/webhook"
+)
+
+type ListResponse struct {
+	Status        int                     `json:"-"`
+	Msg           string                  `json:"-"`
+	Subscriptions *[]webhook.Subscription `json:"subscriptions"`
+}
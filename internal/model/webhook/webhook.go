@@ -0,0 +1,221 @@
+package webhook
+
+import (
+	"api-estoque/internal/model/outbox"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	MaxUrlLength    = 2048
+	MinSecretLength = 16
+	MaxSecretLength = 256
+	// MaxFilterItems limita cada lista de filtros da assinatura
+	MaxFilterItems = 100
+)
+
+// Subscription e uma assinatura de webhook. Listas vazias em 'eventTypes',
+// 'warehouseIds' e 'productIds' recebem todos os eventos. Eventos de produto
+// nao tem galpao e nao chegam a assinaturas filtradas por galpao.
+// 'secret' assina as entregas; so aparece na resposta da criacao.
+type Subscription struct {
+	Id                  *uuid.UUID  `json:"id"`
+	Url                 *string     `json:"url"`
+	Secret              *string     `json:"secret,omitempty"`
+	EventTypes          []string    `json:"eventTypes"`
+	WarehouseIds        []uuid.UUID `json:"warehouseIds"`
+	ProductIds          []uuid.UUID `json:"productIds"`
+	IsActive            *bool       `json:"isActive"`
+	ConsecutiveFailures *int        `json:"consecutiveFailures"`
+	DisabledAt          *time.Time  `json:"disabledAt"`
+	DisabledReason      *string     `json:"disabledReason"`
+	CreatedBy           *string     `json:"createdBy"`
+	CreatedAt           *time.Time  `json:"createdAt"`
+	UpdatedAt           *time.Time  `json:"updatedAt"`
+}
+
+func (s *Subscription) ValidateCreate() error {
+	if s.Id != nil {
+		return errors.New("atributo 'id' é controlado pela API")
+	}
+
+	if err := s.validateControlled(); err != nil {
+		return err
+	}
+
+	if s.Url == nil {
+		return errors.New("atributo 'url' faltando")
+	}
+
+	if s.IsActive != nil && !*s.IsActive {
+		return errors.New("assinatura é criada ativa, omita 'isActive'")
+	}
+
+	if s.Secret == nil {
+		secret, err := NewSecret()
+		if err != nil {
+			return err
+		}
+		s.Secret = &secret
+	}
+
+	if s.EventTypes == nil {
+		s.EventTypes = []string{}
+	}
+	if s.WarehouseIds == nil {
+		s.WarehouseIds = []uuid.UUID{}
+	}
+	if s.ProductIds == nil {
+		s.ProductIds = []uuid.UUID{}
+	}
+
+	return s.validateFields()
+}
+
+// ValidateUpdate confere a alteracao. Atributos omitidos ficam como estao; uma
+// lista vazia remove o filtro. 'isActive' true reativa a assinatura e zera as falhas.
+func (s *Subscription) ValidateUpdate() error {
+	if s.Id == nil {
+		return errors.New("atributo 'id' faltando, necessário para atualizar a assinatura")
+	}
+
+	if err := s.validateControlled(); err != nil {
+		return err
+	}
+
+	if s.Url == nil && s.Secret == nil && s.EventTypes == nil && s.WarehouseIds == nil && s.ProductIds == nil && s.IsActive == nil {
+		return errors.New("nenhum atributo informado para atualização")
+	}
+
+	return s.validateFields()
+}
+
+func (s *Subscription) validateControlled() error {
+	if s.ConsecutiveFailures != nil || s.DisabledAt != nil || s.DisabledReason != nil {
+		return errors.New("atributos 'consecutiveFailures', 'disabledAt' e 'disabledReason' são controlados pela API")
+	}
+	if s.CreatedBy != nil || s.CreatedAt != nil || s.UpdatedAt != nil {
+		return errors.New("atributos 'createdBy', 'createdAt' e 'updatedAt' são controlados pela API")
+	}
+	return nil
+}
+
+func (s *Subscription) validateFields() error {
+	if s.Url != nil {
+		if err := validateUrl(*s.Url); err != nil {
+			return err
+		}
+	}
+
+	if s.Secret != nil && (len(*s.Secret) < MinSecretLength || len(*s.Secret) > MaxSecretLength) {
+		return fmt.Errorf("atributo 'secret' deve ter entre %d e %d caracteres", MinSecretLength, MaxSecretLength)
+	}
+
+	if s.EventTypes != nil {
+		types := []string{}
+		for _, t := range s.EventTypes {
+			if !outbox.IsType(t) {
+				return fmt.Errorf("tipo de evento '%s' desconhecido, tipos aceitos: %s", t, strings.Join(outbox.Types, ", "))
+			}
+			if !slices.Contains(types, t) {
+				types = append(types, t)
+			}
+		}
+		s.EventTypes = types
+	}
+
+	if len(s.WarehouseIds) > MaxFilterItems || len(s.ProductIds) > MaxFilterItems {
+		return fmt.Errorf("atributos 'warehouseIds' e 'productIds' podem ter no máximo %d itens", MaxFilterItems)
+	}
+	s.WarehouseIds = unique(s.WarehouseIds)
+	s.ProductIds = unique(s.ProductIds)
+
+	return nil
+}
+
+func validateUrl(raw string) error {
+	if len(raw) > MaxUrlLength {
+		return fmt.Errorf("atributo 'url' deve ter no máximo %d caracteres", MaxUrlLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("atributo 'url' deve ser uma URL http ou https absoluta")
+	}
+	if u.User != nil {
+		return errors.New("atributo 'url' não pode conter usuário e senha")
+	}
+	// nomes sao verificados de novo na entrega, pelo endereco resolvido
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("atributo 'url' não pode apontar para a rede interna")
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !PublicAddr(addr) {
+		return errors.New("atributo 'url' não pode apontar para a rede interna")
+	}
+	return nil
+}
+
+// sharedAddrs e o espaco compartilhado de CGNAT (RFC 6598)
+var sharedAddrs = netip.MustParsePrefix("100.64.0.0/10")
+
+// PublicAddr indica se as entregas podem ser enviadas ao endereco: recusa
+// loopback, redes privadas, link-local, multicast e enderecos nao especificados
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddrs.Contains(addr)
+}
+
+func unique(ids []uuid.UUID) []uuid.UUID {
+	if ids == nil {
+		return nil
+	}
+	seen := make(map[uuid.UUID]bool, len(ids))
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// NewSecret gera um segredo aleatorio para assinar as entregas
+func NewSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(raw), nil
+}
+
+// Sign calcula o cabecalho X-Webhook-Signature: HMAC-SHA256 com o segredo da
+// assinatura sobre "<timestamp>.<body>", em hexadecimal. O receptor refaz o
+// calculo com o X-Webhook-Timestamp recebido e compara em tempo constante.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
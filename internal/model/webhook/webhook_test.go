@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "8.8.8.8", want: true},
+		{addr: "200.160.2.3", want: true},
+		{addr: "2001:4860:4860::8888", want: true},
+		{addr: "127.0.0.1", want: false},
+		{addr: "::1", want: false},
+		{addr: "10.0.0.1", want: false},
+		{addr: "172.16.5.4", want: false},
+		{addr: "192.168.1.10", want: false},
+		{addr: "fd00::1", want: false},
+		{addr: "169.254.169.254", want: false},
+		{addr: "fe80::1", want: false},
+		{addr: "224.0.0.1", want: false},
+		{addr: "ff02::1", want: false},
+		{addr: "0.0.0.0", want: false},
+		{addr: "::", want: false},
+		{addr: "100.64.0.1", want: false},
+		{addr: "100.127.255.254", want: false},
+		{addr: "100.128.0.1", want: true},
+		{addr: "::ffff:127.0.0.1", want: false},
+		{addr: "::ffff:10.1.2.3", want: false},
+		{addr: "::ffff:8.8.8.8", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := PublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("PublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+
+	if PublicAddr(netip.Addr{}) {
+		t.Error("PublicAddr(endereco zero) = true, want false")
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"stock.adjusted"}`)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		want      string
+	}{
+		{
+			name:      "evento",
+			secret:    "whsec_test",
+			timestamp: 1700000000,
+			body:      body,
+			want:      "sha256=0c9682779b5912a59bb23f81144cd6db484f7691a4d42a90dac6f85627f9004e",
+		},
+		{
+			name:      "outro timestamp muda a assinatura",
+			secret:    "whsec_test",
+			timestamp: 1700000001,
+			body:      body,
+			want:      "sha256=651ee7b8a1d7db3381110a299628289b5d96245845ace201ef638b7b5f4fd1b1",
+		},
+		{
+			name:      "body vazio",
+			secret:    "whsec_test",
+			timestamp: 1700000000,
+			body:      nil,
+			want:      "sha256=5967f3c560522fa40cf2876ebc3c3a08551dd6959aaade3b413460591895bdcc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.timestamp, tt.body); got != tt.want {
+				t.Errorf("Sign() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if Sign("whsec_outro", 1700000000, body) == tests[0].want {
+		t.Error("Sign() com outro segredo gerou a mesma assinatura")
+	}
+}
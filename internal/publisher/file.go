@@ -0,0 +1,51 @@
+package publisher
+
+import (
+	"api-estoque/internal/model/outbox"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// File grava cada evento como uma linha JSON no final de um arquivo, para
+// inspecionar os eventos em desenvolvimento local
+type File struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFile(path string) (*File, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("criar diretorio de eventos: %w", err)
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("abrir arquivo de eventos: %w", err)
+	}
+	return &File{file: file}, nil
+}
+
+func (f *File) Publish(ctx context.Context, event *outbox.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.file.Write(line); err != nil {
+		return fmt.Errorf("gravar evento: %w", err)
+	}
+	return nil
+}
+
+func (f *File) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,60 @@
+package publisher
+
+import (
+	"api-estoque/internal/model/outbox"
+	"context"
+	"sync"
+)
+
+// subscriberBuffer e quantos eventos um assinante pode acumular antes de ser
+// desconectado
+const subscriberBuffer = 256
+
+// Memory distribui os eventos aos assinantes do proprio processo. Serve para
+// desenvolvimento local e para recursos que reagem aos eventos dentro da API,
+// como o stream de estoque. Um assinante lento nao trava a publicacao: quando o
+// buffer dele enche, a assinatura e encerrada e o canal fechado, para que ele
+// retome a partir do outbox em vez de perder eventos sem saber.
+type Memory struct {
+	mu          sync.Mutex
+	subscribers map[chan *outbox.Event]struct{}
+}
+
+func NewMemory() *Memory {
+	return &Memory{subscribers: map[chan *outbox.Event]struct{}{}}
+}
+
+func (m *Memory) Publish(ctx context.Context, event *outbox.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for ch := range m.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(m.subscribers, ch)
+			close(ch)
+		}
+	}
+	return nil
+}
+
+// Subscribe registra um assinante. cancel encerra a assinatura; o canal tambem
+// e fechado se o assinante ficar para tras.
+func (m *Memory) Subscribe() (events <-chan *outbox.Event, cancel func()) {
+	ch := make(chan *outbox.Event, subscriberBuffer)
+
+	m.mu.Lock()
+	m.subscribers[ch] = struct{}{}
+	m.mu.Unlock()
+
+	return ch, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if _, ok := m.subscribers[ch]; ok {
+			delete(m.subscribers, ch)
+			close(ch)
+		}
+	}
+}
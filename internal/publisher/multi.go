@@ -0,0 +1,24 @@
+package publisher
+
+import (
+	"api-estoque/internal/model/outbox"
+	"context"
+)
+
+type multi []Publisher
+
+// Multi entrega cada evento a todos os publishers, em ordem. Se algum falhar o
+// evento volta para o outbox e e reenviado a todos, inclusive aos que ja o
+// receberam.
+func Multi(publishers ...Publisher) Publisher {
+	return multi(publishers)
+}
+
+func (m multi) Publish(ctx context.Context, event *outbox.Event) error {
+	for _, p := range m {
+		if err := p.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
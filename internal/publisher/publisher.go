@@ -0,0 +1,23 @@
+package publisher
+
+import (
+	"api-estoque/internal/config"
+	"api-estoque/internal/model/outbox"
+	"context"
+)
+
+// Publisher entrega os eventos do outbox a quem consome. Publish deve retornar
+// erro se o evento nao foi entregue, para que ele seja reenviado; a entrega e
+// pelo menos uma vez, entao o mesmo evento pode chegar mais de uma vez.
+type Publisher interface {
+	Publish(ctx context.Context, event *outbox.Event) error
+}
+
+// New cria o publisher escolhido em EVENT_PUBLISHER
+func New() (Publisher, error) {
+	env := config.Env
+	if env.EventPublisher == "file" {
+		return NewFile(env.EventFile)
+	}
+	return NewMemory(), nil
+}
@@ -0,0 +1,69 @@
+package allocation
+
+import (
+	"api-estoque/internal/config"
+	"api-estoque/internal/model/allocation"
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	DB *pgxpool.Pool
+}
+
+func New() *Repository {
+	maxConns := 10
+	maxIdleTime := 30 * time.Second
+	maxLifetime := 2 * time.Minute
+
+	return &Repository{
+		DB: config.PostgresConn(maxConns, maxIdleTime, maxLifetime),
+	}
+}
+
+// ListCandidates returns every stock item with available quantity for the given products
+func (r *Repository) ListCandidates(productIds []uuid.UUID) (*[]allocation.Candidate, error) {
+	ctx := context.Background()
+
+	ids := make([]string, 0, len(productIds))
+	for _, id := range productIds {
+		ids = append(ids, id.String())
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT si."ProductId", si."WarehouseId", w."Region", w."Latitude", w."Longitude", w."Priority",
+		       si."Quantity" - si."Reserved"
+		FROM "StockItems" si
+		JOIN "Warehouse" w ON w."Id" = si."WarehouseId"
+		JOIN "Product" p ON p."Id" = si."ProductId"
+		WHERE si."ProductId" = ANY($1::uuid[])
+		  AND p."Status" IN ('active', 'discontinued')
+		  AND si."Quantity" - si."Reserved" > 0
+		  AND w."Status" = 'active'
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []allocation.Candidate
+	for rows.Next() {
+		var c allocation.Candidate
+		if err := rows.Scan(
+			&c.ProductId,
+			&c.WarehouseId,
+			&c.Region,
+			&c.Latitude,
+			&c.Longitude,
+			&c.Priority,
+			&c.Available,
+		); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return &candidates, nil
+}
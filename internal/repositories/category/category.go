@@ -0,0 +1,290 @@
+package category
+
+import (
+	"api-estoque/internal/config"
+	categoryModel "api-estoque/internal/model/category"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrDuplicateName  = errors.New("ja existe categoria com esse nome no mesmo nivel")
+	ErrParentNotFound = errors.New("categoria pai nao encontrada")
+	ErrCycle          = errors.New("categoria pai nao pode ser a propria categoria nem uma de suas subcategorias")
+	ErrHasChildren    = errors.New("categoria possui subcategorias")
+	ErrInUse          = errors.New("categoria possui produtos vinculados")
+)
+
+type Repository struct {
+	DB *pgxpool.Pool
+}
+
+func New() *Repository {
+	maxConns := 10
+	maxIdleTime := 30 * time.Second
+	maxLifetime := 2 * time.Minute
+
+	return &Repository{
+		DB: config.PostgresConn(maxConns, maxIdleTime, maxLifetime),
+	}
+}
+
+// List returns every category ordered by name; the taxonomy is small enough to
+// be returned whole and assembled into a tree by the caller
+func (r *Repository) List() ([]categoryModel.Category, error) {
+	ctx := context.Background()
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT "Id", "Name", "Slug", "ParentId", "CreatedAt"
+		FROM "Category"
+		ORDER BY "Name", "Id"
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []categoryModel.Category
+	for rows.Next() {
+		var c categoryModel.Category
+		if err := rows.Scan(&c.Id, &c.Name, &c.Slug, &c.ParentId, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+func (r *Repository) Create(c *categoryModel.Category) (*uuid.UUID, error) {
+	ctx := context.Background()
+
+	err := r.DB.QueryRow(ctx, `
+		INSERT INTO "Category" ("Name", "ParentId")
+		VALUES ($1, $2)
+		RETURNING "Id", "Slug", "CreatedAt"
+	`, c.Name, c.ParentId).Scan(&c.Id, &c.Slug, &c.CreatedAt)
+	if err != nil {
+		return nil, mapConstraintError(err)
+	}
+	return c.Id, nil
+}
+
+func (r *Repository) GetByID(id *uuid.UUID) (*categoryModel.Category, error) {
+	ctx := context.Background()
+
+	var c categoryModel.Category
+	err := r.DB.QueryRow(ctx, `
+		SELECT "Id", "Name", "Slug", "ParentId", "CreatedAt"
+		FROM "Category"
+		WHERE "Id"=$1
+	`, *id).Scan(&c.Id, &c.Name, &c.Slug, &c.ParentId, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Path returns the ancestors of the category, from the root down to the category itself
+func (r *Repository) Path(id *uuid.UUID) ([]categoryModel.Category, error) {
+	ctx := context.Background()
+
+	rows, err := r.DB.Query(ctx, `
+		WITH RECURSIVE path AS (
+			SELECT "Id", "Name", "Slug", "ParentId", "CreatedAt", 0 AS depth
+			FROM "Category"
+			WHERE "Id"=$1
+			UNION ALL
+			SELECT c."Id", c."Name", c."Slug", c."ParentId", c."CreatedAt", p.depth + 1
+			FROM "Category" c
+			JOIN path p ON c."Id" = p."ParentId"
+		)
+		SELECT "Id", "Name", "Slug", "ParentId", "CreatedAt"
+		FROM path
+		ORDER BY depth DESC
+	`, *id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var path []categoryModel.Category
+	for rows.Next() {
+		var c categoryModel.Category
+		if err := rows.Scan(&c.Id, &c.Name, &c.Slug, &c.ParentId, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		path = append(path, c)
+	}
+	return path, rows.Err()
+}
+
+// treeLockKey is the advisory lock held while a category is moved, so two
+// concurrent moves cannot each pass the cycle check and together form a cycle
+const treeLockKey = 7315_0002
+
+// Update renames and/or moves the category, under another parent or, with
+// MoveToRoot, to the root. Moving under itself or one of its descendants is
+// refused with ErrCycle
+func (r *Repository) Update(c *categoryModel.Category) error {
+	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	setParts := []string{}
+	args := []any{}
+	argPos := 1
+
+	if c.Name != nil {
+		setParts = append(setParts, `"Name"=$`+strconv.Itoa(argPos))
+		args = append(args, *c.Name)
+		argPos++
+	}
+
+	if c.ParentId != nil {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, treeLockKey); err != nil {
+			return fmt.Errorf("category tree lock: %w", err)
+		}
+
+		var cycle bool
+		err = tx.QueryRow(ctx, `
+			WITH RECURSIVE subtree AS (
+				SELECT "Id" FROM "Category" WHERE "Id"=$1
+				UNION ALL
+				SELECT c."Id" FROM "Category" c JOIN subtree s ON c."ParentId" = s."Id"
+			)
+			SELECT EXISTS (SELECT 1 FROM subtree WHERE "Id"=$2)
+		`, *c.Id, *c.ParentId).Scan(&cycle)
+		if err != nil {
+			return fmt.Errorf("check category cycle: %w", err)
+		}
+		if cycle {
+			return ErrCycle
+		}
+
+		setParts = append(setParts, `"ParentId"=$`+strconv.Itoa(argPos))
+		args = append(args, *c.ParentId)
+		argPos++
+	} else if c.MoveToRoot {
+		setParts = append(setParts, `"ParentId"=NULL`)
+	}
+
+	if len(setParts) == 0 {
+		return nil
+	}
+
+	args = append(args, *c.Id)
+	err = tx.QueryRow(ctx, `
+		UPDATE "Category"
+		SET `+strings.Join(setParts, ", ")+`
+		WHERE "Id"=$`+strconv.Itoa(argPos)+`
+		RETURNING "Slug"
+	`, args...).Scan(&c.Slug)
+	if err != nil {
+		return mapConstraintError(err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Delete removes a leaf category with no products
+func (r *Repository) Delete(id *uuid.UUID) error {
+	ctx := context.Background()
+
+	var hasChildren, inUse bool
+	err := r.DB.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM "Category" WHERE "ParentId"=$1),
+		       EXISTS (SELECT 1 FROM "Product" WHERE "CategoryId"=$1)
+	`, *id).Scan(&hasChildren, &inUse)
+	if err != nil {
+		return fmt.Errorf("check category usage: %w", err)
+	}
+	if hasChildren {
+		return ErrHasChildren
+	}
+	if inUse {
+		return ErrInUse
+	}
+
+	tag, err := r.DB.Exec(ctx, `DELETE FROM "Category" WHERE "Id"=$1`, *id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		// produto ou subcategoria vinculado depois da verificacao acima
+		return ErrInUse
+	}
+	if err != nil {
+		return fmt.Errorf("delete category: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// StockReport returns, for every category, the stock of the products linked
+// directly to it, optionally restricted to one warehouse. Roll-up along the tree
+// is done by the caller
+func (r *Repository) StockReport(warehouseId *uuid.UUID) ([]categoryModel.ReportLine, error) {
+	ctx := context.Background()
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT c."Id", c."Name", c."ParentId",
+		       count(DISTINCT p."Id"),
+		       coalesce(sum(si."Quantity"), 0)::bigint,
+		       coalesce(sum(si."Reserved"), 0)::bigint,
+		       coalesce(sum(si."Quantity" * p."Price"), 0)::bigint
+		FROM "Category" c
+		LEFT JOIN "Product" p ON p."CategoryId" = c."Id"
+		LEFT JOIN "StockItems" si ON si."ProductId" = p."Id"
+		     AND ($1::uuid IS NULL OR si."WarehouseId" = $1)
+		GROUP BY c."Id", c."Name", c."ParentId"
+		ORDER BY c."Name", c."Id"
+	`, warehouseId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []categoryModel.ReportLine
+	for rows.Next() {
+		var l categoryModel.ReportLine
+		if err := rows.Scan(
+			&l.CategoryId,
+			&l.Name,
+			&l.ParentId,
+			&l.Own.Products,
+			&l.Own.Quantity,
+			&l.Own.Reserved,
+			&l.Own.StockValue,
+		); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+func mapConstraintError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return ErrDuplicateName
+		case "23503":
+			return ErrParentNotFound
+		}
+	}
+	return err
+}
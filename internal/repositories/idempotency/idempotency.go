@@ -0,0 +1,143 @@
+package idempotency
+
+import (
+	"api-estoque/internal/config"
+	"api-estoque/internal/model/idempotency"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	DB *pgxpool.Pool
+}
+
+func New() *Repository {
+	maxConns := 10
+	maxIdleTime := 30 * time.Second
+	maxLifetime := 2 * time.Minute
+
+	return &Repository{
+		DB: config.PostgresConn(maxConns, maxIdleTime, maxLifetime),
+	}
+}
+
+// Claim reserves the key for a new request, held until lease runs out unless
+// Extend renews it. It returns false when a live record already exists for the
+// key; expired records, and claims whose request stopped renewing the lease
+// without completing, are taken over.
+func (r *Repository) Claim(scope string, key string, requestHash string, ttl time.Duration, lease time.Duration) (bool, error) {
+	ctx := context.Background()
+
+	var claimed bool
+	err := r.DB.QueryRow(ctx, `
+		INSERT INTO "IdempotencyKeys" ("Scope", "Key", "RequestHash", "ExpiresAt", "LockedUntil")
+		VALUES ($1, $2, $3, now() + make_interval(secs => $4), now() + make_interval(secs => $5))
+		ON CONFLICT ("Scope", "Key") DO UPDATE
+		SET "RequestHash" = EXCLUDED."RequestHash",
+		    "StatusCode" = NULL,
+		    "ContentType" = NULL,
+		    "ETag" = NULL,
+		    "ResponseBody" = NULL,
+		    "CreatedAt" = now(),
+		    "ExpiresAt" = EXCLUDED."ExpiresAt",
+		    "LockedUntil" = EXCLUDED."LockedUntil"
+		WHERE "IdempotencyKeys"."ExpiresAt" < now()
+		   OR ("IdempotencyKeys"."StatusCode" IS NULL
+		       AND coalesce("IdempotencyKeys"."LockedUntil", '-infinity') < now())
+		RETURNING true
+	`, scope, key, requestHash, ttl.Seconds(), lease.Seconds()).Scan(&claimed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claim idempotency key: %w", err)
+	}
+	return claimed, nil
+}
+
+func (r *Repository) Get(scope string, key string) (*idempotency.Record, error) {
+	ctx := context.Background()
+
+	var rec idempotency.Record
+	err := r.DB.QueryRow(ctx, `
+		SELECT "Scope", "Key", "RequestHash", "StatusCode", "ContentType", "ETag", "ResponseBody", "ExpiresAt"
+		FROM "IdempotencyKeys"
+		WHERE "Scope"=$1 AND "Key"=$2
+	`, scope, key).Scan(
+		&rec.Scope,
+		&rec.Key,
+		&rec.RequestHash,
+		&rec.StatusCode,
+		&rec.ContentType,
+		&rec.ETag,
+		&rec.ResponseBody,
+		&rec.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Extend renews the lease of a key still being processed
+func (r *Repository) Extend(scope string, key string, lease time.Duration) error {
+	ctx := context.Background()
+
+	_, err := r.DB.Exec(ctx, `
+		UPDATE "IdempotencyKeys"
+		SET "LockedUntil" = now() + make_interval(secs => $3)
+		WHERE "Scope"=$1 AND "Key"=$2 AND "StatusCode" IS NULL
+	`, scope, key, lease.Seconds())
+	if err != nil {
+		return fmt.Errorf("extend idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Complete stores the response given to the request that claimed the key. An
+// empty etag is stored as NULL
+func (r *Repository) Complete(scope string, key string, statusCode int, contentType string, etag string, body []byte) error {
+	ctx := context.Background()
+
+	_, err := r.DB.Exec(ctx, `
+		UPDATE "IdempotencyKeys"
+		SET "StatusCode"=$3, "ContentType"=$4, "ETag"=nullif($5, ''), "ResponseBody"=$6, "LockedUntil"=NULL
+		WHERE "Scope"=$1 AND "Key"=$2
+	`, scope, key, statusCode, contentType, etag, body)
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release removes a claimed key so the request can be retried
+func (r *Repository) Release(scope string, key string) error {
+	ctx := context.Background()
+
+	_, err := r.DB.Exec(ctx, `
+		DELETE FROM "IdempotencyKeys"
+		WHERE "Scope"=$1 AND "Key"=$2
+	`, scope, key)
+	if err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) DeleteExpired() (int64, error) {
+	ctx := context.Background()
+
+	tag, err := r.DB.Exec(ctx, `
+		DELETE FROM "IdempotencyKeys"
+		WHERE "ExpiresAt" < now()
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
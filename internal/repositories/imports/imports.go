@@ -0,0 +1,68 @@
+package imports
+
+import (
+	"api-estoque/internal/config"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	DB *pgxpool.Pool
+}
+
+func New() *Repository {
+	maxConns := 4
+	maxIdleTime := 30 * time.Second
+	maxLifetime := 2 * time.Minute
+
+	return &Repository{
+		DB: config.PostgresConn(maxConns, maxIdleTime, maxLifetime),
+	}
+}
+
+// Run calls insert for each of the rows inside a single transaction. Every row
+// runs in its own savepoint, so a failing row is reported without hiding the
+// errors of the rows after it. The transaction is committed only when no row
+// failed and dryRun is false; otherwise everything is rolled back. rowErrs maps
+// the row index to its error; err is returned for failures of the transaction itself
+func (r *Repository) Run(rows []int, dryRun bool, insert func(ctx context.Context, tx pgx.Tx, row int) error) (rowErrs map[int]error, committed bool, err error) {
+	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rowErrs = map[int]error{}
+	for _, row := range rows {
+		if _, err := tx.Exec(ctx, `SAVEPOINT import_row`); err != nil {
+			return nil, false, fmt.Errorf("savepoint: %w", err)
+		}
+
+		if rowErr := insert(ctx, tx, row); rowErr != nil {
+			rowErrs[row] = rowErr
+			if _, err := tx.Exec(ctx, `ROLLBACK TO SAVEPOINT import_row`); err != nil {
+				return nil, false, fmt.Errorf("rollback to savepoint: %w", err)
+			}
+			continue
+		}
+
+		if _, err := tx.Exec(ctx, `RELEASE SAVEPOINT import_row`); err != nil {
+			return nil, false, fmt.Errorf("release savepoint: %w", err)
+		}
+	}
+
+	if dryRun || len(rowErrs) > 0 {
+		return rowErrs, false, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, fmt.Errorf("commit: %w", err)
+	}
+	return rowErrs, true, nil
+}
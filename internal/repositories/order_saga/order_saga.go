@@ -0,0 +1,289 @@
+package ordersaga
+
+import (
+	"api-estoque/internal/config"
+	"api-estoque/internal/model/order"
+	stockitems "api-estoque/internal/model/stock_items"
+	stockitemsRepository "api-estoque/internal/repositories/stock_items"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Falhas de negocio, respondidas ao servico de pedidos como eventos de falha
+var (
+	ErrOrderReleased  = errors.New("pedido ja cancelado, reserva liberada")
+	ErrNoReservation  = errors.New("pedido sem reserva de estoque")
+	ErrOrderCommitted = errors.New("pedido ja baixado, estoque nao pode ser liberado")
+)
+
+type Repository struct {
+	DB *pgxpool.Pool
+}
+
+func New() *Repository {
+	maxConns := 4
+	maxIdleTime := 30 * time.Second
+	maxLifetime := 2 * time.Minute
+
+	return &Repository{
+		DB: config.PostgresConn(maxConns, maxIdleTime, maxLifetime),
+	}
+}
+
+// PendingReply e uma resposta gravada no inbox que ainda nao foi publicada
+type PendingReply struct {
+	MessageId string
+	Reply     order.Reply
+}
+
+// Process aplica o evento do pedido e o grava no inbox junto com a resposta,
+// numa unica transacao. Falhas de negocio, e refused quando nao e nil, viram
+// respostas de falha em vez de erros; as alteracoes de estoque de um evento que
+// falhou sao desfeitas. Uma mensagem que ja esta no inbox nao e aplicada de
+// novo: a resposta gravada e devolvida com duplicate. Os eventos do mesmo
+// pedido sao serializados por um advisory lock no id do pedido
+func (r *Repository) Process(e *order.Event, refused error) (reply *order.Reply, duplicate bool, err error) {
+	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("begin process: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Reserva a mensagem no inbox antes de tudo: uma entrega concorrente da
+	// mesma mensagem espera aqui e depois encontra a resposta gravada
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO "OrderInbox" ("MessageId", "Type", "OrderId", "Reply")
+		VALUES ($1, $2, $3, '{}'::jsonb)
+		ON CONFLICT ("MessageId") DO NOTHING
+	`, e.Id, e.Type, e.OrderId)
+	if err != nil {
+		return nil, false, fmt.Errorf("insert inbox: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		reply, err := storedReply(ctx, tx, e.Id)
+		return reply, true, err
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('order:' || $1))`, e.OrderId); err != nil {
+		return nil, false, fmt.Errorf("order lock: %w", err)
+	}
+
+	failure, err := apply(ctx, tx, e, refused)
+	if err != nil {
+		return nil, false, err
+	}
+
+	reason := ""
+	if failure != nil {
+		reason = failure.Error()
+	}
+	reply = order.NewReply(e, reason)
+	raw, err := json.Marshal(reply)
+	if err != nil {
+		return nil, false, fmt.Errorf("marshal reply: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE "OrderInbox" SET "Reply"=$2 WHERE "MessageId"=$1`, e.Id, raw); err != nil {
+		return nil, false, fmt.Errorf("store reply: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, err
+	}
+	return reply, false, nil
+}
+
+// apply executa a transicao da reserva do pedido para o evento. failure e um
+// resultado de negocio; err e um erro de infraestrutura
+func apply(ctx context.Context, tx pgx.Tx, e *order.Event, refused error) (failure error, err error) {
+	var status string
+	var lines []stockitems.StockItemsBaixa
+	err = tx.QueryRow(ctx, `
+		SELECT "Status", "Lines" FROM "OrderReservation" WHERE "OrderId"=$1
+		FOR UPDATE
+	`, e.OrderId).Scan(&status, &lines)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("select reservation: %w", err)
+	}
+
+	switch e.Type {
+	case order.OrderPlaced:
+		switch status {
+		case order.StatusReserved, order.StatusCommitted:
+			return nil, nil
+		case order.StatusReleased:
+			return ErrOrderReleased, nil
+		}
+		if refused != nil {
+			return refused, nil
+		}
+		failure, err = inSavepoint(ctx, tx, func(sp pgx.Tx) error {
+			return stockitemsRepository.ReserveQuantitiesTx(ctx, sp, e.Lines)
+		}, stockitemsRepository.ErrInsufficientStock)
+		if failure != nil || err != nil {
+			return failure, err
+		}
+		raw, err := json.Marshal(e.Lines)
+		if err != nil {
+			return nil, fmt.Errorf("marshal lines: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO "OrderReservation" ("OrderId", "Status", "Lines")
+			VALUES ($1, $2, $3)
+		`, e.OrderId, order.StatusReserved, raw)
+		if err != nil {
+			return nil, fmt.Errorf("insert reservation: %w", err)
+		}
+		return nil, nil
+
+	case order.OrderPaid:
+		switch status {
+		case "":
+			return ErrNoReservation, nil
+		case order.StatusReleased:
+			return ErrOrderReleased, nil
+		case order.StatusCommitted:
+			return nil, nil
+		}
+		failure, err = inSavepoint(ctx, tx, func(sp pgx.Tx) error {
+			return stockitemsRepository.CommitReservedTx(ctx, sp, lines, "Baixa do pedido "+e.OrderId, nil)
+		}, stockitemsRepository.ErrInsufficientReserved)
+		if failure != nil || err != nil {
+			return failure, err
+		}
+		return nil, setStatus(ctx, tx, e.OrderId, order.StatusCommitted)
+
+	default:
+		switch status {
+		case "":
+			// Cancelamento antes do pedido: grava a reserva como liberada para
+			// recusar o OrderPlaced que chegar depois
+			_, err = tx.Exec(ctx, `
+				INSERT INTO "OrderReservation" ("OrderId", "Status")
+				VALUES ($1, $2)
+			`, e.OrderId, order.StatusReleased)
+			if err != nil {
+				return nil, fmt.Errorf("insert released reservation: %w", err)
+			}
+			return nil, nil
+		case order.StatusReleased:
+			return nil, nil
+		case order.StatusCommitted:
+			return ErrOrderCommitted, nil
+		}
+		failure, err = inSavepoint(ctx, tx, func(sp pgx.Tx) error {
+			return stockitemsRepository.ReleaseQuantitiesTx(ctx, sp, lines)
+		}, stockitemsRepository.ErrInsufficientReserved)
+		if failure != nil || err != nil {
+			return failure, err
+		}
+		return nil, setStatus(ctx, tx, e.OrderId, order.StatusReleased)
+	}
+}
+
+// inSavepoint executa fn num savepoint. Quando fn falha com um dos erros de
+// negocio o savepoint e desfeito e o erro e devolvido como failure
+func inSavepoint(ctx context.Context, tx pgx.Tx, fn func(pgx.Tx) error, business ...error) (failure error, err error) {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("savepoint: %w", err)
+	}
+	defer sp.Rollback(ctx)
+
+	if err := fn(sp); err != nil {
+		for _, b := range business {
+			if errors.Is(err, b) {
+				return err, nil
+			}
+		}
+		return nil, err
+	}
+	return nil, sp.Commit(ctx)
+}
+
+func setStatus(ctx context.Context, tx pgx.Tx, orderId string, status string) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE "OrderReservation" SET "Status"=$2, "UpdatedAt"=now()
+		WHERE "OrderId"=$1
+	`, orderId, status)
+	if err != nil {
+		return fmt.Errorf("update reservation status: %w", err)
+	}
+	return nil
+}
+
+func storedReply(ctx context.Context, tx pgx.Tx, messageId string) (*order.Reply, error) {
+	var reply order.Reply
+	err := tx.QueryRow(ctx, `SELECT "Reply" FROM "OrderInbox" WHERE "MessageId"=$1`, messageId).Scan(&reply)
+	if err != nil {
+		return nil, fmt.Errorf("select stored reply: %w", err)
+	}
+	return &reply, nil
+}
+
+// MarkReplied registra que a resposta da mensagem foi publicada
+func (r *Repository) MarkReplied(messageId string) error {
+	ctx := context.Background()
+
+	_, err := r.DB.Exec(ctx, `
+		UPDATE "OrderInbox" SET "RepliedAt"=now()
+		WHERE "MessageId"=$1 AND "RepliedAt" IS NULL
+	`, messageId)
+	if err != nil {
+		return fmt.Errorf("mark replied: %w", err)
+	}
+	return nil
+}
+
+// PendingReplies retorna ate limit respostas ainda nao publicadas, recebidas
+// ha mais de grace, da mais antiga a mais nova. grace da tempo para o consumer
+// que processou a mensagem publicar a resposta ele mesmo
+func (r *Repository) PendingReplies(grace time.Duration, limit int) ([]PendingReply, error) {
+	ctx := context.Background()
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT "MessageId", "Reply"
+		FROM "OrderInbox"
+		WHERE "RepliedAt" IS NULL
+		  AND "ReceivedAt" < now() - make_interval(secs => $1)
+		ORDER BY "ReceivedAt"
+		LIMIT $2
+	`, grace.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("select pending replies: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []PendingReply
+	for rows.Next() {
+		var p PendingReply
+		if err := rows.Scan(&p.MessageId, &p.Reply); err != nil {
+			return nil, err
+		}
+		pending = append(pending, p)
+	}
+	return pending, rows.Err()
+}
+
+// DeleteProcessed remove as entradas do inbox respondidas antes da janela de
+// retencao. Uma mensagem reentregue depois disso e processada de novo, e o
+// status da reserva do pedido impede que ela altere o estoque duas vezes
+func (r *Repository) DeleteProcessed(retention time.Duration) (int64, error) {
+	ctx := context.Background()
+
+	tag, err := r.DB.Exec(ctx, `
+		DELETE FROM "OrderInbox"
+		WHERE "RepliedAt" < now() - make_interval(secs => $1)
+	`, retention.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("delete processed messages: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
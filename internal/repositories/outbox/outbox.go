@@ -0,0 +1,324 @@
+package outbox
+
+import (
+	"api-estoque/internal/config"
+	"api-estoque/internal/model/outbox"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// dispatchLockKey is the advisory lock that keeps a single dispatcher running
+// across instances, so events are published in order
+const dispatchLockKey = 7315_0001
+
+// stockSQL snapshots the stock item ($2, $3) into an event of type $1, merging
+// the extra fields in $4
+const stockSQL = `
+	INSERT INTO "Outbox" ("Type", "ProductId", "WarehouseId", "Payload")
+	SELECT $1, s."ProductId", s."WarehouseId",
+	       jsonb_build_object(
+	           'product_id', s."ProductId",
+	           'warehouse_id', s."WarehouseId",
+	           'quantity', s."Quantity",
+	           'reserved', s."Reserved",
+	           'available', s."Quantity" - s."Reserved",
+	           'updated_at', s."UpdatedAt"
+	       ) || coalesce($4::jsonb, '{}'::jsonb)
+	FROM "StockItems" s
+	WHERE s."ProductId"=$2 AND s."WarehouseId"=$3
+`
+
+// movesSQL snapshots the stock moves in $1 into stock.moved events
+const movesSQL = `
+	INSERT INTO "Outbox" ("Type", "ProductId", "WarehouseId", "Payload")
+	SELECT '` + outbox.StockMoved + `', m."ProductId", m."WarehouseId",
+	       jsonb_strip_nulls(jsonb_build_object(
+	           'id', m."Id",
+	           'product_id', m."ProductId",
+	           'warehouse_id', m."WarehouseId",
+	           'qty_moved', m."QtyMoved",
+	           'reason', m."Reason",
+	           'reversal_of', m."ReversalOf",
+	           'created_by', m."CreatedBy",
+	           'created_at', m."CreatedAt"
+	       ))
+	FROM "StockMoves" m
+	WHERE m."Id" = ANY($1::uuid[])
+	ORDER BY m."CreatedAt", m."Id"
+`
+
+// productSQL snapshots the product $2 into an event of type $1. $3 is the
+// default currency of "Price"
+const productSQL = `
+	INSERT INTO "Outbox" ("Type", "ProductId", "Payload")
+	SELECT $1, p."Id",
+	       jsonb_build_object(
+	           'id', p."Id",
+	           'sku', p."Sku",
+	           'name', p."Name",
+	           'description', p."Description",
+	           'status', p."Status",
+	           'isActive', p."IsActive",
+	           'price', p."Price",
+	           'currency', $3::text,
+	           'prices', coalesce((
+	               SELECT jsonb_agg(jsonb_build_object('currency', pp."Currency", 'amount', pp."Amount") ORDER BY pp."Currency")
+	               FROM "ProductPrice" pp
+	               WHERE pp."ProductId" = p."Id"
+	           ), '[]'::jsonb),
+	           'categoryId', p."CategoryId",
+	           'lengthMm', p."LengthMm",
+	           'widthMm', p."WidthMm",
+	           'heightMm', p."HeightMm",
+	           'weightGrams', p."WeightGrams",
+	           'version', p."Version"
+	       )
+	FROM "Product" p
+	WHERE p."Id"=$2
+`
+
+type Repository struct {
+	DB *pgxpool.Pool
+}
+
+func New() *Repository {
+	maxConns := 4
+	maxIdleTime := 30 * time.Second
+	maxLifetime := 2 * time.Minute
+
+	return &Repository{
+		DB: config.PostgresConn(maxConns, maxIdleTime, maxLifetime),
+	}
+}
+
+// AppendStock records an event with the current balance of the stock item.
+// extra is merged into the payload, e.g. the applied delta; it may be nil.
+// Call it after the change, or before a delete, inside the same transaction
+func AppendStock(ctx context.Context, tx pgx.Tx, eventType string, productId uuid.UUID, warehouseId uuid.UUID, extra map[string]any) error {
+	raw, err := marshalExtra(extra)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, stockSQL, eventType, productId, warehouseId, raw); err != nil {
+		return fmt.Errorf("append stock event: %w", err)
+	}
+	return nil
+}
+
+// AppendMoves records a stock.moved event for each of the given ledger entries
+func AppendMoves(ctx context.Context, tx pgx.Tx, moveIds ...uuid.UUID) error {
+	if _, err := tx.Exec(ctx, movesSQL, uuidStrings(moveIds)); err != nil {
+		return fmt.Errorf("append move events: %w", err)
+	}
+	return nil
+}
+
+// AppendProduct records an event with the current state of the product. For
+// deletes, call it before the product row is removed
+func AppendProduct(ctx context.Context, tx pgx.Tx, eventType string, productId uuid.UUID) error {
+	if _, err := tx.Exec(ctx, productSQL, eventType, productId, config.Env.DefaultCurrency); err != nil {
+		return fmt.Errorf("append product event: %w", err)
+	}
+	return nil
+}
+
+// QueueProduct is AppendProduct for writes sent as a pgx.Batch
+func QueueProduct(batch *pgx.Batch, eventType string, productId uuid.UUID) {
+	batch.Queue(productSQL, eventType, productId, config.Env.DefaultCurrency)
+}
+
+// Dispatch publishes up to limit pending events in outbox order. Only one
+// instance dispatches at a time; the others return right away. When publish
+// fails, the event is retried with exponential backoff and the later events of
+// the same product wait for it, so each product's events keep their order.
+// Each publish attempt takes the next publish sequence, so the sequences seen
+// by the publishers only grow, with gaps where an attempt failed. Events are
+// marked as published only after publish returns, so a crash in between
+// publishes them again (at-least-once)
+func (r *Repository) Dispatch(limit int, publish func(*outbox.Event) error) (published int, failed int, err error) {
+	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin dispatch: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, dispatchLockKey).Scan(&locked); err != nil {
+		return 0, 0, fmt.Errorf("dispatch lock: %w", err)
+	}
+	if !locked {
+		return 0, 0, nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT o."Id", o."EventId", o."Type", o."ProductId", o."WarehouseId", o."Payload", o."OccurredAt"
+		FROM "Outbox" o
+		WHERE o."PublishedAt" IS NULL
+		  AND o."TxId" < pg_snapshot_xmin(pg_current_snapshot())
+		  AND NOT EXISTS (
+		      SELECT 1 FROM "Outbox" b
+		      WHERE b."ProductId" = o."ProductId"
+		        AND b."PublishedAt" IS NULL
+		        AND b."Id" <= o."Id"
+		        AND b."NextAttemptAt" > now()
+		  )
+		ORDER BY o."Id"
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return 0, 0, fmt.Errorf("select pending events: %w", err)
+	}
+	var ids []int64
+	var events []outbox.Event
+	for rows.Next() {
+		var id int64
+		var e outbox.Event
+		if err := rows.Scan(&id, &e.Id, &e.Type, &e.ProductId, &e.WarehouseId, &e.Payload, &e.OccurredAt); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		ids = append(ids, id)
+		events = append(events, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	var done, seqs []int64
+	blocked := map[uuid.UUID]bool{}
+	for i := range events {
+		e := &events[i]
+		if blocked[e.ProductId] {
+			continue
+		}
+
+		// a sequencia nao volta atras num rollback, entao nunca e repetida
+		if err := tx.QueryRow(ctx, `SELECT nextval('"Outbox_PublishSeq_seq"')`).Scan(&e.Sequence); err != nil {
+			return 0, 0, fmt.Errorf("next publish sequence: %w", err)
+		}
+
+		if pubErr := publish(e); pubErr != nil {
+			blocked[e.ProductId] = true
+			failed++
+			_, err := tx.Exec(ctx, `
+				UPDATE "Outbox"
+				SET "Attempts" = "Attempts" + 1,
+				    "LastError" = $2,
+				    "NextAttemptAt" = now() + least(interval '1 second' * power(2, "Attempts"), interval '5 minutes')
+				WHERE "Id"=$1
+			`, ids[i], pubErr.Error())
+			if err != nil {
+				return 0, 0, fmt.Errorf("record publish failure: %w", err)
+			}
+			continue
+		}
+		done = append(done, ids[i])
+		seqs = append(seqs, e.Sequence)
+	}
+
+	if len(done) > 0 {
+		_, err = tx.Exec(ctx, `
+			UPDATE "Outbox" o SET "PublishedAt" = now(), "LastError" = NULL, "PublishSeq" = d.seq
+			FROM unnest($1::bigint[], $2::bigint[]) AS d(id, seq)
+			WHERE o."Id" = d.id
+		`, done, seqs)
+		if err != nil {
+			return 0, 0, fmt.Errorf("mark published: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, err
+	}
+	return len(done), failed, nil
+}
+
+// DeletePublished removes events published before the retention window
+func (r *Repository) DeletePublished(retention time.Duration) (int64, error) {
+	ctx := context.Background()
+
+	tag, err := r.DB.Exec(ctx, `
+		DELETE FROM "Outbox"
+		WHERE "PublishedAt" < now() - make_interval(secs => $1)
+	`, retention.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("delete published events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Published returns up to limit published events after the given publish
+// sequence, in sequence order, of the given types and, when the lists are not
+// empty, of the given warehouses and products
+func (r *Repository) Published(after int64, limit int, types []string, warehouseIds []uuid.UUID, productIds []uuid.UUID) ([]outbox.Event, error) {
+	ctx := context.Background()
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT "PublishSeq", "EventId", "Type", "ProductId", "WarehouseId", "Payload", "OccurredAt"
+		FROM "Outbox"
+		WHERE "PublishSeq" > $1
+		  AND "Type" = ANY($2)
+		  AND (cardinality($3::uuid[]) = 0 OR "WarehouseId" = ANY($3::uuid[]))
+		  AND (cardinality($4::uuid[]) = 0 OR "ProductId" = ANY($4::uuid[]))
+		ORDER BY "PublishSeq"
+		LIMIT $5
+	`, after, types, uuidStrings(warehouseIds), uuidStrings(productIds), limit)
+	if err != nil {
+		return nil, fmt.Errorf("select published events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []outbox.Event
+	for rows.Next() {
+		var e outbox.Event
+		if err := rows.Scan(&e.Sequence, &e.Id, &e.Type, &e.ProductId, &e.WarehouseId, &e.Payload, &e.OccurredAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// OldestSequence returns the smallest publish sequence still kept in the
+// outbox or, when no published event is left, the next one to be assigned.
+// Events before it were removed by DeletePublished
+func (r *Repository) OldestSequence() (int64, error) {
+	ctx := context.Background()
+
+	var oldest int64
+	err := r.DB.QueryRow(ctx, `
+		SELECT coalesce(
+		    (SELECT min("PublishSeq") FROM "Outbox"),
+		    (SELECT CASE WHEN is_called THEN last_value + 1 ELSE last_value END FROM "Outbox_PublishSeq_seq")
+		)
+	`).Scan(&oldest)
+	return oldest, err
+}
+
+func marshalExtra(extra map[string]any) ([]byte, error) {
+	if len(extra) == 0 {
+		return nil, nil
+	}
+	raw, err := json.Marshal(extra)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event payload: %w", err)
+	}
+	return raw, nil
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, id.String())
+	}
+	return out
+}
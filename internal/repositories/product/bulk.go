@@ -0,0 +1,185 @@
+package product
+
+import (
+	"api-estoque/internal/config"
+	"api-estoque/internal/model/outbox"
+	productModel "api-estoque/internal/model/product"
+	outboxRepository "api-estoque/internal/repositories/outbox"
+	"context"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// BulkPlan decide, dados os produtos travados por Sku e as categorias que
+// existem, quais itens inserir e quais alterar
+type BulkPlan func(current map[string]*productModel.Product, categories map[uuid.UUID]bool) (creates []*productModel.Product, updates []*productModel.Product, err error)
+
+// BulkUpsert trava os produtos com Sku em skus e as categorias em categoryIds,
+// deixa plan escolher as gravacoes e envia todas num unico pgx.Batch dentro da
+// mesma transacao. Os produtos criados recebem o Id. As alteracoes substituem
+// todas as colunas; os precos seguem as mesmas regras do Update
+func (r *Repository) BulkUpsert(skus []string, categoryIds []uuid.UUID, plan BulkPlan) error {
+	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockBySku(ctx, tx, skus)
+	if err != nil {
+		return err
+	}
+
+	categories := make(map[uuid.UUID]bool, len(categoryIds))
+	rows, err := tx.Query(ctx, `
+		SELECT "Id" FROM "Category"
+		WHERE "Id" = ANY($1::uuid[])
+		FOR SHARE
+	`, uuidStrings(categoryIds))
+	if err != nil {
+		return fmt.Errorf("lock categories: %w", err)
+	}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		categories[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	creates, updates, err := plan(current, categories)
+	if err != nil {
+		return err
+	}
+	if len(creates) == 0 && len(updates) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, p := range creates {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return err
+		}
+		p.Id = &id
+		batch.Queue(`
+			INSERT INTO "Product" (
+				"Id", "Sku", "Name", "Description", "Price", "CategoryId", "ImagesJson", "IsActive", "Status",
+				"LengthMm", "WidthMm", "HeightMm", "WeightGrams"
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		`, p.Id, p.Sku, p.Name, p.Description, p.Price, p.CategoryId, p.Images, p.IsActive, p.Status,
+			p.LengthMm, p.WidthMm, p.HeightMm, p.WeightGrams)
+		queuePrices(batch, p)
+		outboxRepository.QueueProduct(batch, outbox.ProductCreated, *p.Id)
+	}
+	for _, p := range updates {
+		batch.Queue(`
+			UPDATE "Product"
+			SET "Name"=$2, "Description"=$3, "Price"=$4, "CategoryId"=$5, "ImagesJson"=$6, "IsActive"=$7, "Status"=$8,
+			    "LengthMm"=$9, "WidthMm"=$10, "HeightMm"=$11, "WeightGrams"=$12
+			WHERE "Id"=$1
+		`, p.Id, p.Name, p.Description, p.Price, p.CategoryId, p.Images, p.IsActive, p.Status,
+			p.LengthMm, p.WidthMm, p.HeightMm, p.WeightGrams)
+		queuePrices(batch, p)
+		outboxRepository.QueueProduct(batch, outbox.ProductUpdated, *p.Id)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			if isForeignKeyViolation(err) {
+				return ErrCategoryNotFound
+			}
+			if isSkuViolation(err) {
+				return ErrSkuTaken
+			}
+			return fmt.Errorf("bulk upsert: %w", err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("bulk upsert: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// lockBySku seleciona FOR UPDATE os produtos com os skus informados, em ordem
+// de Id para que lotes concorrentes os travem na mesma ordem
+func lockBySku(ctx context.Context, tx pgx.Tx, skus []string) (map[string]*productModel.Product, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT p."Id", p."Sku", p."Name", p."Description", p."Price", `+pricesColumn+`, p."CategoryId", p."ImagesJson", p."IsActive", p."Status",
+		       p."LengthMm", p."WidthMm", p."HeightMm", p."WeightGrams"
+		FROM "Product" p
+		WHERE p."Sku" = ANY($1::text[])
+		ORDER BY p."Id"
+		FOR UPDATE
+	`, skus)
+	if err != nil {
+		return nil, fmt.Errorf("lock products: %w", err)
+	}
+	defer rows.Close()
+
+	current := make(map[string]*productModel.Product, len(skus))
+	for rows.Next() {
+		var p productModel.Product
+		if err := rows.Scan(
+			&p.Id,
+			&p.Sku,
+			&p.Name,
+			&p.Description,
+			&p.Price,
+			&p.Prices,
+			&p.CategoryId,
+			&p.Images,
+			&p.IsActive,
+			&p.Status,
+			&p.LengthMm,
+			&p.WidthMm,
+			&p.HeightMm,
+			&p.WeightGrams,
+		); err != nil {
+			return nil, err
+		}
+		current[*p.Sku] = &p
+	}
+	return current, rows.Err()
+}
+
+// queuePrices enfileira as gravacoes de preco de p: a lista inteira quando
+// Prices e informado, senao so a moeda padrao, gravando as alteracoes no
+// historico
+func queuePrices(batch *pgx.Batch, p *productModel.Product) {
+	prices := []productModel.Price{{Currency: config.Env.DefaultCurrency, Amount: *p.Price}}
+	if p.Prices != nil {
+		prices = *p.Prices
+		currencies := make([]string, len(prices))
+		for i, price := range prices {
+			currencies[i] = price.Currency
+		}
+		batch.Queue(deletePricesSQL, *p.Id, currencies)
+	}
+
+	for _, price := range prices {
+		batch.Queue(upsertPriceSQL, *p.Id, price.Currency, price.Amount)
+		batch.Queue(recordPriceSQL, *p.Id, price.Currency, price.Amount, p.ChangedBy, nil)
+	}
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, id.String())
+	}
+	return out
+}
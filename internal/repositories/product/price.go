@@ -0,0 +1,205 @@
+package product
+
+import (
+	"api-estoque/internal/config"
+	"api-estoque/internal/model/outbox"
+	"api-estoque/internal/model/pagination"
+	productModel "api-estoque/internal/model/product"
+	outboxRepository "api-estoque/internal/repositories/outbox"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrProductNotFound = errors.New("produto nao encontrado")
+	ErrScheduleClosed  = errors.New("agendamento ja aplicado ou cancelado")
+)
+
+// PriceHistory retorna uma pagina do historico de precos do produto numa moeda,
+// do mais novo ao mais antigo. Com at, retorna so o preco que valia naquele
+// momento
+func (r *Repository) PriceHistory(productId *uuid.UUID, currency string, at *time.Time, page *pagination.Page) (*[]productModel.PriceChange, error) {
+	ctx := context.Background()
+
+	afterEffectiveAt, afterId, err := page.ByCreatedAt()
+	if err != nil {
+		return nil, err
+	}
+
+	limit := page.Fetch()
+	if at != nil {
+		limit = 1
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT "Id", "ProductId", "Currency", "Price", "EffectiveAt", "ChangedBy", "ScheduleId"
+		FROM "ProductPriceHistory"
+		WHERE "ProductId"=$1 AND "Currency"=$2
+		  AND ($3::timestamptz IS NULL OR "EffectiveAt" <= $3)
+		  AND ($4::timestamptz IS NULL OR ("EffectiveAt", "Id") < ($4, $5))
+		ORDER BY "EffectiveAt" DESC, "Id" DESC
+		LIMIT $6
+	`, *productId, currency, at, afterEffectiveAt, afterId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []productModel.PriceChange
+	for rows.Next() {
+		var c productModel.PriceChange
+		if err := rows.Scan(&c.Id, &c.ProductId, &c.Currency, &c.Price, &c.EffectiveAt, &c.ChangedBy, &c.ScheduleId); err != nil {
+			return nil, err
+		}
+		history = append(history, c)
+	}
+	return &history, rows.Err()
+}
+
+func (r *Repository) CreatePriceSchedule(s *productModel.PriceSchedule) (*uuid.UUID, error) {
+	ctx := context.Background()
+
+	err := r.DB.QueryRow(ctx, `
+		INSERT INTO "ProductPriceSchedule" ("ProductId", "Price", "EffectiveAt", "CreatedBy")
+		VALUES ($1, $2, $3, $4)
+		RETURNING "Id", "CreatedAt"
+	`, *s.ProductId, *s.Price, *s.EffectiveAt, s.CreatedBy).Scan(&s.Id, &s.CreatedAt)
+	if isForeignKeyViolation(err) {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.Id, nil
+}
+
+// ListPriceSchedules retorna os agendamentos do produto ordenados pela data de
+// vigencia. Com pendingOnly, os aplicados e cancelados ficam de fora
+func (r *Repository) ListPriceSchedules(productId *uuid.UUID, pendingOnly bool) (*[]productModel.PriceSchedule, error) {
+	ctx := context.Background()
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT "Id", "ProductId", "Price", "EffectiveAt", "CreatedBy", "CreatedAt", "AppliedAt", "CanceledAt"
+		FROM "ProductPriceSchedule"
+		WHERE "ProductId"=$1
+		  AND (NOT $2 OR ("AppliedAt" IS NULL AND "CanceledAt" IS NULL))
+		ORDER BY "EffectiveAt", "CreatedAt"
+	`, *productId, pendingOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []productModel.PriceSchedule
+	for rows.Next() {
+		var s productModel.PriceSchedule
+		if err := rows.Scan(&s.Id, &s.ProductId, &s.Price, &s.EffectiveAt, &s.CreatedBy, &s.CreatedAt, &s.AppliedAt, &s.CanceledAt); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	return &schedules, rows.Err()
+}
+
+// CancelPriceSchedule cancela um agendamento pendente. Retorna pgx.ErrNoRows
+// quando o agendamento nao existe para o produto e ErrScheduleClosed quando ja
+// foi aplicado ou cancelado
+func (r *Repository) CancelPriceSchedule(productId *uuid.UUID, scheduleId *uuid.UUID) error {
+	ctx := context.Background()
+
+	tag, err := r.DB.Exec(ctx, `
+		UPDATE "ProductPriceSchedule"
+		SET "CanceledAt" = now()
+		WHERE "Id"=$1 AND "ProductId"=$2 AND "AppliedAt" IS NULL AND "CanceledAt" IS NULL
+	`, *scheduleId, *productId)
+	if err != nil {
+		return fmt.Errorf("cancel price schedule: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = r.DB.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM "ProductPriceSchedule" WHERE "Id"=$1 AND "ProductId"=$2)
+	`, *scheduleId, *productId).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("check price schedule: %w", err)
+	}
+	if exists {
+		return ErrScheduleClosed
+	}
+	return pgx.ErrNoRows
+}
+
+// ApplyDuePriceSchedules aplica ate limit agendamentos com a data de vigencia
+// ja passada, do mais antigo ao mais novo, alterando o preco do produto na
+// moeda padrao e o historico na mesma transacao. Linhas travadas por outra
+// instancia sao puladas, entao varios workers podem rodar ao mesmo tempo.
+// Retorna quantos agendamentos foram aplicados
+func (r *Repository) ApplyDuePriceSchedules(limit int) (int, error) {
+	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT "Id", "ProductId", "Price", "CreatedBy"
+		FROM "ProductPriceSchedule"
+		WHERE "AppliedAt" IS NULL AND "CanceledAt" IS NULL AND "EffectiveAt" <= now()
+		ORDER BY "EffectiveAt", "CreatedAt"
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("select due price schedules: %w", err)
+	}
+
+	var due []productModel.PriceSchedule
+	for rows.Next() {
+		var s productModel.PriceSchedule
+		if err := rows.Scan(&s.Id, &s.ProductId, &s.Price, &s.CreatedBy); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, s := range due {
+		_, err := tx.Exec(ctx, `UPDATE "Product" SET "Price"=$1 WHERE "Id"=$2`, *s.Price, *s.ProductId)
+		if err != nil {
+			return 0, fmt.Errorf("apply price schedule %s: %w", s.Id, err)
+		}
+
+		price := productModel.Price{Currency: config.Env.DefaultCurrency, Amount: *s.Price}
+		if err := upsertPrice(ctx, tx, s.ProductId, price, s.CreatedBy, s.Id); err != nil {
+			return 0, err
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE "ProductPriceSchedule" SET "AppliedAt" = now() WHERE "Id"=$1`, *s.Id)
+		if err != nil {
+			return 0, fmt.Errorf("mark price schedule %s: %w", s.Id, err)
+		}
+
+		if err := outboxRepository.AppendProduct(ctx, tx, outbox.ProductUpdated, *s.ProductId); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(due), nil
+}
@@ -2,17 +2,65 @@ package product
 
 import (
 	"api-estoque/internal/config"
+	"api-estoque/internal/model/outbox"
+	"api-estoque/internal/model/pagination"
 	productModel "api-estoque/internal/model/product"
+	outboxRepository "api-estoque/internal/repositories/outbox"
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	ErrVersionMismatch  = errors.New("produto alterado desde a leitura")
+	ErrCategoryNotFound = errors.New("categoria nao encontrada")
+	ErrSkuTaken         = errors.New("sku ja cadastrado em outro produto")
+	ErrStatusChanged    = errors.New("status do produto alterado durante a atualizacao")
+)
+
+// pricesColumn agrega a lista de precos por moeda do produto de alias p
+const pricesColumn = `coalesce((
+			SELECT jsonb_agg(jsonb_build_object('currency', pp."Currency", 'amount', pp."Amount") ORDER BY pp."Currency")
+			FROM "ProductPrice" pp
+			WHERE pp."ProductId" = p."Id"
+		), '[]'::jsonb)`
+
+// deletePricesSQL remove os precos de $1 em moedas diferentes de $2
+const deletePricesSQL = `
+		DELETE FROM "ProductPrice"
+		WHERE "ProductId"=$1 AND NOT ("Currency" = ANY($2::text[]))
+	`
+
+// upsertPriceSQL define o preco de $1 na moeda $2 como $3
+const upsertPriceSQL = `
+		INSERT INTO "ProductPrice" ("ProductId", "Currency", "Amount")
+		VALUES ($1, $2, $3)
+		ON CONFLICT ("ProductId", "Currency") DO UPDATE
+		SET "Amount" = EXCLUDED."Amount", "UpdatedAt" = now()
+		WHERE "ProductPrice"."Amount" <> EXCLUDED."Amount"
+	`
+
+// recordPriceSQL acrescenta o preco ao historico, a menos que seja igual ao
+// ultimo preco registrado na moeda
+const recordPriceSQL = `
+		INSERT INTO "ProductPriceHistory" ("ProductId", "Currency", "Price", "ChangedBy", "ScheduleId")
+		SELECT $1::uuid, $2::text, $3::bigint, $4::text, $5::uuid
+		WHERE $3::bigint IS DISTINCT FROM (
+			SELECT "Price" FROM "ProductPriceHistory"
+			WHERE "ProductId"=$1 AND "Currency"=$2
+			ORDER BY "EffectiveAt" DESC, "Id" DESC
+			LIMIT 1
+		)
+	`
+
 type Repository struct {
 	DB *pgxpool.Pool
 }
@@ -27,14 +75,25 @@ func New() *Repository {
 	}
 }
 
-func (r *Repository) List() (*[]productModel.Product, error) {
+// List retorna uma pagina de produtos ordenada por CreatedAt desc, Id desc,
+// lendo uma linha alem do limite para o chamador saber se ha proxima pagina
+func (r *Repository) List(page *pagination.Page) (*[]productModel.Product, error) {
 	ctx := context.Background()
 
+	afterCreatedAt, afterId, err := page.ByCreatedAt()
+	if err != nil {
+		return nil, err
+	}
+
 	rows, err := r.DB.Query(ctx, `
-		SELECT "Id", "CreatedAt", "Name", "Description", "Price", "Category", "ImagesJson", "IsActive"
-		FROM "Product"
-		ORDER BY "CreatedAt" DESC
-	`)
+		SELECT p."Id", p."Sku", p."CreatedAt", p."Name", p."Description", p."Price", `+pricesColumn+`, p."CategoryId", c."Name", p."ImagesJson", p."IsActive", p."Status",
+		       p."LengthMm", p."WidthMm", p."HeightMm", p."WeightGrams"
+		FROM "Product" p
+		JOIN "Category" c ON c."Id" = p."CategoryId"
+		WHERE $1::timestamptz IS NULL OR (p."CreatedAt", p."Id") < ($1, $2)
+		ORDER BY p."CreatedAt" DESC, p."Id" DESC
+		LIMIT $3
+	`, afterCreatedAt, afterId, page.Fetch())
 	if err != nil {
 		return nil, err
 	}
@@ -45,13 +104,21 @@ func (r *Repository) List() (*[]productModel.Product, error) {
 		var p productModel.Product
 		if err := rows.Scan(
 			&p.Id,
+			&p.Sku,
 			&p.CreatedAt,
 			&p.Name,
 			&p.Description,
 			&p.Price,
+			&p.Prices,
+			&p.CategoryId,
 			&p.Category,
-			&p.ImagesJson,
+			&p.Images,
 			&p.IsActive,
+			&p.Status,
+			&p.LengthMm,
+			&p.WidthMm,
+			&p.HeightMm,
+			&p.WeightGrams,
 		); err != nil {
 			return nil, err
 		}
@@ -60,47 +127,217 @@ func (r *Repository) List() (*[]productModel.Product, error) {
 	return &products, nil
 }
 
+// Export passa o catalogo inteiro a fn na mesma ordem CreatedAt desc, Id desc
+// do List. As linhas sao lidas a medida que fn as consome; um erro de fn
+// interrompe a consulta
+func (r *Repository) Export(fn func(*productModel.Product) error) error {
+	ctx := context.Background()
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT p."Id", p."Sku", p."CreatedAt", p."Name", p."Description", p."Price", `+pricesColumn+`, p."CategoryId", c."Name", p."IsActive", p."Status",
+		       p."LengthMm", p."WidthMm", p."HeightMm", p."WeightGrams"
+		FROM "Product" p
+		JOIN "Category" c ON c."Id" = p."CategoryId"
+		ORDER BY p."CreatedAt" DESC, p."Id" DESC
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p productModel.Product
+		if err := rows.Scan(
+			&p.Id,
+			&p.Sku,
+			&p.CreatedAt,
+			&p.Name,
+			&p.Description,
+			&p.Price,
+			&p.Prices,
+			&p.CategoryId,
+			&p.Category,
+			&p.IsActive,
+			&p.Status,
+			&p.LengthMm,
+			&p.WidthMm,
+			&p.HeightMm,
+			&p.WeightGrams,
+		); err != nil {
+			return err
+		}
+		if err := fn(&p); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Search faz a busca textual em nome, categoria e descricao, ordenada por rank
+// desc, Id desc. A busca ignora acentos e reduz as palavras ao radical em
+// portugues. Le uma linha alem do limite para o chamador saber se ha proxima
+// pagina
+func (r *Repository) Search(q *productModel.SearchQuery, page *pagination.Page) (*[]productModel.SearchResult, error) {
+	ctx := context.Background()
+
+	var afterRank *float32
+	var afterId *uuid.UUID
+	if page.After != nil {
+		if page.After.Rank == nil || page.After.Id == nil {
+			return nil, pagination.ErrInvalidCursor
+		}
+		afterRank, afterId = page.After.Rank, page.After.Id
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		WITH ranked AS (
+			SELECT p.*, c."Name" AS "CategoryName", ts_rank_cd(p."SearchVector", tsq) AS "Rank"
+			FROM "Product" p
+			JOIN "Category" c ON c."Id" = p."CategoryId",
+			     to_tsquery('portuguese', immutable_unaccent($1)) tsq
+			WHERE p."SearchVector" @@ tsq
+			  AND (NOT $2 OR p."Status" = 'active')
+			  AND (NOT $3 OR EXISTS (
+			      SELECT 1 FROM "StockItems" si
+			      WHERE si."ProductId" = p."Id" AND si."Quantity" - si."Reserved" > 0
+			  ))
+		)
+		SELECT "Id", "Sku", "CreatedAt", "Name", "Description", "Price", `+pricesColumn+`, "CategoryId", "CategoryName", "ImagesJson", "IsActive", "Status",
+		       "LengthMm", "WidthMm", "HeightMm", "WeightGrams", "Rank"
+		FROM ranked p
+		WHERE $4::real IS NULL OR ("Rank", "Id") < ($4, $5)
+		ORDER BY "Rank" DESC, "Id" DESC
+		LIMIT $6
+	`, q.TSQuery(), q.ActiveOnly, q.InStock, afterRank, afterId, page.Fetch())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []productModel.SearchResult
+	for rows.Next() {
+		var p productModel.SearchResult
+		if err := rows.Scan(
+			&p.Id,
+			&p.Sku,
+			&p.CreatedAt,
+			&p.Name,
+			&p.Description,
+			&p.Price,
+			&p.Prices,
+			&p.CategoryId,
+			&p.Category,
+			&p.Images,
+			&p.IsActive,
+			&p.Status,
+			&p.LengthMm,
+			&p.WidthMm,
+			&p.HeightMm,
+			&p.WeightGrams,
+			&p.Rank,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, p)
+	}
+	return &results, rows.Err()
+}
+
+// Create grava o produto com a lista de precos e abre o historico de precos
 func (r *Repository) Create(p *productModel.Product) (*uuid.UUID, error) {
 	ctx := context.Background()
 
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := CreateTx(ctx, tx, p); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return p.Id, nil
+}
+
+// CreateTx e o Create dentro da transacao do chamador, para que a importacao
+// grave varios produtos e confirme todos juntos
+func CreateTx(ctx context.Context, tx pgx.Tx, p *productModel.Product) error {
 	query := `
-		INSERT INTO "Product" ("Name", "Description", "Price", "Category", "ImagesJson", "IsActive")
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO "Product" (
+			"Name", "Description", "Price", "CategoryId", "ImagesJson", "IsActive", "Status",
+			"LengthMm", "WidthMm", "HeightMm", "WeightGrams", "Sku"
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING "Id"
 	`
-	err := r.DB.QueryRow(ctx, query,
+	err := tx.QueryRow(ctx, query,
 		p.Name,
 		p.Description,
 		p.Price,
-		p.Category,
-		p.ImagesJson,
+		p.CategoryId,
+		p.Images,
 		p.IsActive,
+		p.Status,
+		p.LengthMm,
+		p.WidthMm,
+		p.HeightMm,
+		p.WeightGrams,
+		p.Sku,
 	).Scan(&p.Id)
 
+	if isForeignKeyViolation(err) {
+		return ErrCategoryNotFound
+	}
+	if isSkuViolation(err) {
+		return ErrSkuTaken
+	}
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return p.Id, nil
+
+	prices := []productModel.Price{{Currency: config.Env.DefaultCurrency, Amount: *p.Price}}
+	if p.Prices != nil {
+		prices = *p.Prices
+	}
+	if err := replacePrices(ctx, tx, p.Id, prices, p.ChangedBy); err != nil {
+		return err
+	}
+	return outboxRepository.AppendProduct(ctx, tx, outbox.ProductCreated, *p.Id)
 }
 
 func (r *Repository) GetByID(id *uuid.UUID) (*productModel.Product, error) {
 	ctx := context.Background()
 	query := `
-		SELECT "Id", "CreatedAt", "Name", "Description", "Price", "Category", "ImagesJson", "IsActive"
-		FROM "Product"
-		WHERE "Id"=$1
+		SELECT p."Id", p."Sku", p."CreatedAt", p."Name", p."Description", p."Price", ` + pricesColumn + `, p."CategoryId", c."Name", p."ImagesJson", p."IsActive", p."Status",
+		       p."LengthMm", p."WidthMm", p."HeightMm", p."WeightGrams", p."Version"
+		FROM "Product" p
+		JOIN "Category" c ON c."Id" = p."CategoryId"
+		WHERE p."Id"=$1
 	`
 
 	var p productModel.Product
 	err := r.DB.QueryRow(ctx, query, *id).Scan(
 		&p.Id,
+		&p.Sku,
 		&p.CreatedAt,
 		&p.Name,
 		&p.Description,
 		&p.Price,
+		&p.Prices,
+		&p.CategoryId,
 		&p.Category,
-		&p.ImagesJson,
+		&p.Images,
 		&p.IsActive,
+		&p.Status,
+		&p.LengthMm,
+		&p.WidthMm,
+		&p.HeightMm,
+		&p.WeightGrams,
+		&p.Version,
 	)
 	if err != nil {
 		return nil, err
@@ -108,7 +345,60 @@ func (r *Repository) GetByID(id *uuid.UUID) (*productModel.Product, error) {
 	return &p, nil
 }
 
-func (r *Repository) Update(p *productModel.Product) error {
+// GetByIDs busca os produtos informados numa unica consulta. Ids que nao
+// existem ficam fora do resultado
+func (r *Repository) GetByIDs(ids []uuid.UUID) (*[]productModel.Product, error) {
+	ctx := context.Background()
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT p."Id", p."Sku", p."CreatedAt", p."Name", p."Description", p."Price", `+pricesColumn+`, p."CategoryId", c."Name", p."ImagesJson", p."IsActive", p."Status",
+		       p."LengthMm", p."WidthMm", p."HeightMm", p."WeightGrams", p."Version"
+		FROM "Product" p
+		JOIN "Category" c ON c."Id" = p."CategoryId"
+		WHERE p."Id" = ANY($1::uuid[])
+	`, uuidStrings(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []productModel.Product
+	for rows.Next() {
+		var p productModel.Product
+		if err := rows.Scan(
+			&p.Id,
+			&p.Sku,
+			&p.CreatedAt,
+			&p.Name,
+			&p.Description,
+			&p.Price,
+			&p.Prices,
+			&p.CategoryId,
+			&p.Category,
+			&p.Images,
+			&p.IsActive,
+			&p.Status,
+			&p.LengthMm,
+			&p.WidthMm,
+			&p.HeightMm,
+			&p.WeightGrams,
+			&p.Version,
+		); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return &products, rows.Err()
+}
+
+// Update aplica os campos informados. Com expectedVersion a linha so e
+// alterada se a versao ainda for a mesma; senao retorna ErrVersionMismatch.
+// Prices substitui a lista de precos inteira; price sozinho altera a moeda
+// padrao. Todo preco alterado entra no historico. Com fromStatus a linha so e
+// alterada se o status ainda for o validado na transicao; senao retorna
+// ErrStatusChanged. Quando images e informado, retorna as imagens que o produto
+// tinha antes
+func (r *Repository) Update(p *productModel.Product, expectedVersion *int64, fromStatus *string) ([]productModel.Image, error) {
 	ctx := context.Background()
 
 	setParts := []string{}
@@ -127,21 +417,27 @@ func (r *Repository) Update(p *productModel.Product) error {
 		argPos++
 	}
 
+	if p.Sku != nil {
+		setParts = append(setParts, `"Sku"=$`+strconv.Itoa(argPos))
+		args = append(args, *p.Sku)
+		argPos++
+	}
+
 	if p.Price != nil {
 		setParts = append(setParts, `"Price"=$`+strconv.Itoa(argPos))
 		args = append(args, *p.Price)
 		argPos++
 	}
 
-	if p.Category != nil {
-		setParts = append(setParts, `"Category"=$`+strconv.Itoa(argPos))
-		args = append(args, *p.Category)
+	if p.CategoryId != nil {
+		setParts = append(setParts, `"CategoryId"=$`+strconv.Itoa(argPos))
+		args = append(args, *p.CategoryId)
 		argPos++
 	}
 
-	if p.ImagesJson != nil {
+	if p.Images != nil {
 		setParts = append(setParts, `"ImagesJson"=$`+strconv.Itoa(argPos))
-		args = append(args, *p.ImagesJson)
+		args = append(args, *p.Images)
 		argPos++
 	}
 
@@ -151,8 +447,38 @@ func (r *Repository) Update(p *productModel.Product) error {
 		argPos++
 	}
 
+	if p.Status != nil {
+		setParts = append(setParts, `"Status"=$`+strconv.Itoa(argPos))
+		args = append(args, *p.Status)
+		argPos++
+	}
+
+	if p.LengthMm != nil {
+		setParts = append(setParts, `"LengthMm"=$`+strconv.Itoa(argPos))
+		args = append(args, *p.LengthMm)
+		argPos++
+	}
+
+	if p.WidthMm != nil {
+		setParts = append(setParts, `"WidthMm"=$`+strconv.Itoa(argPos))
+		args = append(args, *p.WidthMm)
+		argPos++
+	}
+
+	if p.HeightMm != nil {
+		setParts = append(setParts, `"HeightMm"=$`+strconv.Itoa(argPos))
+		args = append(args, *p.HeightMm)
+		argPos++
+	}
+
+	if p.WeightGrams != nil {
+		setParts = append(setParts, `"WeightGrams"=$`+strconv.Itoa(argPos))
+		args = append(args, *p.WeightGrams)
+		argPos++
+	}
+
 	if len(setParts) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	query := `
@@ -160,19 +486,209 @@ func (r *Repository) Update(p *productModel.Product) error {
 		SET ` + strings.Join(setParts, ", ") + `
 		WHERE "Id"=$` + strconv.Itoa(argPos)
 	args = append(args, p.Id)
+	if expectedVersion != nil {
+		query += ` AND "Version"=$` + strconv.Itoa(argPos+1)
+		args = append(args, *expectedVersion)
+		argPos++
+	}
+	if fromStatus != nil {
+		query += ` AND "Status"=$` + strconv.Itoa(argPos+1)
+		args = append(args, *fromStatus)
+	}
+	query += ` RETURNING "Version"`
 
-	_, err := r.DB.Exec(ctx, query, args...)
+	tx, err := r.DB.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("update product: %w", err)
+		return nil, fmt.Errorf("begin tx: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	return nil
+	var previousImages []productModel.Image
+	if p.Images != nil {
+		err = tx.QueryRow(ctx, `
+			SELECT coalesce("ImagesJson", '[]'::jsonb)
+			FROM "Product"
+			WHERE "Id"=$1
+			FOR UPDATE
+		`, p.Id).Scan(&previousImages)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.QueryRow(ctx, query, args...).Scan(&p.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if current, getErr := r.GetByID(p.Id); getErr == nil {
+			if expectedVersion != nil && *current.Version != *expectedVersion {
+				return nil, ErrVersionMismatch
+			}
+			if fromStatus != nil {
+				return nil, ErrStatusChanged
+			}
+		}
+		return nil, err
+	}
+	if isForeignKeyViolation(err) {
+		return nil, ErrCategoryNotFound
+	}
+	if isSkuViolation(err) {
+		return nil, ErrSkuTaken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("update product: %w", err)
+	}
+
+	switch {
+	case p.Prices != nil:
+		err = replacePrices(ctx, tx, p.Id, *p.Prices, p.ChangedBy)
+	case p.Price != nil:
+		err = upsertPrice(ctx, tx, p.Id, productModel.Price{Currency: config.Env.DefaultCurrency, Amount: *p.Price}, p.ChangedBy, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := outboxRepository.AppendProduct(ctx, tx, outbox.ProductUpdated, *p.Id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return previousImages, nil
 }
 
+// UpdateImages trava a linha do produto e substitui as imagens pelo resultado
+// de change, para que uploads concorrentes nao se sobrescrevam. Retorna
+// pgx.ErrNoRows quando o produto nao existe
+func (r *Repository) UpdateImages(id *uuid.UUID, change func([]productModel.Image) ([]productModel.Image, error)) ([]productModel.Image, error) {
+	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var images []productModel.Image
+	err = tx.QueryRow(ctx, `
+		SELECT coalesce("ImagesJson", '[]'::jsonb)
+		FROM "Product"
+		WHERE "Id"=$1
+		FOR UPDATE
+	`, *id).Scan(&images)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err = change(images)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE "Product"
+		SET "ImagesJson"=$1
+		WHERE "Id"=$2
+	`, images, *id)
+	if err != nil {
+		return nil, fmt.Errorf("update product images: %w", err)
+	}
+
+	if err := outboxRepository.AppendProduct(ctx, tx, outbox.ProductUpdated, *id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// MissingPrices conta os produtos sem preco na moeda currency. Todo produto
+// tem preco na moeda padrao, a menos que os precos tenham sido migrados em
+// outra moeda
+func (r *Repository) MissingPrices(currency string) (int64, error) {
+	ctx := context.Background()
+
+	var count int64
+	err := r.DB.QueryRow(ctx, `
+		SELECT count(*)
+		FROM "Product" p
+		WHERE NOT EXISTS (
+			SELECT 1 FROM "ProductPrice" pp
+			WHERE pp."ProductId" = p."Id" AND pp."Currency" = $1
+		)
+	`, currency).Scan(&count)
+	return count, err
+}
+
+// HasStock indica se algum galpao ainda tem quantidade ou reserva do produto
+func (r *Repository) HasStock(id *uuid.UUID) (bool, error) {
+	ctx := context.Background()
+
+	var exists bool
+	err := r.DB.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM "StockItems"
+			WHERE "ProductId"=$1 AND ("Quantity" > 0 OR "Reserved" > 0)
+		)
+	`, *id).Scan(&exists)
+	return exists, err
+}
+
+// HasHistory indica se o produto ja teve estoque ou movimentacao
+func (r *Repository) HasHistory(id *uuid.UUID) (bool, error) {
+	ctx := context.Background()
+
+	var exists bool
+	err := r.DB.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM "StockMoves" WHERE "ProductId"=$1)
+		    OR EXISTS (SELECT 1 FROM "StockItems" WHERE "ProductId"=$1)
+	`, *id).Scan(&exists)
+	return exists, err
+}
+
+func (r *Repository) Archive(id *uuid.UUID) error {
+	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE "Product"
+		SET "Status" = 'archived', "IsActive" = false
+		WHERE "Id"=$1
+	`, *id)
+
+	if err != nil {
+		return fmt.Errorf("archive product: %w", err)
+	}
+
+	if err := outboxRepository.AppendProduct(ctx, tx, outbox.ProductUpdated, *id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Delete remove o produto, gravando o ultimo estado num evento
 func (r *Repository) Delete(id *uuid.UUID) error {
 	ctx := context.Background()
 
-	_, err := r.DB.Exec(ctx, `
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := outboxRepository.AppendProduct(ctx, tx, outbox.ProductDeleted, *id); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
 		DELETE FROM "Product"
 		WHERE "Id"=$1
 	`, *id)
@@ -180,5 +696,51 @@ func (r *Repository) Delete(id *uuid.UUID) error {
 	if err != nil {
 		return fmt.Errorf("delete product: %w", err)
 	}
+	return tx.Commit(ctx)
+}
+
+// replacePrices deixa a lista de precos do produto igual a prices, gravando os
+// alterados no historico
+func replacePrices(ctx context.Context, tx pgx.Tx, productId *uuid.UUID, prices []productModel.Price, changedBy *string) error {
+	currencies := make([]string, len(prices))
+	for i, price := range prices {
+		currencies[i] = price.Currency
+	}
+
+	_, err := tx.Exec(ctx, deletePricesSQL, *productId, currencies)
+	if err != nil {
+		return fmt.Errorf("delete product prices: %w", err)
+	}
+
+	for _, price := range prices {
+		if err := upsertPrice(ctx, tx, productId, price, changedBy, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsertPrice define o preco do produto numa moeda e, quando ele muda, o
+// acrescenta ao historico valendo a partir de agora
+func upsertPrice(ctx context.Context, tx pgx.Tx, productId *uuid.UUID, price productModel.Price, changedBy *string, scheduleId *uuid.UUID) error {
+	_, err := tx.Exec(ctx, upsertPriceSQL, *productId, price.Currency, price.Amount)
+	if err != nil {
+		return fmt.Errorf("upsert product price: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, recordPriceSQL, *productId, price.Currency, price.Amount, changedBy, scheduleId)
+	if err != nil {
+		return fmt.Errorf("record price history: %w", err)
+	}
 	return nil
 }
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+func isSkuViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "Product_Sku_key"
+}
@@ -1,24 +1,48 @@
 package repositories
 
 import (
+	"api-estoque/internal/repositories/allocation"
+	"api-estoque/internal/repositories/category"
+	"api-estoque/internal/repositories/idempotency"
+	"api-estoque/internal/repositories/imports"
+	ordersaga "api-estoque/internal/repositories/order_saga"
+	"api-estoque/internal/repositories/outbox"
 	"api-estoque/internal/repositories/product"
 	stockitems "api-estoque/internal/repositories/stock_items"
 	stockmoves "api-estoque/internal/repositories/stock_moves"
+	"api-estoque/internal/repositories/tcc"
 	"api-estoque/internal/repositories/warehouse"
+	"api-estoque/internal/repositories/webhook"
 )
 
 type Repositories struct {
-	StockItemsRepository *stockitems.Repository
-	StockMovesRepository *stockmoves.Repository
-	WarehouseRepository  *warehouse.Repository
-	ProductRepository    *product.Repository
+	StockItemsRepository  *stockitems.Repository
+	StockMovesRepository  *stockmoves.Repository
+	WarehouseRepository   *warehouse.Repository
+	ProductRepository     *product.Repository
+	AllocationRepository  *allocation.Repository
+	IdempotencyRepository *idempotency.Repository
+	CategoryRepository    *category.Repository
+	ImportsRepository     *imports.Repository
+	OutboxRepository      *outbox.Repository
+	WebhookRepository     *webhook.Repository
+	OrderSagaRepository   *ordersaga.Repository
+	TccRepository         *tcc.Repository
 }
 
 func InstanciateRepositories() *Repositories {
 	return &Repositories{
-		StockItemsRepository: stockitems.New(),
-		StockMovesRepository: stockmoves.New(),
-		WarehouseRepository:  warehouse.New(),
-		ProductRepository:    product.New(),
+		StockItemsRepository:  stockitems.New(),
+		StockMovesRepository:  stockmoves.New(),
+		WarehouseRepository:   warehouse.New(),
+		ProductRepository:     product.New(),
+		AllocationRepository:  allocation.New(),
+		IdempotencyRepository: idempotency.New(),
+		CategoryRepository:    category.New(),
+		ImportsRepository:     imports.New(),
+		OutboxRepository:      outbox.New(),
+		WebhookRepository:     webhook.New(),
+		OrderSagaRepository:   ordersaga.New(),
+		TccRepository:         tcc.New(),
 	}
 }
@@ -2,17 +2,32 @@ package stockitems
 
 import (
 	"api-estoque/internal/config"
+	"api-estoque/internal/model/outbox"
+	"api-estoque/internal/model/pagination"
 	stockitems "api-estoque/internal/model/stock_items"
+	stockmoves "api-estoque/internal/model/stock_moves"
+	outboxRepository "api-estoque/internal/repositories/outbox"
+	stockmovesRepository "api-estoque/internal/repositories/stock_moves"
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	ErrInsufficientStock    = errors.New("saldo disponivel insuficiente")
+	ErrInsufficientReserved = errors.New("quantidade reservada menor que a liberada")
+	ErrVersionMismatch      = errors.New("item de estoque alterado desde a leitura")
+	ErrAlreadyExists        = errors.New("item de estoque ja existe para esse produto e galpao")
+)
+
 type Repository struct {
 	DB *pgxpool.Pool
 }
@@ -27,14 +42,24 @@ func New() *Repository {
 	}
 }
 
-func (r *Repository) List() (*[]stockitems.StockItems, error) {
+// List retorna uma pagina de itens de estoque ordenada por ("ProductId",
+// "WarehouseId"), que ao contrario de UpdatedAt nao muda durante a leitura. Le
+// uma linha alem do limite para o chamador saber se ha proxima pagina
+func (r *Repository) List(page *pagination.Page) (*[]stockitems.StockItems, error) {
 	ctx := context.Background()
 
+	afterProductId, afterWarehouseId, err := page.ByStockItem()
+	if err != nil {
+		return nil, err
+	}
+
 	rows, err := r.DB.Query(ctx, `
 		SELECT "ProductId", "WarehouseId", "Quantity", "Reserved", "UpdatedAt"
 		FROM "StockItems"
-		ORDER BY "UpdatedAt" DESC
-	`)
+		WHERE $1::uuid IS NULL OR ("ProductId", "WarehouseId") > ($1, $2)
+		ORDER BY "ProductId", "WarehouseId"
+		LIMIT $3
+	`, afterProductId, afterWarehouseId, page.Fetch())
 	if err != nil {
 		return nil, err
 	}
@@ -57,30 +82,141 @@ func (r *Repository) List() (*[]stockitems.StockItems, error) {
 	return &items, nil
 }
 
+// ListByProducts retorna os itens de estoque dos produtos informados,
+// ordenados por ("ProductId", "WarehouseId")
+func (r *Repository) ListByProducts(productIds []uuid.UUID) (*[]stockitems.StockItems, error) {
+	return r.listBy(`"ProductId"`, productIds)
+}
+
+// ListByWarehouses retorna os itens de estoque dos galpoes informados,
+// ordenados por ("ProductId", "WarehouseId")
+func (r *Repository) ListByWarehouses(warehouseIds []uuid.UUID) (*[]stockitems.StockItems, error) {
+	return r.listBy(`"WarehouseId"`, warehouseIds)
+}
+
+func (r *Repository) listBy(column string, ids []uuid.UUID) (*[]stockitems.StockItems, error) {
+	ctx := context.Background()
+
+	idStrings := make([]string, 0, len(ids))
+	for _, id := range ids {
+		idStrings = append(idStrings, id.String())
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT "ProductId", "WarehouseId", "Quantity", "Reserved", "UpdatedAt", "Version"
+		FROM "StockItems"
+		WHERE `+column+` = ANY($1::uuid[])
+		ORDER BY "ProductId", "WarehouseId"
+	`, idStrings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []stockitems.StockItems
+	for rows.Next() {
+		var s stockitems.StockItems
+		if err := rows.Scan(
+			&s.ProductId,
+			&s.WarehouseId,
+			&s.Quantity,
+			&s.Reserved,
+			&s.UpdatedAt,
+			&s.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	return &items, rows.Err()
+}
+
+// Export passa cada item de estoque a fn, com os nomes do produto e do galpao,
+// na mesma ordem ("ProductId", "WarehouseId") do List. As linhas sao lidas a
+// medida que fn as consome; um erro de fn interrompe a consulta
+func (r *Repository) Export(fn func(*stockitems.ExportRow) error) error {
+	ctx := context.Background()
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT s."ProductId", p."Name", s."WarehouseId", w."Name", s."Quantity", s."Reserved", s."UpdatedAt"
+		FROM "StockItems" s
+		JOIN "Product" p ON p."Id" = s."ProductId"
+		JOIN "Warehouse" w ON w."Id" = s."WarehouseId"
+		ORDER BY s."ProductId", s."WarehouseId"
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s stockitems.ExportRow
+		if err := rows.Scan(
+			&s.ProductId,
+			&s.ProductName,
+			&s.WarehouseId,
+			&s.WarehouseName,
+			&s.Quantity,
+			&s.Reserved,
+			&s.UpdatedAt,
+		); err != nil {
+			return err
+		}
+		if err := fn(&s); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (r *Repository) Create(s *stockitems.StockItems) (*stockitems.StockItems, error) {
 	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin create: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := CreateTx(ctx, tx, s); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// CreateTx grava o item de estoque e o evento dele na transacao do chamador,
+// para que a importacao grave varios saldos e confirme todos juntos
+func CreateTx(ctx context.Context, tx pgx.Tx, s *stockitems.StockItems) error {
 	query := `
 		INSERT INTO "StockItems" ("ProductId", "WarehouseId", "Quantity", "Reserved")
 		VALUES ($1, $2, $3, $4)
 		RETURNING "UpdatedAt"
 	`
-	err := r.DB.QueryRow(ctx, query,
+	err := tx.QueryRow(ctx, query,
 		s.ProductId,
 		s.WarehouseId,
 		s.Quantity,
 		s.Reserved,
 	).Scan(&s.UpdatedAt)
 
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrAlreadyExists
+	}
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return s, nil
+
+	return outboxRepository.AppendStock(ctx, tx, outbox.StockReceived, *s.ProductId, *s.WarehouseId, nil)
 }
 
 func (r *Repository) GetByID(idWarehouse *uuid.UUID, idProduct *uuid.UUID) (*stockitems.StockItems, error) {
 	ctx := context.Background()
 	query := `
-		SELECT "ProductId", "WarehouseId", "Quantity", "Reserved", "UpdatedAt"
+		SELECT "ProductId", "WarehouseId", "Quantity", "Reserved", "UpdatedAt", "Version"
 		FROM "StockItems"
 		WHERE "WarehouseId"=$1 AND "ProductId"=$2
 	`
@@ -91,6 +227,7 @@ func (r *Repository) GetByID(idWarehouse *uuid.UUID, idProduct *uuid.UUID) (*sto
 		&s.Quantity,
 		&s.Reserved,
 		&s.UpdatedAt,
+		&s.Version,
 	)
 	if err != nil {
 		return nil, err
@@ -98,7 +235,9 @@ func (r *Repository) GetByID(idWarehouse *uuid.UUID, idProduct *uuid.UUID) (*sto
 	return &s, nil
 }
 
-func (r *Repository) Update(s *stockitems.StockItems) error {
+// Update aplica os campos informados. Com expectedVersion a linha so e
+// alterada se a versao ainda for a mesma; senao retorna ErrVersionMismatch
+func (r *Repository) Update(s *stockitems.StockItems, expectedVersion *int64) error {
 	ctx := context.Background()
 
 	setParts := []string{}
@@ -123,19 +262,46 @@ func (r *Repository) Update(s *stockitems.StockItems) error {
 		return nil
 	}
 
+	where := `"ProductId"=$` + strconv.Itoa(argPos) + ` AND "WarehouseId"=$` + strconv.Itoa(argPos+1)
+	args = append(args, s.ProductId, s.WarehouseId)
+	if expectedVersion != nil {
+		where += ` AND "Version"=$` + strconv.Itoa(argPos+2)
+		args = append(args, *expectedVersion)
+	}
+
 	query := `
 		UPDATE "StockItems"
 		SET ` + strings.Join(setParts, ", ") + `
-		WHERE "ProductId"=$` + strconv.Itoa(argPos) + ` AND "WarehouseId"=$` + strconv.Itoa(argPos+1) + `
-		RETURNING "UpdatedAt"
+		WHERE ` + where + `
+		RETURNING "UpdatedAt", "Version"
 	`
 
-	args = append(args, s.ProductId, s.WarehouseId)
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin update: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	return r.DB.QueryRow(ctx, query, args...).Scan(&s.UpdatedAt)
+	err = tx.QueryRow(ctx, query, args...).Scan(&s.UpdatedAt, &s.Version)
+	if errors.Is(err, pgx.ErrNoRows) && expectedVersion != nil {
+		if _, getErr := r.GetByID(s.WarehouseId, s.ProductId); getErr == nil {
+			return ErrVersionMismatch
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := outboxRepository.AppendStock(ctx, tx, outbox.StockAdjusted, *s.ProductId, *s.WarehouseId, nil); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *Repository) DeductQuantity(baixa *stockitems.StockItemsBaixa) error {
+// DeductQuantity baixa a quantidade do item de estoque e registra a baixa no
+// razao, com os eventos, numa unica transacao. Retorna pgx.ErrNoRows quando o
+// item nao existe e ErrInsufficientStock quando tem menos que a quantidade
+func (r *Repository) DeductQuantity(baixa *stockitems.StockItemsBaixa, createdBy *string) (*stockmoves.StockMove, error) {
 	ctx := context.Background()
 
 	query := `
@@ -148,23 +314,248 @@ func (r *Repository) DeductQuantity(baixa *stockitems.StockItemsBaixa) error {
 		RETURNING "Quantity"
 	`
 
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin deduct: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	var newQuantity int
-	err := r.DB.QueryRow(ctx, query, *baixa.Quantity, *baixa.WarehouseId, *baixa.ProductId).Scan(&newQuantity)
+	err = tx.QueryRow(ctx, query, *baixa.Quantity, *baixa.WarehouseId, *baixa.ProductId).Scan(&newQuantity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		err = tx.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM "StockItems" WHERE "WarehouseId" = $1 AND "ProductId" = $2)
+		`, *baixa.WarehouseId, *baixa.ProductId).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("check stock item: %w", err)
+		}
+		if !exists {
+			return nil, pgx.ErrNoRows
+		}
+		return nil, ErrInsufficientStock
+	}
 	if err != nil {
-		return fmt.Errorf("failed to deduct quantity: %w", err)
+		return nil, fmt.Errorf("failed to deduct quantity: %w", err)
 	}
 
 	if newQuantity < 0 {
-		return fmt.Errorf("quantity cannot be negative")
+		return nil, fmt.Errorf("quantity cannot be negative")
+	}
+
+	err = outboxRepository.AppendStock(ctx, tx, outbox.StockDeducted, *baixa.ProductId, *baixa.WarehouseId, map[string]any{
+		"delta": -*baixa.Quantity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reason := stockmoves.ReasonDeduct
+	qtyMoved := -*baixa.Quantity
+	move := stockmoves.StockMove{
+		ProductId:   baixa.ProductId,
+		WarehouseId: baixa.WarehouseId,
+		QtyMoved:    &qtyMoved,
+		Reason:      &reason,
+		CreatedBy:   createdBy,
+	}
+	if err := stockmovesRepository.CreateTx(ctx, tx, &move); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
 	}
+	return &move, nil
+}
+
+// ReserveQuantities reserva as quantidades informadas numa unica transacao.
+// Se algum item nao tiver saldo disponivel suficiente nada e reservado.
+func (r *Repository) ReserveQuantities(items *[]stockitems.StockItemsBaixa) error {
+	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin reserve: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
+	if err := ReserveQuantitiesTx(ctx, tx, *items); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ReserveQuantitiesTx e o ReserveQuantities dentro de uma transacao do chamador,
+// que deve desfaze-la quando o retorno for ErrInsufficientStock
+func ReserveQuantitiesTx(ctx context.Context, tx pgx.Tx, items []stockitems.StockItemsBaixa) error {
+	for _, item := range items {
+		tag, err := tx.Exec(ctx, `
+			UPDATE "StockItems"
+			SET "Reserved" = "Reserved" + $1,
+			    "UpdatedAt" = now()
+			WHERE "WarehouseId" = $2
+			  AND "ProductId" = $3
+			  AND "Quantity" - "Reserved" >= $1
+		`, *item.Quantity, *item.WarehouseId, *item.ProductId)
+		if err != nil {
+			return fmt.Errorf("reserve quantity: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrInsufficientStock
+		}
+
+		err = outboxRepository.AppendStock(ctx, tx, outbox.StockReserved, *item.ProductId, *item.WarehouseId, map[string]any{
+			"reserved_delta": *item.Quantity,
+		})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// ReleaseQuantities desfaz reservas numa unica transacao. Se algum item tiver
+// reservado menos que a quantidade informada nada e liberado.
+func (r *Repository) ReleaseQuantities(items *[]stockitems.StockItemsBaixa) error {
+	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin release: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := ReleaseQuantitiesTx(ctx, tx, *items); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ReleaseQuantitiesTx e o ReleaseQuantities dentro de uma transacao do chamador
+func ReleaseQuantitiesTx(ctx context.Context, tx pgx.Tx, items []stockitems.StockItemsBaixa) error {
+	for _, item := range items {
+		tag, err := tx.Exec(ctx, `
+			UPDATE "StockItems"
+			SET "Reserved" = "Reserved" - $1,
+			    "UpdatedAt" = now()
+			WHERE "WarehouseId" = $2
+			  AND "ProductId" = $3
+			  AND "Reserved" >= $1
+		`, *item.Quantity, *item.WarehouseId, *item.ProductId)
+		if err != nil {
+			return fmt.Errorf("release quantity: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrInsufficientReserved
+		}
+
+		err = outboxRepository.AppendStock(ctx, tx, outbox.StockReleased, *item.ProductId, *item.WarehouseId, map[string]any{
+			"reserved_delta": -*item.Quantity,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CommitReservedTx da baixa em quantidades reservadas antes: tira cada
+// quantidade do saldo e da reserva ao mesmo tempo e registra a saida no razao
+// com o motivo informado. Retorna ErrInsufficientReserved se algum item tiver
+// reservado menos que o informado.
+func CommitReservedTx(ctx context.Context, tx pgx.Tx, items []stockitems.StockItemsBaixa, reason string, createdBy *string) error {
+	for _, item := range items {
+		tag, err := tx.Exec(ctx, `
+			UPDATE "StockItems"
+			SET "Quantity" = "Quantity" - $1,
+			    "Reserved" = "Reserved" - $1,
+			    "UpdatedAt" = now()
+			WHERE "WarehouseId" = $2
+			  AND "ProductId" = $3
+			  AND "Reserved" >= $1
+			  AND "Quantity" >= $1
+		`, *item.Quantity, *item.WarehouseId, *item.ProductId)
+		if err != nil {
+			return fmt.Errorf("commit reserved quantity: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrInsufficientReserved
+		}
+
+		err = outboxRepository.AppendStock(ctx, tx, outbox.StockDeducted, *item.ProductId, *item.WarehouseId, map[string]any{
+			"delta":          -*item.Quantity,
+			"reserved_delta": -*item.Quantity,
+		})
+		if err != nil {
+			return err
+		}
+
+		qtyMoved := -*item.Quantity
+		move := stockmoves.StockMove{
+			ProductId:   item.ProductId,
+			WarehouseId: item.WarehouseId,
+			QtyMoved:    &qtyMoved,
+			Reason:      &reason,
+			CreatedBy:   createdBy,
+		}
+		if err := stockmovesRepository.CreateTx(ctx, tx, &move); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Available retorna o saldo disponivel (quantidade menos reservado) dos itens
+// informados. Itens que nao existem ficam fora do mapa
+func (r *Repository) Available(keys []stockitems.ItemKey) (map[stockitems.ItemKey]int64, error) {
+	ctx := context.Background()
+
+	productIds := make([]string, 0, len(keys))
+	warehouseIds := make([]string, 0, len(keys))
+	for _, k := range keys {
+		productIds = append(productIds, k.ProductId.String())
+		warehouseIds = append(warehouseIds, k.WarehouseId.String())
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT s."ProductId", s."WarehouseId", s."Quantity" - s."Reserved"
+		FROM "StockItems" s
+		JOIN unnest($1::uuid[], $2::uuid[]) AS k("ProductId", "WarehouseId")
+		  ON k."ProductId" = s."ProductId" AND k."WarehouseId" = s."WarehouseId"
+	`, productIds, warehouseIds)
+	if err != nil {
+		return nil, fmt.Errorf("select available: %w", err)
+	}
+	defer rows.Close()
+
+	available := make(map[stockitems.ItemKey]int64, len(keys))
+	for rows.Next() {
+		var k stockitems.ItemKey
+		var qty int64
+		if err := rows.Scan(&k.ProductId, &k.WarehouseId, &qty); err != nil {
+			return nil, err
+		}
+		available[k] = qty
+	}
+	return available, rows.Err()
+}
+
+// Delete remove o item de estoque, gravando o ultimo saldo num evento
 func (r *Repository) Delete(idWarehouse *uuid.UUID, idProduct *uuid.UUID) error {
 	ctx := context.Background()
 
-	_, err := r.DB.Exec(ctx, `
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin delete: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := outboxRepository.AppendStock(ctx, tx, outbox.StockRemoved, *idProduct, *idWarehouse, nil); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
 		DELETE FROM "StockItems"
 		WHERE "ProductId"=$1 AND "WarehouseId"=$2
 	`, *idProduct, *idWarehouse)
@@ -172,5 +563,5 @@ func (r *Repository) Delete(idWarehouse *uuid.UUID, idProduct *uuid.UUID) error
 	if err != nil {
 		return fmt.Errorf("delete stock item: %w", err)
 	}
-	return nil
+	return tx.Commit(ctx)
 }
@@ -2,15 +2,30 @@ package stockmoves
 
 import (
 	"api-estoque/internal/config"
+	"api-estoque/internal/model/outbox"
+	"api-estoque/internal/model/pagination"
 	stockmoves "api-estoque/internal/model/stock_moves"
+	"api-estoque/internal/model/warehouse"
+	outboxRepository "api-estoque/internal/repositories/outbox"
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	ErrIsReversal          = errors.New("movimentacao e um estorno e nao pode ser estornada")
+	ErrAlreadyReversed     = errors.New("movimentacao ja foi estornada")
+	ErrReversalUnavailable = errors.New("item de estoque inexistente ou sem saldo livre para o estorno")
+	ErrWarehouseClosed     = errors.New("galpao encerrado, movimentacao nao pode ser estornada")
+)
+
 type Repository struct {
 	DB *pgxpool.Pool
 }
@@ -25,15 +40,60 @@ func New() *Repository {
 	}
 }
 
-// List returns all stock moves ordered by CreatedAt desc
-func (r *Repository) List() (*[]stockmoves.StockMove, error) {
+// List returns one page of the ledger matching q, ordered by q.Sort with Id as
+// tie-breaker so the cursor stays stable under concurrent inserts. It fetches one
+// row past the limit so the caller can tell whether there is a next page
+func (r *Repository) List(q *stockmoves.Query, page *pagination.Page) (*[]stockmoves.StockMove, error) {
 	ctx := context.Background()
 
-	rows, err := r.DB.Query(ctx, `
-		SELECT "Id", "ProductId", "WarehouseId", "QtyMoved", "Reason", "CreatedAt"
-		FROM "StockMoves"
-		ORDER BY "CreatedAt" DESC
-	`)
+	conditions, args := filterConditions(q)
+	argPos := len(args) + 1
+
+	column := stockmoves.SortColumns[q.SortField()]
+	direction, comparison := "ASC", ">"
+	if q.SortDesc() {
+		direction, comparison = "DESC", "<"
+	}
+
+	if page.After != nil {
+		if page.After.Sort != q.Sort || page.After.Id == nil {
+			return nil, pagination.ErrInvalidCursor
+		}
+
+		var key any
+		switch q.SortField() {
+		case "createdAt":
+			if page.After.CreatedAt == nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+			key = *page.After.CreatedAt
+		case "qtyMoved":
+			if page.After.QtyMoved == nil {
+				return nil, pagination.ErrInvalidCursor
+			}
+			key = *page.After.QtyMoved
+		}
+
+		conditions = append(conditions, `(`+column+`, m."Id") `+comparison+` ($`+strconv.Itoa(argPos)+`, $`+strconv.Itoa(argPos+1)+`)`)
+		args = append(args, key, *page.After.Id)
+		argPos += 2
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := `
+		SELECT m."Id", m."ProductId", m."WarehouseId", m."QtyMoved", m."Reason", m."ReversalOf", m."CreatedBy", m."CreatedAt"
+		FROM "StockMoves" m
+		JOIN "Product" p ON p."Id" = m."ProductId"
+		` + where + `
+		ORDER BY ` + column + ` ` + direction + `, m."Id" ` + direction + `
+		LIMIT $` + strconv.Itoa(argPos)
+	args = append(args, page.Fetch())
+
+	rows, err := r.DB.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -48,43 +108,220 @@ func (r *Repository) List() (*[]stockmoves.StockMove, error) {
 			&m.WarehouseId,
 			&m.QtyMoved,
 			&m.Reason,
+			&m.ReversalOf,
+			&m.CreatedBy,
 			&m.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
 		moves = append(moves, m)
 	}
-	return &moves, nil
+	return &moves, rows.Err()
+}
+
+// Export streams every move matching q to fn, with product and warehouse names,
+// in the order of q.Sort. Rows are read as fn consumes them, so the ledger is
+// never held in memory; an error from fn stops the query
+func (r *Repository) Export(q *stockmoves.Query, fn func(*stockmoves.ExportRow) error) error {
+	ctx := context.Background()
+
+	conditions, args := filterConditions(q)
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	column := stockmoves.SortColumns[q.SortField()]
+	direction := "ASC"
+	if q.SortDesc() {
+		direction = "DESC"
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT m."Id", m."CreatedAt", m."ProductId", p."Name", m."WarehouseId", w."Name",
+		       m."QtyMoved", m."Reason", m."ReversalOf", m."CreatedBy"
+		FROM "StockMoves" m
+		JOIN "Product" p ON p."Id" = m."ProductId"
+		JOIN "Warehouse" w ON w."Id" = m."WarehouseId"
+		`+where+`
+		ORDER BY `+column+` `+direction+`, m."Id" `+direction, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m stockmoves.ExportRow
+		if err := rows.Scan(
+			&m.Id,
+			&m.CreatedAt,
+			&m.ProductId,
+			&m.ProductName,
+			&m.WarehouseId,
+			&m.WarehouseName,
+			&m.QtyMoved,
+			&m.Reason,
+			&m.ReversalOf,
+			&m.CreatedBy,
+		); err != nil {
+			return err
+		}
+		if err := fn(&m); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// filterConditions builds the WHERE conditions of q over "StockMoves" m joined
+// with "Product" p. Placeholders start at $1, in the order of args
+func filterConditions(q *stockmoves.Query) ([]string, []any) {
+	conditions := []string{}
+	args := []any{}
+	argPos := 1
+
+	if q.From != nil {
+		conditions = append(conditions, `m."CreatedAt" >= $`+strconv.Itoa(argPos))
+		args = append(args, *q.From)
+		argPos++
+	}
+
+	if q.To != nil {
+		conditions = append(conditions, `m."CreatedAt" < $`+strconv.Itoa(argPos))
+		args = append(args, *q.To)
+		argPos++
+	}
+
+	if q.Type != nil {
+		switch *q.Type {
+		case stockmoves.TypeIn:
+			conditions = append(conditions, `m."QtyMoved" > 0 AND m."ReversalOf" IS NULL`)
+		case stockmoves.TypeOut:
+			conditions = append(conditions, `m."QtyMoved" < 0 AND m."ReversalOf" IS NULL`)
+		case stockmoves.TypeReversal:
+			conditions = append(conditions, `m."ReversalOf" IS NOT NULL`)
+		}
+	}
+
+	if q.Reason != nil {
+		conditions = append(conditions, `m."Reason" ILIKE '%' || $`+strconv.Itoa(argPos)+` || '%'`)
+		args = append(args, *q.Reason)
+		argPos++
+	}
+
+	if q.MinQty != nil {
+		conditions = append(conditions, `abs(m."QtyMoved") >= $`+strconv.Itoa(argPos))
+		args = append(args, *q.MinQty)
+		argPos++
+	}
+
+	if q.MaxQty != nil {
+		conditions = append(conditions, `abs(m."QtyMoved") <= $`+strconv.Itoa(argPos))
+		args = append(args, *q.MaxQty)
+		argPos++
+	}
+
+	if q.CategoryId != nil {
+		conditions = append(conditions, `p."CategoryId" IN (
+			WITH RECURSIVE subtree AS (
+				SELECT "Id" FROM "Category" WHERE "Id" = $`+strconv.Itoa(argPos)+`
+				UNION ALL
+				SELECT c."Id" FROM "Category" c JOIN subtree s ON c."ParentId" = s."Id"
+			)
+			SELECT "Id" FROM subtree
+		)`)
+		args = append(args, *q.CategoryId)
+		argPos++
+	}
+
+	if len(q.WarehouseIds) > 0 {
+		conditions = append(conditions, `m."WarehouseId" = ANY($`+strconv.Itoa(argPos)+`::uuid[])`)
+		args = append(args, uuidStrings(q.WarehouseIds))
+		argPos++
+	}
+
+	if len(q.ProductIds) > 0 {
+		conditions = append(conditions, `m."ProductId" = ANY($`+strconv.Itoa(argPos)+`::uuid[])`)
+		args = append(args, uuidStrings(q.ProductIds))
+		argPos++
+	}
+
+	if q.CreatedBy != nil {
+		conditions = append(conditions, `m."CreatedBy" = $`+strconv.Itoa(argPos))
+		args = append(args, *q.CreatedBy)
+		argPos++
+	}
+
+	return conditions, args
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, id.String())
+	}
+	return out
 }
 
-// Create inserts a new stock move and returns it
+// Create inserts a new stock move and returns it, recording its event in the
+// same transaction. The move only goes to the ledger; StockItems is not changed
 func (r *Repository) Create(m *stockmoves.StockMove) (*stockmoves.StockMove, error) {
 	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin create: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		INSERT INTO "StockMoves" ("ProductId", "WarehouseId", "QtyMoved", "Reason")
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO "StockMoves" ("ProductId", "WarehouseId", "QtyMoved", "Reason", "CreatedBy", "AppliedToStock")
+		VALUES ($1, $2, $3, $4, $5, false)
 		RETURNING "Id", "CreatedAt"
 	`
-	err := r.DB.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		m.ProductId,
 		m.WarehouseId,
 		m.QtyMoved,
 		m.Reason,
+		m.CreatedBy,
 	).Scan(&m.Id, &m.CreatedAt)
-
 	if err != nil {
 		return nil, err
 	}
+
+	if err := outboxRepository.AppendMoves(ctx, tx, *m.Id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return m, nil
 }
 
+// CreateTx inserts a move whose quantity the caller applied to StockItems in
+// the same transaction, recording its event
+func CreateTx(ctx context.Context, tx pgx.Tx, m *stockmoves.StockMove) error {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO "StockMoves" ("ProductId", "WarehouseId", "QtyMoved", "Reason", "CreatedBy")
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING "Id", "CreatedAt"
+	`, m.ProductId, m.WarehouseId, m.QtyMoved, m.Reason, m.CreatedBy).Scan(&m.Id, &m.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert stock move: %w", err)
+	}
+	return outboxRepository.AppendMoves(ctx, tx, *m.Id)
+}
+
 // GetByID fetches one stock move by its primary key
 func (r *Repository) GetByID(id *uuid.UUID) (*stockmoves.StockMove, error) {
 	ctx := context.Background()
 	query := `
-		SELECT "Id", "ProductId", "WarehouseId", "QtyMoved", "Reason", "CreatedAt"
-		FROM "StockMoves"
-		WHERE "Id"=$1
+		SELECT m."Id", m."ProductId", m."WarehouseId", m."QtyMoved", m."Reason", m."ReversalOf", m."CreatedBy", m."CreatedAt",
+		       (SELECT rv."Id" FROM "StockMoves" rv WHERE rv."ReversalOf" = m."Id")
+		FROM "StockMoves" m
+		WHERE m."Id"=$1
 	`
 	var m stockmoves.StockMove
 	err := r.DB.QueryRow(ctx, query, *id).Scan(
@@ -93,7 +330,10 @@ func (r *Repository) GetByID(id *uuid.UUID) (*stockmoves.StockMove, error) {
 		&m.WarehouseId,
 		&m.QtyMoved,
 		&m.Reason,
+		&m.ReversalOf,
+		&m.CreatedBy,
 		&m.CreatedAt,
+		&m.ReversedBy,
 	)
 	if err != nil {
 		return nil, err
@@ -101,15 +341,16 @@ func (r *Repository) GetByID(id *uuid.UUID) (*stockmoves.StockMove, error) {
 	return &m, nil
 }
 
-// ListByProduct fetches all moves for a given product
-func (r *Repository) ListByProduct(productId *uuid.UUID) (*[]stockmoves.StockMove, error) {
+// GetByIDs fetches the given stock moves in one query. Ids that do not exist
+// are left out of the result
+func (r *Repository) GetByIDs(ids []uuid.UUID) (*[]stockmoves.StockMove, error) {
 	ctx := context.Background()
+
 	rows, err := r.DB.Query(ctx, `
-		SELECT "Id", "ProductId", "WarehouseId", "QtyMoved", "Reason", "CreatedAt"
+		SELECT "Id", "ProductId", "WarehouseId", "QtyMoved", "Reason", "ReversalOf", "CreatedBy", "CreatedAt"
 		FROM "StockMoves"
-		WHERE "ProductId"=$1
-		ORDER BY "CreatedAt" DESC
-	`, *productId)
+		WHERE "Id" = ANY($1::uuid[])
+	`, uuidStrings(ids))
 	if err != nil {
 		return nil, err
 	}
@@ -124,23 +365,50 @@ func (r *Repository) ListByProduct(productId *uuid.UUID) (*[]stockmoves.StockMov
 			&m.WarehouseId,
 			&m.QtyMoved,
 			&m.Reason,
+			&m.ReversalOf,
+			&m.CreatedBy,
 			&m.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
 		moves = append(moves, m)
 	}
-	return &moves, nil
+	return &moves, rows.Err()
 }
 
-func (r *Repository) ListByWarehouse(warehouseId *uuid.UUID) (*[]stockmoves.StockMove, error) {
+// ListByProduct fetches one page of moves for a given product
+func (r *Repository) ListByProduct(productId *uuid.UUID, page *pagination.Page) (*[]stockmoves.StockMove, error) {
+	return r.listPage(page, `"ProductId"=$4`, *productId)
+}
+
+func (r *Repository) ListByWarehouse(warehouseId *uuid.UUID, page *pagination.Page) (*[]stockmoves.StockMove, error) {
+	return r.listPage(page, `"WarehouseId"=$4`, *warehouseId)
+}
+
+func (r *Repository) ListByWarehouseAndProduct(warehouseId *uuid.UUID, productId *uuid.UUID, page *pagination.Page) (*[]stockmoves.StockMove, error) {
+	return r.listPage(page, `"WarehouseId"=$4 AND "ProductId"=$5`, *warehouseId, *productId)
+}
+
+// listPage selects the moves matching filter, after the page cursor, ordered by
+// ("CreatedAt", "Id") desc. It fetches one row past the limit so the caller can
+// tell whether there is a next page. Placeholders in filter start at $4
+func (r *Repository) listPage(page *pagination.Page, filter string, filterArgs ...any) (*[]stockmoves.StockMove, error) {
 	ctx := context.Background()
+
+	afterCreatedAt, afterId, err := page.ByCreatedAt()
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]any{afterCreatedAt, afterId, page.Fetch()}, filterArgs...)
 	rows, err := r.DB.Query(ctx, `
-		SELECT "Id", "ProductId", "WarehouseId", "QtyMoved", "Reason", "CreatedAt"
+		SELECT "Id", "ProductId", "WarehouseId", "QtyMoved", "Reason", "ReversalOf", "CreatedBy", "CreatedAt"
 		FROM "StockMoves"
-		WHERE "WarehouseId"=$1
-		ORDER BY "CreatedAt" DESC
-	`, *warehouseId)
+		WHERE (`+filter+`)
+		  AND ($1::timestamptz IS NULL OR ("CreatedAt", "Id") < ($1, $2))
+		ORDER BY "CreatedAt" DESC, "Id" DESC
+		LIMIT $3
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -155,23 +423,56 @@ func (r *Repository) ListByWarehouse(warehouseId *uuid.UUID) (*[]stockmoves.Stoc
 			&m.WarehouseId,
 			&m.QtyMoved,
 			&m.Reason,
+			&m.ReversalOf,
+			&m.CreatedBy,
 			&m.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
 		moves = append(moves, m)
 	}
-	return &moves, nil
+	return &moves, rows.Err()
+}
+
+// RecentByProducts returns, for each of the given products, its latest limit
+// moves. Moves come grouped by product, newest first within each product
+func (r *Repository) RecentByProducts(productIds []uuid.UUID, limit int) (*[]stockmoves.StockMove, error) {
+	return r.recent(`unnest($1::uuid[]) AS k("ProductId")`, `m."ProductId" = k."ProductId"`, limit, uuidStrings(productIds))
+}
+
+// RecentByWarehouses returns, for each of the given warehouses, its latest
+// limit moves. Moves come grouped by warehouse, newest first within each one
+func (r *Repository) RecentByWarehouses(warehouseIds []uuid.UUID, limit int) (*[]stockmoves.StockMove, error) {
+	return r.recent(`unnest($1::uuid[]) AS k("WarehouseId")`, `m."WarehouseId" = k."WarehouseId"`, limit, uuidStrings(warehouseIds))
 }
 
-func (r *Repository) ListByWarehouseAndProduct(warehouseId *uuid.UUID, productId *uuid.UUID) (*[]stockmoves.StockMove, error) {
+// RecentByItems returns, for each stock item given as the pair (productIds[i],
+// warehouseIds[i]), its latest limit moves. Moves come grouped by item, newest
+// first within each item
+func (r *Repository) RecentByItems(productIds []uuid.UUID, warehouseIds []uuid.UUID, limit int) (*[]stockmoves.StockMove, error) {
+	return r.recent(`unnest($1::uuid[], $3::uuid[]) AS k("ProductId", "WarehouseId")`,
+		`m."ProductId" = k."ProductId" AND m."WarehouseId" = k."WarehouseId"`,
+		limit, uuidStrings(productIds), uuidStrings(warehouseIds))
+}
+
+// recent selects, for each row of keys, the latest limit moves matching
+// filter. $1 and, when given, $3 are the key arrays; $2 is the limit
+func (r *Repository) recent(keys string, filter string, limit int, keyArgs ...any) (*[]stockmoves.StockMove, error) {
 	ctx := context.Background()
+
+	args := []any{keyArgs[0], limit}
+	args = append(args, keyArgs[1:]...)
 	rows, err := r.DB.Query(ctx, `
-		SELECT "Id", "ProductId", "WarehouseId", "QtyMoved", "Reason", "CreatedAt"
-		FROM "StockMoves"
-		WHERE "WarehouseId"=$1 AND "ProductId"=$2
-		ORDER BY "CreatedAt" DESC
-	`, *warehouseId)
+		SELECT m."Id", m."ProductId", m."WarehouseId", m."QtyMoved", m."Reason", m."ReversalOf", m."CreatedBy", m."CreatedAt"
+		FROM `+keys+`
+		CROSS JOIN LATERAL (
+		    SELECT m.*
+		    FROM "StockMoves" m
+		    WHERE `+filter+`
+		    ORDER BY m."CreatedAt" DESC, m."Id" DESC
+		    LIMIT $2
+		) m
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -186,26 +487,124 @@ func (r *Repository) ListByWarehouseAndProduct(warehouseId *uuid.UUID, productId
 			&m.WarehouseId,
 			&m.QtyMoved,
 			&m.Reason,
+			&m.ReversalOf,
+			&m.CreatedBy,
 			&m.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
 		moves = append(moves, m)
 	}
-	return &moves, nil
+	return &moves, rows.Err()
 }
 
-// Delete removes a stock move by Id
-func (r *Repository) Delete(id *uuid.UUID) error {
+// Reverse inserts a compensating move for the given move, linked to it through
+// ReversalOf. When the original changed StockItems, the opposite quantity is
+// applied to it in the same transaction, unless the warehouse has been closed;
+// otherwise the reversal only goes to the ledger too
+func (r *Repository) Reverse(id *uuid.UUID, reason string, createdBy *string) (*stockmoves.StockMove, error) {
 	ctx := context.Background()
 
-	_, err := r.DB.Exec(ctx, `
-		DELETE FROM "StockMoves"
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin reverse: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var original stockmoves.StockMove
+	var applied bool
+	err = tx.QueryRow(ctx, `
+		SELECT "Id", "ProductId", "WarehouseId", "QtyMoved", "ReversalOf", "AppliedToStock"
+		FROM "StockMoves"
 		WHERE "Id"=$1
-	`, *id)
+		FOR UPDATE
+	`, *id).Scan(
+		&original.Id,
+		&original.ProductId,
+		&original.WarehouseId,
+		&original.QtyMoved,
+		&original.ReversalOf,
+		&applied,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if original.ReversalOf != nil {
+		return nil, ErrIsReversal
+	}
+
+	var alreadyReversed bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM "StockMoves" WHERE "ReversalOf"=$1)
+	`, *id).Scan(&alreadyReversed)
+	if err != nil {
+		return nil, err
+	}
+	if alreadyReversed {
+		return nil, ErrAlreadyReversed
+	}
 
+	qty := -*original.QtyMoved
+	if applied {
+		// Close zeroed the warehouse's items and moved them elsewhere, so a
+		// reversal there would bring back stock that no longer exists.
+		var status string
+		err = tx.QueryRow(ctx, `
+			SELECT "Status" FROM "Warehouse" WHERE "Id"=$1 FOR SHARE
+		`, *original.WarehouseId).Scan(&status)
+		if err != nil {
+			return nil, fmt.Errorf("lock warehouse: %w", err)
+		}
+		if status == warehouse.StatusClosed {
+			return nil, ErrWarehouseClosed
+		}
+
+		tag, err := tx.Exec(ctx, `
+			UPDATE "StockItems"
+			SET "Quantity" = "Quantity" + $1,
+			    "UpdatedAt" = now()
+			WHERE "WarehouseId" = $2
+			  AND "ProductId" = $3
+			  AND "Quantity" + $1 >= "Reserved"
+		`, qty, *original.WarehouseId, *original.ProductId)
+		if err != nil {
+			return nil, fmt.Errorf("apply reversal: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil, ErrReversalUnavailable
+		}
+	}
+
+	reversal := stockmoves.StockMove{
+		ProductId:   original.ProductId,
+		WarehouseId: original.WarehouseId,
+		QtyMoved:    &qty,
+		Reason:      &reason,
+		ReversalOf:  original.Id,
+		CreatedBy:   createdBy,
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO "StockMoves" ("ProductId", "WarehouseId", "QtyMoved", "Reason", "ReversalOf", "CreatedBy", "AppliedToStock")
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING "Id", "CreatedAt"
+	`, reversal.ProductId, reversal.WarehouseId, reversal.QtyMoved, reversal.Reason, reversal.ReversalOf, reversal.CreatedBy, applied,
+	).Scan(&reversal.Id, &reversal.CreatedAt)
 	if err != nil {
-		return fmt.Errorf("delete stock move: %w", err)
+		return nil, fmt.Errorf("insert reversal: %w", err)
+	}
+
+	if applied {
+		err = outboxRepository.AppendStock(ctx, tx, outbox.StockAdjusted, *original.ProductId, *original.WarehouseId, map[string]any{
+			"delta":       qty,
+			"reversal_of": *original.Id,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := outboxRepository.AppendMoves(ctx, tx, *reversal.Id); err != nil {
+		return nil, err
 	}
-	return nil
+
+	return &reversal, tx.Commit(ctx)
 }
@@ -0,0 +1,304 @@
+package tcc
+
+import (
+	"api-estoque/internal/config"
+	stockitems "api-estoque/internal/model/stock_items"
+	"api-estoque/internal/model/tcc"
+	stockitemsRepository "api-estoque/internal/repositories/stock_items"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Recusas por causa do estado da transacao. O que foi alterado antes delas,
+// como o cancelamento de um Try vencido, e gravado
+var (
+	ErrTxCancelled   = errors.New("transacao ja cancelada")
+	ErrTxExpired     = errors.New("transacao expirada, reserva liberada")
+	ErrTxConfirmed   = errors.New("transacao ja confirmada, reserva nao pode ser liberada")
+	ErrLinesMismatch = errors.New("transacao ja iniciada com outras linhas")
+)
+
+// selectSQL le a transacao $1. A ultima coluna indica se e um Try com o prazo
+// vencido, pelo relogio do banco
+const selectSQL = `
+	SELECT "TxId", "Status", "Lines", "ExpiresAt", "CancelReason", "CreatedBy", "CreatedAt", "UpdatedAt",
+	       "Status" = 'tried' AND "ExpiresAt" <= now()
+	FROM "TccTransaction"
+	WHERE "TxId"=$1
+`
+
+type Repository struct {
+	DB *pgxpool.Pool
+}
+
+func New() *Repository {
+	maxConns := 4
+	maxIdleTime := 30 * time.Second
+	maxLifetime := 2 * time.Minute
+
+	return &Repository{
+		DB: config.PostgresConn(maxConns, maxIdleTime, maxLifetime),
+	}
+}
+
+// Try reserva as linhas da transacao ate o prazo timeout. A repeticao com as
+// mesmas linhas devolve a transacao como esta, mantendo o primeiro prazo; uma
+// transacao ja cancelada e recusada, entao o Try que chega depois do Cancel
+// nunca reserva. Retorna stockitemsRepository.ErrInsufficientStock quando
+// alguma linha nao tem saldo, e nesse caso nada e gravado
+func (r *Repository) Try(txId string, try *tcc.Try, timeout time.Duration) (*tcc.Transaction, error) {
+	return r.run(txId, func(ctx context.Context, tx pgx.Tx, t *tcc.Transaction) error {
+		if t != nil {
+			if t.Status == tcc.StatusCancelled {
+				return cancelledErr(t)
+			}
+			if !tcc.SameLines(t.Lines, try.Lines) {
+				return ErrLinesMismatch
+			}
+			return nil
+		}
+
+		if err := stockitemsRepository.ReserveQuantitiesTx(ctx, tx, try.Lines); err != nil {
+			return err
+		}
+		raw, err := json.Marshal(try.Lines)
+		if err != nil {
+			return fmt.Errorf("marshal lines: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO "TccTransaction" ("TxId", "Status", "Lines", "ExpiresAt", "CreatedBy")
+			VALUES ($1, $2, $3, now() + make_interval(secs => $4), $5)
+		`, txId, tcc.StatusTried, raw, timeout.Seconds(), try.CreatedBy)
+		if err != nil {
+			return fmt.Errorf("insert transaction: %w", err)
+		}
+		return nil
+	})
+}
+
+// Confirm transforma a reserva da transacao em baixa, registrada no razao com
+// o id da transacao no motivo e confirmedBy como autor. A repeticao nao faz
+// nada. Retorna pgx.ErrNoRows quando nao houve Try, e
+// stockitemsRepository.ErrInsufficientReserved quando o reservado foi alterado
+// manualmente nesse meio tempo
+func (r *Repository) Confirm(txId string, confirmedBy *string) (*tcc.Transaction, error) {
+	return r.run(txId, func(ctx context.Context, tx pgx.Tx, t *tcc.Transaction) error {
+		if t == nil {
+			return pgx.ErrNoRows
+		}
+
+		switch t.Status {
+		case tcc.StatusConfirmed:
+			return nil
+		case tcc.StatusCancelled:
+			return cancelledErr(t)
+		}
+
+		if err := stockitemsRepository.CommitReservedTx(ctx, tx, t.Lines, "Baixa da transacao TCC "+txId, confirmedBy); err != nil {
+			return err
+		}
+		return setStatus(ctx, tx, txId, tcc.StatusConfirmed, nil)
+	})
+}
+
+// Cancel libera a reserva da transacao. A repeticao nao faz nada. Sem Try,
+// grava a transacao como cancelada para recusar o Try atrasado. Retorna
+// stockitemsRepository.ErrInsufficientReserved quando o reservado foi alterado
+// manualmente nesse meio tempo
+func (r *Repository) Cancel(txId string) (*tcc.Transaction, error) {
+	return r.run(txId, func(ctx context.Context, tx pgx.Tx, t *tcc.Transaction) error {
+		if t == nil {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO "TccTransaction" ("TxId", "Status", "CancelReason")
+				VALUES ($1, $2, $3)
+			`, txId, tcc.StatusCancelled, tcc.CancelRequested)
+			if err != nil {
+				return fmt.Errorf("insert cancelled transaction: %w", err)
+			}
+			return nil
+		}
+
+		switch t.Status {
+		case tcc.StatusCancelled:
+			return nil
+		case tcc.StatusConfirmed:
+			return ErrTxConfirmed
+		}
+
+		if err := stockitemsRepository.ReleaseQuantitiesTx(ctx, tx, t.Lines); err != nil {
+			return err
+		}
+		reason := tcc.CancelRequested
+		return setStatus(ctx, tx, txId, tcc.StatusCancelled, &reason)
+	})
+}
+
+// Expire cancela a transacao se for um Try com o prazo vencido. Retorna
+// pgx.ErrNoRows quando ela nao existe mais
+func (r *Repository) Expire(txId string) (*tcc.Transaction, error) {
+	return r.run(txId, func(ctx context.Context, tx pgx.Tx, t *tcc.Transaction) error {
+		if t == nil {
+			return pgx.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// run serializa as chamadas da mesma transacao com um advisory lock, carrega a
+// transacao (nil quando nao existe) e chama fn. Um Try vencido e cancelado
+// antes de fn ve-lo. As alteracoes sao confirmadas quando fn termina sem erro
+// ou com um dos erros de estado acima; qualquer outro erro as desfaz. Sem
+// erro, retorna a transacao como fn a deixou
+func (r *Repository) run(txId string, fn func(ctx context.Context, tx pgx.Tx, t *tcc.Transaction) error) (*tcc.Transaction, error) {
+	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('tcc:' || $1))`, txId); err != nil {
+		return nil, fmt.Errorf("transaction lock: %w", err)
+	}
+
+	// a linha fica travada contra o encerramento de galpao, que reescreve as
+	// linhas das transacoes em aberto
+	t, expired, err := scanTransaction(tx.QueryRow(ctx, selectSQL+"FOR UPDATE", txId))
+	if errors.Is(err, pgx.ErrNoRows) {
+		t = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("select transaction: %w", err)
+	}
+
+	if expired {
+		if err := stockitemsRepository.ReleaseQuantitiesTx(ctx, tx, t.Lines); err != nil {
+			return nil, err
+		}
+		reason := tcc.CancelExpired
+		if err := setStatus(ctx, tx, txId, tcc.StatusCancelled, &reason); err != nil {
+			return nil, err
+		}
+		t.Status = tcc.StatusCancelled
+		t.CancelReason = &reason
+	}
+
+	fnErr := fn(ctx, tx, t)
+	if fnErr != nil && !isStateErr(fnErr) {
+		return nil, fnErr
+	}
+
+	if fnErr == nil {
+		t, _, err = scanTransaction(tx.QueryRow(ctx, selectSQL, txId))
+		if err != nil {
+			return nil, fmt.Errorf("select transaction: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	if fnErr != nil {
+		return nil, fnErr
+	}
+	return t, nil
+}
+
+func isStateErr(err error) bool {
+	return errors.Is(err, ErrTxCancelled) ||
+		errors.Is(err, ErrTxExpired) ||
+		errors.Is(err, ErrTxConfirmed) ||
+		errors.Is(err, ErrLinesMismatch)
+}
+
+func cancelledErr(t *tcc.Transaction) error {
+	if t.CancelReason != nil && *t.CancelReason == tcc.CancelExpired {
+		return ErrTxExpired
+	}
+	return ErrTxCancelled
+}
+
+func setStatus(ctx context.Context, tx pgx.Tx, txId string, status string, cancelReason *string) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE "TccTransaction" SET "Status"=$2, "CancelReason"=$3, "UpdatedAt"=now()
+		WHERE "TxId"=$1
+	`, txId, status, cancelReason)
+	if err != nil {
+		return fmt.Errorf("update transaction status: %w", err)
+	}
+	return nil
+}
+
+func scanTransaction(row pgx.Row) (*tcc.Transaction, bool, error) {
+	var t tcc.Transaction
+	var lines []stockitems.StockItemsBaixa
+	var expired bool
+	err := row.Scan(&t.TxId, &t.Status, &lines, &t.ExpiresAt, &t.CancelReason, &t.CreatedBy, &t.CreatedAt, &t.UpdatedAt, &expired)
+	if err != nil {
+		return nil, false, err
+	}
+	t.Lines = lines
+	return &t, expired, nil
+}
+
+// GetByID retorna a transacao como esta gravada. Um Try vencido continua
+// 'tried' ate ser expirado
+func (r *Repository) GetByID(txId string) (*tcc.Transaction, error) {
+	ctx := context.Background()
+
+	t, _, err := scanTransaction(r.DB.QueryRow(ctx, selectSQL, txId))
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Due retorna os ids de ate limit Tries vencidos, do mais antigo ao mais novo
+func (r *Repository) Due(limit int) ([]string, error) {
+	ctx := context.Background()
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT "TxId"
+		FROM "TccTransaction"
+		WHERE "Status" = 'tried' AND "ExpiresAt" <= now()
+		ORDER BY "ExpiresAt"
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("select due transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// DeleteFinished remove as transacoes confirmadas e canceladas alteradas pela
+// ultima vez antes da janela de retencao. Um Try que chegar depois da remocao
+// do seu Cancel deixa de ser recusado
+func (r *Repository) DeleteFinished(retention time.Duration) (int64, error) {
+	ctx := context.Background()
+
+	tag, err := r.DB.Exec(ctx, `
+		DELETE FROM "TccTransaction"
+		WHERE "Status" <> 'tried'
+		  AND "UpdatedAt" < now() - make_interval(secs => $1)
+	`, retention.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("delete finished transactions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
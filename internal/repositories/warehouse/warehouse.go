@@ -2,17 +2,33 @@ package warehouse
 
 import (
 	"api-estoque/internal/config"
+	"api-estoque/internal/model/order"
+	"api-estoque/internal/model/outbox"
+	"api-estoque/internal/model/pagination"
+	stockitems "api-estoque/internal/model/stock_items"
+	"api-estoque/internal/model/tcc"
 	warehouse "api-estoque/internal/model/warehouse"
+	outboxRepository "api-estoque/internal/repositories/outbox"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var (
+	ErrWarehouseClosed   = errors.New("galpao ja esta encerrado")
+	ErrWarehouseNotEmpty = errors.New("galpao ainda possui estoque ou reservas")
+	ErrInvalidTarget     = errors.New("galpao de destino inexistente ou encerrado")
+	ErrVersionMismatch   = errors.New("galpao alterado desde a leitura")
+)
+
 type Repository struct {
 	DB *pgxpool.Pool
 }
@@ -27,14 +43,26 @@ func New() *Repository {
 	}
 }
 
-func (r *Repository) List() (*[]warehouse.Warehouse, error) {
+// List returns one page of warehouses ordered by CreatedAt desc, Id desc, leaving
+// closed ones out unless includeClosed is set. It fetches one row past the limit
+// so the caller can tell whether there is a next page
+func (r *Repository) List(includeClosed bool, page *pagination.Page) (*[]warehouse.Warehouse, error) {
 	ctx := context.Background()
 
+	afterCreatedAt, afterId, err := page.ByCreatedAt()
+	if err != nil {
+		return nil, err
+	}
+
 	rows, err := r.DB.Query(ctx, `
-		SELECT "Id", "Name", "Location", "CreatedAt"
+		SELECT "Id", "Name", "Location", "Region", "Priority", "Street", "Number", "Complement", "District", "City", "State", "ZipCode", "Latitude", "Longitude",
+		       "VolumeCapacityLiters", "WeightCapacityKg", "Status", "ClosedAt", "CreatedAt"
 		FROM "Warehouse"
-		ORDER BY "CreatedAt" DESC
-	`)
+		WHERE ($1 OR "Status" = 'active')
+		  AND ($2::timestamptz IS NULL OR ("CreatedAt", "Id") < ($2, $3))
+		ORDER BY "CreatedAt" DESC, "Id" DESC
+		LIMIT $4
+	`, includeClosed, afterCreatedAt, afterId, page.Fetch())
 	if err != nil {
 		return nil, err
 	}
@@ -47,6 +75,21 @@ func (r *Repository) List() (*[]warehouse.Warehouse, error) {
 			&w.Id,
 			&w.Name,
 			&w.Location,
+			&w.Region,
+			&w.Priority,
+			&w.Street,
+			&w.Number,
+			&w.Complement,
+			&w.District,
+			&w.City,
+			&w.State,
+			&w.ZipCode,
+			&w.Latitude,
+			&w.Longitude,
+			&w.VolumeCapacityLiters,
+			&w.WeightCapacityKg,
+			&w.Status,
+			&w.ClosedAt,
 			&w.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -59,14 +102,30 @@ func (r *Repository) List() (*[]warehouse.Warehouse, error) {
 func (r *Repository) Create(w *warehouse.Warehouse) (*warehouse.Warehouse, error) {
 	ctx := context.Background()
 	query := `
-		INSERT INTO "Warehouse" ("Name", "Location")
-		VALUES ($1, $2)
-		RETURNING "Id", "CreatedAt"
+		INSERT INTO "Warehouse" (
+			"Name", "Location", "Region", "Priority", "Street", "Number", "Complement",
+			"District", "City", "ZipCode", "Latitude", "Longitude",
+			"VolumeCapacityLiters", "WeightCapacityKg"
+		)
+		VALUES ($1, $2, $3, COALESCE($4, 100), $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING "Id", "Region", "Priority", "Street", "Number", "Complement", "District", "City", "State", "ZipCode", "Status", "CreatedAt"
 	`
 	err := r.DB.QueryRow(ctx, query,
 		w.Name,
 		w.Location,
-	).Scan(&w.Id, &w.CreatedAt)
+		optional(w.Region),
+		w.Priority,
+		optional(w.Street),
+		optional(w.Number),
+		optional(w.Complement),
+		optional(w.District),
+		optional(w.City),
+		optional(w.ZipCode),
+		w.Latitude,
+		w.Longitude,
+		w.VolumeCapacityLiters,
+		w.WeightCapacityKg,
+	).Scan(&w.Id, &w.Region, &w.Priority, &w.Street, &w.Number, &w.Complement, &w.District, &w.City, &w.State, &w.ZipCode, &w.Status, &w.CreatedAt)
 
 	if err != nil {
 		return nil, err
@@ -77,7 +136,8 @@ func (r *Repository) Create(w *warehouse.Warehouse) (*warehouse.Warehouse, error
 func (r *Repository) GetByID(id *uuid.UUID) (*warehouse.Warehouse, error) {
 	ctx := context.Background()
 	query := `
-		SELECT "Id", "Name", "Location", "CreatedAt"
+		SELECT "Id", "Name", "Location", "Region", "Priority", "Street", "Number", "Complement", "District", "City", "State", "ZipCode", "Latitude", "Longitude",
+		       "VolumeCapacityLiters", "WeightCapacityKg", "Status", "ClosedAt", "CreatedAt", "Version"
 		FROM "Warehouse"
 		WHERE "Id"=$1
 	`
@@ -86,7 +146,23 @@ func (r *Repository) GetByID(id *uuid.UUID) (*warehouse.Warehouse, error) {
 		&w.Id,
 		&w.Name,
 		&w.Location,
+		&w.Region,
+		&w.Priority,
+		&w.Street,
+		&w.Number,
+		&w.Complement,
+		&w.District,
+		&w.City,
+		&w.State,
+		&w.ZipCode,
+		&w.Latitude,
+		&w.Longitude,
+		&w.VolumeCapacityLiters,
+		&w.WeightCapacityKg,
+		&w.Status,
+		&w.ClosedAt,
 		&w.CreatedAt,
+		&w.Version,
 	)
 	if err != nil {
 		return nil, err
@@ -94,51 +170,462 @@ func (r *Repository) GetByID(id *uuid.UUID) (*warehouse.Warehouse, error) {
 	return &w, nil
 }
 
-func (r *Repository) Update(w *warehouse.Warehouse) error {
+// GetByIDs fetches the given warehouses in one query. Ids that do not exist
+// are left out of the result
+func (r *Repository) GetByIDs(ids []uuid.UUID) (*[]warehouse.Warehouse, error) {
+	ctx := context.Background()
+
+	idStrings := make([]string, 0, len(ids))
+	for _, id := range ids {
+		idStrings = append(idStrings, id.String())
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT "Id", "Name", "Location", "Region", "Priority", "Street", "Number", "Complement", "District", "City", "State", "ZipCode", "Latitude", "Longitude",
+		       "VolumeCapacityLiters", "WeightCapacityKg", "Status", "ClosedAt", "CreatedAt", "Version"
+		FROM "Warehouse"
+		WHERE "Id" = ANY($1::uuid[])
+	`, idStrings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warehouses []warehouse.Warehouse
+	for rows.Next() {
+		var w warehouse.Warehouse
+		if err := rows.Scan(
+			&w.Id,
+			&w.Name,
+			&w.Location,
+			&w.Region,
+			&w.Priority,
+			&w.Street,
+			&w.Number,
+			&w.Complement,
+			&w.District,
+			&w.City,
+			&w.State,
+			&w.ZipCode,
+			&w.Latitude,
+			&w.Longitude,
+			&w.VolumeCapacityLiters,
+			&w.WeightCapacityKg,
+			&w.Status,
+			&w.ClosedAt,
+			&w.CreatedAt,
+			&w.Version,
+		); err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, w)
+	}
+	return &warehouses, rows.Err()
+}
+
+// Update applies the given fields: a nil field is left unchanged and any other
+// value is written, with an empty string clearing the optional text fields.
+// "State" follows "Region". When expectedVersion is set the row is only
+// updated if its version still matches, otherwise ErrVersionMismatch is returned
+func (r *Repository) Update(w *warehouse.Warehouse, expectedVersion *int64) error {
 	ctx := context.Background()
 
 	setParts := []string{}
 	args := []any{}
 	argPos := 1
 
-	if w.Name != nil && *w.Name != "" {
+	if w.Name != nil {
 		setParts = append(setParts, `"Name"=$`+strconv.Itoa(argPos))
 		args = append(args, *w.Name)
 		argPos++
 	}
 
-	if w.Location != nil && *w.Location != "" {
+	if w.Location != nil {
 		setParts = append(setParts, `"Location"=$`+strconv.Itoa(argPos))
 		args = append(args, *w.Location)
 		argPos++
 	}
 
+	if w.Region != nil {
+		setParts = append(setParts, `"Region"=$`+strconv.Itoa(argPos))
+		args = append(args, optional(w.Region))
+		argPos++
+	}
+
+	if w.Priority != nil {
+		setParts = append(setParts, `"Priority"=$`+strconv.Itoa(argPos))
+		args = append(args, *w.Priority)
+		argPos++
+	}
+
+	if w.Street != nil {
+		setParts = append(setParts, `"Street"=$`+strconv.Itoa(argPos))
+		args = append(args, optional(w.Street))
+		argPos++
+	}
+
+	if w.Number != nil {
+		setParts = append(setParts, `"Number"=$`+strconv.Itoa(argPos))
+		args = append(args, optional(w.Number))
+		argPos++
+	}
+
+	if w.Complement != nil {
+		setParts = append(setParts, `"Complement"=$`+strconv.Itoa(argPos))
+		args = append(args, optional(w.Complement))
+		argPos++
+	}
+
+	if w.District != nil {
+		setParts = append(setParts, `"District"=$`+strconv.Itoa(argPos))
+		args = append(args, optional(w.District))
+		argPos++
+	}
+
+	if w.City != nil {
+		setParts = append(setParts, `"City"=$`+strconv.Itoa(argPos))
+		args = append(args, optional(w.City))
+		argPos++
+	}
+
+	if w.ZipCode != nil {
+		setParts = append(setParts, `"ZipCode"=$`+strconv.Itoa(argPos))
+		args = append(args, optional(w.ZipCode))
+		argPos++
+	}
+
+	if w.Latitude != nil {
+		setParts = append(setParts, `"Latitude"=$`+strconv.Itoa(argPos))
+		args = append(args, *w.Latitude)
+		argPos++
+	}
+
+	if w.Longitude != nil {
+		setParts = append(setParts, `"Longitude"=$`+strconv.Itoa(argPos))
+		args = append(args, *w.Longitude)
+		argPos++
+	}
+
+	if w.VolumeCapacityLiters != nil {
+		setParts = append(setParts, `"VolumeCapacityLiters"=$`+strconv.Itoa(argPos))
+		args = append(args, *w.VolumeCapacityLiters)
+		argPos++
+	}
+
+	if w.WeightCapacityKg != nil {
+		setParts = append(setParts, `"WeightCapacityKg"=$`+strconv.Itoa(argPos))
+		args = append(args, *w.WeightCapacityKg)
+		argPos++
+	}
+
 	if len(setParts) == 0 {
 		return nil
 	}
 
+	where := `"Id"=$` + strconv.Itoa(argPos)
+	args = append(args, w.Id)
+	if expectedVersion != nil {
+		where += ` AND "Version"=$` + strconv.Itoa(argPos+1)
+		args = append(args, *expectedVersion)
+	}
+
 	query := `
 		UPDATE "Warehouse"
 		SET ` + strings.Join(setParts, ", ") + `
-		WHERE "Id"=$` + strconv.Itoa(argPos) + `
-		RETURNING "CreatedAt"
+		WHERE ` + where + `
+		RETURNING "CreatedAt", "Version"
 	`
 
-	args = append(args, w.Id)
+	err := r.DB.QueryRow(ctx, query, args...).Scan(&w.CreatedAt, &w.Version)
+	if errors.Is(err, pgx.ErrNoRows) && expectedVersion != nil {
+		if _, getErr := r.GetByID(w.Id); getErr == nil {
+			return ErrVersionMismatch
+		}
+	}
+	return err
+}
+
+// ListWithProductAvailable returns the geolocated warehouses holding available stock of a product
+func (r *Repository) ListWithProductAvailable(productId *uuid.UUID) (*[]warehouse.NearestWarehouse, error) {
+	ctx := context.Background()
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT w."Id", w."Name", w."Location", w."Region", w."Priority", w."Street", w."Number", w."Complement",
+		       w."District", w."City", w."State", w."ZipCode", w."Latitude", w."Longitude",
+		       w."VolumeCapacityLiters", w."WeightCapacityKg", w."Status", w."ClosedAt", w."CreatedAt",
+		       si."Quantity" - si."Reserved"
+		FROM "Warehouse" w
+		JOIN "StockItems" si ON si."WarehouseId" = w."Id"
+		WHERE si."ProductId" = $1
+		  AND si."Quantity" - si."Reserved" > 0
+		  AND w."Status" = 'active'
+		  AND w."Latitude" IS NOT NULL
+		  AND w."Longitude" IS NOT NULL
+	`, *productId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warehouses []warehouse.NearestWarehouse
+	for rows.Next() {
+		var w warehouse.NearestWarehouse
+		if err := rows.Scan(
+			&w.Id,
+			&w.Name,
+			&w.Location,
+			&w.Region,
+			&w.Priority,
+			&w.Street,
+			&w.Number,
+			&w.Complement,
+			&w.District,
+			&w.City,
+			&w.State,
+			&w.ZipCode,
+			&w.Latitude,
+			&w.Longitude,
+			&w.VolumeCapacityLiters,
+			&w.WeightCapacityKg,
+			&w.Status,
+			&w.ClosedAt,
+			&w.CreatedAt,
+			&w.Available,
+		); err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, w)
+	}
+	return &warehouses, nil
+}
 
-	return r.DB.QueryRow(ctx, query, args...).Scan(&w.CreatedAt)
+// GetUtilization sums the volume and weight of the stock currently held by a warehouse
+func (r *Repository) GetUtilization(id *uuid.UUID) (*warehouse.Utilization, error) {
+	ctx := context.Background()
+	query := `
+		SELECT w."Id", w."VolumeCapacityLiters", w."WeightCapacityKg",
+		       (COALESCE(SUM(si."Quantity" * p."LengthMm" * p."WidthMm" * p."HeightMm"), 0) / 1e6)::float8,
+		       (COALESCE(SUM(si."Quantity" * p."WeightGrams"), 0) / 1e3)::float8,
+		       COUNT(si."ProductId") FILTER (
+		           WHERE si."Quantity" > 0
+		             AND (p."LengthMm" IS NULL OR p."WidthMm" IS NULL OR p."HeightMm" IS NULL OR p."WeightGrams" IS NULL)
+		       )
+		FROM "Warehouse" w
+		LEFT JOIN "StockItems" si ON si."WarehouseId" = w."Id"
+		LEFT JOIN "Product" p ON p."Id" = si."ProductId"
+		WHERE w."Id"=$1
+		GROUP BY w."Id"
+	`
+	var u warehouse.Utilization
+	err := r.DB.QueryRow(ctx, query, *id).Scan(
+		&u.WarehouseId,
+		&u.VolumeCapacityLiters,
+		&u.WeightCapacityKg,
+		&u.UsedVolumeLiters,
+		&u.UsedWeightKg,
+		&u.UnmeasuredItems,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
 }
 
-func (r *Repository) Delete(id *uuid.UUID) error {
+// Close marks a warehouse as closed. Remaining stock and reservations are moved to
+// the target warehouse, with ledger entries on both sides, and the lines of open
+// order reservations and TCC tries follow them; without a target the warehouse
+// must be empty.
+func (r *Repository) Close(id *uuid.UUID, targetId *uuid.UUID, closedBy *string) (int, error) {
 	ctx := context.Background()
 
-	_, err := r.DB.Exec(ctx, `
-		DELETE FROM "Warehouse"
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin close warehouse: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT "Status" FROM "Warehouse" WHERE "Id"=$1 FOR UPDATE
+	`, *id).Scan(&status)
+	if err != nil {
+		return 0, err
+	}
+	if status == warehouse.StatusClosed {
+		return 0, ErrWarehouseClosed
+	}
+
+	if targetId != nil {
+		var targetStatus string
+		err = tx.QueryRow(ctx, `
+			SELECT "Status" FROM "Warehouse" WHERE "Id"=$1 FOR SHARE
+		`, *targetId).Scan(&targetStatus)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && targetStatus != warehouse.StatusActive) {
+			return 0, ErrInvalidTarget
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		// reservations move with the stock, so they must be released or
+		// committed against the target from now on
+		err = retargetReservations(ctx, tx, "OrderReservation", "OrderId", order.StatusReserved, *id, *targetId)
+		if err != nil {
+			return 0, err
+		}
+		err = retargetReservations(ctx, tx, "TccTransaction", "TxId", tcc.StatusTried, *id, *targetId)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT "ProductId", "Quantity", "Reserved"
+		FROM "StockItems"
+		WHERE "WarehouseId"=$1 AND ("Quantity" > 0 OR "Reserved" > 0)
+		FOR UPDATE
+	`, *id)
+	if err != nil {
+		return 0, err
+	}
+	var items []stockItem
+	for rows.Next() {
+		var item stockItem
+		if err := rows.Scan(&item.productId, &item.quantity, &item.reserved); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+
+	if len(items) > 0 && targetId == nil {
+		return 0, ErrWarehouseNotEmpty
+	}
+
+	outReason := "Transferencia por encerramento do galpao " + id.String()
+	for _, item := range items {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO "StockItems" ("ProductId", "WarehouseId", "Quantity", "Reserved")
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT ("ProductId", "WarehouseId") DO UPDATE
+			SET "Quantity" = "StockItems"."Quantity" + EXCLUDED."Quantity",
+			    "Reserved" = "StockItems"."Reserved" + EXCLUDED."Reserved",
+			    "UpdatedAt" = now()
+		`, item.productId, *targetId, item.quantity, item.reserved)
+		if err != nil {
+			return 0, fmt.Errorf("transfer stock item: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE "StockItems"
+			SET "Quantity" = 0, "Reserved" = 0, "UpdatedAt" = now()
+			WHERE "ProductId"=$1 AND "WarehouseId"=$2
+		`, item.productId, *id)
+		if err != nil {
+			return 0, fmt.Errorf("empty stock item: %w", err)
+		}
+
+		transfer := map[string]any{"closed_warehouse_id": *id}
+		if err := outboxRepository.AppendStock(ctx, tx, outbox.StockAdjusted, item.productId, *id, transfer); err != nil {
+			return 0, err
+		}
+		if err := outboxRepository.AppendStock(ctx, tx, outbox.StockAdjusted, item.productId, *targetId, transfer); err != nil {
+			return 0, err
+		}
+
+		if item.quantity == 0 {
+			continue
+		}
+		var outId, inId uuid.UUID
+		err = tx.QueryRow(ctx, `
+			WITH moves AS (
+				INSERT INTO "StockMoves" ("ProductId", "WarehouseId", "QtyMoved", "Reason", "CreatedBy")
+				VALUES ($1, $2, $3, $4, $7), ($1, $5, $6, $4, $7)
+				RETURNING "Id", "WarehouseId"
+			)
+			SELECT (SELECT "Id" FROM moves WHERE "WarehouseId"=$2), (SELECT "Id" FROM moves WHERE "WarehouseId"=$5)
+		`, item.productId, *id, -item.quantity, outReason, *targetId, item.quantity, closedBy).Scan(&outId, &inId)
+		if err != nil {
+			return 0, fmt.Errorf("insert transfer stock moves: %w", err)
+		}
+		if err := outboxRepository.AppendMoves(ctx, tx, outId, inId); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE "Warehouse"
+		SET "Status" = 'closed', "ClosedAt" = now()
 		WHERE "Id"=$1
 	`, *id)
+	if err != nil {
+		return 0, fmt.Errorf("close warehouse: %w", err)
+	}
+
+	return len(items), tx.Commit(ctx)
+}
 
+// retargetReservations points the lines that open reservations in table hold
+// at the closed warehouse to the target one. key is the table's primary key
+// and open the status of reservations still holding stock. Rows are locked
+// before the stock items, in the same order the reservation owners use
+func retargetReservations(ctx context.Context, tx pgx.Tx, table string, key string, open string, from uuid.UUID, to uuid.UUID) error {
+	rows, err := tx.Query(ctx, `
+		SELECT "`+key+`", "Lines"
+		FROM "`+table+`"
+		WHERE "Status"=$1
+		  AND "Lines" @> jsonb_build_array(jsonb_build_object('warehouse_id', $2::text))
+		FOR UPDATE
+	`, open, from.String())
 	if err != nil {
-		return fmt.Errorf("delete warehouse: %w", err)
+		return fmt.Errorf("select %s: %w", table, err)
+	}
+	keys := []string{}
+	linesByKey := [][]stockitems.StockItemsBaixa{}
+	for rows.Next() {
+		var k string
+		var lines []stockitems.StockItemsBaixa
+		if err := rows.Scan(&k, &lines); err != nil {
+			rows.Close()
+			return err
+		}
+		keys = append(keys, k)
+		linesByKey = append(linesByKey, lines)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i, k := range keys {
+		if !stockitems.RetargetLines(linesByKey[i], from, to) {
+			continue
+		}
+		raw, err := json.Marshal(linesByKey[i])
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE "`+table+`" SET "Lines"=$2, "UpdatedAt"=now() WHERE "`+key+`"=$1
+		`, k, raw)
+		if err != nil {
+			return fmt.Errorf("retarget %s: %w", table, err)
+		}
 	}
 	return nil
 }
+
+type stockItem struct {
+	productId uuid.UUID
+	quantity  int64
+	reserved  int64
+}
+
+// optional grava a string vazia de um campo de texto opcional como NULL
+func optional(value *string) *string {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return value
+}
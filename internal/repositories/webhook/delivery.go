@@ -0,0 +1,151 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Attempt is a claimed delivery with what is needed to send it
+type Attempt struct {
+	Id             uuid.UUID
+	SubscriptionId uuid.UUID
+	EventId        uuid.UUID
+	EventType      string
+	Payload        json.RawMessage
+	Attempts       int
+	Url            string
+	Secret         string
+}
+
+// Claim takes up to limit due deliveries of active subscriptions and pushes
+// their next attempt lease into the future, so other instances skip them while
+// they are being sent. If the sender dies, the lease expires and the delivery
+// is retried
+func (r *Repository) Claim(limit int, lease time.Duration) ([]Attempt, error) {
+	ctx := context.Background()
+
+	rows, err := r.DB.Query(ctx, `
+		UPDATE "WebhookDelivery" d
+		SET "NextAttemptAt" = now() + make_interval(secs => $2)
+		FROM "WebhookSubscription" s
+		WHERE s."Id" = d."SubscriptionId"
+		  AND d."Id" IN (
+		      SELECT due."Id"
+		      FROM "WebhookDelivery" due
+		      JOIN "WebhookSubscription" active ON active."Id" = due."SubscriptionId"
+		      WHERE due."Status" = 'pending'
+		        AND due."NextAttemptAt" <= now()
+		        AND active."IsActive"
+		      ORDER BY due."NextAttemptAt"
+		      LIMIT $1
+		      FOR UPDATE OF due SKIP LOCKED
+		  )
+		RETURNING d."Id", d."SubscriptionId", d."EventId", d."EventType", d."Payload", d."Attempts", s."Url", s."Secret"
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []Attempt
+	for rows.Next() {
+		var a Attempt
+		if err := rows.Scan(&a.Id, &a.SubscriptionId, &a.EventId, &a.EventType, &a.Payload, &a.Attempts, &a.Url, &a.Secret); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+// RecordSuccess marks the delivery as delivered and resets the subscription
+// failure count
+func (r *Repository) RecordSuccess(a *Attempt, statusCode int) error {
+	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin record success: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE "WebhookDelivery"
+		SET "Status" = 'delivered',
+		    "Attempts" = "Attempts" + 1,
+		    "LastStatusCode" = $2,
+		    "LastError" = NULL,
+		    "DeliveredAt" = now()
+		WHERE "Id"=$1
+	`, a.Id, statusCode)
+	if err != nil {
+		return fmt.Errorf("mark delivery delivered: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE "WebhookSubscription"
+		SET "ConsecutiveFailures" = 0
+		WHERE "Id"=$1 AND "ConsecutiveFailures" <> 0
+	`, a.SubscriptionId)
+	if err != nil {
+		return fmt.Errorf("reset subscription failures: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RecordFailure schedules the next attempt with exponential backoff, starting
+// at baseDelay and capped at maxDelay, or marks the delivery as failed after
+// maxAttempts. The subscription failure count goes up and, when it reaches
+// disableAfter, the subscription is disabled with reason. statusCode is nil
+// when no response was received. Returns whether the subscription was disabled
+func (r *Repository) RecordFailure(a *Attempt, statusCode *int, errMsg string, maxAttempts int, baseDelay time.Duration, maxDelay time.Duration, disableAfter int, reason string) (bool, error) {
+	ctx := context.Background()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin record failure: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE "WebhookDelivery"
+		SET "Attempts" = "Attempts" + 1,
+		    "LastStatusCode" = $2,
+		    "LastError" = $3,
+		    "Status" = CASE WHEN "Attempts" + 1 >= $4 THEN 'failed' ELSE 'pending' END,
+		    "NextAttemptAt" = now() + least(make_interval(secs => $5) * power(2, "Attempts"), make_interval(secs => $6))
+		WHERE "Id"=$1
+	`, a.Id, statusCode, errMsg, maxAttempts, baseDelay.Seconds(), maxDelay.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("record delivery failure: %w", err)
+	}
+
+	var wasActive, isActive bool
+	err = tx.QueryRow(ctx, `
+		SELECT "IsActive" FROM "WebhookSubscription" WHERE "Id"=$1 FOR UPDATE
+	`, a.SubscriptionId).Scan(&wasActive)
+	if err != nil {
+		return false, fmt.Errorf("lock subscription: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE "WebhookSubscription"
+		SET "ConsecutiveFailures" = "ConsecutiveFailures" + 1,
+		    "IsActive" = "IsActive" AND "ConsecutiveFailures" + 1 < $2,
+		    "DisabledAt" = CASE WHEN "IsActive" AND "ConsecutiveFailures" + 1 >= $2 THEN now() ELSE "DisabledAt" END,
+		    "DisabledReason" = CASE WHEN "IsActive" AND "ConsecutiveFailures" + 1 >= $2 THEN $3 ELSE "DisabledReason" END
+		WHERE "Id"=$1
+		RETURNING "IsActive"
+	`, a.SubscriptionId, disableAfter, reason).Scan(&isActive)
+	if err != nil {
+		return false, fmt.Errorf("record subscription failure: %w", err)
+	}
+	disabled := wasActive && !isActive
+
+	return disabled, tx.Commit(ctx)
+}
@@ -0,0 +1,307 @@
+package webhook
+
+import (
+	"api-estoque/internal/config"
+	"api-estoque/internal/model/outbox"
+	"api-estoque/internal/model/pagination"
+	"api-estoque/internal/model/webhook"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// subscriptionColumns lists the subscription columns read by scanSubscription.
+// The secret is never selected here; only Claim reads it
+const subscriptionColumns = `
+	"Id", "Url", "EventTypes", "WarehouseIds"::text[], "ProductIds"::text[], "IsActive",
+	"ConsecutiveFailures", "DisabledAt", "DisabledReason", "CreatedBy", "CreatedAt", "UpdatedAt"
+`
+
+type Repository struct {
+	DB *pgxpool.Pool
+}
+
+func New() *Repository {
+	maxConns := 10
+	maxIdleTime := 30 * time.Second
+	maxLifetime := 2 * time.Minute
+
+	return &Repository{
+		DB: config.PostgresConn(maxConns, maxIdleTime, maxLifetime),
+	}
+}
+
+func (r *Repository) Create(s *webhook.Subscription) (*uuid.UUID, error) {
+	ctx := context.Background()
+
+	var id uuid.UUID
+	err := r.DB.QueryRow(ctx, `
+		INSERT INTO "WebhookSubscription" ("Url", "Secret", "EventTypes", "WarehouseIds", "ProductIds", "CreatedBy")
+		VALUES ($1, $2, $3, $4::uuid[], $5::uuid[], $6)
+		RETURNING "Id"
+	`, *s.Url, *s.Secret, s.EventTypes, uuidStrings(s.WarehouseIds), uuidStrings(s.ProductIds), s.CreatedBy).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// List returns every subscription, newest first
+func (r *Repository) List() (*[]webhook.Subscription, error) {
+	ctx := context.Background()
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+subscriptionColumns+`
+		FROM "WebhookSubscription"
+		ORDER BY "CreatedAt" DESC, "Id" DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []webhook.Subscription{}
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, *s)
+	}
+	return &subscriptions, rows.Err()
+}
+
+func (r *Repository) GetByID(id *uuid.UUID) (*webhook.Subscription, error) {
+	ctx := context.Background()
+
+	row := r.DB.QueryRow(ctx, `
+		SELECT `+subscriptionColumns+`
+		FROM "WebhookSubscription"
+		WHERE "Id"=$1
+	`, *id)
+	return scanSubscription(row)
+}
+
+// Update applies the given fields. Reactivating clears the failure count and
+// the disable reason; deactivating records reason. Returns pgx.ErrNoRows when
+// the subscription does not exist
+func (r *Repository) Update(s *webhook.Subscription, reason string) error {
+	ctx := context.Background()
+
+	setParts := []string{`"UpdatedAt"=now()`}
+	args := []any{}
+	argPos := 1
+
+	if s.Url != nil {
+		setParts = append(setParts, `"Url"=$`+strconv.Itoa(argPos))
+		args = append(args, *s.Url)
+		argPos++
+	}
+
+	if s.Secret != nil {
+		setParts = append(setParts, `"Secret"=$`+strconv.Itoa(argPos))
+		args = append(args, *s.Secret)
+		argPos++
+	}
+
+	if s.EventTypes != nil {
+		setParts = append(setParts, `"EventTypes"=$`+strconv.Itoa(argPos))
+		args = append(args, s.EventTypes)
+		argPos++
+	}
+
+	if s.WarehouseIds != nil {
+		setParts = append(setParts, `"WarehouseIds"=$`+strconv.Itoa(argPos)+`::uuid[]`)
+		args = append(args, uuidStrings(s.WarehouseIds))
+		argPos++
+	}
+
+	if s.ProductIds != nil {
+		setParts = append(setParts, `"ProductIds"=$`+strconv.Itoa(argPos)+`::uuid[]`)
+		args = append(args, uuidStrings(s.ProductIds))
+		argPos++
+	}
+
+	if s.IsActive != nil {
+		if *s.IsActive {
+			setParts = append(setParts, `"IsActive"=true`, `"ConsecutiveFailures"=0`, `"DisabledAt"=NULL`, `"DisabledReason"=NULL`)
+		} else {
+			// keeps the first reason when it was already disabled
+			setParts = append(setParts,
+				`"IsActive"=false`,
+				`"DisabledAt"=coalesce("DisabledAt", now())`,
+				`"DisabledReason"=coalesce("DisabledReason", $`+strconv.Itoa(argPos)+`)`,
+			)
+			args = append(args, reason)
+			argPos++
+		}
+	}
+
+	args = append(args, *s.Id)
+	tag, err := r.DB.Exec(ctx, `
+		UPDATE "WebhookSubscription"
+		SET `+strings.Join(setParts, ", ")+`
+		WHERE "Id"=$`+strconv.Itoa(argPos), args...)
+	if err != nil {
+		return fmt.Errorf("update webhook subscription: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Delete removes the subscription and its delivery log. Returns pgx.ErrNoRows
+// when the subscription does not exist
+func (r *Repository) Delete(id *uuid.UUID) error {
+	ctx := context.Background()
+
+	tag, err := r.DB.Exec(ctx, `
+		DELETE FROM "WebhookSubscription"
+		WHERE "Id"=$1
+	`, *id)
+	if err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Enqueue creates a pending delivery of the event for every active subscription
+// whose filters match it. An event already enqueued for a subscription is
+// skipped, so publishing the same event again is harmless
+func (r *Repository) Enqueue(e *outbox.Event) (int64, error) {
+	ctx := context.Background()
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		return 0, fmt.Errorf("marshal event: %w", err)
+	}
+
+	tag, err := r.DB.Exec(ctx, `
+		INSERT INTO "WebhookDelivery" ("SubscriptionId", "EventId", "EventType", "Payload")
+		SELECT s."Id", $1, $2, $3
+		FROM "WebhookSubscription" s
+		WHERE s."IsActive"
+		  AND (cardinality(s."EventTypes") = 0 OR $2 = ANY(s."EventTypes"))
+		  AND (cardinality(s."ProductIds") = 0 OR $4::uuid = ANY(s."ProductIds"))
+		  AND (cardinality(s."WarehouseIds") = 0 OR $5::uuid = ANY(s."WarehouseIds"))
+		ON CONFLICT ("SubscriptionId", "EventId") DO NOTHING
+	`, e.Id, e.Type, body, e.ProductId, e.WarehouseId)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue webhook deliveries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Deliveries returns one page of the subscription's delivery log, newest
+// first, optionally only those in status. It fetches one row past the limit so
+// the caller can tell whether there is a next page
+func (r *Repository) Deliveries(subscriptionId *uuid.UUID, status *string, page *pagination.Page) (*[]webhook.Delivery, error) {
+	ctx := context.Background()
+
+	afterCreatedAt, afterId, err := page.ByCreatedAt()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT "Id", "SubscriptionId", "EventId", "EventType", "Payload", "Status", "Attempts",
+		       "NextAttemptAt", "LastStatusCode", "LastError", "DeliveredAt", "CreatedAt"
+		FROM "WebhookDelivery"
+		WHERE "SubscriptionId"=$1
+		  AND ($2::text IS NULL OR "Status"=$2)
+		  AND ($3::timestamptz IS NULL OR ("CreatedAt", "Id") < ($3, $4))
+		ORDER BY "CreatedAt" DESC, "Id" DESC
+		LIMIT $5
+	`, *subscriptionId, status, afterCreatedAt, afterId, page.Fetch())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []webhook.Delivery{}
+	for rows.Next() {
+		var d webhook.Delivery
+		if err := rows.Scan(
+			&d.Id,
+			&d.SubscriptionId,
+			&d.EventId,
+			&d.EventType,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.LastStatusCode,
+			&d.LastError,
+			&d.DeliveredAt,
+			&d.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if *d.Status != webhook.DeliveryPending {
+			d.NextAttemptAt = nil
+		}
+		deliveries = append(deliveries, d)
+	}
+	return &deliveries, rows.Err()
+}
+
+func scanSubscription(row pgx.Row) (*webhook.Subscription, error) {
+	var s webhook.Subscription
+	var warehouseIds, productIds []string
+	err := row.Scan(
+		&s.Id,
+		&s.Url,
+		&s.EventTypes,
+		&warehouseIds,
+		&productIds,
+		&s.IsActive,
+		&s.ConsecutiveFailures,
+		&s.DisabledAt,
+		&s.DisabledReason,
+		&s.CreatedBy,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.WarehouseIds, err = parseUUIDs(warehouseIds); err != nil {
+		return nil, err
+	}
+	if s.ProductIds, err = parseUUIDs(productIds); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func parseUUIDs(values []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(values))
+	for _, value := range values {
+		id, err := uuid.FromString(value)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, id.String())
+	}
+	return out
+}
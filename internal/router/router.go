@@ -2,13 +2,23 @@ package router
 
 import (
 	_ "api-estoque/docs"
+	"api-estoque/internal/config"
 	"api-estoque/internal/controllers"
+	"api-estoque/internal/controllers/allocation"
+	"api-estoque/internal/controllers/category"
+	"api-estoque/internal/controllers/graph"
+	"api-estoque/internal/controllers/imports"
 	"api-estoque/internal/controllers/product"
 	stockitems "api-estoque/internal/controllers/stock_items"
 	stockmoves "api-estoque/internal/controllers/stock_moves"
+	"api-estoque/internal/controllers/stream"
+	"api-estoque/internal/controllers/tcc"
 	"api-estoque/internal/controllers/warehouse"
+	"api-estoque/internal/controllers/webhook"
 	middleware "api-estoque/internal/middleware/auth"
+	"api-estoque/internal/middleware/idempotency"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -22,9 +32,17 @@ type Router struct {
 	StockItemsController *stockitems.Controller
 	StockMovesController *stockmoves.Controller
 	ProductController    *product.Controller
+	AllocationController *allocation.Controller
+	CategoryController   *category.Controller
+	ImportsController    *imports.Controller
+	WebhookController    *webhook.Controller
+	StreamController     *stream.Controller
+	GraphController      *graph.Controller
+	TccController        *tcc.Controller
+	Idempotency          *idempotency.Middleware
 }
 
-func New(logger *logrus.Logger, controllers *controllers.Controllers) *Router {
+func New(logger *logrus.Logger, controllers *controllers.Controllers, idempotency *idempotency.Middleware) *Router {
 	return &Router{
 		Logger:               logger,
 		Router:               mux.NewRouter(),
@@ -32,6 +50,14 @@ func New(logger *logrus.Logger, controllers *controllers.Controllers) *Router {
 		StockItemsController: controllers.StockItemsController,
 		StockMovesController: controllers.StockMovesController,
 		ProductController:    controllers.ProductController,
+		AllocationController: controllers.AllocationController,
+		CategoryController:   controllers.CategoryController,
+		ImportsController:    controllers.ImportsController,
+		WebhookController:    controllers.WebhookController,
+		StreamController:     controllers.StreamController,
+		GraphController:      controllers.GraphController,
+		TccController:        controllers.TccController,
+		Idempotency:          idempotency,
 	}
 }
 
@@ -50,6 +76,14 @@ func (r *Router) AttachRoutes() {
 	r.AttachWarehouseRoutes()
 	r.AttachStockMovesRoutes()
 	r.AttachProductRoutes()
+	r.AttachAllocationRoutes()
+	r.AttachCategoryRoutes()
+	r.AttachImportRoutes()
+	r.AttachWebhookRoutes()
+	r.AttachStreamRoutes()
+	r.AttachGraphRoutes()
+	r.AttachTccRoutes()
+	r.AttachUploadRoutes()
 	r.Router.PathPrefix("/api/v1/estoque/swagger/").Handler(httpSwagger.WrapHandler)
 }
 
@@ -57,10 +91,11 @@ func (r *Router) AttachStockItemsRoutes() {
 	subrouter := r.Router.PathPrefix("/api/v1/estoque/stock-items").Subrouter()
 
 	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.StockItemsController.List))).Methods(http.MethodGet)
-	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.StockItemsController.Create))).Methods(http.MethodPost)
-	subrouter.Handle("/baixa", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.StockItemsController.DeductQuantity))).Methods(http.MethodPost)
+	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(r.Idempotency.Handle(http.HandlerFunc(r.StockItemsController.Create)))).Methods(http.MethodPost)
+	subrouter.Handle("/baixa", middleware.JWTAuthMiddleware("Administrador", "Manager")(r.Idempotency.Handle(http.HandlerFunc(r.StockItemsController.DeductQuantity)))).Methods(http.MethodPost)
+	subrouter.Handle("/export", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.StockItemsController.Export))).Methods(http.MethodGet)
 	subrouter.Handle("/{idWarehouse}/{idProduct}", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.StockItemsController.GetByID))).Methods(http.MethodGet)
-	subrouter.Handle("/{idWarehouse}/{idProduct}", middleware.JWTAuthMiddleware("Administrador")(http.HandlerFunc(r.StockItemsController.Update))).Methods(http.MethodPut)
+	subrouter.Handle("/{idWarehouse}/{idProduct}", middleware.JWTAuthMiddleware("Administrador")(r.Idempotency.Handle(http.HandlerFunc(r.StockItemsController.Update)))).Methods(http.MethodPut)
 	subrouter.Handle("/{idWarehouse}/{idProduct}", middleware.JWTAuthMiddleware("Administrador")(http.HandlerFunc(r.StockItemsController.Delete))).Methods(http.MethodDelete)
 }
 
@@ -68,8 +103,10 @@ func (r *Router) AttachStockMovesRoutes() {
 	subrouter := r.Router.PathPrefix("/api/v1/estoque/stock-move").Subrouter()
 
 	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.StockMovesController.List))).Methods(http.MethodGet)
-	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.StockMovesController.Create))).Methods(http.MethodPost)
+	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(r.Idempotency.Handle(http.HandlerFunc(r.StockMovesController.Create)))).Methods(http.MethodPost)
+	subrouter.Handle("/export", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.StockMovesController.Export))).Methods(http.MethodGet)
 	subrouter.Handle("/{id}", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.StockMovesController.GetByID))).Methods(http.MethodGet)
+	subrouter.Handle("/{id}/reverse", middleware.JWTAuthMiddleware("Administrador")(r.Idempotency.Handle(http.HandlerFunc(r.StockMovesController.Reverse)))).Methods(http.MethodPost)
 	subrouter.HandleFunc("/by-product/{idProduct}", r.StockMovesController.ListByProduct).Methods(http.MethodGet)
 	subrouter.HandleFunc("/by-warehouse/{idWarehouse}", r.StockMovesController.ListByWarehouse).Methods(http.MethodGet)
 	subrouter.HandleFunc("/by-warehouse-product/{idWarehouse}/{idProduct}", r.StockMovesController.ListByWarehouseAndProduct).Methods(http.MethodGet)
@@ -81,8 +118,10 @@ func (r *Router) AttachWarehouseRoutes() {
 	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.WarehouseController.List))).Methods(http.MethodGet)
 	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.WarehouseController.Create))).Methods(http.MethodPost)
 	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador")(http.HandlerFunc(r.WarehouseController.Update))).Methods(http.MethodPut)
+	subrouter.Handle("/nearest", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.WarehouseController.Nearest))).Methods(http.MethodGet)
 	subrouter.Handle("/{id}", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.WarehouseController.GetByID))).Methods(http.MethodGet)
-	subrouter.Handle("/{id}", middleware.JWTAuthMiddleware("Administrador")(http.HandlerFunc(r.WarehouseController.Delete))).Methods(http.MethodDelete)
+	subrouter.Handle("/{id}", middleware.JWTAuthMiddleware("Administrador")(http.HandlerFunc(r.WarehouseController.Close))).Methods(http.MethodDelete)
+	subrouter.Handle("/{id}/utilization", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.WarehouseController.Utilization))).Methods(http.MethodGet)
 }
 
 func (r *Router) AttachProductRoutes() {
@@ -91,6 +130,93 @@ func (r *Router) AttachProductRoutes() {
 	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.ProductController.List))).Methods(http.MethodGet)
 	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.ProductController.Create))).Methods(http.MethodPost)
 	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador")(http.HandlerFunc(r.ProductController.Update))).Methods(http.MethodPut)
+	subrouter.Handle("/bulk", middleware.JWTAuthMiddleware("Administrador", "Manager")(r.Idempotency.Handle(http.HandlerFunc(r.ProductController.BulkUpsert)))).Methods(http.MethodPost)
+	subrouter.Handle("/search", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.ProductController.Search))).Methods(http.MethodGet)
+	subrouter.Handle("/export", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.ProductController.Export))).Methods(http.MethodGet)
 	subrouter.Handle("/{id}", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.ProductController.GetByID))).Methods(http.MethodGet)
 	subrouter.Handle("/{id}", middleware.JWTAuthMiddleware("Administrador")(http.HandlerFunc(r.ProductController.Delete))).Methods(http.MethodDelete)
+	subrouter.Handle("/{id}/images", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.ProductController.UploadImage))).Methods(http.MethodPost)
+	subrouter.Handle("/{id}/price-history", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.ProductController.PriceHistory))).Methods(http.MethodGet)
+	subrouter.Handle("/{id}/price-schedule", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.ProductController.ListPriceSchedules))).Methods(http.MethodGet)
+	subrouter.Handle("/{id}/price-schedule", middleware.JWTAuthMiddleware("Administrador")(http.HandlerFunc(r.ProductController.CreatePriceSchedule))).Methods(http.MethodPost)
+	subrouter.Handle("/{id}/price-schedule/{scheduleId}", middleware.JWTAuthMiddleware("Administrador")(http.HandlerFunc(r.ProductController.CancelPriceSchedule))).Methods(http.MethodDelete)
+}
+
+func (r *Router) AttachImportRoutes() {
+	subrouter := r.Router.PathPrefix("/api/v1/estoque/import").Subrouter()
+
+	subrouter.Handle("/products", middleware.JWTAuthMiddleware("Administrador", "Manager")(r.Idempotency.Handle(http.HandlerFunc(r.ImportsController.Products)))).Methods(http.MethodPost)
+	subrouter.Handle("/stock-items", middleware.JWTAuthMiddleware("Administrador", "Manager")(r.Idempotency.Handle(http.HandlerFunc(r.ImportsController.StockItems)))).Methods(http.MethodPost)
+}
+
+// AttachUploadRoutes serve os arquivos enviados quando o armazenamento e local;
+// com S3 as URLs apontam direto para o bucket
+func (r *Router) AttachUploadRoutes() {
+	if config.Env.StorageBackend != "local" {
+		return
+	}
+
+	prefix := strings.TrimRight(config.Env.StoragePublicURL, "/") + "/"
+	if !strings.HasPrefix(prefix, "/") {
+		return
+	}
+	files := http.StripPrefix(prefix, http.FileServer(http.Dir(config.Env.StorageLocalDir)))
+	r.Router.PathPrefix(prefix).Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// sem listagem de diretorios
+		if strings.HasSuffix(req.URL.Path, "/") {
+			http.NotFound(w, req)
+			return
+		}
+		files.ServeHTTP(w, req)
+	})).Methods(http.MethodGet, http.MethodHead)
+}
+
+func (r *Router) AttachAllocationRoutes() {
+	subrouter := r.Router.PathPrefix("/api/v1/estoque/allocations").Subrouter()
+
+	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(r.Idempotency.Handle(http.HandlerFunc(r.AllocationController.Create)))).Methods(http.MethodPost)
+}
+
+func (r *Router) AttachCategoryRoutes() {
+	subrouter := r.Router.PathPrefix("/api/v1/estoque/categories").Subrouter()
+
+	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.CategoryController.List))).Methods(http.MethodGet)
+	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.CategoryController.Create))).Methods(http.MethodPost)
+	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador")(http.HandlerFunc(r.CategoryController.Update))).Methods(http.MethodPut)
+	subrouter.Handle("/report", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.CategoryController.Report))).Methods(http.MethodGet)
+	subrouter.Handle("/{id}", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.CategoryController.GetByID))).Methods(http.MethodGet)
+	subrouter.Handle("/{id}", middleware.JWTAuthMiddleware("Administrador")(http.HandlerFunc(r.CategoryController.Delete))).Methods(http.MethodDelete)
+}
+
+func (r *Router) AttachWebhookRoutes() {
+	subrouter := r.Router.PathPrefix("/api/v1/estoque/webhooks").Subrouter()
+
+	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.WebhookController.List))).Methods(http.MethodGet)
+	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.WebhookController.Create))).Methods(http.MethodPost)
+	subrouter.Handle("", middleware.JWTAuthMiddleware("Administrador")(http.HandlerFunc(r.WebhookController.Update))).Methods(http.MethodPut)
+	subrouter.Handle("/{id}", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.WebhookController.GetByID))).Methods(http.MethodGet)
+	subrouter.Handle("/{id}", middleware.JWTAuthMiddleware("Administrador")(http.HandlerFunc(r.WebhookController.Delete))).Methods(http.MethodDelete)
+	subrouter.Handle("/{id}/deliveries", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.WebhookController.Deliveries))).Methods(http.MethodGet)
+}
+
+func (r *Router) AttachStreamRoutes() {
+	subrouter := r.Router.PathPrefix("/api/v1/estoque/stream").Subrouter()
+
+	subrouter.Handle("/stock", middleware.StreamTokenMiddleware(middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.StreamController.SSE)))).Methods(http.MethodGet)
+	subrouter.Handle("/stock/ws", middleware.StreamTokenMiddleware(middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.StreamController.WebSocket)))).Methods(http.MethodGet)
+}
+
+func (r *Router) AttachGraphRoutes() {
+	r.Router.Handle("/api/v1/estoque/graphql", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.GraphController.Query))).Methods(http.MethodPost)
+}
+
+// AttachTccRoutes expoe o estoque como participante de transacoes TCC. As fases
+// ja sao idempotentes pelo id da transacao, sem precisar de Idempotency-Key
+func (r *Router) AttachTccRoutes() {
+	subrouter := r.Router.PathPrefix("/api/v1/estoque/tcc").Subrouter()
+
+	subrouter.Handle("/{txId}", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.TccController.GetByID))).Methods(http.MethodGet)
+	subrouter.Handle("/{txId}/try", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.TccController.Try))).Methods(http.MethodPost)
+	subrouter.Handle("/{txId}/confirm", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.TccController.Confirm))).Methods(http.MethodPost)
+	subrouter.Handle("/{txId}/cancel", middleware.JWTAuthMiddleware("Administrador", "Manager")(http.HandlerFunc(r.TccController.Cancel))).Methods(http.MethodPost)
 }
@@ -0,0 +1,208 @@
+package allocation
+
+import (
+	allocationModel "api-estoque/internal/model/allocation"
+	"api-estoque/internal/model/allocation/response/create"
+	stockitemsModel "api-estoque/internal/model/stock_items"
+	"api-estoque/internal/model/warehouse"
+	allocationRepo "api-estoque/internal/repositories/allocation"
+	stockitemsRepo "api-estoque/internal/repositories/stock_items"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/gofrs/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type Service struct {
+	Repository           *allocationRepo.Repository
+	StockItemsRepository *stockitemsRepo.Repository
+	Logger               *logrus.Logger
+}
+
+func New(repository *allocationRepo.Repository, stockItemsRepository *stockitemsRepo.Repository, logger *logrus.Logger) *Service {
+	return &Service{
+		Repository:           repository,
+		StockItemsRepository: stockItemsRepository,
+		Logger:               logger,
+	}
+}
+
+func (s *Service) Create(order *allocationModel.Order) *create.CreateResponse {
+	productIds := []uuid.UUID{}
+	for _, line := range order.Lines {
+		productIds = append(productIds, *line.ProductId)
+	}
+
+	candidates, err := s.Repository.ListCandidates(productIds)
+	if err != nil {
+		s.Logger.Errorf("(Allocation) Create - %v", err)
+		return &create.CreateResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao consultar estoque disponivel para alocacao",
+		}
+	}
+
+	lines := propose(order, *candidates)
+
+	fulfillable := true
+	for _, line := range lines {
+		if line.Unallocated > 0 {
+			fulfillable = false
+			break
+		}
+	}
+
+	if order.Reserve {
+		if !fulfillable {
+			return &create.CreateResponse{
+				Status: http.StatusConflict,
+				Msg:    "estoque insuficiente para reservar o pedido completo",
+			}
+		}
+
+		err = s.StockItemsRepository.ReserveQuantities(toReservations(lines))
+		if errors.Is(err, stockitemsRepo.ErrInsufficientStock) {
+			return &create.CreateResponse{
+				Status: http.StatusConflict,
+				Msg:    "saldo alterado durante a reserva, tente novamente",
+			}
+		}
+		if err != nil {
+			s.Logger.Errorf("(Allocation) Create - %v", err)
+			return &create.CreateResponse{
+				Status: http.StatusInternalServerError,
+				Msg:    "falha ao reservar estoque alocado",
+			}
+		}
+	}
+
+	return &create.CreateResponse{
+		Status:      http.StatusOK,
+		Msg:         "Sucesso",
+		Fulfillable: fulfillable,
+		Reserved:    order.Reserve,
+		Lines:       lines,
+	}
+}
+
+// propose distribui cada linha do pedido entre os galpoes candidatos. Um
+// galpao que atenda a linha inteira tem preferencia; caso nenhum atenda, a
+// linha e dividida seguindo a mesma ordem (faixa de distancia, prioridade,
+// distancia exata, saldo).
+func propose(order *allocationModel.Order, candidates []allocationModel.Candidate) []allocationModel.LineProposal {
+	byProduct := map[uuid.UUID][]*allocationModel.Candidate{}
+	for i := range candidates {
+		c := &candidates[i]
+		byProduct[c.ProductId] = append(byProduct[c.ProductId], c)
+	}
+
+	for _, list := range byProduct {
+		sort.SliceStable(list, func(i, j int) bool {
+			bi, ki := distance(order, list[i])
+			bj, kj := distance(order, list[j])
+			if bi != bj {
+				return bi < bj
+			}
+			if list[i].Priority != list[j].Priority {
+				return list[i].Priority < list[j].Priority
+			}
+			if ki != kj {
+				return ki < kj
+			}
+			return list[i].Available > list[j].Available
+		})
+	}
+
+	lines := make([]allocationModel.LineProposal, 0, len(order.Lines))
+	for _, line := range order.Lines {
+		proposal := allocationModel.LineProposal{
+			ProductId:   *line.ProductId,
+			Requested:   *line.Quantity,
+			Allocations: []allocationModel.Allocation{},
+		}
+		list := byProduct[*line.ProductId]
+
+		for _, c := range list {
+			if c.Available >= *line.Quantity {
+				c.Available -= *line.Quantity
+				proposal.Allocations = append(proposal.Allocations, allocationModel.Allocation{
+					WarehouseId: c.WarehouseId,
+					Quantity:    *line.Quantity,
+				})
+				proposal.Allocated = *line.Quantity
+				break
+			}
+		}
+
+		if proposal.Allocated == 0 {
+			remaining := *line.Quantity
+			for _, c := range list {
+				if remaining == 0 {
+					break
+				}
+				if c.Available == 0 {
+					continue
+				}
+				qty := min(c.Available, remaining)
+				c.Available -= qty
+				remaining -= qty
+				proposal.Allocations = append(proposal.Allocations, allocationModel.Allocation{
+					WarehouseId: c.WarehouseId,
+					Quantity:    qty,
+				})
+				proposal.Allocated += qty
+			}
+		}
+
+		proposal.Unallocated = proposal.Requested - proposal.Allocated
+		proposal.Split = len(proposal.Allocations) > 1
+		lines = append(lines, proposal)
+	}
+
+	return lines
+}
+
+// distance retorna uma faixa de distancia, para que a prioridade do galpao
+// desempate galpoes igualmente proximos, e a distancia em km quando ha
+// coordenadas no pedido e no galpao. Sem coordenadas a faixa vem da UF.
+func distance(order *allocationModel.Order, c *allocationModel.Candidate) (int, float64) {
+	if order.Latitude != nil && c.Latitude != nil {
+		km := warehouse.GreatCircleDistance(*order.Latitude, *order.Longitude, *c.Latitude, *c.Longitude)
+		switch {
+		case km <= 100:
+			return 0, km
+		case km <= 500:
+			return 1, km
+		case km <= 1500:
+			return 2, km
+		default:
+			return 3, km
+		}
+	}
+	if order.Region == nil {
+		return 0, 0
+	}
+	if c.Region == nil {
+		return 4, 0
+	}
+	return warehouse.RegionDistance(*order.Region, *c.Region), 0
+}
+
+func toReservations(lines []allocationModel.LineProposal) *[]stockitemsModel.StockItemsBaixa {
+	reservations := []stockitemsModel.StockItemsBaixa{}
+	for _, line := range lines {
+		for _, a := range line.Allocations {
+			productId := line.ProductId
+			warehouseId := a.WarehouseId
+			quantity := a.Quantity
+			reservations = append(reservations, stockitemsModel.StockItemsBaixa{
+				ProductId:   &productId,
+				WarehouseId: &warehouseId,
+				Quantity:    &quantity,
+			})
+		}
+	}
+	return &reservations
+}
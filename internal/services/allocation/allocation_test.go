@@ -0,0 +1,198 @@
+package allocation
+
+import (
+	allocationModel "api-estoque/internal/model/allocation"
+	"testing"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestPropose(t *testing.T) {
+	product := uuid.Must(uuid.NewV4())
+	sp := uuid.Must(uuid.NewV4())
+	sp2 := uuid.Must(uuid.NewV4())
+	rj := uuid.Must(uuid.NewV4())
+	ba := uuid.Must(uuid.NewV4())
+	noRegion := uuid.Must(uuid.NewV4())
+
+	uf := func(s string) *string { return &s }
+	coord := func(f float64) *float64 { return &f }
+	regions := map[uuid.UUID]*string{sp: uf("SP"), sp2: uf("SP"), rj: uf("RJ"), ba: uf("BA")}
+
+	candidate := func(warehouse uuid.UUID, priority int, available int64) allocationModel.Candidate {
+		return allocationModel.Candidate{
+			ProductId:   product,
+			WarehouseId: warehouse,
+			Region:      regions[warehouse],
+			Priority:    priority,
+			Available:   available,
+		}
+	}
+	located := func(warehouse uuid.UUID, priority int, available int64, lat float64, lng float64) allocationModel.Candidate {
+		c := candidate(warehouse, priority, available)
+		c.Latitude, c.Longitude = coord(lat), coord(lng)
+		return c
+	}
+	order := func(region *string, quantities ...int64) *allocationModel.Order {
+		o := &allocationModel.Order{Region: region}
+		for i := range quantities {
+			o.Lines = append(o.Lines, allocationModel.OrderLine{ProductId: &product, Quantity: &quantities[i]})
+		}
+		return o
+	}
+	// pedido na cidade de Sao Paulo
+	inSaoPaulo := func(quantities ...int64) *allocationModel.Order {
+		o := order(nil, quantities...)
+		o.Latitude, o.Longitude = coord(-23.5505), coord(-46.6333)
+		return o
+	}
+
+	type alloc struct {
+		warehouse uuid.UUID
+		quantity  int64
+	}
+
+	tests := []struct {
+		name        string
+		order       *allocationModel.Order
+		candidates  []allocationModel.Candidate
+		want        [][]alloc
+		unallocated []int64
+	}{
+		{
+			name:        "mesma UF antes de outra UF da regiao",
+			order:       order(uf("SP"), 5),
+			candidates:  []allocationModel.Candidate{candidate(rj, 0, 10), candidate(sp, 0, 10)},
+			want:        [][]alloc{{{sp, 5}}},
+			unallocated: []int64{0},
+		},
+		{
+			name:        "mesma regiao antes de outra regiao",
+			order:       order(uf("RJ"), 5),
+			candidates:  []allocationModel.Candidate{candidate(ba, 0, 10), candidate(sp, 0, 10)},
+			want:        [][]alloc{{{sp, 5}}},
+			unallocated: []int64{0},
+		},
+		{
+			name:        "galpao que atende a linha inteira vence o mais proximo",
+			order:       order(uf("SP"), 5),
+			candidates:  []allocationModel.Candidate{candidate(sp, 0, 3), candidate(rj, 0, 10)},
+			want:        [][]alloc{{{rj, 5}}},
+			unallocated: []int64{0},
+		},
+		{
+			name:        "prioridade desempata a mesma faixa",
+			order:       order(uf("SP"), 5),
+			candidates:  []allocationModel.Candidate{candidate(sp, 2, 10), candidate(sp2, 1, 10)},
+			want:        [][]alloc{{{sp2, 5}}},
+			unallocated: []int64{0},
+		},
+		{
+			name:        "saldo maior desempata a mesma prioridade",
+			order:       order(uf("SP"), 5),
+			candidates:  []allocationModel.Candidate{candidate(sp, 0, 6), candidate(sp2, 0, 20)},
+			want:        [][]alloc{{{sp2, 5}}},
+			unallocated: []int64{0},
+		},
+		{
+			name:        "galpao sem UF fica por ultimo",
+			order:       order(uf("SP"), 5),
+			candidates:  []allocationModel.Candidate{candidate(noRegion, 0, 10), candidate(ba, 0, 10)},
+			want:        [][]alloc{{{ba, 5}}},
+			unallocated: []int64{0},
+		},
+		{
+			name:        "dividida na ordem de distancia quando nenhum galpao atende",
+			order:       order(uf("SP"), 6),
+			candidates:  []allocationModel.Candidate{candidate(ba, 0, 4), candidate(rj, 0, 2), candidate(sp, 0, 3)},
+			want:        [][]alloc{{{sp, 3}, {rj, 2}, {ba, 1}}},
+			unallocated: []int64{0},
+		},
+		{
+			name:        "saldo insuficiente deixa o resto sem alocar",
+			order:       order(uf("SP"), 5),
+			candidates:  []allocationModel.Candidate{candidate(sp, 0, 2), candidate(rj, 0, 1)},
+			want:        [][]alloc{{{sp, 2}, {rj, 1}}},
+			unallocated: []int64{2},
+		},
+		{
+			name:        "sem candidatos",
+			order:       order(uf("SP"), 5),
+			candidates:  nil,
+			want:        [][]alloc{{}},
+			unallocated: []int64{5},
+		},
+		{
+			name:        "linhas do mesmo produto consomem o saldo em sequencia",
+			order:       order(uf("SP"), 4, 4),
+			candidates:  []allocationModel.Candidate{candidate(sp, 0, 5), candidate(rj, 0, 5)},
+			want:        [][]alloc{{{sp, 4}}, {{rj, 4}}},
+			unallocated: []int64{0, 0},
+		},
+		{
+			name:  "coordenadas: faixa de distancia antes da prioridade",
+			order: inSaoPaulo(5),
+			candidates: []allocationModel.Candidate{
+				located(rj, 0, 10, -22.9068, -43.1729),
+				located(sp, 5, 10, -22.9056, -47.0608),
+			},
+			want:        [][]alloc{{{sp, 5}}},
+			unallocated: []int64{0},
+		},
+		{
+			name:  "coordenadas: prioridade antes da distancia exata na mesma faixa",
+			order: inSaoPaulo(5),
+			candidates: []allocationModel.Candidate{
+				located(sp, 1, 10, -23.5614, -46.6559),
+				located(sp2, 0, 10, -22.9056, -47.0608),
+			},
+			want:        [][]alloc{{{sp2, 5}}},
+			unallocated: []int64{0},
+		},
+		{
+			name:  "coordenadas: distancia exata desempata a mesma prioridade",
+			order: inSaoPaulo(5),
+			candidates: []allocationModel.Candidate{
+				located(sp2, 0, 10, -22.9056, -47.0608),
+				located(sp, 0, 10, -23.5614, -46.6559),
+			},
+			want:        [][]alloc{{{sp, 5}}},
+			unallocated: []int64{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := propose(tt.order, tt.candidates)
+			if len(lines) != len(tt.want) {
+				t.Fatalf("propose() retornou %d linhas, want %d", len(lines), len(tt.want))
+			}
+
+			for i, line := range lines {
+				got := []alloc{}
+				for _, a := range line.Allocations {
+					got = append(got, alloc{a.WarehouseId, a.Quantity})
+				}
+				if len(got) != len(tt.want[i]) {
+					t.Fatalf("linha %d: alocacoes %v, want %v", i, got, tt.want[i])
+				}
+				for j := range got {
+					if got[j] != tt.want[i][j] {
+						t.Errorf("linha %d: alocacoes %v, want %v", i, got, tt.want[i])
+						break
+					}
+				}
+
+				if line.Unallocated != tt.unallocated[i] {
+					t.Errorf("linha %d: unallocated = %d, want %d", i, line.Unallocated, tt.unallocated[i])
+				}
+				if line.Allocated+line.Unallocated != line.Requested {
+					t.Errorf("linha %d: allocated %d + unallocated %d != requested %d", i, line.Allocated, line.Unallocated, line.Requested)
+				}
+				if line.Split != (len(tt.want[i]) > 1) {
+					t.Errorf("linha %d: split = %v, want %v", i, line.Split, len(tt.want[i]) > 1)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package category
+
+import (
+	categoryModel "api-estoque/internal/model/category"
+	"api-estoque/internal/model/category/response/create"
+	getbyid "api-estoque/internal/model/category/response/get_by_id"
+	"api-estoque/internal/model/category/response/list"
+	"api-estoque/internal/model/category/response/report"
+	httpresponse "api-estoque/internal/model/http_response"
+	categoryRepo "api-estoque/internal/repositories/category"
+	"errors"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+type Service struct {
+	Repository *categoryRepo.Repository
+	Logger     *logrus.Logger
+}
+
+func New(repository *categoryRepo.Repository, logger *logrus.Logger) *Service {
+	return &Service{
+		Repository: repository,
+		Logger:     logger,
+	}
+}
+
+// List retorna a arvore completa de categorias
+func (s *Service) List() *list.ListResponse {
+	categories, err := s.Repository.List()
+	if err != nil {
+		s.Logger.Errorf("(Category) List - %v", err)
+		return &list.ListResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao listar categorias",
+		}
+	}
+
+	return &list.ListResponse{
+		Status:     http.StatusOK,
+		Msg:        "Sucesso",
+		Categories: categoryModel.BuildTree(categories),
+	}
+}
+
+func (s *Service) Create(c *categoryModel.Category) *create.CreateResponse {
+	id, err := s.Repository.Create(c)
+	if errors.Is(err, categoryRepo.ErrDuplicateName) {
+		return &create.CreateResponse{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	}
+	if errors.Is(err, categoryRepo.ErrParentNotFound) {
+		return &create.CreateResponse{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(Category) Create - %v", err)
+		return &create.CreateResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao criar categoria",
+		}
+	}
+
+	return &create.CreateResponse{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+		Id:     *id,
+	}
+}
+
+// GetByID retorna a categoria com o caminho desde a raiz
+func (s *Service) GetByID(id *uuid.UUID) *getbyid.GetByIdResponse {
+	category, err := s.Repository.GetByID(id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &getbyid.GetByIdResponse{
+			Status: http.StatusNotFound,
+			Msg:    "categoria nao encontrada",
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(Category) GetByID - %v", err)
+		return &getbyid.GetByIdResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao buscar categoria",
+		}
+	}
+
+	path, err := s.Repository.Path(id)
+	if err != nil {
+		s.Logger.Errorf("(Category) GetByID - %v", err)
+		return &getbyid.GetByIdResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao buscar caminho da categoria",
+		}
+	}
+
+	return &getbyid.GetByIdResponse{
+		Status:    http.StatusOK,
+		Msg:       "Sucesso",
+		Id:        *category.Id,
+		Name:      *category.Name,
+		Slug:      *category.Slug,
+		ParentId:  category.ParentId,
+		CreatedAt: *category.CreatedAt,
+		Path:      path,
+	}
+}
+
+func (s *Service) Update(c *categoryModel.Category) *httpresponse.Response {
+	err := s.Repository.Update(c)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return &httpresponse.Response{
+			Status: http.StatusNotFound,
+			Msg:    "categoria nao encontrada",
+		}
+	case errors.Is(err, categoryRepo.ErrParentNotFound):
+		return &httpresponse.Response{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	case errors.Is(err, categoryRepo.ErrDuplicateName),
+		errors.Is(err, categoryRepo.ErrCycle):
+		return &httpresponse.Response{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	case err != nil:
+		s.Logger.Errorf("(Category) Update - %v", err)
+		return &httpresponse.Response{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao atualizar categoria",
+		}
+	}
+
+	return &httpresponse.Response{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+	}
+}
+
+// Delete remove apenas categorias sem subcategorias e sem produtos
+func (s *Service) Delete(id *uuid.UUID) *httpresponse.Response {
+	err := s.Repository.Delete(id)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return &httpresponse.Response{
+			Status: http.StatusNotFound,
+			Msg:    "categoria nao encontrada",
+		}
+	case errors.Is(err, categoryRepo.ErrHasChildren),
+		errors.Is(err, categoryRepo.ErrInUse):
+		return &httpresponse.Response{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	case err != nil:
+		s.Logger.Errorf("(Category) Delete - %v", err)
+		return &httpresponse.Response{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao remover categoria",
+		}
+	}
+
+	return &httpresponse.Response{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+	}
+}
+
+// Report retorna o estoque e o valor de estoque por categoria, somando as
+// subcategorias em cada no. Com categoryId, retorna apenas aquela subarvore.
+func (s *Service) Report(categoryId *uuid.UUID, warehouseId *uuid.UUID) *report.ReportResponse {
+	lines, err := s.Repository.StockReport(warehouseId)
+	if err != nil {
+		s.Logger.Errorf("(Category) Report - %v", err)
+		return &report.ReportResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao gerar relatorio de estoque por categoria",
+		}
+	}
+
+	roots := categoryModel.RollUp(lines)
+	if categoryId != nil {
+		subtree := categoryModel.Find(roots, *categoryId)
+		if subtree == nil {
+			return &report.ReportResponse{
+				Status: http.StatusNotFound,
+				Msg:    "categoria nao encontrada",
+			}
+		}
+		roots = []*categoryModel.ReportLine{subtree}
+	}
+
+	return &report.ReportResponse{
+		Status:     http.StatusOK,
+		Msg:        "Sucesso",
+		Categories: roots,
+	}
+}
@@ -0,0 +1,110 @@
+package graph
+
+import (
+	_ "embed"
+
+	graphModel "api-estoque/internal/model/graph"
+	"api-estoque/internal/model/pagination"
+	productRepo "api-estoque/internal/repositories/product"
+	stockitemsRepo "api-estoque/internal/repositories/stock_items"
+	stockmovesRepo "api-estoque/internal/repositories/stock_moves"
+	warehouseRepo "api-estoque/internal/repositories/warehouse"
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// maxDepth limita o aninhamento das consultas, ja que cada nivel de relacao
+// pode multiplicar as linhas lidas
+const maxDepth = 8
+
+// Service resolve as consultas GraphQL. As relacoes sao carregadas pelos
+// loaders de cada requisicao, que juntam as chaves pedidas por resolvers
+// paralelos numa unica consulta ao repositorio.
+type Service struct {
+	ProductRepository    *productRepo.Repository
+	WarehouseRepository  *warehouseRepo.Repository
+	StockItemsRepository *stockitemsRepo.Repository
+	StockMovesRepository *stockmovesRepo.Repository
+	Logger               *logrus.Logger
+	Schema               *graphql.Schema
+}
+
+func New(productRepository *productRepo.Repository, warehouseRepository *warehouseRepo.Repository, stockItemsRepository *stockitemsRepo.Repository, stockMovesRepository *stockmovesRepo.Repository, logger *logrus.Logger) *Service {
+	s := &Service{
+		ProductRepository:    productRepository,
+		WarehouseRepository:  warehouseRepository,
+		StockItemsRepository: stockItemsRepository,
+		StockMovesRepository: stockMovesRepository,
+		Logger:               logger,
+	}
+
+	parsed, err := graphql.ParseSchema(schema, &queryResolver{s: s},
+		graphql.MaxDepth(maxDepth),
+		graphql.MaxParallelism(pagination.MaxLimit),
+	)
+	if err != nil {
+		logger.Fatalf("(Graph) schema GraphQL invalido: %v", err)
+	}
+	s.Schema = parsed
+	return s
+}
+
+// Execute roda a consulta com loaders novos, que valem so para esta requisicao
+func (s *Service) Execute(ctx context.Context, req *graphModel.Request) *graphql.Response {
+	ctx = withLoaders(ctx, s.newLoaders())
+	return s.Schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+}
+
+// Long e o escalar de inteiros de 64 bits, ja que o Int do GraphQL tem 32
+type Long int64
+
+func (Long) ImplementsGraphQLType(name string) bool {
+	return name == "Long"
+}
+
+func (l *Long) UnmarshalGraphQL(input any) error {
+	switch v := input.(type) {
+	case int32:
+		*l = Long(v)
+	case int64:
+		*l = Long(v)
+	case float64:
+		*l = Long(v)
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return errors.New("valor Long invalido")
+		}
+		*l = Long(n)
+	default:
+		return errors.New("valor Long invalido")
+	}
+	return nil
+}
+
+func (l Long) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(l), 10), nil
+}
+
+func toLong(v *int64) *Long {
+	if v == nil {
+		return nil
+	}
+	l := Long(*v)
+	return &l
+}
+
+func deref[T any](v *T) T {
+	var zero T
+	if v == nil {
+		return zero
+	}
+	return *v
+}
@@ -0,0 +1,214 @@
+package graph
+
+import (
+	"api-estoque/internal/config"
+	productModel "api-estoque/internal/model/product"
+	stockitemsModel "api-estoque/internal/model/stock_items"
+	stockmovesModel "api-estoque/internal/model/stock_moves"
+	warehouseModel "api-estoque/internal/model/warehouse"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// loaderWait e quanto cada loader espera por mais chaves antes de consultar o
+// repositorio. Os resolvers de uma lista rodam em paralelo, entao as chaves
+// chegam quase juntas.
+const loaderWait = 2 * time.Millisecond
+
+var errLoad = errors.New("falha ao consultar dados")
+
+type loadersKey struct{}
+
+// movesKey pede as limit movimentacoes mais recentes de um produto, de um
+// galpao ou de um item de estoque; os ids que nao filtram ficam zerados
+type movesKey struct {
+	ProductId   uuid.UUID
+	WarehouseId uuid.UUID
+	Limit       int
+}
+
+type loaders struct {
+	products         *dataloader.Loader[uuid.UUID, *productModel.Product]
+	warehouses       *dataloader.Loader[uuid.UUID, *warehouseModel.Warehouse]
+	moves            *dataloader.Loader[uuid.UUID, *stockmovesModel.StockMove]
+	itemsByProduct   *dataloader.Loader[uuid.UUID, []stockitemsModel.StockItems]
+	itemsByWarehouse *dataloader.Loader[uuid.UUID, []stockitemsModel.StockItems]
+	recentMoves      *dataloader.Loader[movesKey, []stockmovesModel.StockMove]
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+func newLoader[K comparable, V any](batch dataloader.BatchFunc[K, V]) *dataloader.Loader[K, V] {
+	return dataloader.NewBatchedLoader(batch, dataloader.WithWait[K, V](loaderWait))
+}
+
+func (s *Service) newLoaders() *loaders {
+	return &loaders{
+		products:         newLoader(s.loadProducts),
+		warehouses:       newLoader(s.loadWarehouses),
+		moves:            newLoader(s.loadMoves),
+		itemsByProduct:   newLoader(s.loadItemsByProduct),
+		itemsByWarehouse: newLoader(s.loadItemsByWarehouse),
+		recentMoves:      newLoader(s.loadRecentMoves),
+	}
+}
+
+func (s *Service) loadProducts(ctx context.Context, ids []uuid.UUID) []*dataloader.Result[*productModel.Product] {
+	products, err := s.ProductRepository.GetByIDs(ids)
+	if err != nil {
+		s.Logger.Errorf("(Graph) loadProducts - %v", err)
+		return failAll[*productModel.Product](len(ids))
+	}
+
+	byId := make(map[uuid.UUID]*productModel.Product, len(*products))
+	for i := range *products {
+		p := &(*products)[i]
+		p.SelectCurrency(config.Env.DefaultCurrency, config.Env.DefaultCurrency)
+		byId[*p.Id] = p
+	}
+	return resultsFor(ids, byId)
+}
+
+func (s *Service) loadWarehouses(ctx context.Context, ids []uuid.UUID) []*dataloader.Result[*warehouseModel.Warehouse] {
+	warehouses, err := s.WarehouseRepository.GetByIDs(ids)
+	if err != nil {
+		s.Logger.Errorf("(Graph) loadWarehouses - %v", err)
+		return failAll[*warehouseModel.Warehouse](len(ids))
+	}
+
+	byId := make(map[uuid.UUID]*warehouseModel.Warehouse, len(*warehouses))
+	for i := range *warehouses {
+		byId[*(*warehouses)[i].Id] = &(*warehouses)[i]
+	}
+	return resultsFor(ids, byId)
+}
+
+func (s *Service) loadMoves(ctx context.Context, ids []uuid.UUID) []*dataloader.Result[*stockmovesModel.StockMove] {
+	moves, err := s.StockMovesRepository.GetByIDs(ids)
+	if err != nil {
+		s.Logger.Errorf("(Graph) loadMoves - %v", err)
+		return failAll[*stockmovesModel.StockMove](len(ids))
+	}
+
+	byId := make(map[uuid.UUID]*stockmovesModel.StockMove, len(*moves))
+	for i := range *moves {
+		byId[*(*moves)[i].Id] = &(*moves)[i]
+	}
+	return resultsFor(ids, byId)
+}
+
+func (s *Service) loadItemsByProduct(ctx context.Context, ids []uuid.UUID) []*dataloader.Result[[]stockitemsModel.StockItems] {
+	items, err := s.StockItemsRepository.ListByProducts(ids)
+	if err != nil {
+		s.Logger.Errorf("(Graph) loadItemsByProduct - %v", err)
+		return failAll[[]stockitemsModel.StockItems](len(ids))
+	}
+
+	grouped := map[uuid.UUID][]stockitemsModel.StockItems{}
+	for _, item := range *items {
+		grouped[*item.ProductId] = append(grouped[*item.ProductId], item)
+	}
+	return groupsFor(ids, grouped)
+}
+
+func (s *Service) loadItemsByWarehouse(ctx context.Context, ids []uuid.UUID) []*dataloader.Result[[]stockitemsModel.StockItems] {
+	items, err := s.StockItemsRepository.ListByWarehouses(ids)
+	if err != nil {
+		s.Logger.Errorf("(Graph) loadItemsByWarehouse - %v", err)
+		return failAll[[]stockitemsModel.StockItems](len(ids))
+	}
+
+	grouped := map[uuid.UUID][]stockitemsModel.StockItems{}
+	for _, item := range *items {
+		grouped[*item.WarehouseId] = append(grouped[*item.WarehouseId], item)
+	}
+	return groupsFor(ids, grouped)
+}
+
+// loadRecentMoves separa as chaves por tipo de filtro e por limite, ja que cada
+// combinacao e uma consulta, e devolve a cada chave as suas movimentacoes
+func (s *Service) loadRecentMoves(ctx context.Context, keys []movesKey) []*dataloader.Result[[]stockmovesModel.StockMove] {
+	type query struct {
+		byProduct   bool
+		byWarehouse bool
+		limit       int
+	}
+	queries := map[query][]movesKey{}
+	for _, k := range keys {
+		q := query{byProduct: k.ProductId != uuid.Nil, byWarehouse: k.WarehouseId != uuid.Nil, limit: k.Limit}
+		queries[q] = append(queries[q], k)
+	}
+
+	grouped := map[movesKey][]stockmovesModel.StockMove{}
+	for q, qKeys := range queries {
+		productIds := make([]uuid.UUID, 0, len(qKeys))
+		warehouseIds := make([]uuid.UUID, 0, len(qKeys))
+		for _, k := range qKeys {
+			productIds = append(productIds, k.ProductId)
+			warehouseIds = append(warehouseIds, k.WarehouseId)
+		}
+
+		var moves *[]stockmovesModel.StockMove
+		var err error
+		switch {
+		case q.byProduct && q.byWarehouse:
+			moves, err = s.StockMovesRepository.RecentByItems(productIds, warehouseIds, q.limit)
+		case q.byProduct:
+			moves, err = s.StockMovesRepository.RecentByProducts(productIds, q.limit)
+		default:
+			moves, err = s.StockMovesRepository.RecentByWarehouses(warehouseIds, q.limit)
+		}
+		if err != nil {
+			s.Logger.Errorf("(Graph) loadRecentMoves - %v", err)
+			return failAll[[]stockmovesModel.StockMove](len(keys))
+		}
+
+		for _, m := range *moves {
+			k := movesKey{Limit: q.limit}
+			if q.byProduct {
+				k.ProductId = *m.ProductId
+			}
+			if q.byWarehouse {
+				k.WarehouseId = *m.WarehouseId
+			}
+			grouped[k] = append(grouped[k], m)
+		}
+	}
+	return groupsFor(keys, grouped)
+}
+
+// resultsFor devolve o valor de cada chave na ordem pedida; chaves sem valor
+// recebem nil, que os resolvers tratam como nao encontrado
+func resultsFor[K comparable, V any](keys []K, values map[K]*V) []*dataloader.Result[*V] {
+	results := make([]*dataloader.Result[*V], len(keys))
+	for i, k := range keys {
+		results[i] = &dataloader.Result[*V]{Data: values[k]}
+	}
+	return results
+}
+
+func groupsFor[K comparable, V any](keys []K, groups map[K][]V) []*dataloader.Result[[]V] {
+	results := make([]*dataloader.Result[[]V], len(keys))
+	for i, k := range keys {
+		results[i] = &dataloader.Result[[]V]{Data: groups[k]}
+	}
+	return results
+}
+
+func failAll[V any](n int) []*dataloader.Result[V] {
+	results := make([]*dataloader.Result[V], n)
+	for i := range results {
+		results[i] = &dataloader.Result[V]{Error: errLoad}
+	}
+	return results
+}
@@ -0,0 +1,446 @@
+package graph
+
+import (
+	"api-estoque/internal/config"
+	"api-estoque/internal/model/pagination"
+	productModel "api-estoque/internal/model/product"
+	stockitemsModel "api-estoque/internal/model/stock_items"
+	stockmovesModel "api-estoque/internal/model/stock_moves"
+	warehouseModel "api-estoque/internal/model/warehouse"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/jackc/pgx/v5"
+)
+
+type queryResolver struct {
+	s *Service
+}
+
+func (r *queryResolver) Product(ctx context.Context, args struct{ Id graphql.ID }) (*productResolver, error) {
+	id, err := parseId(args.Id, "id")
+	if err != nil {
+		return nil, err
+	}
+	p, err := loadersFrom(ctx).products.Load(ctx, id)()
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return &productResolver{p: p}, nil
+}
+
+func (r *queryResolver) Products(ctx context.Context, args struct {
+	First int32
+	After *string
+}) (*productPageResolver, error) {
+	page, err := pageFrom(args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := r.s.ProductRepository.List(page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return nil, err
+	}
+	if err != nil {
+		r.s.Logger.Errorf("(Graph) Products - %v", err)
+		return nil, errors.New("falha ao listar produtos")
+	}
+
+	res := &productPageResolver{}
+	if pagination.Trim(products, page.Limit) {
+		last := (*products)[len(*products)-1]
+		res.nextCursor = pagination.Encode(&pagination.Cursor{CreatedAt: last.CreatedAt, Id: last.Id})
+	}
+	for i := range *products {
+		p := &(*products)[i]
+		p.SelectCurrency(config.Env.DefaultCurrency, config.Env.DefaultCurrency)
+		loadersFrom(ctx).products.Prime(ctx, *p.Id, p)
+		res.items = append(res.items, &productResolver{p: p})
+	}
+	return res, nil
+}
+
+func (r *queryResolver) Warehouse(ctx context.Context, args struct{ Id graphql.ID }) (*warehouseResolver, error) {
+	id, err := parseId(args.Id, "id")
+	if err != nil {
+		return nil, err
+	}
+	w, err := loadersFrom(ctx).warehouses.Load(ctx, id)()
+	if err != nil || w == nil {
+		return nil, err
+	}
+	return &warehouseResolver{w: w}, nil
+}
+
+func (r *queryResolver) Warehouses(ctx context.Context, args struct {
+	First         int32
+	After         *string
+	IncludeClosed bool
+}) (*warehousePageResolver, error) {
+	page, err := pageFrom(args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouses, err := r.s.WarehouseRepository.List(args.IncludeClosed, page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return nil, err
+	}
+	if err != nil {
+		r.s.Logger.Errorf("(Graph) Warehouses - %v", err)
+		return nil, errors.New("falha ao listar galpoes")
+	}
+
+	res := &warehousePageResolver{}
+	if pagination.Trim(warehouses, page.Limit) {
+		last := (*warehouses)[len(*warehouses)-1]
+		res.nextCursor = pagination.Encode(&pagination.Cursor{CreatedAt: last.CreatedAt, Id: last.Id})
+	}
+	for i := range *warehouses {
+		w := &(*warehouses)[i]
+		loadersFrom(ctx).warehouses.Prime(ctx, *w.Id, w)
+		res.items = append(res.items, &warehouseResolver{w: w})
+	}
+	return res, nil
+}
+
+func (r *queryResolver) StockItem(ctx context.Context, args struct {
+	ProductId   graphql.ID
+	WarehouseId graphql.ID
+}) (*stockItemResolver, error) {
+	idProduct, err := parseId(args.ProductId, "productId")
+	if err != nil {
+		return nil, err
+	}
+	idWarehouse, err := parseId(args.WarehouseId, "warehouseId")
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := r.s.StockItemsRepository.GetByID(&idWarehouse, &idProduct)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		r.s.Logger.Errorf("(Graph) StockItem - %v", err)
+		return nil, errors.New("falha ao buscar item de estoque")
+	}
+	return &stockItemResolver{i: item}, nil
+}
+
+func (r *queryResolver) StockMove(ctx context.Context, args struct{ Id graphql.ID }) (*stockMoveResolver, error) {
+	id, err := parseId(args.Id, "id")
+	if err != nil {
+		return nil, err
+	}
+	m, err := loadersFrom(ctx).moves.Load(ctx, id)()
+	if err != nil || m == nil {
+		return nil, err
+	}
+	return &stockMoveResolver{m: m}, nil
+}
+
+type productPageResolver struct {
+	items      []*productResolver
+	nextCursor *string
+}
+
+func (r *productPageResolver) Items() []*productResolver { return r.items }
+func (r *productPageResolver) NextCursor() *string       { return r.nextCursor }
+
+type warehousePageResolver struct {
+	items      []*warehouseResolver
+	nextCursor *string
+}
+
+func (r *warehousePageResolver) Items() []*warehouseResolver { return r.items }
+func (r *warehousePageResolver) NextCursor() *string         { return r.nextCursor }
+
+type productResolver struct {
+	p *productModel.Product
+}
+
+func (r *productResolver) ID() graphql.ID      { return graphql.ID(r.p.Id.String()) }
+func (r *productResolver) Sku() *string        { return r.p.Sku }
+func (r *productResolver) Name() string        { return deref(r.p.Name) }
+func (r *productResolver) Description() string { return deref(r.p.Description) }
+func (r *productResolver) Price() *Long        { return toLong(r.p.Price) }
+func (r *productResolver) Currency() string    { return deref(r.p.Currency) }
+func (r *productResolver) CategoryId() graphql.ID {
+	return graphql.ID(r.p.CategoryId.String())
+}
+func (r *productResolver) Category() string   { return deref(r.p.Category) }
+func (r *productResolver) Status() string     { return deref(r.p.Status) }
+func (r *productResolver) IsActive() bool     { return deref(r.p.IsActive) }
+func (r *productResolver) LengthMm() *Long    { return toLong(r.p.LengthMm) }
+func (r *productResolver) WidthMm() *Long     { return toLong(r.p.WidthMm) }
+func (r *productResolver) HeightMm() *Long    { return toLong(r.p.HeightMm) }
+func (r *productResolver) WeightGrams() *Long { return toLong(r.p.WeightGrams) }
+func (r *productResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: deref(r.p.CreatedAt)}
+}
+
+func (r *productResolver) Prices() []*priceResolver {
+	var out []*priceResolver
+	for _, p := range deref(r.p.Prices) {
+		out = append(out, &priceResolver{p: p})
+	}
+	return out
+}
+
+func (r *productResolver) Images() []*imageResolver {
+	var out []*imageResolver
+	for _, img := range deref(r.p.Images) {
+		out = append(out, &imageResolver{i: img})
+	}
+	return out
+}
+
+func (r *productResolver) StockItems(ctx context.Context) ([]*stockItemResolver, error) {
+	items, err := loadersFrom(ctx).itemsByProduct.Load(ctx, *r.p.Id)()
+	if err != nil {
+		return nil, err
+	}
+	return toStockItems(items), nil
+}
+
+func (r *productResolver) Moves(ctx context.Context, args struct{ First int32 }) ([]*stockMoveResolver, error) {
+	limit, err := movesLimit(args.First)
+	if err != nil {
+		return nil, err
+	}
+	moves, err := loadersFrom(ctx).recentMoves.Load(ctx, movesKey{ProductId: *r.p.Id, Limit: limit})()
+	if err != nil {
+		return nil, err
+	}
+	return toStockMoves(moves), nil
+}
+
+type priceResolver struct {
+	p productModel.Price
+}
+
+func (r *priceResolver) Currency() string { return r.p.Currency }
+func (r *priceResolver) Amount() Long     { return Long(r.p.Amount) }
+func (r *priceResolver) MinorUnits() int32 {
+	return int32(r.p.MinorUnits)
+}
+func (r *priceResolver) Display() string { return r.p.Display }
+
+type imageResolver struct {
+	i productModel.Image
+}
+
+func (r *imageResolver) Url() string { return r.i.Url }
+func (r *imageResolver) ThumbnailUrl() *string {
+	if r.i.ThumbnailUrl == "" {
+		return nil
+	}
+	return &r.i.ThumbnailUrl
+}
+func (r *imageResolver) Alt() string     { return r.i.Alt }
+func (r *imageResolver) Position() int32 { return int32(r.i.Position) }
+func (r *imageResolver) Primary() bool   { return r.i.Primary }
+
+type warehouseResolver struct {
+	w *warehouseModel.Warehouse
+}
+
+func (r *warehouseResolver) ID() graphql.ID      { return graphql.ID(r.w.Id.String()) }
+func (r *warehouseResolver) Name() string        { return deref(r.w.Name) }
+func (r *warehouseResolver) Location() *string   { return r.w.Location }
+func (r *warehouseResolver) Region() *string     { return r.w.Region }
+func (r *warehouseResolver) Street() *string     { return r.w.Street }
+func (r *warehouseResolver) Number() *string     { return r.w.Number }
+func (r *warehouseResolver) Complement() *string { return r.w.Complement }
+func (r *warehouseResolver) District() *string   { return r.w.District }
+func (r *warehouseResolver) City() *string       { return r.w.City }
+func (r *warehouseResolver) State() *string      { return r.w.State }
+func (r *warehouseResolver) ZipCode() *string    { return r.w.ZipCode }
+func (r *warehouseResolver) Latitude() *float64  { return r.w.Latitude }
+func (r *warehouseResolver) Longitude() *float64 { return r.w.Longitude }
+func (r *warehouseResolver) Status() string      { return deref(r.w.Status) }
+func (r *warehouseResolver) VolumeCapacityLiters() *Long {
+	return toLong(r.w.VolumeCapacityLiters)
+}
+func (r *warehouseResolver) WeightCapacityKg() *Long {
+	return toLong(r.w.WeightCapacityKg)
+}
+
+func (r *warehouseResolver) Priority() *int32 {
+	if r.w.Priority == nil {
+		return nil
+	}
+	p := int32(*r.w.Priority)
+	return &p
+}
+
+func (r *warehouseResolver) ClosedAt() *graphql.Time  { return toTime(r.w.ClosedAt) }
+func (r *warehouseResolver) CreatedAt() *graphql.Time { return toTime(r.w.CreatedAt) }
+
+func (r *warehouseResolver) StockItems(ctx context.Context) ([]*stockItemResolver, error) {
+	items, err := loadersFrom(ctx).itemsByWarehouse.Load(ctx, *r.w.Id)()
+	if err != nil {
+		return nil, err
+	}
+	return toStockItems(items), nil
+}
+
+func (r *warehouseResolver) Moves(ctx context.Context, args struct{ First int32 }) ([]*stockMoveResolver, error) {
+	limit, err := movesLimit(args.First)
+	if err != nil {
+		return nil, err
+	}
+	moves, err := loadersFrom(ctx).recentMoves.Load(ctx, movesKey{WarehouseId: *r.w.Id, Limit: limit})()
+	if err != nil {
+		return nil, err
+	}
+	return toStockMoves(moves), nil
+}
+
+type stockItemResolver struct {
+	i *stockitemsModel.StockItems
+}
+
+func (r *stockItemResolver) Product(ctx context.Context) (*productResolver, error) {
+	return loadProduct(ctx, *r.i.ProductId)
+}
+
+func (r *stockItemResolver) Warehouse(ctx context.Context) (*warehouseResolver, error) {
+	return loadWarehouse(ctx, *r.i.WarehouseId)
+}
+
+func (r *stockItemResolver) Quantity() Long { return Long(deref(r.i.Quantity)) }
+func (r *stockItemResolver) Reserved() Long { return Long(deref(r.i.Reserved)) }
+func (r *stockItemResolver) Available() Long {
+	return Long(deref(r.i.Quantity) - deref(r.i.Reserved))
+}
+func (r *stockItemResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: deref(r.i.UpdatedAt)}
+}
+
+func (r *stockItemResolver) Moves(ctx context.Context, args struct{ First int32 }) ([]*stockMoveResolver, error) {
+	limit, err := movesLimit(args.First)
+	if err != nil {
+		return nil, err
+	}
+	key := movesKey{ProductId: *r.i.ProductId, WarehouseId: *r.i.WarehouseId, Limit: limit}
+	moves, err := loadersFrom(ctx).recentMoves.Load(ctx, key)()
+	if err != nil {
+		return nil, err
+	}
+	return toStockMoves(moves), nil
+}
+
+type stockMoveResolver struct {
+	m *stockmovesModel.StockMove
+}
+
+func (r *stockMoveResolver) ID() graphql.ID     { return graphql.ID(r.m.Id.String()) }
+func (r *stockMoveResolver) QtyMoved() Long     { return Long(deref(r.m.QtyMoved)) }
+func (r *stockMoveResolver) Reason() string     { return deref(r.m.Reason) }
+func (r *stockMoveResolver) CreatedBy() *string { return r.m.CreatedBy }
+func (r *stockMoveResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: deref(r.m.CreatedAt)}
+}
+
+func (r *stockMoveResolver) Product(ctx context.Context) (*productResolver, error) {
+	return loadProduct(ctx, *r.m.ProductId)
+}
+
+func (r *stockMoveResolver) Warehouse(ctx context.Context) (*warehouseResolver, error) {
+	return loadWarehouse(ctx, *r.m.WarehouseId)
+}
+
+func (r *stockMoveResolver) ReversalOf(ctx context.Context) (*stockMoveResolver, error) {
+	if r.m.ReversalOf == nil {
+		return nil, nil
+	}
+	m, err := loadersFrom(ctx).moves.Load(ctx, *r.m.ReversalOf)()
+	if err != nil || m == nil {
+		return nil, err
+	}
+	return &stockMoveResolver{m: m}, nil
+}
+
+// loadProduct resolve relacoes obrigatorias, em que o produto sempre existe
+func loadProduct(ctx context.Context, id uuid.UUID) (*productResolver, error) {
+	p, err := loadersFrom(ctx).products.Load(ctx, id)()
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("produto nao encontrado")
+	}
+	return &productResolver{p: p}, nil
+}
+
+func loadWarehouse(ctx context.Context, id uuid.UUID) (*warehouseResolver, error) {
+	w, err := loadersFrom(ctx).warehouses.Load(ctx, id)()
+	if err != nil {
+		return nil, err
+	}
+	if w == nil {
+		return nil, errors.New("galpao nao encontrado")
+	}
+	return &warehouseResolver{w: w}, nil
+}
+
+func toStockItems(items []stockitemsModel.StockItems) []*stockItemResolver {
+	out := make([]*stockItemResolver, 0, len(items))
+	for i := range items {
+		out = append(out, &stockItemResolver{i: &items[i]})
+	}
+	return out
+}
+
+func toStockMoves(moves []stockmovesModel.StockMove) []*stockMoveResolver {
+	out := make([]*stockMoveResolver, 0, len(moves))
+	for i := range moves {
+		out = append(out, &stockMoveResolver{m: &moves[i]})
+	}
+	return out
+}
+
+func toTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
+
+func parseId(id graphql.ID, name string) (uuid.UUID, error) {
+	parsed, err := uuid.FromString(string(id))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("argumento '%s' invalido", name)
+	}
+	return parsed, nil
+}
+
+// pageFrom monta a pagina a partir dos argumentos first e after das listas
+func pageFrom(first int32, after *string) (*pagination.Page, error) {
+	if first < 1 || first > pagination.MaxLimit {
+		return nil, fmt.Errorf("argumento 'first' deve ser um inteiro entre 1 e %d", pagination.MaxLimit)
+	}
+	page := &pagination.Page{Limit: int(first)}
+	if after != nil && *after != "" {
+		c, err := pagination.Decode(*after)
+		if err != nil {
+			return nil, err
+		}
+		page.After = c
+	}
+	return page, nil
+}
+
+func movesLimit(first int32) (int, error) {
+	if first < 1 || first > pagination.MaxLimit {
+		return 0, fmt.Errorf("argumento 'first' deve ser um inteiro entre 1 e %d", pagination.MaxLimit)
+	}
+	return int(first), nil
+}
@@ -0,0 +1,181 @@
+package imports
+
+import (
+	"api-estoque/internal/config"
+	importsModel "api-estoque/internal/model/imports"
+	importfile "api-estoque/internal/model/imports/response/import_file"
+	productModel "api-estoque/internal/model/product"
+	stockitemsModel "api-estoque/internal/model/stock_items"
+	warehouseModel "api-estoque/internal/model/warehouse"
+	importsRepo "api-estoque/internal/repositories/imports"
+	productRepo "api-estoque/internal/repositories/product"
+	stockitemsRepo "api-estoque/internal/repositories/stock_items"
+	stockitems "api-estoque/internal/services/stock_items"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+type Service struct {
+	Repository        *importsRepo.Repository
+	StockItemsService *stockitems.Service
+	Logger            *logrus.Logger
+}
+
+func New(repository *importsRepo.Repository, stockItemsService *stockitems.Service, logger *logrus.Logger) *Service {
+	return &Service{
+		Repository:        repository,
+		StockItemsService: stockItemsService,
+		Logger:            logger,
+	}
+}
+
+// Products importa os produtos da planilha. Cada linha passa pelas mesmas
+// validacoes do POST /products e e gravada em uma transacao unica: com
+// qualquer erro, ou em dry-run, nada e gravado.
+func (s *Service) Products(table *importsModel.Table, dryRun bool, changedBy *string) *importfile.ImportFileResponse {
+	if err := table.CheckColumns("name", "description", "categoryId"); err != nil {
+		return &importfile.ImportFileResponse{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
+
+	report := importsModel.Report{DryRun: dryRun, Total: len(table.Rows)}
+	products := make([]*productModel.Product, len(table.Rows))
+	var valid []int
+
+	for i := range table.Rows {
+		p, err := table.Product(i)
+		if err == nil {
+			err = p.ValidateCreate()
+		}
+		if err == nil {
+			err = p.ReconcilePrices(config.Env.DefaultCurrency)
+		}
+		if err != nil {
+			report.Fail(table.Lines[i], err.Error())
+			continue
+		}
+
+		p.ChangedBy = changedBy
+		products[i] = p
+		valid = append(valid, i)
+	}
+
+	return s.run(&report, table, valid, func(ctx context.Context, tx pgx.Tx, row int) error {
+		err := productRepo.CreateTx(ctx, tx, products[row])
+		if errors.Is(err, productRepo.ErrCategoryNotFound) || errors.Is(err, productRepo.ErrSkuTaken) {
+			return err
+		}
+		if err != nil {
+			s.Logger.Errorf("(Imports) Products - linha %d: %v", table.Lines[row], err)
+			return errors.New("falha ao gravar produto")
+		}
+		return nil
+	})
+}
+
+// StockItems importa saldos iniciais. Cada linha passa pelas validacoes do
+// POST /stock-items: galpao ativo, produto que aceita entradas e capacidade do
+// galpao, somando as linhas do arquivo que vao para o mesmo galpao. Com
+// qualquer erro, ou em dry-run, nada e gravado.
+func (s *Service) StockItems(table *importsModel.Table, dryRun bool) *importfile.ImportFileResponse {
+	if err := table.CheckColumns("warehouseId", "productId", "quantity"); err != nil {
+		return &importfile.ImportFileResponse{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
+
+	report := importsModel.Report{DryRun: dryRun, Total: len(table.Rows)}
+	items := make([]*stockitemsModel.StockItems, len(table.Rows))
+	var valid []int
+	pending := make(map[uuid.UUID]*warehouseModel.Load)
+
+	for i := range table.Rows {
+		line := table.Lines[i]
+		item, err := table.StockItem(i)
+		if err == nil {
+			err = item.ValidateCreate()
+		}
+		if err != nil {
+			report.Fail(line, err.Error())
+			continue
+		}
+
+		warnings, err := s.StockItemsService.CheckReceiptBatch(item.WarehouseId, item.ProductId, *item.Quantity, pending)
+		if errors.Is(err, stockitems.ErrOperationRefused) {
+			report.Fail(line, err.Error())
+			continue
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			report.Fail(line, "galpao ou produto nao encontrado")
+			continue
+		}
+		if err != nil {
+			s.Logger.Errorf("(Imports) StockItems - linha %d: %v", line, err)
+			return &importfile.ImportFileResponse{
+				Status: http.StatusInternalServerError,
+				Msg:    fmt.Sprintf("falha ao validar linha %d", line),
+			}
+		}
+		for _, warning := range warnings {
+			report.Warn(line, warning)
+		}
+
+		items[i] = item
+		valid = append(valid, i)
+	}
+
+	return s.run(&report, table, valid, func(ctx context.Context, tx pgx.Tx, row int) error {
+		err := stockitemsRepo.CreateTx(ctx, tx, items[row])
+		if errors.Is(err, stockitemsRepo.ErrAlreadyExists) {
+			return err
+		}
+		if err != nil {
+			s.Logger.Errorf("(Imports) StockItems - linha %d: %v", table.Lines[row], err)
+			return errors.New("falha ao gravar item de estoque")
+		}
+		return nil
+	})
+}
+
+// run grava as linhas validas. Mesmo com erros de validacao as linhas passam
+// pelo banco, para que o relatorio aponte tambem os erros de gravacao, mas a
+// transacao so e confirmada sem nenhum erro e fora do dry-run.
+func (s *Service) run(report *importsModel.Report, table *importsModel.Table, rows []int, insert func(ctx context.Context, tx pgx.Tx, row int) error) *importfile.ImportFileResponse {
+	rowErrs, committed, err := s.Repository.Run(rows, report.DryRun || len(report.Errors) > 0, insert)
+	if err != nil {
+		s.Logger.Errorf("(Imports) run - %v", err)
+		return &importfile.ImportFileResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao executar importacao",
+		}
+	}
+
+	for row, rowErr := range rowErrs {
+		report.Fail(table.Lines[row], rowErr.Error())
+	}
+	report.Committed = committed
+	report.Finish()
+
+	if !report.DryRun && !committed {
+		return &importfile.ImportFileResponse{
+			Status: http.StatusUnprocessableEntity,
+			Msg:    fmt.Sprintf("importacao cancelada, %d linhas com erro", report.Total-report.Valid),
+			Report: *report,
+		}
+	}
+
+	return &importfile.ImportFileResponse{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+		Report: *report,
+	}
+}
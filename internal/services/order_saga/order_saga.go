@@ -0,0 +1,184 @@
+package ordersaga
+
+import (
+	"api-estoque/internal/consumer"
+	"api-estoque/internal/model/order"
+	productModel "api-estoque/internal/model/product"
+	stockitemsModel "api-estoque/internal/model/stock_items"
+	ordersagaRepository "api-estoque/internal/repositories/order_saga"
+	stockitems "api-estoque/internal/services/stock_items"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// replyTimeout limita cada publicacao de resposta
+const replyTimeout = 10 * time.Second
+
+// replyGrace e quanto uma resposta pode ficar sem publicacao antes de o
+// reenvio periodico assumir
+const replyGrace = 30 * time.Second
+
+// replyBatch e quantas respostas pendentes sao lidas por vez
+const replyBatch = 100
+
+// Service participa da saga de pedidos: reserva o estoque no OrderPlaced, da
+// baixa na reserva no OrderPaid e a libera no OrderCancelled, respondendo cada
+// evento com sucesso ou falha.
+type Service struct {
+	Repository        *ordersagaRepository.Repository
+	StockItemsService *stockitems.Service
+	Consumer          consumer.Consumer
+	Logger            *logrus.Logger
+}
+
+func New(repository *ordersagaRepository.Repository, stockItemsService *stockitems.Service, consumer consumer.Consumer, logger *logrus.Logger) *Service {
+	return &Service{
+		Repository:        repository,
+		StockItemsService: stockItemsService,
+		Consumer:          consumer,
+		Logger:            logger,
+	}
+}
+
+// Start consome os eventos de pedido ate o contexto ser cancelado.
+func (s *Service) Start(ctx context.Context) {
+	go func() {
+		err := s.Consumer.Consume(ctx, s.handle)
+		if err != nil && ctx.Err() != nil {
+			s.Logger.Infof("(OrderSaga) Consumer encerrado - %v", err)
+		} else if err != nil {
+			s.Logger.Errorf("(OrderSaga) Consumer - %v", err)
+		}
+	}()
+}
+
+// handle processa uma mensagem. Erros de infraestrutura voltam para o consumer
+// entregar a mensagem de novo; mensagens malformadas sao descartadas.
+func (s *Service) handle(ctx context.Context, body []byte) error {
+	var event order.Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		s.Logger.Warnf("(OrderSaga) Mensagem descartada, JSON invalido: %v", err)
+		return nil
+	}
+	if err := event.Validate(); err != nil {
+		s.Logger.Warnf("(OrderSaga) Mensagem %s descartada: %v", event.Id, err)
+		return nil
+	}
+
+	refused, err := s.checkPlaced(&event)
+	if err != nil {
+		s.Logger.Errorf("(OrderSaga) %s %s - %v", event.Type, event.OrderId, err)
+		return err
+	}
+
+	reply, duplicate, err := s.Repository.Process(&event, refused)
+	if err != nil {
+		s.Logger.Errorf("(OrderSaga) %s %s - %v", event.Type, event.OrderId, err)
+		return err
+	}
+	if duplicate {
+		s.Logger.Infof("(OrderSaga) Mensagem %s repetida, reenviando a resposta", event.Id)
+	}
+
+	s.reply(ctx, event.Id, reply)
+	return nil
+}
+
+// checkPlaced valida as linhas do OrderPlaced e o status dos produtos. O
+// motivo de recusa vira uma resposta de falha; err e um erro de infraestrutura.
+func (s *Service) checkPlaced(event *order.Event) (refused error, err error) {
+	if event.Type != order.OrderPlaced {
+		return nil, nil
+	}
+	if err := stockitemsModel.ValidateLines(event.Lines); err != nil {
+		return err, nil
+	}
+
+	err = s.StockItemsService.CheckOperation(event.Lines, productModel.OperationReserve)
+	if errors.Is(err, stockitems.ErrOperationRefused) {
+		return err, nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors.New("produto nao encontrado"), nil
+	}
+	return nil, err
+}
+
+// reply publica a resposta e a marca como enviada. Se falhar, o reenvio
+// periodico tenta de novo.
+func (s *Service) reply(ctx context.Context, messageId string, reply *order.Reply) {
+	replyCtx, cancel := context.WithTimeout(ctx, replyTimeout)
+	defer cancel()
+
+	if err := s.Consumer.Reply(replyCtx, reply); err != nil {
+		s.Logger.Warnf("(OrderSaga) Falha ao publicar resposta da mensagem %s: %v", messageId, err)
+		return
+	}
+	if err := s.Repository.MarkReplied(messageId); err != nil {
+		s.Logger.Errorf("(OrderSaga) Reply - %v", err)
+	}
+}
+
+// StartReplies reenvia periodicamente as respostas que nao foram publicadas,
+// ate o contexto ser cancelado.
+func (s *Service) StartReplies(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.resendReplies(ctx)
+			}
+		}
+	}()
+}
+
+func (s *Service) resendReplies(ctx context.Context) {
+	for ctx.Err() == nil {
+		pending, err := s.Repository.PendingReplies(replyGrace, replyBatch)
+		if err != nil {
+			s.Logger.Errorf("(OrderSaga) Replies - %v", err)
+			return
+		}
+		for i := range pending {
+			s.reply(ctx, pending[i].MessageId, &pending[i].Reply)
+		}
+		if len(pending) < replyBatch {
+			return
+		}
+	}
+}
+
+// StartCleanup remove periodicamente do inbox as mensagens respondidas ha mais
+// de retention, ate o contexto ser cancelado.
+func (s *Service) StartCleanup(ctx context.Context, interval time.Duration, retention time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := s.Repository.DeleteProcessed(retention)
+				if err != nil {
+					s.Logger.Errorf("(OrderSaga) Cleanup - %v", err)
+					continue
+				}
+				if deleted > 0 {
+					s.Logger.Infof("(OrderSaga) Cleanup - %d mensagens removidas do inbox", deleted)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,94 @@
+package outbox
+
+import (
+	outboxModel "api-estoque/internal/model/outbox"
+	"api-estoque/internal/publisher"
+	outboxRepository "api-estoque/internal/repositories/outbox"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// dispatchBatch e quantos eventos sao lidos do outbox por vez
+const dispatchBatch = 200
+
+// publishTimeout limita cada chamada ao publisher
+const publishTimeout = 10 * time.Second
+
+type Service struct {
+	Repository *outboxRepository.Repository
+	Publisher  publisher.Publisher
+	Logger     *logrus.Logger
+}
+
+func New(repository *outboxRepository.Repository, publisher publisher.Publisher, logger *logrus.Logger) *Service {
+	return &Service{
+		Repository: repository,
+		Publisher:  publisher,
+		Logger:     logger,
+	}
+}
+
+// StartDispatcher publica periodicamente os eventos pendentes do outbox, ate o
+// contexto ser cancelado.
+func (s *Service) StartDispatcher(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.dispatch(ctx)
+			}
+		}
+	}()
+}
+
+func (s *Service) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		published, failed, err := s.Repository.Dispatch(dispatchBatch, func(event *outboxModel.Event) error {
+			publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+			defer cancel()
+			return s.Publisher.Publish(publishCtx, event)
+		})
+		if err != nil {
+			s.Logger.Errorf("(Outbox) Dispatcher - %v", err)
+			return
+		}
+		if failed > 0 {
+			s.Logger.Warnf("(Outbox) Dispatcher - %d eventos nao publicados, nova tentativa agendada", failed)
+		}
+		if published+failed < dispatchBatch {
+			return
+		}
+	}
+}
+
+// StartCleanup remove periodicamente os eventos publicados ha mais de
+// retention, ate o contexto ser cancelado.
+func (s *Service) StartCleanup(ctx context.Context, interval time.Duration, retention time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := s.Repository.DeletePublished(retention)
+				if err != nil {
+					s.Logger.Errorf("(Outbox) Cleanup - %v", err)
+					continue
+				}
+				if deleted > 0 {
+					s.Logger.Infof("(Outbox) Cleanup - %d eventos publicados removidos", deleted)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,137 @@
+package product
+
+import (
+	"api-estoque/internal/config"
+	productModel "api-estoque/internal/model/product"
+	bulkupsert "api-estoque/internal/model/product/response/bulk_upsert"
+	productRepo "api-estoque/internal/repositories/product"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+)
+
+// BulkUpsert cria ou atualiza os produtos do lote pelo sku, em uma unica
+// transacao. Itens invalidos voltam com outcome 'error' e nao impedem a
+// gravacao dos demais; itens iguais ao produto atual nao sao regravados.
+func (s *Service) BulkUpsert(b *productModel.BulkUpsert, changedBy *string) *bulkupsert.BulkUpsertResponse {
+	results := make([]productModel.BulkResult, len(b.Products))
+	fail := func(i int, msg string) {
+		results[i].Outcome = productModel.OutcomeError
+		results[i].Msg = msg
+	}
+
+	seen := map[string]int{}
+	var skus []string
+	var categoryIds []uuid.UUID
+	for i := range b.Products {
+		p := &b.Products[i]
+		results[i] = productModel.BulkResult{Index: i, Sku: p.Sku}
+
+		err := p.ValidateUpsert()
+		if err == nil {
+			err = p.ReconcilePrices(config.Env.DefaultCurrency)
+		}
+		if err != nil {
+			fail(i, err.Error())
+			continue
+		}
+		if first, ok := seen[*p.Sku]; ok {
+			fail(i, fmt.Sprintf("sku repetido no lote, ja informado no item %d", first))
+			continue
+		}
+
+		seen[*p.Sku] = i
+		p.ChangedBy = changedBy
+		skus = append(skus, *p.Sku)
+		categoryIds = append(categoryIds, *p.CategoryId)
+	}
+
+	err := s.Repository.BulkUpsert(skus, categoryIds, func(current map[string]*productModel.Product, categories map[uuid.UUID]bool) ([]*productModel.Product, []*productModel.Product, error) {
+		var creates, updates []*productModel.Product
+		for i := range b.Products {
+			if results[i].Outcome == productModel.OutcomeError {
+				continue
+			}
+			p := &b.Products[i]
+
+			if !categories[*p.CategoryId] {
+				fail(i, productRepo.ErrCategoryNotFound.Error())
+				continue
+			}
+
+			existing, ok := current[*p.Sku]
+			if !ok {
+				if *p.Status != productModel.StatusDraft && *p.Status != productModel.StatusActive {
+					fail(i, "atributo 'status' deve ser 'draft' ou 'active' na criação")
+					continue
+				}
+				results[i].Outcome = productModel.OutcomeCreated
+				creates = append(creates, p)
+				continue
+			}
+
+			p.Id = existing.Id
+			results[i].Id = existing.Id
+			p.KeepUnset(existing)
+			if p.SameAs(existing) {
+				results[i].Outcome = productModel.OutcomeUnchanged
+				continue
+			}
+
+			if err := productModel.ValidateTransition(*existing.Status, *p.Status); err != nil {
+				fail(i, err.Error())
+				continue
+			}
+			if *p.Status == productModel.StatusArchived && *existing.Status != productModel.StatusArchived {
+				hasStock, err := s.Repository.HasStock(p.Id)
+				if err != nil {
+					return nil, nil, err
+				}
+				if hasStock {
+					fail(i, "produto ainda possui estoque ou reservas, nao pode ser arquivado")
+					continue
+				}
+			}
+
+			results[i].Outcome = productModel.OutcomeUpdated
+			updates = append(updates, p)
+		}
+		return creates, updates, nil
+	})
+	if errors.Is(err, productRepo.ErrCategoryNotFound) || errors.Is(err, productRepo.ErrSkuTaken) {
+		// categoria removida ou sku criado por outra requisicao durante o lote
+		return &bulkupsert.BulkUpsertResponse{
+			Status: http.StatusConflict,
+			Msg:    err.Error() + ", nada foi gravado, repita o lote",
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(Product) BulkUpsert - %v", err)
+		return &bulkupsert.BulkUpsertResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao gravar lote de produtos",
+		}
+	}
+
+	res := &bulkupsert.BulkUpsertResponse{
+		Status:  http.StatusOK,
+		Msg:     "Sucesso",
+		Results: results,
+	}
+	for i := range results {
+		switch results[i].Outcome {
+		case productModel.OutcomeCreated:
+			results[i].Id = b.Products[i].Id
+			res.Created++
+		case productModel.OutcomeUpdated:
+			res.Updated++
+		case productModel.OutcomeUnchanged:
+			res.Unchanged++
+		case productModel.OutcomeError:
+			res.Errors++
+		}
+	}
+	return res
+}
@@ -0,0 +1,190 @@
+package product
+
+import (
+	productModel "api-estoque/internal/model/product"
+	uploadimage "api-estoque/internal/model/product/response/upload_image"
+	"api-estoque/internal/storage"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// invalidImagesError envolve erros de validacao da lista de imagens, para
+// diferencia-los de falhas do banco
+type invalidImagesError struct{ error }
+
+// UploadImage grava o arquivo e sua miniatura no armazenamento e acrescenta a
+// imagem ao produto. Se a imagem nao puder ser registrada, os arquivos gravados
+// sao removidos.
+func (s *Service) UploadImage(productId *uuid.UUID, data []byte, upload *productModel.ImageUpload) *uploadimage.UploadImageResponse {
+	contentType, ext, err := detectImage(data)
+	if errors.Is(err, ErrUnsupportedImage) || errors.Is(err, ErrImageTooLarge) {
+		return &uploadimage.UploadImageResponse{
+			Status: http.StatusUnsupportedMediaType,
+			Msg:    err.Error(),
+		}
+	}
+
+	if _, err := s.Repository.GetByID(productId); errors.Is(err, pgx.ErrNoRows) {
+		return &uploadimage.UploadImageResponse{
+			Status: http.StatusNotFound,
+			Msg:    "produto nao encontrado",
+		}
+	} else if err != nil {
+		s.Logger.Errorf("(Product) UploadImage - %v", err)
+		return &uploadimage.UploadImageResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao buscar produto",
+		}
+	}
+
+	thumb, thumbType, thumbExt, err := thumbnail(data, contentType)
+	if err != nil {
+		s.Logger.Errorf("(Product) UploadImage - thumbnail: %v", err)
+		return &uploadimage.UploadImageResponse{
+			Status: http.StatusUnsupportedMediaType,
+			Msg:    ErrUnsupportedImage.Error(),
+		}
+	}
+
+	ctx := context.Background()
+	fileId, err := uuid.NewV4()
+	if err != nil {
+		s.Logger.Errorf("(Product) UploadImage - %v", err)
+		return &uploadimage.UploadImageResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao gerar nome do arquivo",
+		}
+	}
+	dir := path.Join("products", productId.String())
+	key := path.Join(dir, fileId.String()+"."+ext)
+	thumbKey := path.Join(dir, fileId.String()+"_thumb."+thumbExt)
+
+	url, err := s.Storage.Put(ctx, key, contentType, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		s.Logger.Errorf("(Product) UploadImage - %v", err)
+		return &uploadimage.UploadImageResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao gravar imagem",
+		}
+	}
+	stored := []string{key}
+
+	thumbUrl, err := s.Storage.Put(ctx, thumbKey, thumbType, bytes.NewReader(thumb), int64(len(thumb)))
+	if err != nil {
+		s.Logger.Errorf("(Product) UploadImage - %v", err)
+		s.removeFiles("UploadImage", stored)
+		return &uploadimage.UploadImageResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao gravar miniatura",
+		}
+	}
+	stored = append(stored, thumbKey)
+
+	image := productModel.Image{
+		Url:          url,
+		ThumbnailUrl: thumbUrl,
+		Alt:          upload.Alt,
+		Primary:      upload.Primary,
+	}
+
+	images, err := s.Repository.UpdateImages(productId, func(images []productModel.Image) ([]productModel.Image, error) {
+		image.Position = productModel.NextPosition(images)
+		if upload.Position != nil {
+			// abre espaco na posicao pedida, deslocando as imagens seguintes
+			image.Position = *upload.Position
+			for i := range images {
+				if images[i].Position >= image.Position {
+					images[i].Position++
+				}
+			}
+		}
+		if image.Primary {
+			for i := range images {
+				images[i].Primary = false
+			}
+		}
+
+		images = append(images, image)
+		if err := productModel.NormalizeImages(images); err != nil {
+			return nil, invalidImagesError{err}
+		}
+		return images, nil
+	})
+	if err != nil {
+		s.removeFiles("UploadImage", stored)
+	}
+	var invalid invalidImagesError
+	switch {
+	case errors.As(err, &invalid):
+		return &uploadimage.UploadImageResponse{
+			Status: http.StatusBadRequest,
+			Msg:    invalid.Error(),
+		}
+	case errors.Is(err, pgx.ErrNoRows):
+		return &uploadimage.UploadImageResponse{
+			Status: http.StatusNotFound,
+			Msg:    "produto nao encontrado",
+		}
+	case err != nil:
+		s.Logger.Errorf("(Product) UploadImage - %v", err)
+		return &uploadimage.UploadImageResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao registrar imagem do produto",
+		}
+	}
+
+	for _, img := range images {
+		if img.Url == url {
+			image = img
+		}
+	}
+
+	return &uploadimage.UploadImageResponse{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+		Image:  image,
+		Images: images,
+	}
+}
+
+// removeDroppedImages apaga do armazenamento os arquivos e miniaturas das
+// imagens que sairam do produto. So sao apagados arquivos enviados para o
+// proprio produto; URLs externas e de outros produtos ficam como estao.
+func (s *Service) removeDroppedImages(productId *uuid.UUID, before []productModel.Image, after []productModel.Image) {
+	kept := map[string]bool{}
+	for _, img := range after {
+		kept[img.Url] = true
+		kept[img.ThumbnailUrl] = true
+	}
+
+	dir := path.Join("products", productId.String()) + "/"
+	var keys []string
+	for _, img := range before {
+		for _, url := range []string{img.Url, img.ThumbnailUrl} {
+			if url == "" || kept[url] {
+				continue
+			}
+			if key, ok := s.Storage.Key(url); ok && strings.HasPrefix(key, dir) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	s.removeFiles("Update", keys)
+}
+
+// removeFiles apaga arquivos de um upload que nao foi concluido ou de imagens
+// removidas do produto. Falhas so sao registradas no log.
+func (s *Service) removeFiles(op string, keys []string) {
+	for _, key := range keys {
+		if err := s.Storage.Delete(context.Background(), key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			s.Logger.Warnf("(Product) %s - falha ao remover %s: %v", op, key, err)
+		}
+	}
+}
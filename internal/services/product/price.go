@@ -0,0 +1,183 @@
+package product
+
+import (
+	httpresponse "api-estoque/internal/model/http_response"
+	"api-estoque/internal/model/pagination"
+	productModel "api-estoque/internal/model/product"
+	"api-estoque/internal/model/product/response/create"
+	pricehistory "api-estoque/internal/model/product/response/price_history"
+	priceschedule "api-estoque/internal/model/product/response/price_schedule"
+	productRepo "api-estoque/internal/repositories/product"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// lote de agendamentos aplicados por transacao do worker
+const priceScheduleBatch = 100
+
+// PriceHistory retorna o historico de precos do produto na moeda pedida, do
+// mais recente ao mais antigo. Com at, retorna apenas o preco vigente naquele momento.
+func (s *Service) PriceHistory(productId *uuid.UUID, currency string, at *time.Time, page *pagination.Page) *pricehistory.PriceHistoryResponse {
+	if res := s.checkExists(productId, "(Product) PriceHistory"); res != nil {
+		return &pricehistory.PriceHistoryResponse{Status: res.Status, Msg: res.Msg}
+	}
+
+	history, err := s.Repository.PriceHistory(productId, currency, at, page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return &pricehistory.PriceHistoryResponse{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(Product) PriceHistory - %v", err)
+		return &pricehistory.PriceHistoryResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao buscar historico de precos",
+		}
+	}
+
+	var nextCursor *string
+	if at == nil && pagination.Trim(history, page.Limit) {
+		last := (*history)[len(*history)-1]
+		nextCursor = pagination.Encode(&pagination.Cursor{CreatedAt: last.EffectiveAt, Id: last.Id})
+	}
+
+	return &pricehistory.PriceHistoryResponse{
+		Status:     http.StatusOK,
+		Msg:        "Sucesso",
+		History:    history,
+		NextCursor: nextCursor,
+	}
+}
+
+func (s *Service) CreatePriceSchedule(schedule *productModel.PriceSchedule) *create.CreateResponse {
+	id, err := s.Repository.CreatePriceSchedule(schedule)
+	if errors.Is(err, productRepo.ErrProductNotFound) {
+		return &create.CreateResponse{
+			Status: http.StatusNotFound,
+			Msg:    err.Error(),
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(Product) CreatePriceSchedule - %v", err)
+		return &create.CreateResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao agendar alteracao de preco",
+		}
+	}
+
+	return &create.CreateResponse{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+		Id:     *id,
+	}
+}
+
+// ListPriceSchedules retorna os agendamentos do produto; com pendingOnly,
+// apenas os que ainda nao foram aplicados nem cancelados
+func (s *Service) ListPriceSchedules(productId *uuid.UUID, pendingOnly bool) *priceschedule.PriceScheduleResponse {
+	if res := s.checkExists(productId, "(Product) ListPriceSchedules"); res != nil {
+		return &priceschedule.PriceScheduleResponse{Status: res.Status, Msg: res.Msg}
+	}
+
+	schedules, err := s.Repository.ListPriceSchedules(productId, pendingOnly)
+	if err != nil {
+		s.Logger.Errorf("(Product) ListPriceSchedules - %v", err)
+		return &priceschedule.PriceScheduleResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao listar agendamentos de preco",
+		}
+	}
+
+	return &priceschedule.PriceScheduleResponse{
+		Status:    http.StatusOK,
+		Msg:       "Sucesso",
+		Schedules: schedules,
+	}
+}
+
+func (s *Service) CancelPriceSchedule(productId *uuid.UUID, scheduleId *uuid.UUID) *httpresponse.Response {
+	err := s.Repository.CancelPriceSchedule(productId, scheduleId)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return &httpresponse.Response{
+			Status: http.StatusNotFound,
+			Msg:    "agendamento nao encontrado",
+		}
+	case errors.Is(err, productRepo.ErrScheduleClosed):
+		return &httpresponse.Response{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	case err != nil:
+		s.Logger.Errorf("(Product) CancelPriceSchedule - %v", err)
+		return &httpresponse.Response{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao cancelar agendamento de preco",
+		}
+	}
+
+	return &httpresponse.Response{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+	}
+}
+
+// StartPriceScheduler aplica periodicamente as alteracoes de preco agendadas
+// que ja venceram, ate o contexto ser cancelado.
+func (s *Service) StartPriceScheduler(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.applyDuePrices()
+			}
+		}
+	}()
+}
+
+func (s *Service) applyDuePrices() {
+	for {
+		applied, err := s.Repository.ApplyDuePriceSchedules(priceScheduleBatch)
+		if err != nil {
+			s.Logger.Errorf("(Product) PriceScheduler - %v", err)
+			return
+		}
+		if applied > 0 {
+			s.Logger.Infof("(Product) PriceScheduler - %d alteracoes de preco aplicadas", applied)
+		}
+		if applied < priceScheduleBatch {
+			return
+		}
+	}
+}
+
+// checkExists retorna 404 se o produto nao existe, ou nil
+func (s *Service) checkExists(id *uuid.UUID, op string) *httpresponse.Response {
+	_, err := s.Repository.GetByID(id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &httpresponse.Response{
+			Status: http.StatusNotFound,
+			Msg:    "produto nao encontrado",
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("%s - %v", op, err)
+		return &httpresponse.Response{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao buscar produto",
+		}
+	}
+	return nil
+}
@@ -1,32 +1,49 @@
 package product
 
 import (
+	"api-estoque/internal/config"
+	"api-estoque/internal/model/export"
+	exportfile "api-estoque/internal/model/export/response/export_file"
 	httpresponse "api-estoque/internal/model/http_response"
+	"api-estoque/internal/model/pagination"
 	productModel "api-estoque/internal/model/product"
 	"api-estoque/internal/model/product/response/create"
 	getbyid "api-estoque/internal/model/product/response/get_by_id"
 	"api-estoque/internal/model/product/response/list"
+	"api-estoque/internal/model/product/response/search"
 	productRepo "api-estoque/internal/repositories/product"
+	"api-estoque/internal/storage"
+	"errors"
 	"net/http"
 
 	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/sirupsen/logrus"
 )
 
 type Service struct {
 	Repository *productRepo.Repository
+	Storage    storage.Storage
 	Logger     *logrus.Logger
 }
 
-func New(repository *productRepo.Repository, logger *logrus.Logger) *Service {
+func New(repository *productRepo.Repository, storage storage.Storage, logger *logrus.Logger) *Service {
 	return &Service{
 		Repository: repository,
+		Storage:    storage,
 		Logger:     logger,
 	}
 }
 
-func (s *Service) List() *list.ListResponse {
-	products, err := s.Repository.List()
+// List retorna uma pagina de produtos com 'price' na moeda pedida
+func (s *Service) List(page *pagination.Page, currency string) *list.ListResponse {
+	products, err := s.Repository.List(page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return &list.ListResponse{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
 	if err != nil {
 		s.Logger.Errorf("(Product) List - %v", err)
 		return &list.ListResponse{
@@ -35,15 +52,105 @@ func (s *Service) List() *list.ListResponse {
 		}
 	}
 
+	var nextCursor *string
+	if pagination.Trim(products, page.Limit) {
+		last := (*products)[len(*products)-1]
+		nextCursor = pagination.Encode(&pagination.Cursor{CreatedAt: last.CreatedAt, Id: last.Id})
+	}
+	for i := range *products {
+		(*products)[i].SelectCurrency(currency, config.Env.DefaultCurrency)
+	}
+
 	return &list.ListResponse{
-		Status:   http.StatusOK,
-		Msg:      "Sucesso",
-		Products: products,
+		Status:     http.StatusOK,
+		Msg:        "Sucesso",
+		Products:   products,
+		NextCursor: nextCursor,
+	}
+}
+
+// Export envia o catalogo inteiro, com 'price' na moeda pedida, para o writer
+// conforme os produtos sao lidos do banco
+func (s *Service) Export(w export.Writer, currency string) *exportfile.ExportFileResponse {
+	rows := 0
+	err := s.Repository.Export(func(p *productModel.Product) error {
+		rows++
+		p.SelectCurrency(currency, config.Env.DefaultCurrency)
+		return w.Write(p.ExportValues()...)
+	})
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		s.Logger.Errorf("(Product) Export - linha %d: %v", rows, err)
+		return &exportfile.ExportFileResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao exportar produtos",
+		}
+	}
+
+	return &exportfile.ExportFileResponse{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+		Rows:   rows,
+	}
+}
+
+// Search busca produtos por texto, ordenados pela relevancia
+func (s *Service) Search(q *productModel.SearchQuery, page *pagination.Page, currency string) *search.SearchResponse {
+	products, err := s.Repository.Search(q, page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return &search.SearchResponse{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(Product) Search - %v", err)
+		return &search.SearchResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao buscar produtos",
+		}
+	}
+
+	var nextCursor *string
+	if pagination.Trim(products, page.Limit) {
+		last := (*products)[len(*products)-1]
+		nextCursor = pagination.Encode(&pagination.Cursor{Rank: &last.Rank, Id: last.Id})
+	}
+	for i := range *products {
+		(*products)[i].SelectCurrency(currency, config.Env.DefaultCurrency)
+	}
+
+	return &search.SearchResponse{
+		Status:     http.StatusOK,
+		Msg:        "Sucesso",
+		Products:   products,
+		NextCursor: nextCursor,
 	}
 }
 
 func (s *Service) Create(p *productModel.Product) *create.CreateResponse {
+	if err := p.ReconcilePrices(config.Env.DefaultCurrency); err != nil {
+		return &create.CreateResponse{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
+
 	id, err := s.Repository.Create(p)
+	if errors.Is(err, productRepo.ErrCategoryNotFound) {
+		return &create.CreateResponse{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
+	if errors.Is(err, productRepo.ErrSkuTaken) || errors.Is(err, productRepo.ErrStatusChanged) {
+		return &create.CreateResponse{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	}
 	if err != nil {
 		s.Logger.Errorf("(Product) Create - %v", err)
 		return &create.CreateResponse{
@@ -59,7 +166,9 @@ func (s *Service) Create(p *productModel.Product) *create.CreateResponse {
 	}
 }
 
-func (s *Service) GetByID(id *uuid.UUID) *getbyid.GetByIdResponse {
+// GetByID retorna o produto com 'price' na moeda pedida, ou na moeda padrao
+// quando o produto nao tem preco nela
+func (s *Service) GetByID(id *uuid.UUID, currency string) *getbyid.GetByIdResponse {
 	product, err := s.Repository.GetByID(id)
 	if err != nil {
 		s.Logger.Errorf("(Product) GetByID - %v", err)
@@ -68,23 +177,106 @@ func (s *Service) GetByID(id *uuid.UUID) *getbyid.GetByIdResponse {
 			Msg:    "falha ao buscar produto por ID",
 		}
 	}
+	product.SelectCurrency(currency, config.Env.DefaultCurrency)
 
 	return &getbyid.GetByIdResponse{
-		Status:      http.StatusOK,
-		Msg:         "Sucesso",
-		Id:          product.Id,
-		CreatedAt:   product.CreatedAt,
-		Name:        product.Name,
-		Description: product.Description,
-		Category:    product.Category,
-		Price:       product.Price,
-		ImagesJson:  product.ImagesJson,
-		IsActive:    product.IsActive,
+		Status:        http.StatusOK,
+		Msg:           "Sucesso",
+		Id:            product.Id,
+		Sku:           product.Sku,
+		CreatedAt:     product.CreatedAt,
+		Name:          product.Name,
+		Description:   product.Description,
+		CategoryId:    product.CategoryId,
+		Category:      product.Category,
+		Price:         product.Price,
+		Currency:      product.Currency,
+		Prices:        product.Prices,
+		Images:        product.Images,
+		IsActive:      product.IsActive,
+		ProductStatus: product.Status,
+		LengthMm:      product.LengthMm,
+		WidthMm:       product.WidthMm,
+		HeightMm:      product.HeightMm,
+		WeightGrams:   product.WeightGrams,
+		Version:       *product.Version,
 	}
 }
 
-func (s *Service) Update(p *productModel.Product) *httpresponse.Response {
-	err := s.Repository.Update(p)
+func (s *Service) Update(p *productModel.Product, expectedVersion *int64) *httpresponse.Response {
+	if err := p.ReconcilePrices(config.Env.DefaultCurrency); err != nil {
+		return &httpresponse.Response{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
+
+	var fromStatus *string
+	if p.Status != nil {
+		current, err := s.Repository.GetByID(p.Id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &httpresponse.Response{
+				Status: http.StatusNotFound,
+				Msg:    "produto nao encontrado",
+			}
+		}
+		if err != nil {
+			s.Logger.Errorf("(Product) Update - %v", err)
+			return &httpresponse.Response{
+				Status: http.StatusInternalServerError,
+				Msg:    "falha ao buscar produto para atualizar",
+			}
+		}
+
+		if expectedVersion != nil && *current.Version != *expectedVersion {
+			return &httpresponse.Response{
+				Status: http.StatusPreconditionFailed,
+				Msg:    productRepo.ErrVersionMismatch.Error(),
+			}
+		}
+
+		if err := productModel.ValidateTransition(*current.Status, *p.Status); err != nil {
+			return &httpresponse.Response{
+				Status: http.StatusConflict,
+				Msg:    err.Error(),
+			}
+		}
+		fromStatus = current.Status
+
+		if *p.Status == productModel.StatusArchived {
+			if res := s.checkNoStock(p.Id); res != nil {
+				return res
+			}
+		}
+	}
+
+	// a transicao foi validada contra o status lido acima; o UPDATE so aplica
+	// se ele nao mudou nesse meio tempo
+	previousImages, err := s.Repository.Update(p, expectedVersion, fromStatus)
+	if errors.Is(err, productRepo.ErrVersionMismatch) {
+		return &httpresponse.Response{
+			Status: http.StatusPreconditionFailed,
+			Msg:    err.Error(),
+		}
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &httpresponse.Response{
+			Status: http.StatusNotFound,
+			Msg:    "produto nao encontrado",
+		}
+	}
+	if errors.Is(err, productRepo.ErrCategoryNotFound) {
+		return &httpresponse.Response{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
+	if errors.Is(err, productRepo.ErrSkuTaken) {
+		return &httpresponse.Response{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	}
 	if err != nil {
 		s.Logger.Errorf("(Product) Update - %v", err)
 		return &httpresponse.Response{
@@ -93,14 +285,50 @@ func (s *Service) Update(p *productModel.Product) *httpresponse.Response {
 		}
 	}
 
+	if p.Images != nil {
+		s.removeDroppedImages(p.Id, previousImages, *p.Images)
+	}
+
 	return &httpresponse.Response{
-		Status: http.StatusOK,
-		Msg:    "Sucesso",
+		Status:  http.StatusOK,
+		Msg:     "Sucesso",
+		Version: p.Version,
 	}
 }
 
+// Delete remove o produto apenas se ele nunca teve estoque nem movimentacoes;
+// caso contrario o produto e arquivado para preservar o historico.
 func (s *Service) Delete(id *uuid.UUID) *httpresponse.Response {
-	err := s.Repository.Delete(id)
+	hasHistory, err := s.Repository.HasHistory(id)
+	if err != nil {
+		s.Logger.Errorf("(Product) Delete - %v", err)
+		return &httpresponse.Response{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao verificar historico do produto",
+		}
+	}
+
+	if hasHistory {
+		if res := s.checkNoStock(id); res != nil {
+			return res
+		}
+
+		err = s.Repository.Archive(id)
+		if err != nil {
+			s.Logger.Errorf("(Product) Delete - %v", err)
+			return &httpresponse.Response{
+				Status: http.StatusInternalServerError,
+				Msg:    "falha ao arquivar produto",
+			}
+		}
+
+		return &httpresponse.Response{
+			Status: http.StatusOK,
+			Msg:    "Produto arquivado, pois possui historico de estoque",
+		}
+	}
+
+	err = s.Repository.Delete(id)
 	if err != nil {
 		s.Logger.Errorf("(Product) Delete - %v", err)
 		return &httpresponse.Response{
@@ -114,3 +342,22 @@ func (s *Service) Delete(id *uuid.UUID) *httpresponse.Response {
 		Msg:    "Sucesso",
 	}
 }
+
+// checkNoStock impede o arquivamento de produtos que ainda possuem saldo ou reservas.
+func (s *Service) checkNoStock(id *uuid.UUID) *httpresponse.Response {
+	hasStock, err := s.Repository.HasStock(id)
+	if err != nil {
+		s.Logger.Errorf("(Product) checkNoStock - %v", err)
+		return &httpresponse.Response{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao verificar estoque do produto",
+		}
+	}
+	if hasStock {
+		return &httpresponse.Response{
+			Status: http.StatusConflict,
+			Msg:    "produto ainda possui estoque ou reservas, nao pode ser arquivado",
+		}
+	}
+	return nil
+}
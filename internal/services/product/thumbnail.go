@@ -0,0 +1,90 @@
+package product
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	thumbnailMaxSide = 320
+	// limite de pixels da imagem original, para nao decodificar imagens
+	// pequenas em bytes mas gigantes em memoria
+	maxImagePixels = 40_000_000
+)
+
+var (
+	ErrUnsupportedImage = errors.New("formato de imagem nao suportado, envie JPEG, PNG, GIF ou WebP")
+	ErrImageTooLarge    = errors.New("dimensoes da imagem excedem o limite de 40 megapixels")
+)
+
+// imageExtensions mapeia os content types aceitos para a extensao do arquivo salvo
+var imageExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// detectImage identifica o formato pelo conteudo do arquivo, ignorando o
+// content type e o nome informados pelo cliente
+func detectImage(data []byte) (contentType string, ext string, err error) {
+	contentType = http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return "", "", ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", "", ErrUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return "", "", ErrImageTooLarge
+	}
+	return contentType, ext, nil
+}
+
+// thumbnail reduz a imagem para caber em thumbnailMaxSide, mantendo a
+// proporcao. PNG e GIF geram PNG, para preservar transparencia; os demais, JPEG.
+func thumbnail(data []byte, contentType string) ([]byte, string, string, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", "", ErrUnsupportedImage
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > thumbnailMaxSide || height > thumbnailMaxSide {
+		if width >= height {
+			height = max(1, height*thumbnailMaxSide/width)
+			width = thumbnailMaxSide
+		} else {
+			width = max(1, width*thumbnailMaxSide/height)
+			height = thumbnailMaxSide
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if contentType == "image/png" || contentType == "image/gif" {
+		if err := png.Encode(&buf, dst); err != nil {
+			return nil, "", "", err
+		}
+		return buf.Bytes(), "image/png", "png", nil
+	}
+
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, "", "", err
+	}
+	return buf.Bytes(), "image/jpeg", "jpg", nil
+}
@@ -1,11 +1,23 @@
 package services
 
 import (
+	"api-estoque/internal/consumer"
+	"api-estoque/internal/publisher"
 	"api-estoque/internal/repositories"
+	"api-estoque/internal/services/allocation"
+	"api-estoque/internal/services/category"
+	"api-estoque/internal/services/graph"
+	"api-estoque/internal/services/imports"
+	ordersaga "api-estoque/internal/services/order_saga"
+	"api-estoque/internal/services/outbox"
 	"api-estoque/internal/services/product"
 	stockitems "api-estoque/internal/services/stock_items"
 	stockmoves "api-estoque/internal/services/stock_moves"
+	"api-estoque/internal/services/stream"
+	"api-estoque/internal/services/tcc"
 	"api-estoque/internal/services/warehouse"
+	"api-estoque/internal/services/webhook"
+	"api-estoque/internal/storage"
 
 	"github.com/sirupsen/logrus"
 )
@@ -15,13 +27,35 @@ type Services struct {
 	StockMovesService *stockmoves.Service
 	WarehouseService  *warehouse.Service
 	ProductService    *product.Service
+	AllocationService *allocation.Service
+	CategoryService   *category.Service
+	ImportsService    *imports.Service
+	OutboxService     *outbox.Service
+	WebhookService    *webhook.Service
+	StreamService     *stream.Service
+	GraphService      *graph.Service
+	OrderSagaService  *ordersaga.Service
+	TccService        *tcc.Service
 }
 
-func InstanciateServices(repositories *repositories.Repositories, logger *logrus.Logger) *Services {
+func InstanciateServices(repositories *repositories.Repositories, storage storage.Storage, eventPublisher publisher.Publisher, orderConsumer consumer.Consumer, logger *logrus.Logger) *Services {
+	webhookService := webhook.New(repositories.WebhookRepository, logger)
+	streamService := stream.New(repositories.OutboxRepository, logger)
+	stockItemsService := stockitems.New(repositories.StockItemsRepository, repositories.WarehouseRepository, repositories.ProductRepository, logger)
+
 	return &Services{
-		StockItemsService: stockitems.New(repositories.StockItemsRepository, logger),
-		StockMovesService: stockmoves.New(repositories.StockMovesRepository, logger),
+		StockItemsService: stockItemsService,
+		StockMovesService: stockmoves.New(repositories.StockMovesRepository, repositories.ProductRepository, logger),
 		WarehouseService:  warehouse.New(repositories.WarehouseRepository, logger),
-		ProductService:    product.New(repositories.ProductRepository, logger),
+		ProductService:    product.New(repositories.ProductRepository, storage, logger),
+		AllocationService: allocation.New(repositories.AllocationRepository, repositories.StockItemsRepository, logger),
+		CategoryService:   category.New(repositories.CategoryRepository, logger),
+		ImportsService:    imports.New(repositories.ImportsRepository, stockItemsService, logger),
+		WebhookService:    webhookService,
+		StreamService:     streamService,
+		GraphService:      graph.New(repositories.ProductRepository, repositories.WarehouseRepository, repositories.StockItemsRepository, repositories.StockMovesRepository, logger),
+		OrderSagaService:  ordersaga.New(repositories.OrderSagaRepository, stockItemsService, orderConsumer, logger),
+		TccService:        tcc.New(repositories.TccRepository, stockItemsService, logger),
+		OutboxService:     outbox.New(repositories.OutboxRepository, publisher.Multi(eventPublisher, streamService, webhookService), logger),
 	}
 }
@@ -0,0 +1,142 @@
+package stockitems
+
+import (
+	httpresponse "api-estoque/internal/model/http_response"
+	productModel "api-estoque/internal/model/product"
+	stockitemsModel "api-estoque/internal/model/stock_items"
+	"api-estoque/internal/model/stock_items/response/availability"
+	stockitemsRepo "api-estoque/internal/repositories/stock_items"
+	"errors"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// CheckAvailability compara o saldo disponivel de cada linha com a quantidade
+// pedida, sem reservar nada.
+func (s *Service) CheckAvailability(lines []stockitemsModel.StockItemsBaixa) *availability.AvailabilityResponse {
+	keys := make([]stockitemsModel.ItemKey, 0, len(lines))
+	for _, line := range lines {
+		keys = append(keys, stockitemsModel.ItemKey{ProductId: *line.ProductId, WarehouseId: *line.WarehouseId})
+	}
+
+	available, err := s.Repository.Available(keys)
+	if err != nil {
+		s.Logger.Errorf("(StockItems) CheckAvailability - %v", err)
+		return &availability.AvailabilityResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao consultar saldo disponivel",
+		}
+	}
+
+	result := make([]stockitemsModel.Availability, 0, len(lines))
+	allSufficient := true
+	for i, line := range lines {
+		qty, found := available[keys[i]]
+		sufficient := found && qty >= *line.Quantity
+		allSufficient = allSufficient && sufficient
+		result = append(result, stockitemsModel.Availability{
+			ProductId:   keys[i].ProductId,
+			WarehouseId: keys[i].WarehouseId,
+			Requested:   *line.Quantity,
+			Available:   qty,
+			Sufficient:  sufficient,
+			Found:       found,
+		})
+	}
+
+	return &availability.AvailabilityResponse{
+		Status:        http.StatusOK,
+		Msg:           "Sucesso",
+		Lines:         result,
+		AllSufficient: allSufficient,
+	}
+}
+
+// Reserve reserva todas as linhas ou nenhuma. Os produtos precisam estar num
+// status que permita reservas.
+func (s *Service) Reserve(lines *[]stockitemsModel.StockItemsBaixa) *httpresponse.Response {
+	err := s.CheckOperation(*lines, productModel.OperationReserve)
+	if errors.Is(err, ErrOperationRefused) {
+		return &httpresponse.Response{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &httpresponse.Response{
+			Status: http.StatusNotFound,
+			Msg:    "produto nao encontrado",
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(StockItems) Reserve - %v", err)
+		return &httpresponse.Response{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao verificar status do produto",
+		}
+	}
+
+	err = s.Repository.ReserveQuantities(lines)
+	if errors.Is(err, stockitemsRepo.ErrInsufficientStock) {
+		return &httpresponse.Response{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(StockItems) Reserve - %v", err)
+		return &httpresponse.Response{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao reservar estoque",
+		}
+	}
+
+	return &httpresponse.Response{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+	}
+}
+
+// Release desfaz reservas, todas as linhas ou nenhuma. Nao depende do status
+// do produto, ja que so devolve saldo reservado antes.
+func (s *Service) Release(lines *[]stockitemsModel.StockItemsBaixa) *httpresponse.Response {
+	err := s.Repository.ReleaseQuantities(lines)
+	if errors.Is(err, stockitemsRepo.ErrInsufficientReserved) {
+		return &httpresponse.Response{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(StockItems) Release - %v", err)
+		return &httpresponse.Response{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao liberar reserva de estoque",
+		}
+	}
+
+	return &httpresponse.Response{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+	}
+}
+
+// CheckOperation verifica se o status de cada produto das linhas permite a
+// operacao. Retorna ErrOperationRefused quando algum nao permite, e um erro
+// com pgx.ErrNoRows quando algum produto nao existe.
+func (s *Service) CheckOperation(lines []stockitemsModel.StockItemsBaixa, op productModel.Operation) error {
+	checked := map[uuid.UUID]bool{}
+	for _, line := range lines {
+		if checked[*line.ProductId] {
+			continue
+		}
+		checked[*line.ProductId] = true
+
+		if err := s.checkProduct(line.ProductId, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
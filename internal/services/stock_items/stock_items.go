@@ -1,32 +1,56 @@
 package stockitems
 
 import (
+	"api-estoque/internal/config"
+	"api-estoque/internal/model/export"
+	exportfile "api-estoque/internal/model/export/response/export_file"
 	httpresponse "api-estoque/internal/model/http_response"
+	"api-estoque/internal/model/pagination"
+	productModel "api-estoque/internal/model/product"
 	stockitemsModel "api-estoque/internal/model/stock_items"
 	"api-estoque/internal/model/stock_items/response/create"
 	getbyid "api-estoque/internal/model/stock_items/response/get_by_id"
 	"api-estoque/internal/model/stock_items/response/list"
+	movecreate "api-estoque/internal/model/stock_moves/response/create"
+	warehouseModel "api-estoque/internal/model/warehouse"
+	productRepo "api-estoque/internal/repositories/product"
 	stockitemsRepo "api-estoque/internal/repositories/stock_items"
+	warehouseRepo "api-estoque/internal/repositories/warehouse"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/sirupsen/logrus"
 )
 
+var ErrOperationRefused = errors.New("operacao recusada")
+
 type Service struct {
-	Repository *stockitemsRepo.Repository
-	Logger     *logrus.Logger
+	Repository          *stockitemsRepo.Repository
+	WarehouseRepository *warehouseRepo.Repository
+	ProductRepository   *productRepo.Repository
+	Logger              *logrus.Logger
 }
 
-func New(repository *stockitemsRepo.Repository, logger *logrus.Logger) *Service {
+func New(repository *stockitemsRepo.Repository, warehouseRepository *warehouseRepo.Repository, productRepository *productRepo.Repository, logger *logrus.Logger) *Service {
 	return &Service{
-		Repository: repository,
-		Logger:     logger,
+		Repository:          repository,
+		WarehouseRepository: warehouseRepository,
+		ProductRepository:   productRepository,
+		Logger:              logger,
 	}
 }
 
-func (s *Service) List() *list.ListResponse {
-	stockItems, err := s.Repository.List()
+func (s *Service) List(page *pagination.Page) *list.ListResponse {
+	stockItems, err := s.Repository.List(page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return &list.ListResponse{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
 	if err != nil {
 		s.Logger.Errorf("(StockItems) List - %v", err)
 		return &list.ListResponse{
@@ -35,15 +59,69 @@ func (s *Service) List() *list.ListResponse {
 		}
 	}
 
+	var nextCursor *string
+	if pagination.Trim(stockItems, page.Limit) {
+		last := (*stockItems)[len(*stockItems)-1]
+		nextCursor = pagination.Encode(&pagination.Cursor{ProductId: last.ProductId, WarehouseId: last.WarehouseId})
+	}
+
 	return &list.ListResponse{
 		Status:     http.StatusOK,
 		Msg:        "Sucesso",
 		StockItems: stockItems,
+		NextCursor: nextCursor,
+	}
+}
+
+// Export envia todas as posicoes de estoque, com os nomes de produto e galpao,
+// para o writer conforme sao lidas do banco
+func (s *Service) Export(w export.Writer) *exportfile.ExportFileResponse {
+	rows := 0
+	err := s.Repository.Export(func(item *stockitemsModel.ExportRow) error {
+		rows++
+		return w.Write(item.Values()...)
+	})
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		s.Logger.Errorf("(StockItem) Export - linha %d: %v", rows, err)
+		return &exportfile.ExportFileResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao exportar itens de estoque",
+		}
+	}
+
+	return &exportfile.ExportFileResponse{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+		Rows:   rows,
 	}
 }
 
 func (s *Service) Create(stockItems *stockitemsModel.StockItems) *create.CreateResponse {
-	stockItems, err := s.Repository.Create(stockItems)
+	warnings, err := s.CheckReceipt(stockItems.WarehouseId, stockItems.ProductId, *stockItems.Quantity)
+	if errors.Is(err, ErrOperationRefused) {
+		return &create.CreateResponse{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(StockItems) Create - %v", err)
+		return &create.CreateResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao verificar galpao de destino da entrada",
+		}
+	}
+
+	stockItems, err = s.Repository.Create(stockItems)
+	if errors.Is(err, stockitemsRepo.ErrAlreadyExists) {
+		return &create.CreateResponse{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	}
 	if err != nil {
 		s.Logger.Errorf("(StockItems) Create - %v", err)
 		return &create.CreateResponse{
@@ -57,11 +135,18 @@ func (s *Service) Create(stockItems *stockitemsModel.StockItems) *create.CreateR
 		Msg:         "Sucesso",
 		ProductId:   *stockItems.ProductId,
 		WarehouseId: *stockItems.WarehouseId,
+		Warnings:    warnings,
 	}
 }
 
 func (s *Service) GetByID(idWarehouse *uuid.UUID, idProduct *uuid.UUID) *getbyid.GetByIdResponse {
 	stockItems, err := s.Repository.GetByID(idWarehouse, idProduct)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &getbyid.GetByIdResponse{
+			Status: http.StatusNotFound,
+			Msg:    "item de estoque nao encontrado",
+		}
+	}
 	if err != nil {
 		s.Logger.Errorf("(StockItems) GetByID - %v", err)
 		return &getbyid.GetByIdResponse{
@@ -78,11 +163,77 @@ func (s *Service) GetByID(idWarehouse *uuid.UUID, idProduct *uuid.UUID) *getbyid
 		Quantity:    *stockItems.Quantity,
 		Reserved:    *stockItems.Reserved,
 		UpdatedAt:   *stockItems.UpdatedAt,
+		Version:     *stockItems.Version,
 	}
 }
 
-func (s *Service) Update(stockItems *stockitemsModel.StockItems) *httpresponse.Response {
-	err := s.Repository.Update(stockItems)
+func (s *Service) Update(stockItems *stockitemsModel.StockItems, expectedVersion *int64) *httpresponse.Response {
+	current, err := s.Repository.GetByID(stockItems.WarehouseId, stockItems.ProductId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &httpresponse.Response{
+			Status: http.StatusNotFound,
+			Msg:    "item de estoque nao encontrado",
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(StockItems) Update - %v", err)
+		return &httpresponse.Response{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao buscar item de estoque para atualizacao",
+		}
+	}
+
+	if expectedVersion != nil && *current.Version != *expectedVersion {
+		return &httpresponse.Response{
+			Status: http.StatusPreconditionFailed,
+			Msg:    stockitemsRepo.ErrVersionMismatch.Error(),
+		}
+	}
+
+	if stockItems.Reserved != nil && *stockItems.Reserved > *current.Reserved {
+		err = s.checkProduct(stockItems.ProductId, productModel.OperationReserve)
+		if errors.Is(err, ErrOperationRefused) {
+			return &httpresponse.Response{
+				Status: http.StatusConflict,
+				Msg:    err.Error(),
+			}
+		}
+		if err != nil {
+			s.Logger.Errorf("(StockItems) Update - %v", err)
+			return &httpresponse.Response{
+				Status: http.StatusInternalServerError,
+				Msg:    "falha ao verificar status do produto",
+			}
+		}
+	}
+
+	var warnings []string
+	if stockItems.Quantity != nil {
+		if delta := *stockItems.Quantity - *current.Quantity; delta > 0 {
+			warnings, err = s.CheckReceipt(stockItems.WarehouseId, stockItems.ProductId, delta)
+			if errors.Is(err, ErrOperationRefused) {
+				return &httpresponse.Response{
+					Status: http.StatusConflict,
+					Msg:    err.Error(),
+				}
+			}
+			if err != nil {
+				s.Logger.Errorf("(StockItems) Update - %v", err)
+				return &httpresponse.Response{
+					Status: http.StatusInternalServerError,
+					Msg:    "falha ao verificar galpao de destino da entrada",
+				}
+			}
+		}
+	}
+
+	err = s.Repository.Update(stockItems, expectedVersion)
+	if errors.Is(err, stockitemsRepo.ErrVersionMismatch) {
+		return &httpresponse.Response{
+			Status: http.StatusPreconditionFailed,
+			Msg:    err.Error(),
+		}
+	}
 	if err != nil {
 		s.Logger.Errorf("(StockItems) Update - %v", err)
 		return &httpresponse.Response{
@@ -92,24 +243,62 @@ func (s *Service) Update(stockItems *stockitemsModel.StockItems) *httpresponse.R
 	}
 
 	return &httpresponse.Response{
-		Status: http.StatusOK,
-		Msg:    "Sucesso",
+		Status:   http.StatusOK,
+		Msg:      "Sucesso",
+		Warnings: warnings,
+		Version:  stockItems.Version,
 	}
 }
 
-func (s *Service) DeductQuantity(baixa *stockitemsModel.StockItemsBaixa) *httpresponse.Response {
-	err := s.Repository.DeductQuantity(baixa)
+// DeductQuantity da baixa no item de estoque e grava a movimentacao no razao
+// na mesma transacao. Retorna o id da movimentacao.
+func (s *Service) DeductQuantity(baixa *stockitemsModel.StockItemsBaixa, createdBy *string) *movecreate.CreateResponse {
+	err := s.checkProduct(baixa.ProductId, productModel.OperationDeduct)
+	if errors.Is(err, ErrOperationRefused) {
+		return &movecreate.CreateResponse{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &movecreate.CreateResponse{
+			Status: http.StatusNotFound,
+			Msg:    "produto nao encontrado",
+		}
+	}
 	if err != nil {
 		s.Logger.Errorf("(StockItems) DeductQuantity - %v", err)
-		return &httpresponse.Response{
+		return &movecreate.CreateResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao verificar status do produto",
+		}
+	}
+
+	move, err := s.Repository.DeductQuantity(baixa, createdBy)
+	if errors.Is(err, stockitemsRepo.ErrInsufficientStock) {
+		return &movecreate.CreateResponse{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &movecreate.CreateResponse{
+			Status: http.StatusNotFound,
+			Msg:    "item de estoque nao encontrado",
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(StockItems) DeductQuantity - %v", err)
+		return &movecreate.CreateResponse{
 			Status: http.StatusInternalServerError,
 			Msg:    "falha ao executar a dedução de quantidade do estoque",
 		}
 	}
 
-	return &httpresponse.Response{
+	return &movecreate.CreateResponse{
 		Status: http.StatusOK,
 		Msg:    "Sucesso",
+		Id:     *move.Id,
 	}
 }
 
@@ -128,3 +317,80 @@ func (s *Service) Delete(idWarehouse *uuid.UUID, idProduct *uuid.UUID) *httpresp
 		Msg:    "Sucesso",
 	}
 }
+
+// CheckReceipt verifica se o galpao esta ativo, se o status do produto permite
+// entradas e se a entrada de quantity unidades do produto cabe no galpao. Com a politica de capacidade "warn" o excesso
+// vira um aviso; com "reject" retorna ErrOperationRefused.
+func (s *Service) CheckReceipt(idWarehouse *uuid.UUID, idProduct *uuid.UUID, quantity int64) ([]string, error) {
+	return s.CheckReceiptBatch(idWarehouse, idProduct, quantity, nil)
+}
+
+// CheckReceiptBatch e o CheckReceipt de uma entrada entre varias gravadas
+// juntas. pending acumula, por galpao, o volume e o peso das entradas ja
+// aceitas do lote, que entram na verificacao de capacidade das seguintes.
+func (s *Service) CheckReceiptBatch(idWarehouse *uuid.UUID, idProduct *uuid.UUID, quantity int64, pending map[uuid.UUID]*warehouseModel.Load) ([]string, error) {
+	warehouse, err := s.WarehouseRepository.GetByID(idWarehouse)
+	if err != nil {
+		return nil, fmt.Errorf("get warehouse: %w", err)
+	}
+	if *warehouse.Status == warehouseModel.StatusClosed {
+		return nil, fmt.Errorf("%w: galpao encerrado", ErrOperationRefused)
+	}
+
+	product, err := s.ProductRepository.GetByID(idProduct)
+	if err != nil {
+		return nil, fmt.Errorf("get product: %w", err)
+	}
+	if err := product.Allows(productModel.OperationReceive); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOperationRefused, err)
+	}
+
+	volume, hasVolume := product.VolumeLiters()
+	weight, hasWeight := product.WeightKg()
+	if !hasVolume && !hasWeight {
+		return nil, nil
+	}
+
+	utilization, err := s.WarehouseRepository.GetUtilization(idWarehouse)
+	if err != nil {
+		return nil, fmt.Errorf("get warehouse utilization: %w", err)
+	}
+
+	addition := warehouseModel.Load{
+		VolumeLiters: volume * float64(quantity),
+		WeightKg:     weight * float64(quantity),
+	}
+	load := pending[*idWarehouse]
+	if load == nil {
+		load = &warehouseModel.Load{}
+	}
+
+	exceeded := utilization.CheckAddition(load.VolumeLiters+addition.VolumeLiters, load.WeightKg+addition.WeightKg)
+	if exceeded != nil && config.Env.CapacityPolicy == "reject" {
+		return nil, fmt.Errorf("%w: %v", ErrOperationRefused, exceeded)
+	}
+
+	if pending != nil {
+		load.VolumeLiters += addition.VolumeLiters
+		load.WeightKg += addition.WeightKg
+		pending[*idWarehouse] = load
+	}
+	if exceeded == nil {
+		return nil, nil
+	}
+
+	s.Logger.Warnf("(StockItems) galpao %s: %v", idWarehouse, exceeded)
+	return []string{exceeded.Error()}, nil
+}
+
+// checkProduct verifica se o status do produto permite a operacao de estoque.
+func (s *Service) checkProduct(idProduct *uuid.UUID, op productModel.Operation) error {
+	product, err := s.ProductRepository.GetByID(idProduct)
+	if err != nil {
+		return fmt.Errorf("get product: %w", err)
+	}
+	if err := product.Allows(op); err != nil {
+		return fmt.Errorf("%w: %v", ErrOperationRefused, err)
+	}
+	return nil
+}
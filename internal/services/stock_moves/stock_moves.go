@@ -1,98 +1,143 @@
 package stockmoves
 
 import (
+	"api-estoque/internal/model/export"
+	exportfile "api-estoque/internal/model/export/response/export_file"
+	"api-estoque/internal/model/pagination"
+	productModel "api-estoque/internal/model/product"
 	stockmovesModel "api-estoque/internal/model/stock_moves"
 	"api-estoque/internal/model/stock_moves/response/create"
 	getbyid "api-estoque/internal/model/stock_moves/response/get_by_id"
 	"api-estoque/internal/model/stock_moves/response/list"
+	productRepo "api-estoque/internal/repositories/product"
 	stockmovesRepo "api-estoque/internal/repositories/stock_moves"
+	"errors"
 	"net/http"
 
 	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/sirupsen/logrus"
 )
 
 type Service struct {
-	Repository *stockmovesRepo.Repository
-	Logger     *logrus.Logger
+	Repository        *stockmovesRepo.Repository
+	ProductRepository *productRepo.Repository
+	Logger            *logrus.Logger
 }
 
-func New(repository *stockmovesRepo.Repository, logger *logrus.Logger) *Service {
+func New(repository *stockmovesRepo.Repository, productRepository *productRepo.Repository, logger *logrus.Logger) *Service {
 	return &Service{
-		Repository: repository,
-		Logger:     logger,
+		Repository:        repository,
+		ProductRepository: productRepository,
+		Logger:            logger,
 	}
 }
 
-func (s *Service) List() *list.ListResponse {
-	stockMoves, err := s.Repository.List()
+// List consulta o razao de estoque com os filtros e a ordenacao de q
+func (s *Service) List(q *stockmovesModel.Query, page *pagination.Page) *list.ListResponse {
+	stockMoves, err := s.Repository.List(q, page)
+	return s.listResponse("List", stockMoves, err, page, q.Sort, "falha ao executar consulta para listar movimentos de estoque")
+}
+
+// Export envia o razao filtrado por q, com os nomes de produto e galpao, para
+// o writer conforme as linhas sao lidas do banco
+func (s *Service) Export(q *stockmovesModel.Query, w export.Writer) *exportfile.ExportFileResponse {
+	rows := 0
+	err := s.Repository.Export(q, func(m *stockmovesModel.ExportRow) error {
+		rows++
+		return w.Write(m.Values()...)
+	})
+	if err == nil {
+		err = w.Close()
+	}
 	if err != nil {
-		s.Logger.Errorf("(StockMoves) List - %v", err)
-		return &list.ListResponse{
+		s.Logger.Errorf("(StockMove) Export - linha %d: %v", rows, err)
+		return &exportfile.ExportFileResponse{
 			Status: http.StatusInternalServerError,
-			Msg:    "falha ao executar consulta para listar movimentos de estoque",
+			Msg:    "falha ao exportar movimentos de estoque",
 		}
 	}
 
-	return &list.ListResponse{
-		Status:     http.StatusOK,
-		Msg:        "Sucesso",
-		StockMoves: stockMoves,
+	return &exportfile.ExportFileResponse{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+		Rows:   rows,
 	}
 }
 
-func (s *Service) ListByProduct(idProduct *uuid.UUID) *list.ListResponse {
-	stockMoves, err := s.Repository.ListByProduct(idProduct)
-	if err != nil {
-		s.Logger.Errorf("(StockMoves) ListByProduct - %v", err)
-		return &list.ListResponse{
-			Status: http.StatusInternalServerError,
-			Msg:    "falha ao executar consulta para listar movimentos de estoque por produto",
-		}
-	}
+func (s *Service) ListByProduct(idProduct *uuid.UUID, page *pagination.Page) *list.ListResponse {
+	stockMoves, err := s.Repository.ListByProduct(idProduct, page)
+	return s.listResponse("ListByProduct", stockMoves, err, page, "", "falha ao executar consulta para listar movimentos de estoque por produto")
+}
 
-	return &list.ListResponse{
-		Status:     http.StatusOK,
-		Msg:        "Sucesso",
-		StockMoves: stockMoves,
-	}
+func (s *Service) ListByWarehouse(idWarehouse *uuid.UUID, page *pagination.Page) *list.ListResponse {
+	stockMoves, err := s.Repository.ListByWarehouse(idWarehouse, page)
+	return s.listResponse("ListByWarehouse", stockMoves, err, page, "", "falha ao executar consulta para listar movimentos de estoque por galpao")
 }
 
-func (s *Service) ListByWarehouse(idWarehouse *uuid.UUID) *list.ListResponse {
-	stockMoves, err := s.Repository.ListByWarehouse(idWarehouse)
+func (s *Service) ListByWarehouseAndProduct(idWarehouse *uuid.UUID, idProduct *uuid.UUID, page *pagination.Page) *list.ListResponse {
+	stockMoves, err := s.Repository.ListByWarehouseAndProduct(idWarehouse, idProduct, page)
+	return s.listResponse("ListByWarehouseAndProduct", stockMoves, err, page, "", "falha ao executar consulta para listar movimentos de estoque por galpao e produto")
+}
+
+// listResponse monta a pagina de movimentos, com o next_cursor quando ha mais
+// linhas. sort e a ordenacao da consulta, vazio nas listas por produto/galpao
+func (s *Service) listResponse(op string, stockMoves *[]stockmovesModel.StockMove, err error, page *pagination.Page, sort string, failMsg string) *list.ListResponse {
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return &list.ListResponse{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
 	if err != nil {
-		s.Logger.Errorf("(StockMoves) ListByWarehouse - %v", err)
+		s.Logger.Errorf("(StockMoves) %s - %v", op, err)
 		return &list.ListResponse{
 			Status: http.StatusInternalServerError,
-			Msg:    "falha ao executar consulta para listar movimentos de estoque por galpao",
+			Msg:    failMsg,
 		}
 	}
 
+	var nextCursor *string
+	if pagination.Trim(stockMoves, page.Limit) {
+		last := (*stockMoves)[len(*stockMoves)-1]
+		nextCursor = pagination.Encode(&pagination.Cursor{CreatedAt: last.CreatedAt, QtyMoved: last.QtyMoved, Id: last.Id, Sort: sort})
+	}
+
 	return &list.ListResponse{
 		Status:     http.StatusOK,
 		Msg:        "Sucesso",
 		StockMoves: stockMoves,
+		NextCursor: nextCursor,
 	}
 }
 
-func (s *Service) ListByWarehouseAndProduct(idWarehouse *uuid.UUID, idProduct *uuid.UUID) *list.ListResponse {
-	stockMoves, err := s.Repository.ListByWarehouseAndProduct(idWarehouse, idProduct)
+func (s *Service) Create(warehouse *stockmovesModel.StockMove) *create.CreateResponse {
+	product, err := s.ProductRepository.GetByID(warehouse.ProductId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &create.CreateResponse{
+			Status: http.StatusNotFound,
+			Msg:    "produto nao encontrado",
+		}
+	}
 	if err != nil {
-		s.Logger.Errorf("(StockMoves) ListByWarehouseAndProduct - %v", err)
-		return &list.ListResponse{
+		s.Logger.Errorf("(StockMoves) Create - %v", err)
+		return &create.CreateResponse{
 			Status: http.StatusInternalServerError,
-			Msg:    "falha ao executar consulta para listar movimentos de estoque por galpao e produto",
+			Msg:    "falha ao verificar status do produto",
 		}
 	}
 
-	return &list.ListResponse{
-		Status:     http.StatusOK,
-		Msg:        "Sucesso",
-		StockMoves: stockMoves,
+	op := productModel.OperationReceive
+	if *warehouse.QtyMoved < 0 {
+		op = productModel.OperationDeduct
+	}
+	if err := product.Allows(op); err != nil {
+		return &create.CreateResponse{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
 	}
-}
 
-func (s *Service) Create(warehouse *stockmovesModel.StockMove) *create.CreateResponse {
 	result, err := s.Repository.Create(warehouse)
 	if err != nil {
 		s.Logger.Errorf("(StockMoves) Create - %v", err)
@@ -127,6 +172,83 @@ func (s *Service) GetByID(id *uuid.UUID) *getbyid.GetByIdResponse {
 		WarehouseId: *stockMoves.WarehouseId,
 		QtyMoved:    *stockMoves.QtyMoved,
 		Reason:      *stockMoves.Reason,
+		ReversalOf:  stockMoves.ReversalOf,
+		ReversedBy:  stockMoves.ReversedBy,
+		CreatedBy:   stockMoves.CreatedBy,
 		CreatedAt:   *stockMoves.CreatedAt,
 	}
 }
+
+// Reverse estorna uma movimentacao criando outra com a quantidade oposta. O
+// estorno e uma correcao, entao so e recusado para produtos arquivados.
+func (s *Service) Reverse(id *uuid.UUID, reversal *stockmovesModel.Reversal) *create.CreateResponse {
+	original, err := s.Repository.GetByID(id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &create.CreateResponse{
+			Status: http.StatusNotFound,
+			Msg:    "movimentacao de estoque nao encontrada",
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(StockMoves) Reverse - %v", err)
+		return &create.CreateResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao buscar movimentacao de estoque para estorno",
+		}
+	}
+
+	product, err := s.ProductRepository.GetByID(original.ProductId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &create.CreateResponse{
+			Status: http.StatusNotFound,
+			Msg:    "produto nao encontrado",
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(StockMoves) Reverse - %v", err)
+		return &create.CreateResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao verificar status do produto",
+		}
+	}
+	if *product.Status == productModel.StatusArchived {
+		return &create.CreateResponse{
+			Status: http.StatusConflict,
+			Msg:    "produto arquivado, movimentacao nao pode ser estornada",
+		}
+	}
+
+	reason := "Estorno da movimentacao " + id.String()
+	if reversal.Reason != nil && *reversal.Reason != "" {
+		reason = *reversal.Reason
+	}
+
+	result, err := s.Repository.Reverse(id, reason, reversal.CreatedBy)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return &create.CreateResponse{
+			Status: http.StatusNotFound,
+			Msg:    "movimentacao de estoque nao encontrada",
+		}
+	case errors.Is(err, stockmovesRepo.ErrIsReversal),
+		errors.Is(err, stockmovesRepo.ErrAlreadyReversed),
+		errors.Is(err, stockmovesRepo.ErrReversalUnavailable),
+		errors.Is(err, stockmovesRepo.ErrWarehouseClosed):
+		return &create.CreateResponse{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	case err != nil:
+		s.Logger.Errorf("(StockMoves) Reverse - %v", err)
+		return &create.CreateResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao executar estorno de movimentacao de estoque",
+		}
+	}
+
+	return &create.CreateResponse{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+		Id:     *result.Id,
+	}
+}
@@ -0,0 +1,151 @@
+package stream
+
+import (
+	"api-estoque/internal/model/outbox"
+	streamModel "api-estoque/internal/model/stream"
+	"api-estoque/internal/publisher"
+	outboxRepository "api-estoque/internal/repositories/outbox"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// replayBatch e quantos eventos sao lidos do outbox por vez ao retomar
+	replayBatch = 500
+	// maxReplay limita a retomada; acima disso o cliente recebe stream.reset
+	maxReplay = 5000
+	// pingInterval mantem a conexao aberta em proxies que derrubam conexoes ociosas
+	pingInterval = 15 * time.Second
+)
+
+// ErrLagging encerra o stream de um cliente que nao acompanhou os eventos. Ele
+// deve reconectar com o Last-Event-ID para retomar do outbox.
+var ErrLagging = errors.New("cliente nao acompanhou o stream")
+
+// Sink e o transporte do stream; SSE e WebSocket implementam
+type Sink interface {
+	Send(event *outbox.Event) error
+	// Reset avisa que a retomada nao foi possivel e os saldos devem ser recarregados
+	Reset() error
+	Ping() error
+}
+
+type Service struct {
+	Repository *outboxRepository.Repository
+	Bus        *publisher.Memory
+	Logger     *logrus.Logger
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+func New(repository *outboxRepository.Repository, logger *logrus.Logger) *Service {
+	return &Service{
+		Repository: repository,
+		Bus:        publisher.NewMemory(),
+		Logger:     logger,
+		done:       make(chan struct{}),
+	}
+}
+
+// Shutdown encerra os streams abertos. O shutdown do servidor HTTP nao
+// interrompe conexoes longas, entao e registrado com RegisterOnShutdown.
+func (s *Service) Shutdown() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// Publish recebe os eventos do outbox e os repassa aos streams abertos. Com
+// isso o Service tambem e um publisher.Publisher.
+func (s *Service) Publish(ctx context.Context, event *outbox.Event) error {
+	return s.Bus.Publish(ctx, event)
+}
+
+// Stream envia ao sink as alteracoes de saldo que combinam com o filtro, ate o
+// contexto ser cancelado ou o envio falhar. Com lastEventId, primeiro reenvia os
+// eventos publicados depois dele, lidos do outbox; a assinatura ao vivo comeca
+// antes da leitura, e os eventos que chegam pelos dois caminhos sao enviados
+// uma vez so.
+func (s *Service) Stream(ctx context.Context, filter *streamModel.Filter, lastEventId *int64, sink Sink) error {
+	events, cancel := s.Bus.Subscribe()
+	defer cancel()
+
+	replayed := map[int64]bool{}
+	if lastEventId != nil {
+		var err error
+		replayed, err = s.replay(filter, *lastEventId, sink)
+		if err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.done:
+			return nil
+		case <-ticker.C:
+			if err := sink.Ping(); err != nil {
+				return err
+			}
+		case event, ok := <-events:
+			if !ok {
+				return ErrLagging
+			}
+			if !filter.Matches(event) || replayed[event.Sequence] {
+				continue
+			}
+			if err := sink.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// replay envia os eventos depois de lastEventId e retorna as sequencias
+// enviadas. Se eventos do intervalo ja foram removidos do outbox, ou se sao
+// mais do que maxReplay, envia stream.reset no lugar.
+func (s *Service) replay(filter *streamModel.Filter, lastEventId int64, sink Sink) (map[int64]bool, error) {
+	replayed := map[int64]bool{}
+
+	oldest, err := s.Repository.OldestSequence()
+	if err != nil {
+		s.Logger.Errorf("(Stream) Replay - %v", err)
+		return nil, sink.Reset()
+	}
+	if oldest > lastEventId+1 {
+		return replayed, sink.Reset()
+	}
+
+	var pending []outbox.Event
+	after := lastEventId
+	for {
+		batch, err := s.Repository.Published(after, replayBatch, streamModel.LevelTypes, filter.WarehouseIds, filter.ProductIds)
+		if err != nil {
+			s.Logger.Errorf("(Stream) Replay - %v", err)
+			return replayed, sink.Reset()
+		}
+		pending = append(pending, batch...)
+		if len(pending) > maxReplay {
+			return replayed, sink.Reset()
+		}
+		if len(batch) < replayBatch {
+			break
+		}
+		after = batch[len(batch)-1].Sequence
+	}
+
+	for i := range pending {
+		if err := sink.Send(&pending[i]); err != nil {
+			return nil, err
+		}
+		replayed[pending[i].Sequence] = true
+	}
+	return replayed, nil
+}
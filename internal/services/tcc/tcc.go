@@ -0,0 +1,205 @@
+package tcc
+
+import (
+	"api-estoque/internal/config"
+	productModel "api-estoque/internal/model/product"
+	tccModel "api-estoque/internal/model/tcc"
+	"api-estoque/internal/model/tcc/response/transaction"
+	stockitemsRepo "api-estoque/internal/repositories/stock_items"
+	tccRepo "api-estoque/internal/repositories/tcc"
+	stockitems "api-estoque/internal/services/stock_items"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// expiryBatch e quantas transacoes vencidas sao lidas por vez
+const expiryBatch = 100
+
+// Service e o participante de estoque nas transacoes TCC do checkout: o Try
+// reserva as linhas com prazo, o Confirm transforma a reserva em baixa e o
+// Cancel a libera. Tries sem Confirm nem Cancel dentro do prazo sao cancelados
+// pelo StartExpiry.
+type Service struct {
+	Repository        *tccRepo.Repository
+	StockItemsService *stockitems.Service
+	Logger            *logrus.Logger
+}
+
+func New(repository *tccRepo.Repository, stockItemsService *stockitems.Service, logger *logrus.Logger) *Service {
+	return &Service{
+		Repository:        repository,
+		StockItemsService: stockItemsService,
+		Logger:            logger,
+	}
+}
+
+func (s *Service) Try(txId string, try *tccModel.Try) *transaction.TransactionResponse {
+	// Os produtos so sao verificados no primeiro Try; a repeticao devolve o
+	// mesmo resultado mesmo que o status de algum produto tenha mudado
+	_, err := s.Repository.GetByID(txId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = s.StockItemsService.CheckOperation(try.Lines, productModel.OperationReserve)
+		if errors.Is(err, stockitems.ErrOperationRefused) {
+			return &transaction.TransactionResponse{
+				Status: http.StatusConflict,
+				Msg:    err.Error(),
+			}
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &transaction.TransactionResponse{
+				Status: http.StatusNotFound,
+				Msg:    "produto nao encontrado",
+			}
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(Tcc) Try - %v", err)
+		return &transaction.TransactionResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao verificar transacao",
+		}
+	}
+
+	t, err := s.Repository.Try(txId, try, try.Timeout(config.Env.TccDefaultTimeout))
+	return s.response("Try", t, err, "falha ao reservar estoque da transacao")
+}
+
+func (s *Service) Confirm(txId string, confirmedBy *string) *transaction.TransactionResponse {
+	t, err := s.Repository.Confirm(txId, confirmedBy)
+	return s.response("Confirm", t, err, "falha ao confirmar transacao")
+}
+
+func (s *Service) Cancel(txId string) *transaction.TransactionResponse {
+	t, err := s.Repository.Cancel(txId)
+	return s.response("Cancel", t, err, "falha ao cancelar transacao")
+}
+
+func (s *Service) GetByID(txId string) *transaction.TransactionResponse {
+	t, err := s.Repository.GetByID(txId)
+	return s.response("GetByID", t, err, "falha ao buscar transacao")
+}
+
+// response monta a resposta de uma fase. Recusas pelo estado da transacao ou
+// pelo saldo sao 409; failMsg e a mensagem dos erros inesperados.
+func (s *Service) response(method string, t *tccModel.Transaction, err error, failMsg string) *transaction.TransactionResponse {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &transaction.TransactionResponse{
+			Status: http.StatusNotFound,
+			Msg:    "transacao nao encontrada",
+		}
+	}
+	if errors.Is(err, tccRepo.ErrTxCancelled) ||
+		errors.Is(err, tccRepo.ErrTxExpired) ||
+		errors.Is(err, tccRepo.ErrTxConfirmed) ||
+		errors.Is(err, tccRepo.ErrLinesMismatch) ||
+		errors.Is(err, stockitemsRepo.ErrInsufficientStock) ||
+		errors.Is(err, stockitemsRepo.ErrInsufficientReserved) {
+		return &transaction.TransactionResponse{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(Tcc) %s - %v", method, err)
+		return &transaction.TransactionResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    failMsg,
+		}
+	}
+
+	return &transaction.TransactionResponse{
+		Status:            http.StatusOK,
+		Msg:               "Sucesso",
+		TxId:              t.TxId,
+		TransactionStatus: t.Status,
+		Lines:             t.Lines,
+		ExpiresAt:         t.ExpiresAt,
+		CancelReason:      t.CancelReason,
+		CreatedBy:         t.CreatedBy,
+		CreatedAt:         t.CreatedAt,
+		UpdatedAt:         t.UpdatedAt,
+	}
+}
+
+// StartExpiry cancela periodicamente os Tries vencidos, liberando a reserva,
+// ate o contexto ser cancelado. Assim um coordenador parado nao prende o
+// estoque.
+func (s *Service) StartExpiry(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.expireDue(ctx)
+			}
+		}
+	}()
+}
+
+func (s *Service) expireDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		ids, err := s.Repository.Due(expiryBatch)
+		if err != nil {
+			s.Logger.Errorf("(Tcc) Expiry - %v", err)
+			return
+		}
+
+		expired := 0
+		for _, id := range ids {
+			t, err := s.Repository.Expire(id)
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				s.Logger.Errorf("(Tcc) Expiry %s - %v", id, err)
+				continue
+			}
+			if t.Status == tccModel.StatusCancelled {
+				expired++
+			}
+		}
+		if expired > 0 {
+			s.Logger.Infof("(Tcc) Expiry - %d transacoes vencidas canceladas", expired)
+		}
+
+		// um lote em que nada venceu so tem transacoes que falham; a proxima
+		// rodada tenta de novo
+		if len(ids) < expiryBatch || expired == 0 {
+			return
+		}
+	}
+}
+
+// StartCleanup remove periodicamente as transacoes confirmadas ou canceladas
+// ha mais de retention, ate o contexto ser cancelado.
+func (s *Service) StartCleanup(ctx context.Context, interval time.Duration, retention time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := s.Repository.DeleteFinished(retention)
+				if err != nil {
+					s.Logger.Errorf("(Tcc) Cleanup - %v", err)
+					continue
+				}
+				if deleted > 0 {
+					s.Logger.Infof("(Tcc) Cleanup - %d transacoes finalizadas removidas", deleted)
+				}
+			}
+		}
+	}()
+}
@@ -1,15 +1,23 @@
 package warehouse
 
 import (
+	"api-estoque/internal/config"
 	httpresponse "api-estoque/internal/model/http_response"
+	"api-estoque/internal/model/pagination"
 	warehouseModel "api-estoque/internal/model/warehouse"
+	closewarehouse "api-estoque/internal/model/warehouse/response/close_warehouse"
 	"api-estoque/internal/model/warehouse/response/create"
 	getbyid "api-estoque/internal/model/warehouse/response/get_by_id"
 	"api-estoque/internal/model/warehouse/response/list"
+	"api-estoque/internal/model/warehouse/response/nearest"
+	"api-estoque/internal/model/warehouse/response/utilization"
 	warehouseRepo "api-estoque/internal/repositories/warehouse"
+	"errors"
 	"net/http"
+	"sort"
 
 	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/sirupsen/logrus"
 )
 
@@ -25,8 +33,14 @@ func New(repository *warehouseRepo.Repository, logger *logrus.Logger) *Service {
 	}
 }
 
-func (s *Service) List() *list.ListResponse {
-	warehouses, err := s.Repository.List()
+func (s *Service) List(includeClosed bool, page *pagination.Page) *list.ListResponse {
+	warehouses, err := s.Repository.List(includeClosed, page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return &list.ListResponse{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
 	if err != nil {
 		s.Logger.Errorf("(Warehouse) List - %v", err)
 		return &list.ListResponse{
@@ -35,10 +49,17 @@ func (s *Service) List() *list.ListResponse {
 		}
 	}
 
+	var nextCursor *string
+	if pagination.Trim(warehouses, page.Limit) {
+		last := (*warehouses)[len(*warehouses)-1]
+		nextCursor = pagination.Encode(&pagination.Cursor{CreatedAt: last.CreatedAt, Id: last.Id})
+	}
+
 	return &list.ListResponse{
 		Status:     http.StatusOK,
 		Msg:        "Sucesso",
 		Warehouses: warehouses,
+		NextCursor: nextCursor,
 	}
 }
 
@@ -70,17 +91,98 @@ func (s *Service) GetByID(id *uuid.UUID) *getbyid.GetByIdResponse {
 	}
 
 	return &getbyid.GetByIdResponse{
-		Status:    http.StatusOK,
-		Msg:       "Sucesso",
-		Id:        *warehouse.Id,
-		Name:      *warehouse.Name,
-		Location:  *warehouse.Location,
-		CreatedAt: warehouse.CreatedAt,
+		Status:               http.StatusOK,
+		Msg:                  "Sucesso",
+		Id:                   *warehouse.Id,
+		Name:                 *warehouse.Name,
+		Location:             *warehouse.Location,
+		Region:               warehouse.Region,
+		Priority:             *warehouse.Priority,
+		Street:               warehouse.Street,
+		Number:               warehouse.Number,
+		Complement:           warehouse.Complement,
+		District:             warehouse.District,
+		City:                 warehouse.City,
+		State:                warehouse.State,
+		ZipCode:              warehouse.ZipCode,
+		Latitude:             warehouse.Latitude,
+		Longitude:            warehouse.Longitude,
+		VolumeCapacityLiters: warehouse.VolumeCapacityLiters,
+		WeightCapacityKg:     warehouse.WeightCapacityKg,
+		WarehouseStatus:      *warehouse.Status,
+		ClosedAt:             warehouse.ClosedAt,
+		CreatedAt:            warehouse.CreatedAt,
+		Version:              *warehouse.Version,
+	}
+}
+
+func (s *Service) Nearest(lat float64, lng float64, productId *uuid.UUID, limit int) *nearest.NearestResponse {
+	warehouses, err := s.Repository.ListWithProductAvailable(productId)
+	if err != nil {
+		s.Logger.Errorf("(Warehouse) Nearest - %v", err)
+		return &nearest.NearestResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao executar busca de galpoes mais proximos",
+		}
+	}
+
+	result := *warehouses
+	for i := range result {
+		result[i].DistanceKm = warehouseModel.GreatCircleDistance(lat, lng, *result[i].Latitude, *result[i].Longitude)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DistanceKm < result[j].DistanceKm
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return &nearest.NearestResponse{
+		Status:     http.StatusOK,
+		Msg:        "Sucesso",
+		Warehouses: result,
+	}
+}
+
+func (s *Service) Utilization(id *uuid.UUID) *utilization.UtilizationResponse {
+	u, err := s.Repository.GetUtilization(id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &utilization.UtilizationResponse{
+			Status: http.StatusNotFound,
+			Msg:    "galpao nao encontrado",
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(Warehouse) Utilization - %v", err)
+		return &utilization.UtilizationResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao calcular ocupacao do galpao",
+		}
+	}
+
+	u.ComputeUsage()
+
+	return &utilization.UtilizationResponse{
+		Status:      http.StatusOK,
+		Msg:         "Sucesso",
+		Utilization: *u,
 	}
 }
 
-func (s *Service) Update(warehouse *warehouseModel.Warehouse) *httpresponse.Response {
-	err := s.Repository.Update(warehouse)
+func (s *Service) Update(warehouse *warehouseModel.Warehouse, expectedVersion *int64) *httpresponse.Response {
+	err := s.Repository.Update(warehouse, expectedVersion)
+	if errors.Is(err, warehouseRepo.ErrVersionMismatch) {
+		return &httpresponse.Response{
+			Status: http.StatusPreconditionFailed,
+			Msg:    err.Error(),
+		}
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &httpresponse.Response{
+			Status: http.StatusNotFound,
+			Msg:    "galpao nao encontrado",
+		}
+	}
 	if err != nil {
 		s.Logger.Errorf("(Warehouse) Update - %v", err)
 		return &httpresponse.Response{
@@ -90,23 +192,92 @@ func (s *Service) Update(warehouse *warehouseModel.Warehouse) *httpresponse.Resp
 	}
 
 	return &httpresponse.Response{
-		Status: http.StatusOK,
-		Msg:    "Sucesso",
+		Status:  http.StatusOK,
+		Msg:     "Sucesso",
+		Version: warehouse.Version,
 	}
 }
 
-func (s *Service) Delete(id *uuid.UUID) *httpresponse.Response {
-	err := s.Repository.Delete(id)
-	if err != nil {
-		s.Logger.Errorf("(Warehouse) Delete - %v", err)
-		return &httpresponse.Response{
+// Close encerra o galpao. Com um galpao de destino o estoque restante e
+// transferido para ele, respeitando a politica de capacidade do destino.
+func (s *Service) Close(id *uuid.UUID, targetId *uuid.UUID, closedBy *string) *closewarehouse.CloseResponse {
+	var warnings []string
+	if targetId != nil {
+		if *targetId == *id {
+			return &closewarehouse.CloseResponse{
+				Status: http.StatusBadRequest,
+				Msg:    "galpao de destino deve ser diferente do galpao encerrado",
+			}
+		}
+
+		exceeded, err := s.checkTransferCapacity(id, targetId)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			s.Logger.Errorf("(Warehouse) Close - %v", err)
+			return &closewarehouse.CloseResponse{
+				Status: http.StatusInternalServerError,
+				Msg:    "falha ao verificar capacidade do galpao de destino",
+			}
+		}
+		if exceeded != "" {
+			if config.Env.CapacityPolicy == "reject" {
+				return &closewarehouse.CloseResponse{
+					Status: http.StatusConflict,
+					Msg:    "transferencia recusada: " + exceeded,
+				}
+			}
+			s.Logger.Warnf("(Warehouse) galpao %s: %s", targetId, exceeded)
+			warnings = append(warnings, exceeded)
+		}
+	}
+
+	transferred, err := s.Repository.Close(id, targetId, closedBy)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return &closewarehouse.CloseResponse{
+			Status: http.StatusNotFound,
+			Msg:    "galpao nao encontrado",
+		}
+	case errors.Is(err, warehouseRepo.ErrInvalidTarget):
+		return &closewarehouse.CloseResponse{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	case errors.Is(err, warehouseRepo.ErrWarehouseClosed), errors.Is(err, warehouseRepo.ErrWarehouseNotEmpty):
+		return &closewarehouse.CloseResponse{
+			Status: http.StatusConflict,
+			Msg:    err.Error(),
+		}
+	case err != nil:
+		s.Logger.Errorf("(Warehouse) Close - %v", err)
+		return &closewarehouse.CloseResponse{
 			Status: http.StatusInternalServerError,
-			Msg:    "falha ao deletar galpao",
+			Msg:    "falha ao encerrar galpao",
 		}
 	}
 
-	return &httpresponse.Response{
-		Status: http.StatusOK,
-		Msg:    "Sucesso",
+	return &closewarehouse.CloseResponse{
+		Status:            http.StatusOK,
+		Msg:               "Sucesso",
+		Id:                *id,
+		TargetWarehouseId: targetId,
+		TransferredItems:  transferred,
+		Warnings:          warnings,
+	}
+}
+
+// checkTransferCapacity verifica se todo o estoque do galpao de origem cabe no
+// destino e retorna a descricao do excesso, ou "" se couber.
+func (s *Service) checkTransferCapacity(id *uuid.UUID, targetId *uuid.UUID) (string, error) {
+	source, err := s.Repository.GetUtilization(id)
+	if err != nil {
+		return "", err
+	}
+	target, err := s.Repository.GetUtilization(targetId)
+	if err != nil {
+		return "", err
+	}
+	if exceeded := target.CheckAddition(source.UsedVolumeLiters, source.UsedWeightKg); exceeded != nil {
+		return exceeded.Error(), nil
 	}
+	return "", nil
 }
@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"api-estoque/internal/config"
+	webhookModel "api-estoque/internal/model/webhook"
+	webhookRepo "api-estoque/internal/repositories/webhook"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// deliveryBatch e quantas entregas sao reservadas por vez
+	deliveryBatch = 50
+	// deliveryWorkers e quantas entregas sao enviadas em paralelo
+	deliveryWorkers = 8
+	// espera antes da segunda tentativa; dobra a cada falha ate retryMaxDelay
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = 6 * time.Hour
+)
+
+// ErrPrivateTarget recusa a conexao com um endereco da rede interna
+var ErrPrivateTarget = errors.New("destino na rede interna recusado")
+
+// newClient monta o cliente das entregas. O endereco e verificado depois da
+// resolucao do nome, na hora da conexao, para que um DNS que passe a apontar
+// para a rede interna nao seja seguido. Proxies do ambiente sao ignorados pelo
+// mesmo motivo.
+func newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !webhookModel.PublicAddr(addrPort.Addr()) {
+				return ErrPrivateTarget
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		// redirecionamentos contam como falha: a URL cadastrada deve responder
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// StartDelivery envia periodicamente as entregas pendentes, ate o contexto ser
+// cancelado.
+func (s *Service) StartDelivery(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.deliverDue(ctx)
+			}
+		}
+	}()
+}
+
+func (s *Service) deliverDue(ctx context.Context) {
+	timeout := config.Env.WebhookTimeout
+	for ctx.Err() == nil {
+		attempts, err := s.Repository.Claim(deliveryBatch, 2*timeout)
+		if err != nil {
+			s.Logger.Errorf("(Webhook) Delivery - %v", err)
+			return
+		}
+
+		queue := make(chan *webhookRepo.Attempt)
+		var wg sync.WaitGroup
+		for i := 0; i < deliveryWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for a := range queue {
+					s.deliver(ctx, a, timeout)
+				}
+			}()
+		}
+		for i := range attempts {
+			queue <- &attempts[i]
+		}
+		close(queue)
+		wg.Wait()
+
+		if len(attempts) < deliveryBatch {
+			return
+		}
+	}
+}
+
+// deliver envia uma entrega e registra o resultado. O corpo e o evento do
+// outbox; o receptor deve usar X-Webhook-Event-Id para descartar repeticoes.
+func (s *Service) deliver(ctx context.Context, a *webhookRepo.Attempt, timeout time.Duration) {
+	statusCode, err := s.send(ctx, a, timeout)
+	if err == nil {
+		if err := s.Repository.RecordSuccess(a, statusCode); err != nil {
+			s.Logger.Errorf("(Webhook) Delivery - %v", err)
+		}
+		return
+	}
+
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+	env := config.Env
+	reason := fmt.Sprintf("desativada apos %d falhas seguidas", env.WebhookDisableAfter)
+	disabled, recordErr := s.Repository.RecordFailure(a, code, err.Error(), env.WebhookMaxAttempts, retryBaseDelay, retryMaxDelay, env.WebhookDisableAfter, reason)
+	if recordErr != nil {
+		s.Logger.Errorf("(Webhook) Delivery - %v", recordErr)
+		return
+	}
+	if disabled {
+		s.Logger.Warnf("(Webhook) Delivery - assinatura %s desativada apos %d falhas seguidas: %v", a.SubscriptionId, env.WebhookDisableAfter, err)
+	}
+}
+
+// send faz o POST assinado e retorna o status da resposta, ou 0 se nao houve
+// resposta. Qualquer status fora de 2xx e erro.
+func (s *Service) send(ctx context.Context, a *webhookRepo.Attempt, timeout time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.Url, bytes.NewReader(a.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "api-estoque-webhooks/1.0")
+	req.Header.Set("X-Webhook-Id", a.Id.String())
+	req.Header.Set("X-Webhook-Event", a.EventType)
+	req.Header.Set("X-Webhook-Event-Id", a.EventId.String())
+	req.Header.Set("X-Webhook-Attempt", strconv.Itoa(a.Attempts+1))
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", webhookModel.Sign(a.Secret, timestamp, a.Payload))
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// o corpo da resposta nao e guardado, ele pode trazer dados do receptor;
+	// e so esvaziado para reaproveitar a conexao
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<20))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, errors.New(res.Status)
+	}
+	return res.StatusCode, nil
+}
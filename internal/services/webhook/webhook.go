@@ -0,0 +1,205 @@
+package webhook
+
+import (
+	httpresponse "api-estoque/internal/model/http_response"
+	"api-estoque/internal/model/outbox"
+	"api-estoque/internal/model/pagination"
+	webhookModel "api-estoque/internal/model/webhook"
+	"api-estoque/internal/model/webhook/response/create"
+	"api-estoque/internal/model/webhook/response/deliveries"
+	getbyid "api-estoque/internal/model/webhook/response/get_by_id"
+	"api-estoque/internal/model/webhook/response/list"
+	webhookRepo "api-estoque/internal/repositories/webhook"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+type Service struct {
+	Repository *webhookRepo.Repository
+	Logger     *logrus.Logger
+	Client     *http.Client
+}
+
+func New(repository *webhookRepo.Repository, logger *logrus.Logger) *Service {
+	return &Service{
+		Repository: repository,
+		Logger:     logger,
+		Client:     newClient(),
+	}
+}
+
+func (s *Service) Create(subscription *webhookModel.Subscription) *create.CreateResponse {
+	id, err := s.Repository.Create(subscription)
+	if err != nil {
+		s.Logger.Errorf("(Webhook) Create - %v", err)
+		return &create.CreateResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao criar assinatura de webhook",
+		}
+	}
+
+	return &create.CreateResponse{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+		Id:     *id,
+		Secret: *subscription.Secret,
+	}
+}
+
+func (s *Service) List() *list.ListResponse {
+	subscriptions, err := s.Repository.List()
+	if err != nil {
+		s.Logger.Errorf("(Webhook) List - %v", err)
+		return &list.ListResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao listar assinaturas de webhook",
+		}
+	}
+
+	return &list.ListResponse{
+		Status:        http.StatusOK,
+		Msg:           "Sucesso",
+		Subscriptions: subscriptions,
+	}
+}
+
+func (s *Service) GetByID(id *uuid.UUID) *getbyid.GetByIdResponse {
+	subscription, err := s.Repository.GetByID(id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &getbyid.GetByIdResponse{
+			Status: http.StatusNotFound,
+			Msg:    "assinatura de webhook nao encontrada",
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(Webhook) GetByID - %v", err)
+		return &getbyid.GetByIdResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao buscar assinatura de webhook",
+		}
+	}
+
+	return &getbyid.GetByIdResponse{
+		Status:              http.StatusOK,
+		Msg:                 "Sucesso",
+		Id:                  subscription.Id,
+		Url:                 subscription.Url,
+		EventTypes:          subscription.EventTypes,
+		WarehouseIds:        subscription.WarehouseIds,
+		ProductIds:          subscription.ProductIds,
+		IsActive:            subscription.IsActive,
+		ConsecutiveFailures: subscription.ConsecutiveFailures,
+		DisabledAt:          subscription.DisabledAt,
+		DisabledReason:      subscription.DisabledReason,
+		CreatedBy:           subscription.CreatedBy,
+		CreatedAt:           subscription.CreatedAt,
+		UpdatedAt:           subscription.UpdatedAt,
+	}
+}
+
+// Update altera a assinatura. Com 'isActive' false, registra quem a desativou.
+func (s *Service) Update(subscription *webhookModel.Subscription, changedBy *string) *httpresponse.Response {
+	reason := "desativada manualmente"
+	if changedBy != nil {
+		reason = "desativada por " + *changedBy
+	}
+
+	err := s.Repository.Update(subscription, reason)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &httpresponse.Response{
+			Status: http.StatusNotFound,
+			Msg:    "assinatura de webhook nao encontrada",
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(Webhook) Update - %v", err)
+		return &httpresponse.Response{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao atualizar assinatura de webhook",
+		}
+	}
+
+	return &httpresponse.Response{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+	}
+}
+
+func (s *Service) Delete(id *uuid.UUID) *httpresponse.Response {
+	err := s.Repository.Delete(id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &httpresponse.Response{
+			Status: http.StatusNotFound,
+			Msg:    "assinatura de webhook nao encontrada",
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(Webhook) Delete - %v", err)
+		return &httpresponse.Response{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao remover assinatura de webhook",
+		}
+	}
+
+	return &httpresponse.Response{
+		Status: http.StatusOK,
+		Msg:    "Sucesso",
+	}
+}
+
+// Deliveries retorna o log de entregas da assinatura, da mais recente a mais antiga
+func (s *Service) Deliveries(id *uuid.UUID, status *string, page *pagination.Page) *deliveries.DeliveriesResponse {
+	if _, err := s.Repository.GetByID(id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &deliveries.DeliveriesResponse{
+				Status: http.StatusNotFound,
+				Msg:    "assinatura de webhook nao encontrada",
+			}
+		}
+		s.Logger.Errorf("(Webhook) Deliveries - %v", err)
+		return &deliveries.DeliveriesResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao buscar assinatura de webhook",
+		}
+	}
+
+	log, err := s.Repository.Deliveries(id, status, page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return &deliveries.DeliveriesResponse{
+			Status: http.StatusBadRequest,
+			Msg:    err.Error(),
+		}
+	}
+	if err != nil {
+		s.Logger.Errorf("(Webhook) Deliveries - %v", err)
+		return &deliveries.DeliveriesResponse{
+			Status: http.StatusInternalServerError,
+			Msg:    "falha ao buscar entregas de webhook",
+		}
+	}
+
+	var nextCursor *string
+	if pagination.Trim(log, page.Limit) {
+		last := (*log)[len(*log)-1]
+		nextCursor = pagination.Encode(&pagination.Cursor{CreatedAt: last.CreatedAt, Id: last.Id})
+	}
+
+	return &deliveries.DeliveriesResponse{
+		Status:     http.StatusOK,
+		Msg:        "Sucesso",
+		Deliveries: log,
+		NextCursor: nextCursor,
+	}
+}
+
+// Publish recebe os eventos do outbox e cria as entregas das assinaturas que
+// combinam com cada um. Com isso o Service tambem e um publisher.Publisher.
+func (s *Service) Publish(ctx context.Context, event *outbox.Event) error {
+	_, err := s.Repository.Enqueue(event)
+	return err
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local grava os arquivos em um diretorio do servidor. PublicURL e o prefixo
+// pelo qual o diretorio e servido, ex: "/api/v1/estoque/uploads".
+type Local struct {
+	Dir       string
+	PublicURL string
+}
+
+func NewLocal(dir string, publicURL string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create storage dir: %w", err)
+	}
+	return &Local{Dir: dir, PublicURL: strings.TrimRight(publicURL, "/")}, nil
+}
+
+func (l *Local) Put(ctx context.Context, key string, contentType string, body io.Reader, size int64) (string, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("create dir: %w", err)
+	}
+
+	// grava em arquivo temporario e renomeia, para nunca servir um arquivo pela metade
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("close file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("rename file: %w", err)
+	}
+
+	return l.PublicURL + "/" + key, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (l *Local) Key(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, l.PublicURL+"/")
+	return key, ok && key != ""
+}
+
+// path resolve a key dentro de Dir, recusando caminhos que escapem do diretorio
+func (l *Local) path(key string) (string, error) {
+	if !fs.ValidPath(key) {
+		return "", fmt.Errorf("key invalida: %q", key)
+	}
+	return filepath.Join(l.Dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"api-estoque/internal/config"
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("arquivo nao encontrado no armazenamento")
+
+// Storage guarda arquivos enviados pelos clientes e devolve a URL publica de
+// cada um. key e um caminho relativo, como "products/<id>/<arquivo>.jpg".
+type Storage interface {
+	Put(ctx context.Context, key string, contentType string, body io.Reader, size int64) (string, error)
+	Delete(ctx context.Context, key string) error
+	// Key devolve a key do arquivo de uma URL retornada por Put; ok e false
+	// para URLs de fora deste armazenamento
+	Key(url string) (key string, ok bool)
+}
+
+// New cria o armazenamento escolhido em STORAGE_BACKEND
+func New() (Storage, error) {
+	env := config.Env
+	if env.StorageBackend == "s3" {
+		return NewS3(env.S3Endpoint, env.S3Region, env.S3Bucket, env.S3AccessKey, env.S3SecretKey, env.S3PublicURL), nil
+	}
+	return NewLocal(env.StorageLocalDir, env.StoragePublicURL)
+}
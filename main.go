@@ -2,12 +2,18 @@ package main
 
 import (
 	"api-estoque/internal/config"
+	"api-estoque/internal/consumer"
 	"api-estoque/internal/controllers"
+	"api-estoque/internal/grpcserver"
+	"api-estoque/internal/middleware/idempotency"
+	"api-estoque/internal/publisher"
 	"api-estoque/internal/repositories"
 	"api-estoque/internal/router"
 	"api-estoque/internal/services"
+	"api-estoque/internal/storage"
 	"api-estoque/internal/utils"
 	"context"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -29,14 +35,58 @@ func main() {
 	// Repositories
 	repos := repositories.InstanciateRepositories()
 
+	// "Product"."Price" e a lista de precos precisam concordar sobre a moeda padrao
+	missing, err := repos.ProductRepository.MissingPrices(config.Env.DefaultCurrency)
+	if err != nil {
+		logger.Fatalf("Falha ao verificar precos na moeda padrao: %v", err)
+	}
+	if missing > 0 {
+		logger.Warnf("%d produtos sem preco na moeda padrao %s; rode a migration 0028 com estoque.default_currency = '%s'", missing, config.Env.DefaultCurrency, config.Env.DefaultCurrency)
+	}
+
+	// Armazenamento de arquivos enviados
+	store, err := storage.New()
+	if err != nil {
+		logger.Fatalf("Falha ao iniciar armazenamento de arquivos: %v", err)
+	}
+
+	// Publicacao dos eventos do outbox
+	eventPublisher, err := publisher.New()
+	if err != nil {
+		logger.Fatalf("Falha ao iniciar publicacao de eventos: %v", err)
+	}
+
+	// Consumo dos eventos do servico de pedidos
+	orderConsumer, err := consumer.New()
+	if err != nil {
+		logger.Fatalf("Falha ao iniciar consumo de eventos de pedido: %v", err)
+	}
+
 	// Services
-	srvcs := services.InstanciateServices(repos, logger)
+	srvcs := services.InstanciateServices(repos, store, eventPublisher, orderConsumer, logger)
 
 	// Controllers
 	ctrls := controllers.InstanciateControllers(srvcs, logger)
 
+	// Workers em background
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	srvcs.ProductService.StartPriceScheduler(workersCtx, config.Env.PriceScheduleInterval)
+	srvcs.OutboxService.StartDispatcher(workersCtx, config.Env.OutboxInterval)
+	srvcs.OutboxService.StartCleanup(workersCtx, time.Hour, config.Env.OutboxRetention)
+	srvcs.WebhookService.StartDelivery(workersCtx, config.Env.WebhookInterval)
+	srvcs.OrderSagaService.Start(workersCtx)
+	srvcs.OrderSagaService.StartReplies(workersCtx, 10*time.Second)
+	srvcs.OrderSagaService.StartCleanup(workersCtx, time.Hour, config.Env.OrderInboxRetention)
+	srvcs.TccService.StartExpiry(workersCtx, config.Env.TccExpiryInterval)
+	srvcs.TccService.StartCleanup(workersCtx, time.Hour, config.Env.TccRetention)
+
+	// Middlewares
+	idempotencyMiddleware := idempotency.New(repos.IdempotencyRepository, config.Env.IdempotencyKeyTTL, config.Env.IdempotencyLockTimeout, logger)
+	idempotencyMiddleware.StartCleanup(workersCtx, time.Hour)
+
 	// Router
-	router := router.New(logger, ctrls)
+	router := router.New(logger, ctrls, idempotencyMiddleware)
 	router.Run()
 
 	// Iniciar servidor http
@@ -44,6 +94,7 @@ func main() {
 		Addr:    ":8080",
 		Handler: router.Router,
 	}
+	server.RegisterOnShutdown(srvcs.StreamService.Shutdown)
 	go func() {
 		logger.Info("App iniciado. Servindo api-estoque na porta: 8080")
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -51,6 +102,19 @@ func main() {
 		}
 	}()
 
+	// Iniciar servidor gRPC
+	grpcServer := grpcserver.New(srvcs, repos.IdempotencyRepository, logger)
+	go func() {
+		listener, err := net.Listen("tcp", ":"+config.Env.GrpcPort)
+		if err != nil {
+			logger.Fatalf("Falha ao abrir porta %s do servidor gRPC: %v", config.Env.GrpcPort, err)
+		}
+		logger.Infof("Servindo gRPC na porta: %s", config.Env.GrpcPort)
+		if err := grpcServer.Serve(listener); err != nil {
+			logger.Fatalf("Falha ao iniciar servidor gRPC na porta %s: %v", config.Env.GrpcPort, err)
+		}
+	}()
+
 	// Hook de shutdown:
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
@@ -70,5 +134,19 @@ func main() {
 		logger.Info("Servidor HTTP finalizado com sucesso.")
 	}
 
+	// Fechar servidor gRPC, interrompendo as chamadas que passarem do timeout
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+		logger.Info("Servidor gRPC finalizado com sucesso.")
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+		logger.Warn("Servidor gRPC finalizado a forca apos o timeout.")
+	}
+
 	logger.Info("Shutdown completo. Saindo.")
 }
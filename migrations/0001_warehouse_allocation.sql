@@ -0,0 +1,4 @@
+-- Prioridade e regiao (UF) de atendimento dos galpoes, usadas pelo motor de alocacao.
+ALTER TABLE "Warehouse"
+    ADD COLUMN IF NOT EXISTS "Region" varchar(2),
+    ADD COLUMN IF NOT EXISTS "Priority" integer NOT NULL DEFAULT 100;
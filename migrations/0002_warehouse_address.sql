@@ -0,0 +1,18 @@
+-- Endereco estruturado e coordenadas dos galpoes.
+ALTER TABLE "Warehouse"
+    ADD COLUMN IF NOT EXISTS "Street" text,
+    ADD COLUMN IF NOT EXISTS "Number" text,
+    ADD COLUMN IF NOT EXISTS "Complement" text,
+    ADD COLUMN IF NOT EXISTS "District" text,
+    ADD COLUMN IF NOT EXISTS "City" text,
+    ADD COLUMN IF NOT EXISTS "State" varchar(2),
+    ADD COLUMN IF NOT EXISTS "ZipCode" varchar(8),
+    ADD COLUMN IF NOT EXISTS "Latitude" double precision,
+    ADD COLUMN IF NOT EXISTS "Longitude" double precision;
+
+ALTER TABLE "Warehouse"
+    ADD CONSTRAINT "Warehouse_ZipCode_check" CHECK ("ZipCode" ~ '^[0-9]{8}$'),
+    ADD CONSTRAINT "Warehouse_Coordinates_check" CHECK (
+        ("Latitude" IS NULL AND "Longitude" IS NULL)
+        OR ("Latitude" BETWEEN -90 AND 90 AND "Longitude" BETWEEN -180 AND 180)
+    );
@@ -0,0 +1,10 @@
+-- Dimensoes (mm) e peso (g) dos produtos e capacidade dos galpoes.
+ALTER TABLE "Product"
+    ADD COLUMN IF NOT EXISTS "LengthMm" bigint CHECK ("LengthMm" > 0),
+    ADD COLUMN IF NOT EXISTS "WidthMm" bigint CHECK ("WidthMm" > 0),
+    ADD COLUMN IF NOT EXISTS "HeightMm" bigint CHECK ("HeightMm" > 0),
+    ADD COLUMN IF NOT EXISTS "WeightGrams" bigint CHECK ("WeightGrams" > 0);
+
+ALTER TABLE "Warehouse"
+    ADD COLUMN IF NOT EXISTS "VolumeCapacityLiters" bigint CHECK ("VolumeCapacityLiters" > 0),
+    ADD COLUMN IF NOT EXISTS "WeightCapacityKg" bigint CHECK ("WeightCapacityKg" > 0);
@@ -0,0 +1,5 @@
+-- Galpoes passam a ser encerrados em vez de removidos.
+ALTER TABLE "Warehouse"
+    ADD COLUMN IF NOT EXISTS "Status" varchar(16) NOT NULL DEFAULT 'active'
+        CHECK ("Status" IN ('active', 'closed')),
+    ADD COLUMN IF NOT EXISTS "ClosedAt" timestamptz;
@@ -0,0 +1,7 @@
+-- Ciclo de vida dos produtos. "IsActive" e mantido em sincronia com "Status"
+-- para os clientes que ainda leem a coluna antiga.
+ALTER TABLE "Product"
+    ADD COLUMN IF NOT EXISTS "Status" varchar(16) NOT NULL DEFAULT 'active'
+        CHECK ("Status" IN ('draft', 'active', 'discontinued', 'archived'));
+
+UPDATE "Product" SET "Status" = 'discontinued' WHERE NOT "IsActive";
@@ -0,0 +1,14 @@
+-- Estorno de movimentacoes: o razao passa a ser somente de insercao.
+ALTER TABLE "StockMoves"
+    ADD COLUMN IF NOT EXISTS "ReversalOf" uuid UNIQUE REFERENCES "StockMoves" ("Id");
+
+CREATE OR REPLACE FUNCTION stock_moves_forbid_delete() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'StockMoves e somente de insercao, use um estorno';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS "StockMoves_forbid_delete" ON "StockMoves";
+CREATE TRIGGER "StockMoves_forbid_delete"
+    BEFORE DELETE ON "StockMoves"
+    FOR EACH ROW EXECUTE FUNCTION stock_moves_forbid_delete();
@@ -0,0 +1,14 @@
+-- Chaves de idempotencia das mutacoes de estoque e a resposta gravada para replay.
+CREATE TABLE IF NOT EXISTS "IdempotencyKeys" (
+    "Scope"        text        NOT NULL,
+    "Key"          text        NOT NULL,
+    "RequestHash"  text        NOT NULL,
+    "StatusCode"   integer,
+    "ContentType"  text,
+    "ResponseBody" bytea,
+    "CreatedAt"    timestamptz NOT NULL DEFAULT now(),
+    "ExpiresAt"    timestamptz NOT NULL,
+    PRIMARY KEY ("Scope", "Key")
+);
+
+CREATE INDEX IF NOT EXISTS "IdempotencyKeys_ExpiresAt_idx" ON "IdempotencyKeys" ("ExpiresAt");
@@ -0,0 +1,28 @@
+-- Versao por linha para controle de concorrencia otimista (ETag / If-Match).
+-- O trigger incrementa a versao em qualquer UPDATE, inclusive os feitos por
+-- transferencias e reservas.
+ALTER TABLE "StockItems" ADD COLUMN IF NOT EXISTS "Version" bigint NOT NULL DEFAULT 1;
+ALTER TABLE "Product" ADD COLUMN IF NOT EXISTS "Version" bigint NOT NULL DEFAULT 1;
+ALTER TABLE "Warehouse" ADD COLUMN IF NOT EXISTS "Version" bigint NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_row_version() RETURNS trigger AS $$
+BEGIN
+    NEW."Version" := OLD."Version" + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS "StockItems_bump_version" ON "StockItems";
+CREATE TRIGGER "StockItems_bump_version"
+    BEFORE UPDATE ON "StockItems"
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+DROP TRIGGER IF EXISTS "Product_bump_version" ON "Product";
+CREATE TRIGGER "Product_bump_version"
+    BEFORE UPDATE ON "Product"
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+DROP TRIGGER IF EXISTS "Warehouse_bump_version" ON "Warehouse";
+CREATE TRIGGER "Warehouse_bump_version"
+    BEFORE UPDATE ON "Warehouse"
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
@@ -0,0 +1,8 @@
+-- Indices para a paginacao por cursor (keyset): cada lista e ordenada por
+-- ("CreatedAt" DESC, "Id" DESC), e itens de estoque pela
+-- chave unica ("ProductId", "WarehouseId"), que ja possui indice.
+CREATE INDEX IF NOT EXISTS "Product_CreatedAt_Id_idx" ON "Product" ("CreatedAt" DESC, "Id" DESC);
+CREATE INDEX IF NOT EXISTS "Warehouse_CreatedAt_Id_idx" ON "Warehouse" ("CreatedAt" DESC, "Id" DESC);
+CREATE INDEX IF NOT EXISTS "StockMoves_CreatedAt_Id_idx" ON "StockMoves" ("CreatedAt" DESC, "Id" DESC);
+CREATE INDEX IF NOT EXISTS "StockMoves_ProductId_CreatedAt_Id_idx" ON "StockMoves" ("ProductId", "CreatedAt" DESC, "Id" DESC);
+CREATE INDEX IF NOT EXISTS "StockMoves_WarehouseId_CreatedAt_Id_idx" ON "StockMoves" ("WarehouseId", "CreatedAt" DESC, "Id" DESC);
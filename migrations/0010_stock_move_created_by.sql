@@ -0,0 +1,6 @@
+-- Usuario (email do token) que registrou a movimentacao, para auditoria do razao.
+-- Movimentacoes anteriores ficam sem autor.
+ALTER TABLE "StockMoves" ADD COLUMN IF NOT EXISTS "CreatedBy" text;
+
+CREATE INDEX IF NOT EXISTS "StockMoves_CreatedBy_CreatedAt_idx" ON "StockMoves" ("CreatedBy", "CreatedAt" DESC);
+CREATE INDEX IF NOT EXISTS "StockMoves_QtyMoved_Id_idx" ON "StockMoves" ("QtyMoved", "Id");
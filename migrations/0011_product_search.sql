@@ -0,0 +1,17 @@
+-- Busca textual de produtos: tsvector em portugues sobre nome, categoria e
+-- descricao, sem acentos. unaccent nao e IMMUTABLE, por isso o wrapper abaixo
+-- fixa o dicionario para poder ser usado na coluna gerada e no indice.
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text AS $$
+    SELECT public.unaccent('public.unaccent', $1)
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+ALTER TABLE "Product" ADD COLUMN IF NOT EXISTS "SearchVector" tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('portuguese', immutable_unaccent(coalesce("Name", ''))), 'A') ||
+        setweight(to_tsvector('portuguese', immutable_unaccent(coalesce("Category", ''))), 'B') ||
+        setweight(to_tsvector('portuguese', immutable_unaccent(coalesce("Description", ''))), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS "Product_SearchVector_idx" ON "Product" USING GIN ("SearchVector");
@@ -0,0 +1,82 @@
+-- Categorias passam a ser uma entidade hierarquica. O Slug normaliza o nome
+-- (sem acentos, minusculo, espacos colapsados) e e unico entre irmaos, o que
+-- impede "Eletrônicos", "eletronicos" e "Eletronicos " no mesmo nivel.
+CREATE TABLE IF NOT EXISTS "Category" (
+    "Id"        uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    "Name"      text NOT NULL CHECK (btrim("Name") <> ''),
+    "Slug"      text GENERATED ALWAYS AS (
+                    lower(immutable_unaccent(regexp_replace(btrim("Name"), '\s+', ' ', 'g')))
+                ) STORED,
+    "ParentId"  uuid REFERENCES "Category" ("Id") ON DELETE RESTRICT,
+    "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+    CHECK ("ParentId" IS DISTINCT FROM "Id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "Category_ParentId_Slug_idx"
+    ON "Category" (coalesce("ParentId", '00000000-0000-0000-0000-000000000000'::uuid), "Slug");
+CREATE INDEX IF NOT EXISTS "Category_ParentId_idx" ON "Category" ("ParentId");
+
+-- Cada variacao de grafia vira uma unica categoria raiz, com o nome na grafia
+-- mais usada. Produtos sem categoria vao para "Sem categoria".
+UPDATE "Product" SET "Category" = 'Sem categoria' WHERE "Category" IS NULL OR btrim("Category") = '';
+
+INSERT INTO "Category" ("Name")
+SELECT DISTINCT ON (slug) name
+FROM (
+    SELECT regexp_replace(btrim("Category"), '\s+', ' ', 'g') AS name,
+           lower(immutable_unaccent(regexp_replace(btrim("Category"), '\s+', ' ', 'g'))) AS slug,
+           count(*) AS uses
+    FROM "Product"
+    GROUP BY 1, 2
+) spellings
+ORDER BY slug, uses DESC, name
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE "Product" ADD COLUMN IF NOT EXISTS "CategoryId" uuid REFERENCES "Category" ("Id") ON DELETE RESTRICT;
+
+UPDATE "Product" p
+SET "CategoryId" = c."Id"
+FROM "Category" c
+WHERE c."ParentId" IS NULL
+  AND c."Slug" = lower(immutable_unaccent(regexp_replace(btrim(p."Category"), '\s+', ' ', 'g')));
+
+ALTER TABLE "Product" ALTER COLUMN "CategoryId" SET NOT NULL;
+CREATE INDEX IF NOT EXISTS "Product_CategoryId_idx" ON "Product" ("CategoryId");
+
+-- O vetor de busca deixa de ser coluna gerada, pois o nome da categoria agora
+-- vem de outra tabela; triggers mantem o vetor atualizado.
+ALTER TABLE "Product" DROP COLUMN IF EXISTS "SearchVector";
+ALTER TABLE "Product" DROP COLUMN IF EXISTS "Category";
+ALTER TABLE "Product" ADD COLUMN "SearchVector" tsvector;
+
+CREATE OR REPLACE FUNCTION product_search_vector() RETURNS trigger AS $$
+BEGIN
+    NEW."SearchVector" :=
+        setweight(to_tsvector('portuguese', immutable_unaccent(coalesce(NEW."Name", ''))), 'A') ||
+        setweight(to_tsvector('portuguese', immutable_unaccent(coalesce(
+            (SELECT "Name" FROM "Category" WHERE "Id" = NEW."CategoryId"), ''))), 'B') ||
+        setweight(to_tsvector('portuguese', immutable_unaccent(coalesce(NEW."Description", ''))), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS "Product_search_vector" ON "Product";
+CREATE TRIGGER "Product_search_vector"
+    BEFORE INSERT OR UPDATE OF "Name", "Description", "CategoryId" ON "Product"
+    FOR EACH ROW EXECUTE FUNCTION product_search_vector();
+
+CREATE OR REPLACE FUNCTION category_refresh_search_vector() RETURNS trigger AS $$
+BEGIN
+    UPDATE "Product" SET "CategoryId" = "CategoryId" WHERE "CategoryId" = NEW."Id";
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS "Category_refresh_search_vector" ON "Category";
+CREATE TRIGGER "Category_refresh_search_vector"
+    AFTER UPDATE OF "Name" ON "Category"
+    FOR EACH ROW EXECUTE FUNCTION category_refresh_search_vector();
+
+UPDATE "Product" SET "CategoryId" = "CategoryId";
+
+CREATE INDEX IF NOT EXISTS "Product_SearchVector_idx" ON "Product" USING GIN ("SearchVector");
@@ -0,0 +1,35 @@
+-- ImagesJson passa a ter formato fixo: lista de objetos
+-- {url, thumbnailUrl?, alt, position, primary}.
+-- Valores antigos sao convertidos: listas de strings viram imagens na ordem em
+-- que aparecem, objetos com 'url' sao mantidos e o restante e descartado.
+ALTER TABLE "Product" ALTER COLUMN "ImagesJson" TYPE jsonb USING "ImagesJson"::jsonb;
+
+UPDATE "Product" p
+SET "ImagesJson" = coalesce((
+    SELECT jsonb_agg(jsonb_build_object(
+               'url', img.url,
+               'alt', coalesce(img.alt, ''),
+               'position', img.ord - 1,
+               'primary', img.ord = 1
+           ) ORDER BY img.ord)
+    FROM (
+        SELECT CASE jsonb_typeof(e.value)
+                   WHEN 'string' THEN e.value #>> '{}'
+                   WHEN 'object' THEN e.value ->> 'url'
+               END AS url,
+               CASE WHEN jsonb_typeof(e.value) = 'object' THEN e.value ->> 'alt' END AS alt,
+               row_number() OVER (ORDER BY e.ord) AS ord
+        FROM jsonb_array_elements(p."ImagesJson") WITH ORDINALITY AS e(value, ord)
+        WHERE (jsonb_typeof(e.value) = 'string' AND e.value #>> '{}' <> '')
+           OR (jsonb_typeof(e.value) = 'object' AND coalesce(e.value ->> 'url', '') <> '')
+    ) img
+), '[]'::jsonb)
+WHERE jsonb_typeof(p."ImagesJson") = 'array';
+
+UPDATE "Product"
+SET "ImagesJson" = '[]'::jsonb
+WHERE "ImagesJson" IS NOT NULL AND jsonb_typeof("ImagesJson") <> 'array';
+
+ALTER TABLE "Product" DROP CONSTRAINT IF EXISTS "Product_ImagesJson_array_chk";
+ALTER TABLE "Product" ADD CONSTRAINT "Product_ImagesJson_array_chk"
+    CHECK ("ImagesJson" IS NULL OR jsonb_typeof("ImagesJson") = 'array');
@@ -0,0 +1,38 @@
+-- Historico de precos: cada alteracao de "Product"."Price" gera uma linha com o
+-- momento em que o preco passou a valer e o usuario que fez a alteracao.
+CREATE TABLE IF NOT EXISTS "ProductPriceHistory" (
+    "Id"          uuid        PRIMARY KEY DEFAULT gen_random_uuid(),
+    "ProductId"   uuid        NOT NULL REFERENCES "Product" ("Id") ON DELETE CASCADE,
+    "Price"       bigint      NOT NULL CHECK ("Price" > 0),
+    "EffectiveAt" timestamptz NOT NULL DEFAULT now(),
+    "ChangedBy"   text,
+    "ScheduleId"  uuid
+);
+
+CREATE INDEX IF NOT EXISTS "ProductPriceHistory_ProductId_EffectiveAt_idx"
+    ON "ProductPriceHistory" ("ProductId", "EffectiveAt" DESC, "Id" DESC);
+
+-- Ponto de partida do historico: o preco atual, valendo desde a criacao do produto.
+INSERT INTO "ProductPriceHistory" ("ProductId", "Price", "EffectiveAt")
+SELECT p."Id", p."Price", p."CreatedAt"
+FROM "Product" p
+WHERE NOT EXISTS (SELECT 1 FROM "ProductPriceHistory" h WHERE h."ProductId" = p."Id");
+
+-- Alteracoes de preco agendadas. Ficam pendentes ate o worker aplica-las
+-- (AppliedAt) ou ate serem canceladas (CanceledAt).
+CREATE TABLE IF NOT EXISTS "ProductPriceSchedule" (
+    "Id"          uuid        PRIMARY KEY DEFAULT gen_random_uuid(),
+    "ProductId"   uuid        NOT NULL REFERENCES "Product" ("Id") ON DELETE CASCADE,
+    "Price"       bigint      NOT NULL CHECK ("Price" > 0),
+    "EffectiveAt" timestamptz NOT NULL,
+    "CreatedBy"   text,
+    "CreatedAt"   timestamptz NOT NULL DEFAULT now(),
+    "AppliedAt"   timestamptz,
+    "CanceledAt"  timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS "ProductPriceSchedule_pending_idx"
+    ON "ProductPriceSchedule" ("EffectiveAt")
+    WHERE "AppliedAt" IS NULL AND "CanceledAt" IS NULL;
+CREATE INDEX IF NOT EXISTS "ProductPriceSchedule_ProductId_idx"
+    ON "ProductPriceSchedule" ("ProductId", "EffectiveAt");
@@ -0,0 +1,24 @@
+-- Lista de precos por moeda. "Amount" fica na menor unidade da moeda (centavos
+-- para BRL, guaranis inteiros para PYG). "Product"."Price" continua guardando o
+-- preco na moeda padrao (DEFAULT_CURRENCY), mantido pela API em sincronia com a
+-- linha correspondente desta tabela.
+CREATE TABLE IF NOT EXISTS "ProductPrice" (
+    "ProductId" uuid        NOT NULL REFERENCES "Product" ("Id") ON DELETE CASCADE,
+    "Currency"  char(3)     NOT NULL CHECK ("Currency" ~ '^[A-Z]{3}$'),
+    "Amount"    bigint      NOT NULL CHECK ("Amount" > 0),
+    "UpdatedAt" timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY ("ProductId", "Currency")
+);
+
+-- Os precos existentes estao em reais.
+INSERT INTO "ProductPrice" ("ProductId", "Currency", "Amount")
+SELECT "Id", 'BRL', "Price"
+FROM "Product"
+ON CONFLICT DO NOTHING;
+
+-- O historico passa a registrar a moeda de cada alteracao.
+ALTER TABLE "ProductPriceHistory" ADD COLUMN IF NOT EXISTS "Currency" char(3) NOT NULL DEFAULT 'BRL';
+
+DROP INDEX IF EXISTS "ProductPriceHistory_ProductId_EffectiveAt_idx";
+CREATE INDEX IF NOT EXISTS "ProductPriceHistory_ProductId_Currency_EffectiveAt_idx"
+    ON "ProductPriceHistory" ("ProductId", "Currency", "EffectiveAt" DESC, "Id" DESC);
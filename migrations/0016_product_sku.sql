@@ -0,0 +1,6 @@
+-- Codigo externo do produto (SKU), chave usada pela sincronizacao de catalogo.
+-- Opcional para os produtos ja cadastrados, unico quando informado.
+ALTER TABLE "Product" ADD COLUMN IF NOT EXISTS "Sku" text CHECK ("Sku" ~ '^\S{1,64}$');
+
+ALTER TABLE "Product" DROP CONSTRAINT IF EXISTS "Product_Sku_key";
+ALTER TABLE "Product" ADD CONSTRAINT "Product_Sku_key" UNIQUE ("Sku");
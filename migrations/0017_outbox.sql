@@ -0,0 +1,30 @@
+-- Outbox de eventos de dominio. Cada mutacao de estoque ou de produto grava o
+-- evento na mesma transacao da alteracao; o dispatcher publica os pendentes em
+-- ordem de "Id" e marca "PublishedAt". "ProductId" e a chave de ordenacao: os
+-- eventos de um produto so sao publicados depois dos anteriores.
+-- "TxId" e a transacao que gravou o evento. O dispatcher so le eventos de
+-- transacoes mais antigas que todas as ainda abertas, para que um "Id" menor
+-- gravado por uma transacao lenta nao apareca depois de um maior ja publicado.
+CREATE TABLE IF NOT EXISTS "Outbox" (
+    "Id"            bigserial   PRIMARY KEY,
+    "EventId"       uuid        NOT NULL DEFAULT gen_random_uuid() UNIQUE,
+    "Type"          text        NOT NULL,
+    "ProductId"     uuid        NOT NULL,
+    "WarehouseId"   uuid,
+    "Payload"       jsonb       NOT NULL,
+    "OccurredAt"    timestamptz NOT NULL DEFAULT now(),
+    "TxId"          xid8        NOT NULL DEFAULT pg_current_xact_id(),
+    "PublishedAt"   timestamptz,
+    "Attempts"      integer     NOT NULL DEFAULT 0,
+    "NextAttemptAt" timestamptz NOT NULL DEFAULT now(),
+    "LastError"     text
+);
+
+CREATE INDEX IF NOT EXISTS "Outbox_pending_idx"
+    ON "Outbox" ("Id") WHERE "PublishedAt" IS NULL;
+
+CREATE INDEX IF NOT EXISTS "Outbox_pending_ProductId_idx"
+    ON "Outbox" ("ProductId", "Id") WHERE "PublishedAt" IS NULL;
+
+CREATE INDEX IF NOT EXISTS "Outbox_PublishedAt_idx"
+    ON "Outbox" ("PublishedAt") WHERE "PublishedAt" IS NOT NULL;
@@ -0,0 +1,44 @@
+-- Assinaturas de webhook. Listas vazias em "EventTypes", "WarehouseIds" e
+-- "ProductIds" significam sem filtro. Eventos de produto nao tem galpao, entao
+-- nao chegam a assinaturas filtradas por galpao.
+-- "ConsecutiveFailures" conta as tentativas que falharam desde a ultima entrega
+-- bem sucedida; ao atingir o limite a assinatura e desativada.
+CREATE TABLE IF NOT EXISTS "WebhookSubscription" (
+    "Id"                  uuid        PRIMARY KEY DEFAULT gen_random_uuid(),
+    "Url"                 text        NOT NULL,
+    "Secret"              text        NOT NULL,
+    "EventTypes"          text[]      NOT NULL DEFAULT '{}',
+    "WarehouseIds"        uuid[]      NOT NULL DEFAULT '{}',
+    "ProductIds"          uuid[]      NOT NULL DEFAULT '{}',
+    "IsActive"            boolean     NOT NULL DEFAULT true,
+    "ConsecutiveFailures" integer     NOT NULL DEFAULT 0,
+    "DisabledAt"          timestamptz,
+    "DisabledReason"      text,
+    "CreatedBy"           text,
+    "CreatedAt"           timestamptz NOT NULL DEFAULT now(),
+    "UpdatedAt"           timestamptz NOT NULL DEFAULT now()
+);
+
+-- Uma entrega por assinatura e evento. O outbox pode publicar o mesmo evento
+-- mais de uma vez; a chave unica evita entregas duplicadas.
+CREATE TABLE IF NOT EXISTS "WebhookDelivery" (
+    "Id"             uuid        PRIMARY KEY DEFAULT gen_random_uuid(),
+    "SubscriptionId" uuid        NOT NULL REFERENCES "WebhookSubscription" ("Id") ON DELETE CASCADE,
+    "EventId"        uuid        NOT NULL,
+    "EventType"      text        NOT NULL,
+    "Payload"        jsonb       NOT NULL,
+    "Status"         text        NOT NULL DEFAULT 'pending' CHECK ("Status" IN ('pending', 'delivered', 'failed')),
+    "Attempts"       integer     NOT NULL DEFAULT 0,
+    "NextAttemptAt"  timestamptz NOT NULL DEFAULT now(),
+    "LastStatusCode" integer,
+    "LastError"      text,
+    "DeliveredAt"    timestamptz,
+    "CreatedAt"      timestamptz NOT NULL DEFAULT now(),
+    UNIQUE ("SubscriptionId", "EventId")
+);
+
+CREATE INDEX IF NOT EXISTS "WebhookDelivery_pending_idx"
+    ON "WebhookDelivery" ("NextAttemptAt") WHERE "Status" = 'pending';
+
+CREATE INDEX IF NOT EXISTS "WebhookDelivery_SubscriptionId_CreatedAt_idx"
+    ON "WebhookDelivery" ("SubscriptionId", "CreatedAt" DESC, "Id" DESC);
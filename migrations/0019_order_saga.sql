@@ -0,0 +1,30 @@
+-- Reserva de estoque de cada pedido acompanhado pela saga. "Lines" guarda as
+-- linhas reservadas no OrderPlaced, usadas depois na baixa (OrderPaid) ou na
+-- liberacao (OrderCancelled). Um cancelamento que chega antes do pedido grava
+-- a reserva ja como 'released', para que o OrderPlaced atrasado seja recusado.
+CREATE TABLE IF NOT EXISTS "OrderReservation" (
+    "OrderId"   text        PRIMARY KEY,
+    "Status"    text        NOT NULL CHECK ("Status" IN ('reserved', 'committed', 'released')),
+    "Lines"     jsonb       NOT NULL DEFAULT '[]'::jsonb,
+    "CreatedAt" timestamptz NOT NULL DEFAULT now(),
+    "UpdatedAt" timestamptz NOT NULL DEFAULT now()
+);
+
+-- Inbox das mensagens de pedido ja processadas. A chave e o id da mensagem:
+-- entregas repetidas nao alteram o estoque de novo e recebem a mesma resposta.
+-- A resposta e gravada na mesma transacao da alteracao de estoque;
+-- "RepliedAt" fica nulo ate ela ser publicada.
+CREATE TABLE IF NOT EXISTS "OrderInbox" (
+    "MessageId"  text        PRIMARY KEY,
+    "Type"       text        NOT NULL,
+    "OrderId"    text        NOT NULL,
+    "Reply"      jsonb       NOT NULL,
+    "ReceivedAt" timestamptz NOT NULL DEFAULT now(),
+    "RepliedAt"  timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS "OrderInbox_pending_idx"
+    ON "OrderInbox" ("ReceivedAt") WHERE "RepliedAt" IS NULL;
+
+CREATE INDEX IF NOT EXISTS "OrderInbox_ReceivedAt_idx"
+    ON "OrderInbox" ("ReceivedAt");
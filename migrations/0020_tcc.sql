@@ -0,0 +1,22 @@
+-- Transacoes TCC (Try/Confirm/Cancel) do checkout distribuido, chaveadas pelo
+-- id global da transacao definido pelo coordenador. O Try reserva "Lines" ate
+-- "ExpiresAt"; o Confirm transforma a reserva em baixa e o Cancel a libera.
+-- Um Cancel que chega antes do Try grava a transacao ja como 'cancelled', para
+-- que o Try atrasado seja recusado. Tries vencidos sao cancelados sozinhos,
+-- com "CancelReason" 'expired'.
+CREATE TABLE IF NOT EXISTS "TccTransaction" (
+    "TxId"         text        PRIMARY KEY,
+    "Status"       text        NOT NULL CHECK ("Status" IN ('tried', 'confirmed', 'cancelled')),
+    "Lines"        jsonb       NOT NULL DEFAULT '[]'::jsonb,
+    "ExpiresAt"    timestamptz,
+    "CancelReason" text        CHECK ("CancelReason" IN ('requested', 'expired')),
+    "CreatedBy"    text,
+    "CreatedAt"    timestamptz NOT NULL DEFAULT now(),
+    "UpdatedAt"    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS "TccTransaction_expiry_idx"
+    ON "TccTransaction" ("ExpiresAt") WHERE "Status" = 'tried';
+
+CREATE INDEX IF NOT EXISTS "TccTransaction_finished_idx"
+    ON "TccTransaction" ("UpdatedAt") WHERE "Status" <> 'tried';
@@ -0,0 +1,10 @@
+-- Baixas registradas antes do ciclo de vida dos produtos gravavam "QtyMoved"
+-- positivo. Passam a ser negativas como as atuais, para que o estorno devolva
+-- o saldo e os filtros de entrada/saida do razao as classifiquem como saida.
+-- Estornos nao sao alterados: o de uma baixa antiga foi aplicado com o sinal
+-- invertido, e com a baixa negativa o razao volta a bater com o saldo.
+UPDATE "StockMoves"
+SET "QtyMoved" = -"QtyMoved"
+WHERE "Reason" = 'Baixa de estoque'
+  AND "QtyMoved" > 0
+  AND "ReversalOf" IS NULL;
@@ -0,0 +1,15 @@
+-- Indica se a movimentacao alterou o saldo em "StockItems". As criadas por
+-- POST /stock-move so registram no razao; as baixas, transferencias por
+-- encerramento de galpao e estornos alteram o saldo. O estorno de uma
+-- movimentacao que nao alterou o saldo tambem fica so no razao.
+ALTER TABLE "StockMoves" ADD COLUMN IF NOT EXISTS "AppliedToStock" boolean;
+
+UPDATE "StockMoves"
+SET "AppliedToStock" = "ReversalOf" IS NOT NULL
+    OR "Reason" = 'Baixa de estoque'
+    OR "Reason" LIKE 'Transferencia por encerramento do galpao %'
+WHERE "AppliedToStock" IS NULL;
+
+ALTER TABLE "StockMoves"
+    ALTER COLUMN "AppliedToStock" SET DEFAULT true,
+    ALTER COLUMN "AppliedToStock" SET NOT NULL;
@@ -0,0 +1,2 @@
+-- ETag da resposta gravada, devolvido no replay junto com o body.
+ALTER TABLE "IdempotencyKeys" ADD COLUMN IF NOT EXISTS "ETag" text;
@@ -0,0 +1,4 @@
+-- O log de entregas deixa de guardar o corpo das respostas de erro dos
+-- receptores. As tentativas antigas ficam so com a linha de status.
+UPDATE "WebhookDelivery" SET "LastError" = split_part("LastError", ': ', 1)
+WHERE "LastStatusCode" IS NOT NULL AND "LastError" LIKE '%: %';
@@ -0,0 +1,21 @@
+-- Sequencia de publicacao do outbox. O dispatcher nao publica em ordem de "Id"
+-- (um evento que falha espera, os de outros produtos seguem), entao "Id" nao
+-- serve de cursor para a retomada do stream. "PublishSeq" e atribuido pelo
+-- dispatcher, sob o advisory lock, a cada tentativa de publicacao; cresce na
+-- ordem em que os eventos ficam visiveis como publicados e pode ter lacunas.
+CREATE SEQUENCE IF NOT EXISTS "Outbox_PublishSeq_seq";
+
+ALTER TABLE "Outbox" ADD COLUMN IF NOT EXISTS "PublishSeq" bigint;
+
+-- Os eventos ja publicados mantem o "Id" como sequencia, para que os
+-- Last-Event-ID ja entregues continuem validos; os proximos comecam depois.
+UPDATE "Outbox" SET "PublishSeq" = "Id"
+WHERE "PublishedAt" IS NOT NULL AND "PublishSeq" IS NULL;
+
+SELECT setval('"Outbox_PublishSeq_seq"', greatest(
+    (SELECT coalesce(max("Id"), 0) + 1 FROM "Outbox"),
+    (SELECT CASE WHEN is_called THEN last_value + 1 ELSE last_value END FROM "Outbox_PublishSeq_seq")
+), false);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "Outbox_PublishSeq_idx"
+    ON "Outbox" ("PublishSeq") WHERE "PublishSeq" IS NOT NULL;
@@ -0,0 +1,18 @@
+-- "Region" (UF usada na alocacao) e "State" (UF do endereco) guardavam a mesma
+-- informacao e podiam divergir. "Region" passa a ser a unica UF gravada e
+-- "State" e derivada dela. Onde as duas divergiam vale a UF do endereco.
+UPDATE "Warehouse" SET "Region" = "State"
+WHERE "State" IS NOT NULL AND "State" IS DISTINCT FROM "Region";
+
+ALTER TABLE "Warehouse" DROP COLUMN IF EXISTS "State";
+ALTER TABLE "Warehouse" ADD COLUMN "State" varchar(2) GENERATED ALWAYS AS ("Region") STORED;
+
+-- Campos opcionais de endereco gravados vazios passam a ser nulos, como a API
+-- grava agora.
+UPDATE "Warehouse" SET
+    "Street"     = nullif("Street", ''),
+    "Number"     = nullif("Number", ''),
+    "Complement" = nullif("Complement", ''),
+    "District"   = nullif("District", ''),
+    "City"       = nullif("City", '')
+WHERE '' IN ("Street", "Number", "Complement", "District", "City");
@@ -0,0 +1,4 @@
+-- Prazo da chave em processamento. Enquanto a requisicao roda o prazo e
+-- renovado; se ela cair no meio, a chave pode ser assumida assim que ele vencer,
+-- sem esperar o ExpiresAt.
+ALTER TABLE "IdempotencyKeys" ADD COLUMN IF NOT EXISTS "LockedUntil" timestamptz;
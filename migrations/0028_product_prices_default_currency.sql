@@ -0,0 +1,35 @@
+-- A 0015 gravou os precos existentes como BRL. Em instalacoes com outra moeda
+-- padrao (DEFAULT_CURRENCY) esses precos estao na moeda errada. Esta migration
+-- os corrige lendo a moeda do parametro "estoque.default_currency", que deve ter
+-- o mesmo valor de DEFAULT_CURRENCY (ex.: SET estoque.default_currency = 'PYG'
+-- antes de rodar); sem ele, ou com BRL, nada muda alem do ultimo passo.
+
+-- Preco BRL vindo da 0015 (igual a "Product"."Price") passa para a moeda padrao
+-- quando o produto ainda nao tem preco nela.
+UPDATE "ProductPrice" pp
+SET "Currency" = current_setting('estoque.default_currency', true),
+    "UpdatedAt" = now()
+FROM "Product" p
+WHERE p."Id" = pp."ProductId"
+  AND pp."Currency" = 'BRL'
+  AND pp."Amount" = p."Price"
+  AND coalesce(current_setting('estoque.default_currency', true), '') NOT IN ('', 'BRL')
+  AND NOT EXISTS (
+      SELECT 1 FROM "ProductPrice" d
+      WHERE d."ProductId" = pp."ProductId"
+        AND d."Currency" = current_setting('estoque.default_currency', true)
+  );
+
+-- O historico BRL de produtos que ficaram sem preco BRL era da moeda padrao.
+UPDATE "ProductPriceHistory" h
+SET "Currency" = current_setting('estoque.default_currency', true)
+WHERE h."Currency" = 'BRL'
+  AND coalesce(current_setting('estoque.default_currency', true), '') NOT IN ('', 'BRL')
+  AND NOT EXISTS (
+      SELECT 1 FROM "ProductPrice" pp
+      WHERE pp."ProductId" = h."ProductId" AND pp."Currency" = 'BRL'
+  );
+
+-- A API sempre informa a moeda no historico; o default da 0015 so servia para
+-- as linhas antigas.
+ALTER TABLE "ProductPriceHistory" ALTER COLUMN "Currency" DROP DEFAULT;